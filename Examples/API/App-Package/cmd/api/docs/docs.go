@@ -15,24 +15,596 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/api/blog": {
+            "get": {
+                "description": "List all blogs",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "blog"
+                ],
+                "summary": "List Blogs",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/app.blogResponse"
+                            }
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new blog",
+                "consumes": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "blog"
+                ],
+                "summary": "Create Blog",
+                "parameters": [
+                    {
+                        "description": "Blog data",
+                        "name": "blog",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/app.blogRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/app.blogResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetailValidation"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/blog/{id}": {
+            "get": {
+                "description": "Read Blog by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "blog"
+                ],
+                "summary": "Read Blog",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Blog ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/app.blogResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update a blog by ID",
+                "consumes": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "blog"
+                ],
+                "summary": "Update Blog",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Blog ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Blog data",
+                        "name": "blog",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/app.blogRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/app.blogResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetailValidation"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a blog by ID",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "blog"
+                ],
+                "summary": "Delete Blog",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Blog ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/blog/{id}/comments": {
+            "get": {
+                "description": "List the comments on a blog, oldest first",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "comment"
+                ],
+                "summary": "List Blog Comments",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Blog ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-100, default 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of comments to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/app.commentResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetailValidation"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new comment on a blog",
+                "consumes": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "comment"
+                ],
+                "summary": "Create Comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Blog ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Comment data",
+                        "name": "comment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/app.commentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/app.commentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetailValidation"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/blog/{id}/comments/{commentId}": {
+            "delete": {
+                "description": "Delete a comment on a blog by ID",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "comment"
+                ],
+                "summary": "Delete Comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Blog ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comment ID",
+                        "name": "commentId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/problems/": {
+            "get": {
+                "description": "Human-readable index of every problem type",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "problem"
+                ],
+                "summary": "List Problem Types",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/problems/{type}": {
+            "get": {
+                "description": "Human-readable documentation of a problem type",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "problem"
+                ],
+                "summary": "Read Problem Type",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Problem type slug",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    }
+                }
+            }
+        },
         "/api/user": {
             "get": {
-                "description": "List all users",
+                "description": "List a page of users. Pass the returned next cursor to fetch the following page.",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
                 ],
                 "tags": [
                     "user"
                 ],
                 "summary": "List Users",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-100, default 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor returned as next by the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/app.user"
-                            }
+                            "$ref": "#/definitions/app.listUsersResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetailValidation"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
                         }
                     },
                     "500": {
@@ -46,10 +618,16 @@ const docTemplate = `{
             "post": {
                 "description": "Create a new user",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
                 ],
                 "tags": [
                     "user"
@@ -62,7 +640,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/app.user"
+                            "$ref": "#/definitions/app.createUserRequest"
                         }
                     }
                 ],
@@ -79,6 +657,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/app.problemDetailValidation"
                         }
                     },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetailValidation"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -95,7 +691,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
                 ],
                 "tags": [
                     "user"
@@ -129,6 +728,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/app.problemDetail"
                         }
                     },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -140,10 +745,16 @@ const docTemplate = `{
             "put": {
                 "description": "Update user fields by ID",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
                 ],
                 "tags": [
                     "user"
@@ -186,6 +797,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/app.problemDetail"
                         }
                     },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetailValidation"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -197,7 +826,10 @@ const docTemplate = `{
             "delete": {
                 "description": "Delete a user by ID",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
                 ],
                 "tags": [
                     "user"
@@ -240,6 +872,77 @@ const docTemplate = `{
                 }
             }
         },
+        "/api/user/{id}/comments": {
+            "get": {
+                "description": "List the comments written by a user, oldest first",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "comment"
+                ],
+                "summary": "List User Comments",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-100, default 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of comments to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/app.commentResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetailValidation"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    }
+                }
+            }
+        },
         "/health": {
             "get": {
                 "description": "Health Check endpoint",
@@ -247,7 +950,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "application/cbor"
                 ],
                 "tags": [
                     "health"
@@ -260,6 +966,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/app.healthResponse"
                         }
                     },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/app.problemDetail"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -271,6 +983,100 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "app.blogRequest": {
+            "type": "object",
+            "required": [
+                "authorId",
+                "title"
+            ],
+            "properties": {
+                "authorId": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 200,
+                    "minLength": 1
+                }
+            }
+        },
+        "app.blogResponse": {
+            "type": "object",
+            "properties": {
+                "authorId": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "score": {
+                    "type": "number"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "app.commentRequest": {
+            "type": "object",
+            "required": [
+                "message",
+                "userId"
+            ],
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "maxLength": 1000,
+                    "minLength": 1
+                },
+                "userId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "app.commentResponse": {
+            "type": "object",
+            "properties": {
+                "blogId": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "app.createUserRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "name",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "minLength": 2
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 30,
+                    "minLength": 8
+                }
+            }
+        },
         "app.healthResponse": {
             "type": "object",
             "properties": {
@@ -296,6 +1102,23 @@ const docTemplate = `{
                 }
             }
         },
+        "app.listUsersResponse": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "next": {
+                    "type": "string"
+                },
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/app.userResponse"
+                    }
+                }
+            }
+        },
         "app.problemDetail": {
             "type": "object",
             "properties": {
@@ -303,6 +1126,10 @@ const docTemplate = `{
                     "description": "A human-readable explanation about the occurrence of the problem.",
                     "type": "string"
                 },
+                "instance": {
+                    "description": "The path of the request the problem occurred on.",
+                    "type": "string"
+                },
                 "status": {
                     "description": "The HTTP status code generated by the origin server.",
                     "type": "integer"
@@ -314,6 +1141,10 @@ const docTemplate = `{
                 "traceId": {
                     "description": "An optional trace ID for debugging purposes.",
                     "type": "string"
+                },
+                "type": {
+                    "description": "A URI identifying the problem type.",
+                    "type": "string"
                 }
             }
         },
@@ -324,6 +1155,10 @@ const docTemplate = `{
                     "description": "A human-readable explanation about the occurrence of the problem.",
                     "type": "string"
                 },
+                "instance": {
+                    "description": "The path of the request the problem occurred on.",
+                    "type": "string"
+                },
                 "invalidParams": {
                     "description": "A list of invalid parameters with error details.",
                     "type": "array",
@@ -342,6 +1177,10 @@ const docTemplate = `{
                 "traceId": {
                     "description": "An optional trace ID for debugging purposes.",
                     "type": "string"
+                },
+                "type": {
+                    "description": "A URI identifying the problem type.",
+                    "type": "string"
                 }
             }
         },
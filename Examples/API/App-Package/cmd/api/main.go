@@ -64,8 +64,14 @@ func run(ctx context.Context) error {
 		}
 	}()
 
+	// Build the validator of request models once, to be shared by every request.
+	validate, err := app.NewValidator(logger, db, cfg.AllowedEmailDomains)
+	if err != nil {
+		return fmt.Errorf("[in main.run] failed to create validator: %w", err)
+	}
+
 	// Create the main HTTP handler and wrap it with middleware for tracing, logging, and recovery.
-	handler := app.NewHandler(logger, db, cfg.EnableSwagger)
+	handler := app.NewHandler(logger, db, validate, cfg.PasswordCost, cfg.EnableSwagger)
 
 	// Wrap the handler with middleware for tracing, logging, and recovery.
 	wrappedHandler := app.WrapHandler(
@@ -1,7 +1,6 @@
 package app
 
 import (
-	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -10,25 +9,124 @@ import (
 )
 
 // NewHandler creates and returns a new HTTP handler with all application routes registered.
-// It takes a logger and a database connection as dependencies.
-func NewHandler(logger *slog.Logger, db *sqlx.DB, enableSwagger bool) http.Handler {
+// It takes a logger, a database connection and the validator of request models, built once at
+// startup by NewValidator, as dependencies. User passwords are hashed with bcrypt using passwordCost.
+func NewHandler(
+	logger *slog.Logger,
+	db *sqlx.DB,
+	validate *Validator,
+	passwordCost int,
+	enableSwagger bool,
+) http.Handler {
 	mux := http.NewServeMux()
 
-	addRoutes(mux, logger, db, enableSwagger)
+	addRoutes(mux, logger, db, validate, passwordCost, enableSwagger)
 
 	return mux
 }
 
-// encodeResponseJSON encodes the provided data as a JSON response and writes it to the ResponseWriter.
-// It sets the Content-Type header to "application/json" and writes the specified HTTP status code.
+// encodeResponse encodes data in the media type the request's Accept header prefers and writes it to the
+// ResponseWriter with the specified HTTP status code. A successful response to a request that accepts none of our
+// media types is replaced by a 406 problem detail, while error responses are still sent, as JSON. Problem details are
+// completed by withProblemMembers and sent with the problem variant of the media type, such as
+// application/problem+json.
 // Returns an error if encoding fails.
-func encodeResponseJSON(w http.ResponseWriter, status int, data any) error {
-	w.Header().Set("Content-Type", "application/json")
+func encodeResponse(w http.ResponseWriter, r *http.Request, status int, data any) error {
+	w.Header().Add("Vary", "Accept")
+
+	c, ok := responseCodec(r)
+	if !ok {
+		c = codecs[0]
+		if status < http.StatusBadRequest {
+			status, data = http.StatusNotAcceptable, newNotAcceptableProblem(r)
+		}
+	}
+
+	mediaType := c.mediaType
+	if problem, ok := withProblemMembers(w, r, status, data); ok {
+		data, mediaType = problem, c.problemMediaType
+	}
+
+	w.Header().Set("Content-Type", mediaType)
 	w.WriteHeader(status)
 
-	if err := json.NewEncoder(w).Encode(data); err != nil {
+	if err := c.encode(w, data); err != nil {
 		return fmt.Errorf("failed to encode response: %w", err)
 	}
 
 	return nil
 }
+
+// acceptable reports whether a request accepts one of the media types of our codecs, and writes a 406 problem
+// detail if it does not. Handlers that change state check it first, so that a change is not made only for its
+// response to be refused.
+func acceptable(w http.ResponseWriter, r *http.Request) bool {
+	if _, ok := responseCodec(r); ok {
+		return true
+	}
+
+	_ = encodeResponse(w, r, http.StatusNotAcceptable, newNotAcceptableProblem(r))
+
+	return false
+}
+
+// decodable reports whether the body of a request has a media type one of our codecs can decode, and writes a
+// 415 problem detail if it does not.
+func decodable(w http.ResponseWriter, r *http.Request) bool {
+	if _, ok := requestCodec(r); ok {
+		return true
+	}
+
+	_ = encodeResponse(w, r, http.StatusUnsupportedMediaType, problemDetail{
+		Title:   "Unsupported Media Type",
+		Status:  http.StatusUnsupportedMediaType,
+		Detail:  fmt.Sprintf("The request body must be one of %v.", codecMediaTypes()),
+		TraceID: getTraceID(r.Context()),
+	})
+
+	return false
+}
+
+// newNotAcceptableProblem creates the 406 problem detail for a request that accepts none of the media types of our
+// codecs.
+func newNotAcceptableProblem(r *http.Request) problemDetail {
+	return problemDetail{
+		Title:   "Not Acceptable",
+		Status:  http.StatusNotAcceptable,
+		Detail:  fmt.Sprintf("The response can only be encoded as one of %v.", codecMediaTypes()),
+		TraceID: getTraceID(r.Context()),
+	}
+}
+
+// withProblemMembers fills in the type and instance members of a problem detail left empty by its handler: the type
+// from the status code, and the instance from the path of the request. Its title is translated into the language the
+// Accept-Language header prefers, which is sent as Content-Language. The boolean is false if data is not a problem
+// detail.
+func withProblemMembers(w http.ResponseWriter, r *http.Request, status int, data any) (any, bool) {
+	complete := func(p *problemDetail, invalidParams bool) {
+		trans := translatorFor(r)
+		w.Header().Add("Vary", "Accept-Language")
+		w.Header().Set("Content-Language", trans.Locale())
+
+		p.Title = translate(trans, p.Title)
+		if p.Type == "" {
+			p.Type = problemTypeForStatus(status, invalidParams).URI()
+		}
+		if p.Instance == "" {
+			p.Instance = r.URL.Path
+		}
+	}
+
+	switch p := data.(type) {
+	case problemDetail:
+		complete(&p, false)
+
+		return p, true
+	case problemDetailValidation:
+		complete(&p.problemDetail, true)
+
+		return p, true
+	default:
+		return data, false
+	}
+}
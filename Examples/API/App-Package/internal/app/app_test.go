@@ -100,7 +100,9 @@ func TestEncodeResponse(t *testing.T) {
 
 				rec := httptest.NewRecorder()
 
-				encodeResponseJSON(rec, tc.fields.status, tc.fields.data)
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+				_ = encodeResponse(rec, req, tc.fields.status, tc.fields.data)
 
 				res := rec.Result()
 				defer res.Body.Close()
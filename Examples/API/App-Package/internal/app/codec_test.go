@@ -0,0 +1,150 @@
+package app
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodecs(t *testing.T) {
+	t.Parallel()
+
+	want := blogRequest{AuthorID: 1, Title: "My first blog"}
+
+	for _, c := range codecs {
+		t.Run(c.mediaType, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			if err := c.encode(&buf, want); err != nil {
+				t.Fatalf("failed to encode: %v", err)
+			}
+
+			var got blogRequest
+			if err := c.decode(&buf, &got); err != nil {
+				t.Fatalf("failed to decode: %v", err)
+			}
+			// Only XML records the name of the element it decoded
+			got.XMLName = xml.Name{}
+
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestCodecs_xml(t *testing.T) {
+	t.Parallel()
+
+	c := codecs[1]
+
+	// XML elements are named as JSON members are
+	var buf bytes.Buffer
+	page := listUsersResponse{Users: []userResponse{{ID: 1, Name: "john", Email: "john@mail.com"}}, Limit: 20}
+	if err := c.encode(&buf, page); err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	assert.Equal(
+		t,
+		xml.Header+"<userPage><users><user><id>1</id><name>john</name><email>john@mail.com</email></user></users>"+
+			"<limit>20</limit></userPage>",
+		buf.String(),
+	)
+
+	var got blogRequest
+	body := `<blog><authorId>1</authorId><title>My first blog</title></blog>`
+	if err := c.decode(strings.NewReader(body), &got); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	assert.Equal(t, uint(1), got.AuthorID)
+	assert.Equal(t, "My first blog", got.Title)
+}
+
+func TestEncodeResponseNegotiation(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		accept          string
+		status          int
+		wantStatus      int
+		wantContentType string
+	}{
+		"no_accept_header": {
+			status:          http.StatusOK,
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+		},
+		"xml": {
+			accept:          "application/xml",
+			status:          http.StatusOK,
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/xml",
+		},
+		"cbor_preferred": {
+			accept:          "application/json;q=0.5, application/cbor",
+			status:          http.StatusOK,
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/cbor",
+		},
+		"nothing_acceptable": {
+			accept:          "text/plain",
+			status:          http.StatusOK,
+			wantStatus:      http.StatusNotAcceptable,
+			wantContentType: "application/problem+json",
+		},
+		"errors_are_still_sent": {
+			accept:          "text/plain",
+			status:          http.StatusNotFound,
+			wantStatus:      http.StatusNotFound,
+			wantContentType: "application/json",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept", tc.accept)
+			rec := httptest.NewRecorder()
+
+			if err := encodeResponse(rec, req, tc.status, blogRequest{AuthorID: 1, Title: "My first blog"}); err != nil {
+				t.Fatalf("failed to encode response: %v", err)
+			}
+
+			assert.Equal(t, tc.wantStatus, rec.Code)
+			assert.Equal(t, tc.wantContentType, rec.Header().Get("Content-Type"))
+			assert.Equal(t, "Accept", rec.Header().Get("Vary"))
+		})
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	t.Parallel()
+
+	offers := []string{"application/json", "application/xml"}
+
+	testcases := map[string]struct {
+		accept string
+		want   string
+	}{
+		"no_accept_header":     {accept: "", want: "application/json"},
+		"anything":             {accept: "*/*", want: "application/json"},
+		"exact_match":          {accept: "application/xml", want: "application/xml"},
+		"highest_quality_wins": {accept: "application/json;q=0.5, application/xml", want: "application/xml"},
+		"specific_overrides":   {accept: "*/*, application/json;q=0", want: "application/xml"},
+		"nothing_acceptable":   {accept: "text/plain", want: ""},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.want, negotiate(tc.accept, offers...))
+		})
+	}
+}
@@ -6,20 +6,23 @@ import (
 
 	"github.com/caarlos0/env/v11"
 	"github.com/joho/godotenv"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // Config holds the application configuration settings. The configuration is loaded from
 // environment variables.
 type Config struct {
-	DBHost         string     `env:"DATABASE_HOST,required"`
-	DBUserName     string     `env:"DATABASE_USER,required"`
-	DBUserPassword string     `env:"DATABASE_PASSWORD,required"`
-	DBName         string     `env:"DATABASE_NAME,required"`
-	DBPort         string     `env:"DATABASE_PORT,required"`
-	EnableSwagger  bool       `env:"ENABLE_SWAGGER"`
-	Host           string     `env:"HOST,required"`
-	Port           string     `env:"PORT,required"`
-	LogLevel       slog.Level `env:"LOG_LEVEL,required"`
+	DBHost              string     `env:"DATABASE_HOST,required"`
+	DBUserName          string     `env:"DATABASE_USER,required"`
+	DBUserPassword      string     `env:"DATABASE_PASSWORD,required"`
+	DBName              string     `env:"DATABASE_NAME,required"`
+	DBPort              string     `env:"DATABASE_PORT,required"`
+	EnableSwagger       bool       `env:"ENABLE_SWAGGER"`
+	Host                string     `env:"HOST,required"`
+	Port                string     `env:"PORT,required"`
+	LogLevel            slog.Level `env:"LOG_LEVEL,required"`
+	PasswordCost        int        `env:"PASSWORD_HASH_COST"  envDefault:"10"`
+	AllowedEmailDomains []string   `env:"ALLOWED_EMAIL_DOMAINS" envSeparator:","`
 }
 
 // NewConfig loads configuration from environment variables and a .env file, and returns a
@@ -38,5 +41,16 @@ func NewConfig() (Config, error) {
 		return Config{}, fmt.Errorf("[in config.NewConfig] failed to parse config: %w", err)
 	}
 
+	// bcrypt refuses costs above its maximum and silently raises those below
+	// its minimum, which would hash passwords at a cost other than the one set
+	if cfg.PasswordCost < bcrypt.MinCost || cfg.PasswordCost > bcrypt.MaxCost {
+		return Config{}, fmt.Errorf(
+			"[in config.NewConfig] PASSWORD_HASH_COST %d is out of range, want %d to %d",
+			cfg.PasswordCost,
+			bcrypt.MinCost,
+			bcrypt.MaxCost,
+		)
+	}
+
 	return cfg, nil
 }
@@ -0,0 +1,69 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Postgres error codes of the constraint violations we recognise.
+const (
+	pgForeignKeyViolation = "23503"
+	pgUniqueViolation     = "23505"
+	pgCheckViolation      = "23514"
+)
+
+// constraintFields maps the constraints of our schema to the JSON name of the
+// field they constrain.
+var constraintFields = map[string]string{
+	"users_email_key": "email",
+	"fk_author":       "authorId",
+	"fk_comment_user": "userId",
+	"fk_comment_blog": "blogId",
+}
+
+// constraintViolation reports whether err is a constraint violation reported
+// by Postgres and, if so, returns a problem detail naming the offending field.
+// A duplicate value is a 409 Conflict, while a reference to a missing record
+// or a disallowed value is a 422 Unprocessable Entity. Its message is translated by trans.
+func constraintViolation(ctx context.Context, trans ut.Translator, err error) (problemDetailValidation, bool) {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return problemDetailValidation{}, false
+	}
+
+	field, ok := constraintFields[pgErr.ConstraintName]
+	if !ok {
+		field = pgErr.ColumnName
+	}
+
+	problem := validationProblem{Field: field}
+	t, detail := unprocessableProblem, "The request could not be applied."
+	switch pgErr.Code {
+	case pgUniqueViolation:
+		t, detail = conflictProblem, "The request conflicts with an existing resource."
+		problem.Code = "unique"
+		problem.Message = translate(trans, msgAlreadyTaken, field)
+	case pgForeignKeyViolation:
+		problem.Code = "exists"
+		problem.Message = translate(trans, msgNoSuchResource, field)
+	case pgCheckViolation:
+		problem.Code = "check"
+		problem.Message = translate(trans, msgNotAllowed, field)
+	default:
+		return problemDetailValidation{}, false
+	}
+
+	return problemDetailValidation{
+		problemDetail: problemDetail{
+			Type:    t.URI(),
+			Title:   t.Title,
+			Status:  t.Status,
+			Detail:  detail,
+			TraceID: getTraceID(ctx),
+		},
+		InvalidParams: []validationProblem{problem},
+	}, true
+}
@@ -0,0 +1,153 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// createBlog is an HTTP handler function that creates a new blog in the database.
+//
+//	@Summary		Create Blog
+//	@Description	Create a new blog
+//	@Tags			blog
+//	@Accept			json
+//	@Accept			xml
+//	@Accept			application/msgpack
+//	@Accept			application/cbor
+//	@Produce		json
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
+//	@Param			blog	body		blogRequest	true	"Blog data"
+//	@Success		201		{object}	blogResponse
+//	@Failure		400		{object}	problemDetailValidation
+//	@Failure		404		{object}	problemDetail
+//	@Failure		406		{object}	problemDetail
+//	@Failure		415		{object}	problemDetail
+//	@Failure		500		{object}	problemDetail
+//	@Router			/api/blog [POST]
+func createBlog(logger *slog.Logger, db *sqlx.DB, validate *Validator) http.HandlerFunc {
+	const funcName = "app.createBlog"
+	logger = logger.With(slog.String("func", funcName))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		logger := logger.With(getTraceIDAsAttr(ctx))
+
+		// content negotiation
+		if !acceptable(w, r) || !decodable(w, r) {
+			return
+		}
+
+		// request validation
+		req, problems, err := decodeValid[blogRequest](r, validate)
+		if err != nil && len(problems) == 0 {
+			logger.ErrorContext(
+				ctx,
+				"failed to decode request",
+				slog.String("error", err.Error()),
+			)
+
+			_ = encodeResponse(w, r, http.StatusBadRequest, problemDetail{
+				Title:   "Bad Request",
+				Status:  http.StatusBadRequest,
+				Detail:  "Invalid request body.",
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		if len(problems) > 0 {
+			logger.ErrorContext(
+				ctx,
+				"Validation error",
+				slog.Any("validation_errors", problems),
+			)
+
+			_ = encodeResponse(w, r, http.StatusBadRequest, problemDetailValidation{
+				problemDetail: problemDetail{
+					Title:   "Bad Request",
+					Status:  http.StatusBadRequest,
+					Detail:  "The request contains invalid parameters.",
+					TraceID: getTraceID(ctx),
+				},
+				InvalidParams: problems,
+			})
+
+			return
+		}
+
+		logger.InfoContext(
+			ctx, "Creating blog",
+			slog.Uint64("author_id", uint64(req.AuthorID)),
+			slog.String("title", req.Title),
+		)
+
+		// make sure the author exists before writing the blog
+		exists, err := userExists(ctx, db, req.AuthorID)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to check author", slog.String("error", err.Error()))
+			_ = encodeResponse(w, r, http.StatusInternalServerError, problemDetail{
+				Title:   "Internal Server Error",
+				Status:  http.StatusInternalServerError,
+				Detail:  "An unexpected error occurred.",
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		if !exists {
+			_ = encodeResponse(w, r, http.StatusNotFound, problemDetail{
+				Title:   "Author Not Found",
+				Status:  http.StatusNotFound,
+				Detail:  fmt.Sprintf("User with ID %d not found", req.AuthorID),
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		// insert blog into db
+		var created blog
+		err = db.GetContext(
+			ctx,
+			&created,
+			`
+			INSERT INTO blogs (author_id, title)
+			VALUES ($1, $2)
+			RETURNING id, author_id, title, score
+			`,
+			req.AuthorID,
+			req.Title,
+		)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to insert blog", slog.String("error", err.Error()))
+			_ = encodeResponse(w, r, http.StatusInternalServerError, problemDetail{
+				Title:   "Internal Server Error",
+				Status:  http.StatusInternalServerError,
+				Detail:  "An unexpected error occurred.",
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		logger.InfoContext(
+			ctx, "Blog created successfully",
+			slog.Uint64("id", uint64(created.ID)),
+			slog.String("title", created.Title),
+		)
+
+		_ = encodeResponse(w, r, http.StatusCreated, blogResponse{
+			ID:       created.ID,
+			AuthorID: created.AuthorID,
+			Title:    created.Title,
+			Score:    created.Score,
+		})
+	}
+}
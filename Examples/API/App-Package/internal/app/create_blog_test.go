@@ -0,0 +1,152 @@
+package app
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestCreateBlog(t *testing.T) {
+	t.Parallel()
+	type mockDB struct {
+		mockExistsCalled bool
+		mockAuthorExists bool
+		mockCalled       bool
+		mockInputArgs    []driver.Value
+		mockOutput       *sqlmock.Rows
+		mockError        error
+	}
+
+	testcases := map[string]struct {
+		mockDB
+		inputJSON  string
+		wantStatus int
+		wantBlog   blogResponse
+	}{
+		"success": {
+			mockDB: mockDB{
+				mockExistsCalled: true,
+				mockAuthorExists: true,
+				mockCalled:       true,
+				mockInputArgs:    []driver.Value{1, "First Blog Post"},
+				mockOutput: sqlmock.NewRows([]string{"id", "author_id", "title", "score"}).
+					AddRow(1, 1, "First Blog Post", 0),
+				mockError: nil,
+			},
+			inputJSON:  `{"authorId":1,"title":"First Blog Post"}`,
+			wantStatus: http.StatusCreated,
+			wantBlog:   blogResponse{ID: 1, AuthorID: 1, Title: "First Blog Post", Score: 0},
+		},
+		"invalid_json": {
+			mockDB: mockDB{
+				mockExistsCalled: false,
+				mockCalled:       false,
+			},
+			inputJSON:  `{"authorId": 1, "title": First Blog Post}`,
+			wantStatus: http.StatusBadRequest,
+			wantBlog:   blogResponse{},
+		},
+		"request_validation_error": {
+			mockDB: mockDB{
+				mockExistsCalled: false,
+				mockCalled:       false,
+			},
+			inputJSON:  `{"authorId": 1, "title": ""}`,
+			wantStatus: http.StatusBadRequest,
+			wantBlog:   blogResponse{},
+		},
+		"author_not_found": {
+			mockDB: mockDB{
+				mockExistsCalled: true,
+				mockAuthorExists: false,
+				mockCalled:       false,
+			},
+			inputJSON:  `{"authorId":99,"title":"First Blog Post"}`,
+			wantStatus: http.StatusNotFound,
+			wantBlog:   blogResponse{},
+		},
+		"db_error": {
+			mockDB: mockDB{
+				mockExistsCalled: true,
+				mockAuthorExists: true,
+				mockCalled:       true,
+				mockInputArgs:    []driver.Value{1, "First Blog Post"},
+				mockOutput:       sqlmock.NewRows([]string{"id", "author_id", "title", "score"}),
+				mockError:        sqlmock.ErrCancelled,
+			},
+			inputJSON:  `{"authorId":1,"title":"First Blog Post"}`,
+			wantStatus: http.StatusInternalServerError,
+			wantBlog:   blogResponse{},
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			logger := slog.New(slog.DiscardHandler)
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("unexpected error when opening stub db: %v", err)
+			}
+
+			defer db.Close()
+
+			sqlxDB := sqlx.NewDb(db, "pgx")
+
+			if tc.mockExistsCalled {
+				mock.
+					ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM users WHERE id = $1::int)`)).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(tc.mockAuthorExists))
+			}
+
+			if tc.mockCalled {
+				mock.
+					ExpectQuery(regexp.QuoteMeta(`
+						INSERT INTO blogs (author_id, title)
+						VALUES ($1, $2)
+						RETURNING id, author_id, title, score
+					`)).
+					WithArgs(tc.mockInputArgs...).
+					WillReturnRows(tc.mockOutput).
+					WillReturnError(tc.mockError)
+			}
+
+			req := httptest.NewRequest(
+				http.MethodPost,
+				"/blog",
+				bytes.NewBufferString(tc.inputJSON),
+			)
+			rec := httptest.NewRecorder()
+			handler := createBlog(logger, sqlxDB, newTestValidator(t, sqlxDB))
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Errorf("want status %d, got %d", tc.wantStatus, rec.Code)
+			}
+
+			if tc.wantStatus == http.StatusCreated {
+				var gotBlog blogResponse
+				if err := json.NewDecoder(rec.Body).Decode(&gotBlog); err != nil {
+					t.Errorf("failed to decode response body: %v", err)
+				}
+
+				if gotBlog != tc.wantBlog {
+					t.Errorf("want blog %+v, got %+v", tc.wantBlog, gotBlog)
+				}
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled db expectations: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// createComment is an HTTP handler function that creates a new comment on a blog in the database.
+//
+//	@Summary		Create Comment
+//	@Description	Create a new comment on a blog
+//	@Tags			comment
+//	@Accept			json
+//	@Accept			xml
+//	@Accept			application/msgpack
+//	@Accept			application/cbor
+//	@Produce		json
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
+//	@Param			id		path		string			true	"Blog ID"
+//	@Param			comment	body		commentRequest	true	"Comment data"
+//	@Success		201		{object}	commentResponse
+//	@Failure		400		{object}	problemDetailValidation
+//	@Failure		404		{object}	problemDetail
+//	@Failure		406		{object}	problemDetail
+//	@Failure		415		{object}	problemDetail
+//	@Failure		500		{object}	problemDetail
+//	@Router			/api/blog/{id}/comments [POST]
+func createComment(logger *slog.Logger, db *sqlx.DB, validate *Validator) http.HandlerFunc {
+	const funcName = "app.createComment"
+	logger = logger.With(slog.String("func", funcName))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		logger := logger.With(getTraceIDAsAttr(ctx))
+
+		// read blog id from path parameters
+		idStr := r.PathValue("id")
+		blogID, err := strconv.Atoi(idStr)
+		if err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to parse id from url",
+				slog.String("id", idStr),
+				slog.String("error", err.Error()),
+			)
+
+			_ = encodeResponse(w, r, http.StatusBadRequest, problemDetail{
+				Title:   "Invalid ID",
+				Status:  http.StatusBadRequest,
+				Detail:  "The provided ID is not a valid integer.",
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		// content negotiation
+		if !acceptable(w, r) || !decodable(w, r) {
+			return
+		}
+
+		// request validation
+		req, problems, err := decodeValid[commentRequest](r, validate)
+		if err != nil && len(problems) == 0 {
+			logger.ErrorContext(
+				ctx,
+				"failed to decode request",
+				slog.String("error", err.Error()),
+			)
+
+			_ = encodeResponse(w, r, http.StatusBadRequest, problemDetail{
+				Title:   "Bad Request",
+				Status:  http.StatusBadRequest,
+				Detail:  "Invalid request body.",
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		if len(problems) > 0 {
+			logger.ErrorContext(
+				ctx,
+				"Validation error",
+				slog.Any("validation_errors", problems),
+			)
+
+			_ = encodeResponse(w, r, http.StatusBadRequest, problemDetailValidation{
+				problemDetail: problemDetail{
+					Title:   "Bad Request",
+					Status:  http.StatusBadRequest,
+					Detail:  "The request contains invalid parameters.",
+					TraceID: getTraceID(ctx),
+				},
+				InvalidParams: problems,
+			})
+
+			return
+		}
+
+		logger.InfoContext(
+			ctx, "Creating comment",
+			slog.Int("blog_id", blogID),
+			slog.Uint64("user_id", uint64(req.UserID)),
+		)
+
+		// make sure the blog and the commenting user exist before writing the comment
+		exists, err := blogExists(ctx, db, uint(blogID))
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to check blog", slog.String("error", err.Error()))
+			_ = encodeResponse(w, r, http.StatusInternalServerError, problemDetail{
+				Title:   "Internal Server Error",
+				Status:  http.StatusInternalServerError,
+				Detail:  "An unexpected error occurred.",
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		if !exists {
+			_ = encodeResponse(w, r, http.StatusNotFound, problemDetail{
+				Title:   "Blog Not Found",
+				Status:  http.StatusNotFound,
+				Detail:  fmt.Sprintf("Blog with ID %d not found", blogID),
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		exists, err = userExists(ctx, db, req.UserID)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to check user", slog.String("error", err.Error()))
+			_ = encodeResponse(w, r, http.StatusInternalServerError, problemDetail{
+				Title:   "Internal Server Error",
+				Status:  http.StatusInternalServerError,
+				Detail:  "An unexpected error occurred.",
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		if !exists {
+			_ = encodeResponse(w, r, http.StatusNotFound, problemDetail{
+				Title:   "User Not Found",
+				Status:  http.StatusNotFound,
+				Detail:  fmt.Sprintf("User with ID %d not found", req.UserID),
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		// insert comment into db
+		var created comment
+		err = db.GetContext(
+			ctx,
+			&created,
+			`
+			INSERT INTO comments (user_id, blog_id, message)
+			VALUES ($1, $2, $3)
+			RETURNING id, user_id, blog_id, message, created_at
+			`,
+			req.UserID,
+			blogID,
+			req.Message,
+		)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to insert comment", slog.String("error", err.Error()))
+			_ = encodeResponse(w, r, http.StatusInternalServerError, problemDetail{
+				Title:   "Internal Server Error",
+				Status:  http.StatusInternalServerError,
+				Detail:  "An unexpected error occurred.",
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		logger.InfoContext(ctx, "Comment created successfully", slog.Uint64("id", uint64(created.ID)))
+
+		_ = encodeResponse(w, r, http.StatusCreated, commentResponse{
+			ID:        created.ID,
+			UserID:    created.UserID,
+			BlogID:    created.BlogID,
+			Message:   created.Message,
+			CreatedAt: created.CreatedAt,
+		})
+	}
+}
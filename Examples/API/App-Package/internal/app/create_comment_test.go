@@ -0,0 +1,173 @@
+package app
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestCreateComment(t *testing.T) {
+	t.Parallel()
+	type mockDB struct {
+		mockBlogExists *bool
+		mockUserExists *bool
+		mockCalled     bool
+		mockInputArgs  []driver.Value
+		mockOutput     *sqlmock.Rows
+		mockError      error
+	}
+
+	yes, no := true, false
+	createdAt := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+	columns := []string{"id", "user_id", "blog_id", "message", "created_at"}
+
+	testcases := map[string]struct {
+		mockDB
+		id          string
+		inputJSON   string
+		wantStatus  int
+		wantComment commentResponse
+	}{
+		"success": {
+			mockDB: mockDB{
+				mockBlogExists: &yes,
+				mockUserExists: &yes,
+				mockCalled:     true,
+				mockInputArgs:  []driver.Value{2, 1, "Great post!"},
+				mockOutput:     sqlmock.NewRows(columns).AddRow(1, 2, 1, "Great post!", createdAt),
+				mockError:      nil,
+			},
+			id:         "1",
+			inputJSON:  `{"userId":2,"message":"Great post!"}`,
+			wantStatus: http.StatusCreated,
+			wantComment: commentResponse{
+				ID:        1,
+				UserID:    2,
+				BlogID:    1,
+				Message:   "Great post!",
+				CreatedAt: createdAt,
+			},
+		},
+		"invalid_id": {
+			mockDB:     mockDB{},
+			id:         "abc",
+			inputJSON:  `{"userId":2,"message":"Great post!"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		"request_validation_error": {
+			mockDB:     mockDB{},
+			id:         "1",
+			inputJSON:  `{"userId":2,"message":""}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		"blog_not_found": {
+			mockDB: mockDB{
+				mockBlogExists: &no,
+			},
+			id:         "99",
+			inputJSON:  `{"userId":2,"message":"Great post!"}`,
+			wantStatus: http.StatusNotFound,
+		},
+		"user_not_found": {
+			mockDB: mockDB{
+				mockBlogExists: &yes,
+				mockUserExists: &no,
+			},
+			id:         "1",
+			inputJSON:  `{"userId":99,"message":"Great post!"}`,
+			wantStatus: http.StatusNotFound,
+		},
+		"db_error": {
+			mockDB: mockDB{
+				mockBlogExists: &yes,
+				mockUserExists: &yes,
+				mockCalled:     true,
+				mockInputArgs:  []driver.Value{2, 1, "Great post!"},
+				mockOutput:     sqlmock.NewRows(columns),
+				mockError:      sqlmock.ErrCancelled,
+			},
+			id:         "1",
+			inputJSON:  `{"userId":2,"message":"Great post!"}`,
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			logger := slog.New(slog.DiscardHandler)
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("unexpected error when opening stub db: %v", err)
+			}
+
+			defer db.Close()
+
+			sqlxDB := sqlx.NewDb(db, "pgx")
+
+			if tc.mockBlogExists != nil {
+				mock.
+					ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM blogs WHERE id = $1::int)`)).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(*tc.mockBlogExists))
+			}
+
+			if tc.mockUserExists != nil {
+				mock.
+					ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM users WHERE id = $1::int)`)).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(*tc.mockUserExists))
+			}
+
+			if tc.mockCalled {
+				mock.
+					ExpectQuery(regexp.QuoteMeta(`
+						INSERT INTO comments (user_id, blog_id, message)
+						VALUES ($1, $2, $3)
+						RETURNING id, user_id, blog_id, message, created_at
+					`)).
+					WithArgs(tc.mockInputArgs...).
+					WillReturnRows(tc.mockOutput).
+					WillReturnError(tc.mockError)
+			}
+
+			req := httptest.NewRequest(
+				http.MethodPost,
+				"/blog/"+tc.id+"/comments",
+				bytes.NewBufferString(tc.inputJSON),
+			)
+			req.SetPathValue("id", tc.id)
+
+			rec := httptest.NewRecorder()
+			handler := createComment(logger, sqlxDB, newTestValidator(t, sqlxDB))
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Errorf("want status %d, got %d", tc.wantStatus, rec.Code)
+			}
+
+			if tc.wantStatus == http.StatusCreated {
+				var gotComment commentResponse
+				if err := json.NewDecoder(rec.Body).Decode(&gotComment); err != nil {
+					t.Errorf("failed to decode response body: %v", err)
+				}
+
+				if gotComment != tc.wantComment {
+					t.Errorf("want comment %+v, got %+v", tc.wantComment, gotComment)
+				}
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled db expectations: %v", err)
+			}
+		})
+	}
+}
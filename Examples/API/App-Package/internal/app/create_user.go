@@ -13,22 +13,36 @@ import (
 //	@Description	Create a new user
 //	@Tags			user
 //	@Accept			json
+//	@Accept			xml
+//	@Accept			application/msgpack
+//	@Accept			application/cbor
 //	@Produce		json
-//	@Param			user	body		user	true	"User data"
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
+//	@Param			user	body		createUserRequest	true	"User data"
 //	@Success		201		{object}	userResponse
 //	@Failure		400		{object}	problemDetailValidation
+//	@Failure		406		{object}	problemDetail
+//	@Failure		409		{object}	problemDetailValidation
+//	@Failure		415		{object}	problemDetail
 //	@Failure		500		{object}	problemDetail
 //	@Router			/api/user [POST]
-func createUser(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
+func createUser(logger *slog.Logger, db *sqlx.DB, validate *Validator, passwordCost int) http.HandlerFunc {
 	const funcName = "app.createUser"
 	logger = logger.With(slog.String("func", funcName))
 
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
-		logger = logger.With(getTraceIDAsAttr(ctx))
+		logger := logger.With(getTraceIDAsAttr(ctx))
+
+		// content negotiation
+		if !acceptable(w, r) || !decodable(w, r) {
+			return
+		}
 
 		// request validation
-		req, problems, err := decodeValid[userRequest](r)
+		req, problems, err := decodeValid[createUserRequest](r, validate)
 		if err != nil && len(problems) == 0 {
 			logger.ErrorContext(
 				ctx,
@@ -36,7 +50,7 @@ func createUser(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
 				slog.String("error", err.Error()),
 			)
 
-			_ = encodeResponseJSON(w, http.StatusBadRequest, problemDetail{
+			_ = encodeResponse(w, r, http.StatusBadRequest, problemDetail{
 				Title:   "Bad Request",
 				Status:  http.StatusBadRequest,
 				Detail:  "Invalid request body.",
@@ -53,7 +67,7 @@ func createUser(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
 				slog.Any("validation_errors", problems),
 			)
 
-			_ = encodeResponseJSON(w, http.StatusBadRequest, problemDetailValidation{
+			_ = encodeResponse(w, r, http.StatusBadRequest, problemDetailValidation{
 				problemDetail: problemDetail{
 					Title:   "Bad Request",
 					Status:  http.StatusBadRequest,
@@ -72,7 +86,20 @@ func createUser(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
 			slog.String("email", req.Email),
 		)
 
-		// insert user into db
+		hash, err := hashPassword(req.Password, passwordCost)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to hash password", slog.String("error", err.Error()))
+			_ = encodeResponse(w, r, http.StatusInternalServerError, problemDetail{
+				Title:   "Internal Server Error",
+				Status:  http.StatusInternalServerError,
+				Detail:  "An unexpected error occurred.",
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		// insert user into db, storing only the password hash
 		var id uint
 		err = db.GetContext(
 			ctx,
@@ -84,12 +111,19 @@ func createUser(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
 			`,
 			req.Name,
 			req.Email,
-			req.Password,
+			hash,
 		)
 
 		if err != nil {
+			if problem, ok := constraintViolation(ctx, translatorFor(r), err); ok {
+				logger.InfoContext(ctx, "constraint violated", slog.String("error", err.Error()))
+				_ = encodeResponse(w, r, problem.Status, problem)
+
+				return
+			}
+
 			logger.ErrorContext(ctx, "failed to insert user", slog.String("error", err.Error()))
-			_ = encodeResponseJSON(w, http.StatusInternalServerError, problemDetail{
+			_ = encodeResponse(w, r, http.StatusInternalServerError, problemDetail{
 				Title:   "Internal Server Error",
 				Status:  http.StatusInternalServerError,
 				Detail:  "An unexpected error occurred.",
@@ -107,7 +141,7 @@ func createUser(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
 		)
 
 		// respond with created user (without password)
-		_ = encodeResponseJSON(w, http.StatusCreated, userResponse{
+		_ = encodeResponse(w, r, http.StatusCreated, userResponse{
 			ID:    id,
 			Name:  req.Name,
 			Email: req.Email,
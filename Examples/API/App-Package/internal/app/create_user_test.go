@@ -12,32 +12,39 @@ import (
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jmoiron/sqlx"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestCreateUser(t *testing.T) {
 	t.Parallel()
 	type mockDB struct {
-		mockCalled    bool
-		mockInputArgs []driver.Value
-		mockOutput    *sqlmock.Rows
-		mockError     error
+		mockEmailChecked bool
+		mockEmailTaken   bool
+		mockCalled       bool
+		mockInputArgs    []driver.Value
+		mockOutput       *sqlmock.Rows
+		mockError        error
 	}
 
 	testcases := map[string]struct {
 		mockDB
-		inputJSON  string
-		wantStatus int
-		wantUser   userResponse
+		inputJSON   string
+		accept      string
+		contentType string
+		wantStatus  int
+		wantUser    userResponse
 	}{
 		"success": {
 			mockDB: mockDB{
-				mockCalled:    true,
-				mockInputArgs: []driver.Value{"Alice", "alice@example.com", "supersecret"},
-				mockOutput:    sqlmock.NewRows([]string{"id"}).AddRow(1),
-				mockError:     nil,
+				mockEmailChecked: true,
+				mockCalled:       true,
+				mockInputArgs:    []driver.Value{"Alice", "alice@example.com", bcryptHash{"supersecret1"}},
+				mockOutput:       sqlmock.NewRows([]string{"id"}).AddRow(1),
+				mockError:        nil,
 			},
-			inputJSON:  `{"name":"Alice","email":"alice@example.com","password":"supersecret"}`,
+			inputJSON:  `{"name":"Alice","email":"alice@example.com","password":"supersecret1"}`,
 			wantStatus: 201,
 			wantUser: userResponse{
 				ID:    1,
@@ -58,10 +65,11 @@ func TestCreateUser(t *testing.T) {
 		},
 		"request_validation_error": {
 			mockDB: mockDB{
-				mockCalled:    false,
-				mockInputArgs: nil,
-				mockOutput:    nil,
-				mockError:     nil,
+				mockEmailChecked: true,
+				mockCalled:       false,
+				mockInputArgs:    nil,
+				mockOutput:       nil,
+				mockError:        nil,
 			},
 			inputJSON:  `{"name": "Bob", "email": "bob@example.com", "password": "pass"}`,
 			wantStatus: 400,
@@ -69,15 +77,60 @@ func TestCreateUser(t *testing.T) {
 		},
 		"db_error": {
 			mockDB: mockDB{
-				mockCalled:    true,
-				mockInputArgs: []driver.Value{"Bob", "bob@example.com", "badpass"},
-				mockOutput:    sqlmock.NewRows([]string{"id"}),
-				mockError:     sqlmock.ErrCancelled,
+				mockEmailChecked: true,
+				mockCalled:       true,
+				mockInputArgs:    []driver.Value{"Bob", "bob@example.com", bcryptHash{"password123"}},
+				mockOutput:       sqlmock.NewRows([]string{"id"}),
+				mockError:        sqlmock.ErrCancelled,
 			},
 			inputJSON:  `{"name":"Bob","email":"bob@example.com","password":"password123"}`,
 			wantStatus: 500,
 			wantUser:   userResponse{},
 		},
+		"email_taken": {
+			mockDB: mockDB{
+				mockEmailChecked: true,
+				mockEmailTaken:   true,
+			},
+			inputJSON:  `{"name":"Bob","email":"bob@example.com","password":"password123"}`,
+			wantStatus: 400,
+			wantUser:   userResponse{},
+		},
+		"email_domain_not_allowed": {
+			inputJSON:  `{"name":"Bob","email":"bob@elsewhere.org","password":"password123"}`,
+			wantStatus: 400,
+			wantUser:   userResponse{},
+		},
+		"weak_password": {
+			mockDB: mockDB{
+				mockEmailChecked: true,
+			},
+			inputJSON:  `{"name":"Bob","email":"bob@example.com","password":"12345678"}`,
+			wantStatus: 400,
+			wantUser:   userResponse{},
+		},
+		"not_acceptable": {
+			inputJSON:  `{"name":"Bob","email":"bob@example.com","password":"password123"}`,
+			accept:     "text/plain",
+			wantStatus: 406,
+		},
+		"unsupported_media_type": {
+			inputJSON:   `{"name":"Bob","email":"bob@example.com","password":"password123"}`,
+			contentType: "text/plain",
+			wantStatus:  415,
+		},
+		"duplicate_email": {
+			mockDB: mockDB{
+				mockEmailChecked: true,
+				mockCalled:       true,
+				mockInputArgs:    []driver.Value{"Bob", "bob@example.com", bcryptHash{"password123"}},
+				mockOutput:       sqlmock.NewRows([]string{"id"}),
+				mockError:        &pgconn.PgError{Code: pgUniqueViolation, ConstraintName: "users_email_key"},
+			},
+			inputJSON:  `{"name":"Bob","email":"bob@example.com","password":"password123"}`,
+			wantStatus: 409,
+			wantUser:   userResponse{},
+		},
 	}
 
 	for name, tc := range testcases {
@@ -94,6 +147,13 @@ func TestCreateUser(t *testing.T) {
 
 			sqlxDB := sqlx.NewDb(db, "pgx")
 
+			if tc.mockEmailChecked {
+				mock.
+					ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM users WHERE email = $1)`)).
+					WithArgs(sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(tc.mockEmailTaken))
+			}
+
 			if tc.mockCalled {
 				mock.
 					ExpectQuery(regexp.QuoteMeta(`
@@ -111,14 +171,36 @@ func TestCreateUser(t *testing.T) {
 				"/users",
 				bytes.NewBufferString(tc.inputJSON),
 			)
+			req.Header.Set("Accept", tc.accept)
+			req.Header.Set("Content-Type", tc.contentType)
 			rec := httptest.NewRecorder()
-			handler := createUser(logger, sqlxDB)
+			validate, err := NewValidator(logger, sqlxDB, []string{"example.com"})
+			if err != nil {
+				t.Fatalf("failed to create validator: %v", err)
+			}
+
+			handler := createUser(logger, sqlxDB, validate, bcrypt.MinCost)
 			handler.ServeHTTP(rec, req)
 
 			if rec.Code != tc.wantStatus {
 				t.Errorf("want status %d, got %d", tc.wantStatus, rec.Code)
 			}
 
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unmet database expectations: %v", err)
+			}
+
+			if tc.wantStatus == 409 {
+				var gotProblem problemDetailValidation
+				if err := json.NewDecoder(rec.Body).Decode(&gotProblem); err != nil {
+					t.Errorf("failed to decode response body: %v", err)
+				}
+
+				if len(gotProblem.InvalidParams) != 1 || gotProblem.InvalidParams[0].Field != "email" {
+					t.Errorf("want a problem with the email field, got %+v", gotProblem.InvalidParams)
+				}
+			}
+
 			if tc.wantStatus == 201 {
 				var gotUser userResponse
 				if err := json.NewDecoder(rec.Body).Decode(&gotUser); err != nil {
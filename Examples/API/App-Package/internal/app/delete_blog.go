@@ -0,0 +1,105 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// deleteBlog is an HTTP handler function that deletes a blog by ID from the database.
+//
+//	@Summary		Delete Blog
+//	@Description	Delete a blog by ID
+//	@Tags			blog
+//	@Produce		json
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
+//	@Param			id	path		string	true	"Blog ID"
+//	@Success		204	{string}	string	""
+//	@Failure		400	{object}	problemDetail
+//	@Failure		404	{object}	problemDetail
+//	@Failure		500	{object}	problemDetail
+//	@Router			/api/blog/{id} [DELETE]
+func deleteBlog(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
+	const funcName = "app.deleteBlog"
+	logger = logger.With(slog.String("func", funcName))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		logger := logger.With(getTraceIDAsAttr(ctx))
+
+		// read id from path parameters
+		idStr := r.PathValue("id")
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to parse id from url",
+				slog.String("id", idStr),
+				slog.String("error", err.Error()),
+			)
+
+			_ = encodeResponse(w, r, http.StatusBadRequest, problemDetail{
+				Title:   "Invalid ID",
+				Status:  http.StatusBadRequest,
+				Detail:  "The provided ID is not a valid integer.",
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		// delete blog from db
+		logger.DebugContext(ctx, "Deleting blog", "id", id)
+
+		result, err := db.ExecContext(ctx, "DELETE FROM blogs WHERE id = $1", id)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to delete blog", slog.String("error", err.Error()))
+			_ = encodeResponse(w, r, http.StatusInternalServerError, problemDetail{
+				Title:   "Internal Server Error",
+				Status:  http.StatusInternalServerError,
+				Detail:  "An unexpected error occurred.",
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to get rows affected",
+				slog.String("error", err.Error()),
+			)
+
+			_ = encodeResponse(w, r, http.StatusInternalServerError, problemDetail{
+				Title:   "Internal Server Error",
+				Status:  http.StatusInternalServerError,
+				Detail:  "An unexpected error occurred.",
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		if rowsAffected == 0 {
+			_ = encodeResponse(w, r, http.StatusNotFound, problemDetail{
+				Title:   "Blog Not Found",
+				Status:  http.StatusNotFound,
+				Detail:  fmt.Sprintf("Blog with ID %d not found", id),
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		// respond with no content
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,115 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// deleteComment is an HTTP handler function that deletes a comment on a blog by ID from the database.
+//
+//	@Summary		Delete Comment
+//	@Description	Delete a comment on a blog by ID
+//	@Tags			comment
+//	@Produce		json
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
+//	@Param			id			path		string	true	"Blog ID"
+//	@Param			commentId	path		string	true	"Comment ID"
+//	@Success		204			{string}	string	""
+//	@Failure		400			{object}	problemDetail
+//	@Failure		404			{object}	problemDetail
+//	@Failure		500			{object}	problemDetail
+//	@Router			/api/blog/{id}/comments/{commentId} [DELETE]
+func deleteComment(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
+	const funcName = "app.deleteComment"
+	logger = logger.With(slog.String("func", funcName))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		logger := logger.With(getTraceIDAsAttr(ctx))
+
+		// read ids from path parameters
+		blogIDStr := r.PathValue("id")
+		idStr := r.PathValue("commentId")
+		blogID, blogErr := strconv.Atoi(blogIDStr)
+		id, idErr := strconv.Atoi(idStr)
+		if err := errors.Join(blogErr, idErr); err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to parse id from url",
+				slog.String("id", blogIDStr),
+				slog.String("comment_id", idStr),
+				slog.String("error", err.Error()),
+			)
+
+			_ = encodeResponse(w, r, http.StatusBadRequest, problemDetail{
+				Title:   "Invalid ID",
+				Status:  http.StatusBadRequest,
+				Detail:  "The provided ID is not a valid integer.",
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		// delete comment from db, scoped to the blog in the path
+		logger.DebugContext(ctx, "Deleting comment", "blog_id", blogID, "id", id)
+
+		result, err := db.ExecContext(
+			ctx,
+			"DELETE FROM comments WHERE id = $1 AND blog_id = $2",
+			id,
+			blogID,
+		)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to delete comment", slog.String("error", err.Error()))
+			_ = encodeResponse(w, r, http.StatusInternalServerError, problemDetail{
+				Title:   "Internal Server Error",
+				Status:  http.StatusInternalServerError,
+				Detail:  "An unexpected error occurred.",
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to get rows affected",
+				slog.String("error", err.Error()),
+			)
+
+			_ = encodeResponse(w, r, http.StatusInternalServerError, problemDetail{
+				Title:   "Internal Server Error",
+				Status:  http.StatusInternalServerError,
+				Detail:  "An unexpected error occurred.",
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		if rowsAffected == 0 {
+			_ = encodeResponse(w, r, http.StatusNotFound, problemDetail{
+				Title:   "Comment Not Found",
+				Status:  http.StatusNotFound,
+				Detail:  fmt.Sprintf("Comment with ID %d not found on blog with ID %d", id, blogID),
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		// respond with no content
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
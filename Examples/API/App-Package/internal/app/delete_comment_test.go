@@ -0,0 +1,116 @@
+package app
+
+import (
+	"database/sql/driver"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestDeleteComment(t *testing.T) {
+	t.Parallel()
+	type mockDB struct {
+		mockCalled    bool
+		mockInputArgs []driver.Value
+		mockResult    driver.Result
+		mockError     error
+	}
+
+	testcases := map[string]struct {
+		mockDB
+		blogID     string
+		id         string
+		wantStatus int
+	}{
+		"success": {
+			mockDB: mockDB{
+				mockCalled:    true,
+				mockInputArgs: []driver.Value{8, 1},
+				mockResult:    sqlmock.NewResult(0, 1), // 1 row affected
+				mockError:     nil,
+			},
+			blogID:     "1",
+			id:         "8",
+			wantStatus: http.StatusNoContent,
+		},
+		"invalid_blog_id": {
+			mockDB: mockDB{
+				mockCalled: false,
+			},
+			blogID:     "abc",
+			id:         "8",
+			wantStatus: http.StatusBadRequest,
+		},
+		"invalid_comment_id": {
+			mockDB: mockDB{
+				mockCalled: false,
+			},
+			blogID:     "1",
+			id:         "abc",
+			wantStatus: http.StatusBadRequest,
+		},
+		"db_error": {
+			mockDB: mockDB{
+				mockCalled:    true,
+				mockInputArgs: []driver.Value{8, 2},
+				mockResult:    nil,
+				mockError:     errors.New("db error"),
+			},
+			blogID:     "2",
+			id:         "8",
+			wantStatus: http.StatusInternalServerError,
+		},
+		"not_found": {
+			mockDB: mockDB{
+				mockCalled:    true,
+				mockInputArgs: []driver.Value{8, 3},
+				mockResult:    sqlmock.NewResult(0, 0), // 0 rows affected
+				mockError:     nil,
+			},
+			blogID:     "3",
+			id:         "8",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			logger := slog.New(slog.DiscardHandler)
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("unexpected error when opening stub db: %v", err)
+			}
+
+			defer db.Close()
+
+			sqlxDB := sqlx.NewDb(db, "pgx")
+
+			if tc.mockCalled {
+				mock.ExpectExec(regexp.QuoteMeta("DELETE FROM comments WHERE id = $1 AND blog_id = $2")).
+					WithArgs(tc.mockInputArgs...).
+					WillReturnResult(tc.mockResult).
+					WillReturnError(tc.mockError)
+			}
+
+			req := httptest.NewRequest(http.MethodDelete, "/blog/"+tc.blogID+"/comments/"+tc.id, nil)
+			req.SetPathValue("id", tc.blogID)
+			req.SetPathValue("commentId", tc.id)
+
+			rec := httptest.NewRecorder()
+			handler := deleteComment(logger, sqlxDB)
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Errorf("want status %d, got %d", tc.wantStatus, rec.Code)
+			}
+		})
+	}
+}
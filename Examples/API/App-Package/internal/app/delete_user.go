@@ -15,6 +15,9 @@ import (
 //	@Description	Delete a user by ID
 //	@Tags			user
 //	@Produce		json
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
 //	@Param			id	path		string	true	"User ID"
 //	@Success		204	{string}	string	""
 //	@Failure		400	{object}	problemDetail
@@ -28,7 +31,7 @@ func deleteUser(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
-		logger = logger.With(getTraceIDAsAttr(ctx))
+		logger := logger.With(getTraceIDAsAttr(ctx))
 
 		// read id from path parameters
 		idStr := r.PathValue("id")
@@ -41,7 +44,7 @@ func deleteUser(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
 				slog.String("error", err.Error()),
 			)
 
-			_ = encodeResponseJSON(w, http.StatusBadRequest, problemDetail{
+			_ = encodeResponse(w, r, http.StatusBadRequest, problemDetail{
 				Title:   "Invalid ID",
 				Status:  http.StatusBadRequest,
 				Detail:  "The provided ID is not a valid integer.",
@@ -57,7 +60,7 @@ func deleteUser(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
 		result, err := db.ExecContext(ctx, "DELETE FROM users WHERE id = $1", id)
 		if err != nil {
 			logger.ErrorContext(ctx, "failed to delete user", slog.String("error", err.Error()))
-			_ = encodeResponseJSON(w, http.StatusInternalServerError, problemDetail{
+			_ = encodeResponse(w, r, http.StatusInternalServerError, problemDetail{
 				Title:   "Internal Server Error",
 				Status:  http.StatusInternalServerError,
 				Detail:  "An unexpected error occurred.",
@@ -75,7 +78,7 @@ func deleteUser(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
 				slog.String("error", err.Error()),
 			)
 
-			_ = encodeResponseJSON(w, http.StatusInternalServerError, problemDetail{
+			_ = encodeResponse(w, r, http.StatusInternalServerError, problemDetail{
 				Title:   "Internal Server Error",
 				Status:  http.StatusInternalServerError,
 				Detail:  "An unexpected error occurred.",
@@ -86,7 +89,7 @@ func deleteUser(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
 		}
 
 		if rowsAffected == 0 {
-			_ = encodeResponseJSON(w, http.StatusNotFound, problemDetail{
+			_ = encodeResponse(w, r, http.StatusNotFound, problemDetail{
 				Title:   "User Not Found",
 				Status:  http.StatusNotFound,
 				Detail:  fmt.Sprintf("User with ID %d not found", id),
@@ -0,0 +1,41 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// userExists reports whether a user with the provided id exists in the database.
+func userExists(ctx context.Context, db *sqlx.DB, id uint) (bool, error) {
+	var exists bool
+	err := db.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1::int)`, id)
+	if err != nil {
+		return false, fmt.Errorf("[in app.userExists] failed to check user: %w", err)
+	}
+
+	return exists, nil
+}
+
+// blogExists reports whether a blog with the provided id exists in the database.
+func blogExists(ctx context.Context, db *sqlx.DB, id uint) (bool, error) {
+	var exists bool
+	err := db.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM blogs WHERE id = $1::int)`, id)
+	if err != nil {
+		return false, fmt.Errorf("[in app.blogExists] failed to check blog: %w", err)
+	}
+
+	return exists, nil
+}
+
+// emailTaken reports whether a user has registered the provided email address in the database.
+func emailTaken(ctx context.Context, db *sqlx.DB, email string) (bool, error) {
+	var taken bool
+	err := db.GetContext(ctx, &taken, `SELECT EXISTS (SELECT 1 FROM users WHERE email = $1)`, email)
+	if err != nil {
+		return false, fmt.Errorf("[in app.emailTaken] failed to check email: %w", err)
+	}
+
+	return taken, nil
+}
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"encoding/xml"
 	"log/slog"
 	"net/http"
 
@@ -9,14 +10,15 @@ import (
 
 // HealthStatus represents the status of a dependency.
 type healthStatus struct {
-	Name   string `json:"name"`
-	Status string `json:"status"`
+	Name   string `json:"name"   xml:"name"`
+	Status string `json:"status" xml:"status"`
 }
 
 // healthResponse represents the response for the health check.
 type healthResponse struct {
-	Status        string         `json:"status"`
-	HealthDetails []healthStatus `json:"details"`
+	XMLName       xml.Name       `json:"-"       xml:"health"  swaggerignore:"true"`
+	Status        string         `json:"status"  xml:"status"`
+	HealthDetails []healthStatus `json:"details" xml:"details"`
 }
 
 // HandleHealthCheck handles the deep health check endpoint.
@@ -28,7 +30,11 @@ type healthResponse struct {
 //	@Tags			health
 //	@Accept			json
 //	@Produce		json
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
 //	@Success		200		{object}	healthResponse
+//	@Failure		406		{object}	problemDetail
 //	@Failure		500		{object}	healthResponse
 //	@Router			/health	[GET]
 func HandleHealthCheck(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
@@ -43,12 +49,12 @@ func HandleHealthCheck(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
-		logger = logger.With(getTraceIDAsAttr(ctx))
+		logger := logger.With(getTraceIDAsAttr(ctx))
 		logger.InfoContext(ctx, "health check called")
 
 		if err := db.PingContext(ctx); err != nil {
 			logger.ErrorContext(ctx, "health check failed", slog.String("error", err.Error()))
-			_ = encodeResponseJSON(w, http.StatusInternalServerError, healthResponse{
+			_ = encodeResponse(w, r, http.StatusInternalServerError, healthResponse{
 				Status: unhealthyStatus,
 				HealthDetails: []healthStatus{
 					{
@@ -61,7 +67,7 @@ func HandleHealthCheck(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
 			return
 		}
 
-		_ = encodeResponseJSON(w, http.StatusOK, healthResponse{
+		_ = encodeResponse(w, r, http.StatusOK, healthResponse{
 			Status: healthyStatus,
 			HealthDetails: []healthStatus{
 				{
@@ -0,0 +1,206 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	"github.com/go-playground/locales/fr"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	entranslations "github.com/go-playground/validator/v10/translations/en"
+	estranslations "github.com/go-playground/validator/v10/translations/es"
+	frtranslations "github.com/go-playground/validator/v10/translations/fr"
+	"golang.org/x/text/language"
+)
+
+// Keys of the messages of validation problems the validator does not report itself. The parameter of each is the
+// name of the offending field.
+const (
+	msgAlreadyTaken     = "already-taken"
+	msgNoSuchResource   = "no-such-resource"
+	msgNotAllowed       = "not-allowed"
+	msgWeakPassword     = "weak-password"
+	msgDomainNotAllowed = "domain-not-allowed"
+)
+
+// languages are the languages of the translation catalogue, English first as the fallback, each with its locale and
+// the registration of the validator's default messages in it.
+var languages = []struct {
+	tag      language.Tag
+	locale   string
+	register func(v *validator.Validate, trans ut.Translator) error
+}{
+	{tag: language.English, locale: "en", register: entranslations.RegisterDefaultTranslations},
+	{tag: language.Spanish, locale: "es", register: estranslations.RegisterDefaultTranslations},
+	{tag: language.French, locale: "fr", register: frtranslations.RegisterDefaultTranslations},
+}
+
+// catalogue holds the messages shown to end users beyond the validator's own, by locale and key. Problem titles are
+// keyed by their English title, so English titles need no entry.
+var catalogue = map[string]map[string]string{
+	"en": {
+		msgAlreadyTaken:     "{0} is already taken",
+		msgNoSuchResource:   "{0} does not refer to an existing resource",
+		msgNotAllowed:       "{0} is not an allowed value",
+		msgWeakPassword:     "{0} must contain at least one letter and one digit",
+		msgDomainNotAllowed: "{0} must be an address at an allowed domain",
+	},
+	"es": {
+		msgAlreadyTaken:          "{0} ya está en uso",
+		msgNoSuchResource:        "{0} no hace referencia a un recurso existente",
+		msgNotAllowed:            "{0} no es un valor permitido",
+		msgWeakPassword:          "{0} debe contener al menos una letra y un dígito",
+		msgDomainNotAllowed:      "{0} debe ser una dirección de un dominio permitido",
+		"Bad Request":            "Solicitud incorrecta",
+		"Not Found":              "No encontrado",
+		"Not Acceptable":         "No aceptable",
+		"Conflict":               "Conflicto",
+		"Unsupported Media Type": "Tipo de medio no soportado",
+		"Unprocessable Entity":   "Entidad no procesable",
+		"Internal Server Error":  "Error interno del servidor",
+		"Invalid ID":             "ID no válido",
+		"Path Not Found":         "Ruta no encontrada",
+		"Author Not Found":       "Autor no encontrado",
+		"Blog Not Found":         "Blog no encontrado",
+		"Comment Not Found":      "Comentario no encontrado",
+		"User Not Found":         "Usuario no encontrado",
+	},
+	"fr": {
+		msgAlreadyTaken:          "{0} est déjà utilisé",
+		msgNoSuchResource:        "{0} ne fait pas référence à une ressource existante",
+		msgNotAllowed:            "{0} n'est pas une valeur autorisée",
+		msgWeakPassword:          "{0} doit contenir au moins une lettre et un chiffre",
+		msgDomainNotAllowed:      "{0} doit être une adresse d'un domaine autorisé",
+		"Bad Request":            "Requête incorrecte",
+		"Not Found":              "Introuvable",
+		"Not Acceptable":         "Non acceptable",
+		"Conflict":               "Conflit",
+		"Unsupported Media Type": "Type de média non pris en charge",
+		"Unprocessable Entity":   "Entité non traitable",
+		"Internal Server Error":  "Erreur interne du serveur",
+		"Invalid ID":             "ID non valide",
+		"Path Not Found":         "Chemin introuvable",
+		"Author Not Found":       "Auteur introuvable",
+		"Blog Not Found":         "Blog introuvable",
+		"Comment Not Found":      "Commentaire introuvable",
+		"User Not Found":         "Utilisateur introuvable",
+	},
+}
+
+var (
+	universal       = ut.New(en.New(), en.New(), es.New(), fr.New())
+	languageMatcher = language.NewMatcher([]language.Tag{language.English, language.Spanish, language.French})
+
+	// translators holds the translator of every language by locale.
+	translators = newTranslators()
+)
+
+// newTranslators creates the translator of every language and adds the messages of the catalogue and the
+// validator's default messages to it, the latter once for all with a throwaway validator.
+func newTranslators() map[string]ut.Translator {
+	translators := make(map[string]ut.Translator, len(languages))
+	for _, l := range languages {
+		trans, _ := universal.GetTranslator(l.locale)
+		for key, message := range catalogue[l.locale] {
+			if err := trans.Add(key, message, false); err != nil {
+				panic(fmt.Sprintf("app: add %s message %q: %v", l.locale, key, err))
+			}
+		}
+		if err := l.register(validator.New(), trans); err != nil {
+			panic(fmt.Sprintf("app: add %s validation messages: %v", l.locale, err))
+		}
+		translators[l.locale] = completeTranslator{trans}
+	}
+
+	return translators
+}
+
+// completeTranslator is a translator that already holds every message, so adding one does nothing. Registering the
+// validator's messages with a validator adds them to the translator again, which this makes safe to do at any time,
+// even while other requests are being translated.
+type completeTranslator struct {
+	ut.Translator
+}
+
+func (completeTranslator) Add(any, string, bool) error {
+	return nil
+}
+
+func (completeTranslator) AddCardinal(any, string, locales.PluralRule, bool) error {
+	return nil
+}
+
+func (completeTranslator) AddOrdinal(any, string, locales.PluralRule, bool) error {
+	return nil
+}
+
+func (completeTranslator) AddRange(any, string, locales.PluralRule, bool) error {
+	return nil
+}
+
+// translatorFor returns the translator of the language the Accept-Language header of r prefers, or of English if
+// it accepts none of the catalogue's.
+func translatorFor(r *http.Request) ut.Translator {
+	// A malformed header leaves no tags, which matches English
+	accepted, _, _ := language.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+	_, i, _ := languageMatcher.Match(accepted...)
+
+	return translators[languages[i].locale]
+}
+
+// translate translates the message with key, filling in its parameters. Messages missing from the catalogue, such
+// as titles with no translation, are returned as their key.
+func translate(trans ut.Translator, key string, params ...string) string {
+	if translated, err := trans.T(key, params...); err == nil {
+		return translated
+	}
+
+	return key
+}
+
+// registerValidatorMessages registers the validator's messages in every language of the catalogue with v, and makes
+// v name fields by their JSON names so that messages name fields as clients send them.
+func registerValidatorMessages(v *validator.Validate) error {
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+
+		return name
+	})
+
+	for _, l := range languages {
+		if err := l.register(v, translators[l.locale]); err != nil {
+			return fmt.Errorf("[in app.registerValidatorMessages] register %s messages failed: %w", l.locale, err)
+		}
+	}
+
+	return nil
+}
+
+// registerTagMessage registers the message with key as the message of a custom validation tag of v, in every
+// language of the catalogue. The message is given the name of the offending field as its parameter.
+func registerTagMessage(v *validator.Validate, tag string, key string) error {
+	for _, l := range languages {
+		err := v.RegisterTranslation(
+			tag,
+			translators[l.locale],
+			// The translator already holds the message
+			func(ut.Translator) error { return nil },
+			func(trans ut.Translator, fieldError validator.FieldError) string {
+				return translate(trans, key, fieldError.Field())
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("[in app.registerTagMessage] register %s message of %s failed: %w", l.locale, tag, err)
+		}
+	}
+
+	return nil
+}
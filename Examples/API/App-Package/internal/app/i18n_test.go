@@ -0,0 +1,99 @@
+package app
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslatorFor(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		acceptLanguage string
+		wantLocale     string
+	}{
+		"no_header":            {acceptLanguage: "", wantLocale: "en"},
+		"exact":                {acceptLanguage: "fr", wantLocale: "fr"},
+		"region":               {acceptLanguage: "es-MX", wantLocale: "es"},
+		"highest_quality_wins": {acceptLanguage: "en;q=0.5, fr-CA;q=0.8", wantLocale: "fr"},
+		"none_supported":       {acceptLanguage: "de, ja", wantLocale: "en"},
+		"malformed":            {acceptLanguage: ";;q=x", wantLocale: "en"},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept-Language", tc.acceptLanguage)
+
+			assert.Equal(t, tc.wantLocale, translatorFor(req).Locale())
+		})
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "Not Found", translate(translators["en"], "Not Found"))
+	assert.Equal(t, "No encontrado", translate(translators["es"], "Not Found"))
+	assert.Equal(t, "email est déjà utilisé", translate(translators["fr"], msgAlreadyTaken, "email"))
+	assert.Equal(t, "Teapot", translate(translators["fr"], "Teapot"))
+}
+
+func TestDecodeValidLocalized(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		acceptLanguage string
+		wantMessage    string
+	}{
+		"english": {acceptLanguage: "", wantMessage: "title is a required field"},
+		"spanish": {acceptLanguage: "es", wantMessage: "title es un campo requerido"},
+		"french":  {acceptLanguage: "fr-FR", wantMessage: "title est un champ obligatoire"},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/blog", bytes.NewBufferString(`{"authorId": 1}`))
+			req.Header.Set("Accept-Language", tc.acceptLanguage)
+
+			_, problems, err := decodeValid[blogRequest](req, newTestValidator(t, nil))
+			if err != nil {
+				t.Fatalf("failed to decode: %v", err)
+			}
+
+			if assert.Len(t, problems, 1) {
+				assert.Equal(t, "title", problems[0].Field)
+				assert.Equal(t, "required", problems[0].Code)
+				assert.Equal(t, tc.wantMessage, problems[0].Message)
+			}
+		})
+	}
+}
+
+func TestEncodeResponseProblemLocalized(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/blog/7", nil)
+	req.Header.Set("Accept-Language", "fr")
+	rec := httptest.NewRecorder()
+
+	err := encodeResponse(rec, req, http.StatusNotFound, problemDetail{
+		Title:  "Blog Not Found",
+		Status: http.StatusNotFound,
+		Detail: "Blog with ID 7 not found",
+	})
+	if err != nil {
+		t.Fatalf("failed to encode response: %v", err)
+	}
+
+	assert.Equal(t, "fr", rec.Header().Get("Content-Language"))
+	assert.Contains(t, rec.Body.String(), `"title":"Blog introuvable"`)
+}
@@ -0,0 +1,161 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// listBlogComments is an HTTP handler function that retrieves a page of the comments
+// on a blog from the database, oldest first.
+//
+//	@Summary		List Blog Comments
+//	@Description	List the comments on a blog, oldest first
+//	@Tags			comment
+//	@Produce		json
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
+//	@Param			id		path		string	true	"Blog ID"
+//	@Param			limit	query		int		false	"Page size (1-100, default 20)"
+//	@Param			offset	query		int		false	"Number of comments to skip"
+//	@Success		200		{array}		commentResponse
+//	@Failure		400		{object}	problemDetailValidation
+//	@Failure		404		{object}	problemDetail
+//	@Failure		406		{object}	problemDetail
+//	@Failure		500		{object}	problemDetail
+//	@Router			/api/blog/{id}/comments [GET]
+func listBlogComments(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
+	const funcName = "app.listBlogComments"
+	logger = logger.With(slog.String("func", funcName))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		logger := logger.With(getTraceIDAsAttr(ctx))
+
+		// read id from path parameters
+		idStr := r.PathValue("id")
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to parse id from url",
+				slog.String("id", idStr),
+				slog.String("error", err.Error()),
+			)
+
+			_ = encodeResponse(w, r, http.StatusBadRequest, problemDetail{
+				Title:   "Invalid ID",
+				Status:  http.StatusBadRequest,
+				Detail:  "The provided ID is not a valid integer.",
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		// read the page from query parameters
+		limit, offset, problems := parsePagination(r)
+		if len(problems) > 0 {
+			logger.ErrorContext(
+				ctx,
+				"Validation error",
+				slog.Any("validation_errors", problems),
+			)
+
+			_ = encodeResponse(w, r, http.StatusBadRequest, problemDetailValidation{
+				problemDetail: problemDetail{
+					Title:   "Bad Request",
+					Status:  http.StatusBadRequest,
+					Detail:  "The request contains invalid parameters.",
+					TraceID: getTraceID(ctx),
+				},
+				InvalidParams: problems,
+			})
+
+			return
+		}
+
+		logger.InfoContext(
+			ctx, "Listing blog comments",
+			slog.Int("blog_id", id),
+			slog.Int("limit", limit),
+			slog.Int("offset", offset),
+		)
+
+		// make sure the blog exists so an empty page is not mistaken for a missing blog
+		exists, err := blogExists(ctx, db, uint(id))
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to check blog", slog.String("error", err.Error()))
+			_ = encodeResponse(w, r, http.StatusInternalServerError, problemDetail{
+				Title:   "Internal Server Error",
+				Status:  http.StatusInternalServerError,
+				Detail:  "An unexpected error occurred.",
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		if !exists {
+			_ = encodeResponse(w, r, http.StatusNotFound, problemDetail{
+				Title:   "Blog Not Found",
+				Status:  http.StatusNotFound,
+				Detail:  fmt.Sprintf("Blog with ID %d not found", id),
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		// query db for the page of comments
+		var comments []comment
+		err = db.SelectContext(
+			ctx,
+			&comments,
+			`
+			SELECT id, user_id, blog_id, message, created_at
+			FROM comments
+			WHERE blog_id = $1::int
+			ORDER BY created_at, id
+			LIMIT $2 OFFSET $3
+			`,
+			id,
+			limit,
+			offset,
+		)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to query comments", slog.String("error", err.Error()))
+			_ = encodeResponse(w, r, http.StatusInternalServerError, problemDetail{
+				Title:   "Internal Server Error",
+				Status:  http.StatusInternalServerError,
+				Detail:  "An unexpected error occurred.",
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		_ = encodeResponse(w, r, http.StatusOK, toCommentResponses(comments))
+	}
+}
+
+// toCommentResponses converts []comment to []commentResponse.
+func toCommentResponses(comments []comment) []commentResponse {
+	commentResponses := make([]commentResponse, 0, len(comments))
+	for _, c := range comments {
+		commentResponses = append(commentResponses, commentResponse{
+			ID:        c.ID,
+			UserID:    c.UserID,
+			BlogID:    c.BlogID,
+			Message:   c.Message,
+			CreatedAt: c.CreatedAt,
+		})
+	}
+
+	return commentResponses
+}
@@ -0,0 +1,180 @@
+package app
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestListBlogComments(t *testing.T) {
+	t.Parallel()
+	type mockDB struct {
+		mockExistsCalled bool
+		mockBlogExists   bool
+		mockCalled       bool
+		mockInputArgs    []driver.Value
+		mockRows         *sqlmock.Rows
+		mockError        error
+	}
+
+	createdAt := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+	columns := []string{"id", "user_id", "blog_id", "message", "created_at"}
+
+	testcases := map[string]struct {
+		mockDB
+		id           string
+		query        string
+		wantStatus   int
+		wantComments []commentResponse
+	}{
+		"success": {
+			mockDB: mockDB{
+				mockExistsCalled: true,
+				mockBlogExists:   true,
+				mockCalled:       true,
+				mockInputArgs:    []driver.Value{1, defaultPageLimit, 0},
+				mockRows: sqlmock.NewRows(columns).
+					AddRow(8, 1, 1, "Great post!", createdAt).
+					AddRow(15, 6, 1, "Interesting perspective.", createdAt.Add(time.Hour)),
+				mockError: nil,
+			},
+			id:         "1",
+			wantStatus: http.StatusOK,
+			wantComments: []commentResponse{
+				{ID: 8, UserID: 1, BlogID: 1, Message: "Great post!", CreatedAt: createdAt},
+				{
+					ID:        15,
+					UserID:    6,
+					BlogID:    1,
+					Message:   "Interesting perspective.",
+					CreatedAt: createdAt.Add(time.Hour),
+				},
+			},
+		},
+		"paged": {
+			mockDB: mockDB{
+				mockExistsCalled: true,
+				mockBlogExists:   true,
+				mockCalled:       true,
+				mockInputArgs:    []driver.Value{1, 5, 10},
+				mockRows:         sqlmock.NewRows(columns),
+				mockError:        nil,
+			},
+			id:           "1",
+			query:        "?limit=5&offset=10",
+			wantStatus:   http.StatusOK,
+			wantComments: []commentResponse{},
+		},
+		"invalid_id": {
+			mockDB:     mockDB{},
+			id:         "abc",
+			wantStatus: http.StatusBadRequest,
+		},
+		"invalid_limit": {
+			mockDB:     mockDB{},
+			id:         "1",
+			query:      "?limit=1000",
+			wantStatus: http.StatusBadRequest,
+		},
+		"blog_not_found": {
+			mockDB: mockDB{
+				mockExistsCalled: true,
+				mockBlogExists:   false,
+			},
+			id:         "99",
+			wantStatus: http.StatusNotFound,
+		},
+		"db_error": {
+			mockDB: mockDB{
+				mockExistsCalled: true,
+				mockBlogExists:   true,
+				mockCalled:       true,
+				mockInputArgs:    []driver.Value{1, defaultPageLimit, 0},
+				mockRows:         nil,
+				mockError:        errors.New("db error"),
+			},
+			id:         "1",
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			logger := slog.New(slog.DiscardHandler)
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("unexpected error when opening stub db: %v", err)
+			}
+
+			defer db.Close()
+
+			sqlxDB := sqlx.NewDb(db, "pgx")
+
+			if tc.mockExistsCalled {
+				mock.
+					ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM blogs WHERE id = $1::int)`)).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(tc.mockBlogExists))
+			}
+
+			if tc.mockCalled {
+				expect := mock.
+					ExpectQuery(regexp.QuoteMeta(`
+						SELECT id, user_id, blog_id, message, created_at
+						FROM comments
+						WHERE blog_id = $1::int
+						ORDER BY created_at, id
+						LIMIT $2 OFFSET $3
+					`)).
+					WithArgs(tc.mockInputArgs...)
+				if tc.mockRows != nil {
+					expect.WillReturnRows(tc.mockRows)
+				}
+
+				expect.WillReturnError(tc.mockError)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/blog/"+tc.id+"/comments"+tc.query, nil)
+			req.SetPathValue("id", tc.id)
+
+			rec := httptest.NewRecorder()
+			handler := listBlogComments(logger, sqlxDB)
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Errorf("want status %d, got %d", tc.wantStatus, rec.Code)
+			}
+
+			if tc.wantStatus == http.StatusOK {
+				var gotComments []commentResponse
+				if err := json.NewDecoder(rec.Body).Decode(&gotComments); err != nil {
+					t.Errorf("failed to decode response body: %v", err)
+				}
+
+				if len(gotComments) != len(tc.wantComments) {
+					t.Errorf("want %d comments, got %d", len(tc.wantComments), len(gotComments))
+				}
+
+				for i := range gotComments {
+					if gotComments[i] != tc.wantComments[i] {
+						t.Errorf("want comment %+v, got %+v", tc.wantComments[i], gotComments[i])
+					}
+				}
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled db expectations: %v", err)
+			}
+		})
+	}
+}
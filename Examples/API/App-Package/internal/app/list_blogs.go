@@ -0,0 +1,71 @@
+package app
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// listBlogs is an HTTP handler function that retrieves a list of all blogs from
+// the database.
+//
+//	@Summary		List Blogs
+//	@Description	List all blogs
+//	@Tags			blog
+//	@Produce		json
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
+//	@Success		200			{array}		blogResponse
+//	@Failure		406			{object}	problemDetail
+//	@Failure		500			{object}	problemDetail
+//	@Router			/api/blog	[GET]
+func listBlogs(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
+	const funcName = "app.listBlogs"
+	logger = logger.With(slog.String("func", funcName))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		logger := logger.With(getTraceIDAsAttr(ctx))
+
+		logger.InfoContext(ctx, "Listing all blogs")
+
+		// query db to get all blogs
+		var blogs []blog
+		err := db.SelectContext(
+			ctx,
+			&blogs,
+			`
+			SELECT id, author_id, title, score
+			FROM blogs
+			ORDER BY id
+			`,
+		)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to query blogs", slog.String("error", err.Error()))
+			_ = encodeResponse(w, r, http.StatusInternalServerError, problemDetail{
+				Title:   "Internal Server Error",
+				Status:  http.StatusInternalServerError,
+				Detail:  "An unexpected error occurred.",
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		// convert []blog to []blogResponse
+		blogResponses := make([]blogResponse, 0, len(blogs))
+		for _, b := range blogs {
+			blogResponses = append(blogResponses, blogResponse{
+				ID:       b.ID,
+				AuthorID: b.AuthorID,
+				Title:    b.Title,
+				Score:    b.Score,
+			})
+		}
+
+		_ = encodeResponse(w, r, http.StatusOK, blogResponses)
+	}
+}
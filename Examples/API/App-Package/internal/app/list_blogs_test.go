@@ -0,0 +1,111 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestListBlogs(t *testing.T) {
+	t.Parallel()
+	type mockDB struct {
+		mockRows  *sqlmock.Rows
+		mockError error
+	}
+
+	testcases := map[string]struct {
+		mockDB
+		wantStatus int
+		wantBlogs  []blogResponse
+	}{
+		"success": {
+			mockDB: mockDB{
+				mockRows: sqlmock.NewRows([]string{"id", "author_id", "title", "score"}).
+					AddRow(1, 1, "First Blog Post", 8.5).
+					AddRow(2, 2, "Travel Adventures", 7.2),
+				mockError: nil,
+			},
+			wantStatus: http.StatusOK,
+			wantBlogs: []blogResponse{
+				{ID: 1, AuthorID: 1, Title: "First Blog Post", Score: 8.5},
+				{ID: 2, AuthorID: 2, Title: "Travel Adventures", Score: 7.2},
+			},
+		},
+		"empty": {
+			mockDB: mockDB{
+				mockRows:  sqlmock.NewRows([]string{"id", "author_id", "title", "score"}),
+				mockError: nil,
+			},
+			wantStatus: http.StatusOK,
+			wantBlogs:  []blogResponse{},
+		},
+		"db_error": {
+			mockDB: mockDB{
+				mockRows:  nil,
+				mockError: errors.New("db error"),
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBlogs:  nil,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			logger := slog.New(slog.DiscardHandler)
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("unexpected error when opening stub db: %v", err)
+			}
+
+			defer db.Close()
+
+			sqlxDB := sqlx.NewDb(db, "pgx")
+
+			expect := mock.ExpectQuery(regexp.QuoteMeta(`
+						SELECT id, author_id, title, score
+						FROM blogs
+						ORDER BY id
+					`))
+			if tc.mockRows != nil {
+				expect.WillReturnRows(tc.mockRows)
+			}
+
+			expect.WillReturnError(tc.mockError)
+
+			req := httptest.NewRequest(http.MethodGet, "/blog", nil)
+			rec := httptest.NewRecorder()
+			handler := listBlogs(logger, sqlxDB)
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Errorf("want status %d, got %d", tc.wantStatus, rec.Code)
+			}
+
+			if tc.wantStatus == http.StatusOK {
+				var gotBlogs []blogResponse
+				if err := json.NewDecoder(rec.Body).Decode(&gotBlogs); err != nil {
+					t.Errorf("failed to decode response body: %v", err)
+				}
+
+				if len(gotBlogs) != len(tc.wantBlogs) {
+					t.Errorf("want %d blogs, got %d", len(tc.wantBlogs), len(gotBlogs))
+				}
+
+				for i := range gotBlogs {
+					if gotBlogs[i] != tc.wantBlogs[i] {
+						t.Errorf("want blog %+v, got %+v", tc.wantBlogs[i], gotBlogs[i])
+					}
+				}
+			}
+		})
+	}
+}
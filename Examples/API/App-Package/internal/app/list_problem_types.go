@@ -0,0 +1,61 @@
+package app
+
+import (
+	"html/template"
+	"log/slog"
+	"net/http"
+)
+
+// problemTypesTemplate renders the index of every problem type.
+var problemTypesTemplate = template.Must(template.New("problem_types").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Problem Types</title>
+</head>
+<body>
+<h1>Problem Types</h1>
+<p>Errors are returned as problem details (RFC 9457), as <code>application/problem+json</code> by default.
+The <code>type</code> member names one of the types below and <code>instance</code> is the path of the
+request. Every problem also carries <code>traceId</code>, the ID of the trace of the request.</p>
+<table>
+<thead><tr><th>Status</th><th>Type</th><th>Title</th><th>Description</th></tr></thead>
+<tbody>
+{{- range .}}
+<tr>
+<td>{{.Status}}</td><td><a href="{{.URI}}"><code>{{.Slug}}</code></a></td>
+<td>{{.Title}}</td><td>{{.Description}}</td>
+</tr>
+{{- end}}
+</tbody>
+</table>
+</body>
+</html>
+`))
+
+// listProblemTypes is an HTTP handler function that serves the human-readable index of every problem type.
+//
+//	@Summary		List Problem Types
+//	@Description	Human-readable index of every problem type
+//	@Tags			problem
+//	@Produce		html
+//	@Success		200				{string}	string
+//	@Router			/api/problems/	[GET]
+func listProblemTypes(logger *slog.Logger) http.HandlerFunc {
+	const funcName = "app.listProblemTypes"
+	logger = logger.With(slog.String("func", funcName))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := problemTypesTemplate.Execute(w, problemTypes); err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to render problem types",
+				getTraceIDAsAttr(ctx),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package app
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListProblemTypes(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/problems/", nil)
+	rec := httptest.NewRecorder()
+
+	handler := listProblemTypes(slog.New(slog.DiscardHandler))
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+
+	for _, pt := range problemTypes {
+		assert.Contains(t, rec.Body.String(), `<a href="`+pt.URI()+`">`)
+	}
+}
@@ -0,0 +1,145 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// listUserComments is an HTTP handler function that retrieves a page of the comments
+// written by a user from the database, oldest first.
+//
+//	@Summary		List User Comments
+//	@Description	List the comments written by a user, oldest first
+//	@Tags			comment
+//	@Produce		json
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
+//	@Param			id		path		string	true	"User ID"
+//	@Param			limit	query		int		false	"Page size (1-100, default 20)"
+//	@Param			offset	query		int		false	"Number of comments to skip"
+//	@Success		200		{array}		commentResponse
+//	@Failure		400		{object}	problemDetailValidation
+//	@Failure		404		{object}	problemDetail
+//	@Failure		406		{object}	problemDetail
+//	@Failure		500		{object}	problemDetail
+//	@Router			/api/user/{id}/comments [GET]
+func listUserComments(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
+	const funcName = "app.listUserComments"
+	logger = logger.With(slog.String("func", funcName))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		logger := logger.With(getTraceIDAsAttr(ctx))
+
+		// read id from path parameters
+		idStr := r.PathValue("id")
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to parse id from url",
+				slog.String("id", idStr),
+				slog.String("error", err.Error()),
+			)
+
+			_ = encodeResponse(w, r, http.StatusBadRequest, problemDetail{
+				Title:   "Invalid ID",
+				Status:  http.StatusBadRequest,
+				Detail:  "The provided ID is not a valid integer.",
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		// read the page from query parameters
+		limit, offset, problems := parsePagination(r)
+		if len(problems) > 0 {
+			logger.ErrorContext(
+				ctx,
+				"Validation error",
+				slog.Any("validation_errors", problems),
+			)
+
+			_ = encodeResponse(w, r, http.StatusBadRequest, problemDetailValidation{
+				problemDetail: problemDetail{
+					Title:   "Bad Request",
+					Status:  http.StatusBadRequest,
+					Detail:  "The request contains invalid parameters.",
+					TraceID: getTraceID(ctx),
+				},
+				InvalidParams: problems,
+			})
+
+			return
+		}
+
+		logger.InfoContext(
+			ctx, "Listing user comments",
+			slog.Int("user_id", id),
+			slog.Int("limit", limit),
+			slog.Int("offset", offset),
+		)
+
+		// make sure the user exists so an empty page is not mistaken for a missing user
+		exists, err := userExists(ctx, db, uint(id))
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to check user", slog.String("error", err.Error()))
+			_ = encodeResponse(w, r, http.StatusInternalServerError, problemDetail{
+				Title:   "Internal Server Error",
+				Status:  http.StatusInternalServerError,
+				Detail:  "An unexpected error occurred.",
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		if !exists {
+			_ = encodeResponse(w, r, http.StatusNotFound, problemDetail{
+				Title:   "User Not Found",
+				Status:  http.StatusNotFound,
+				Detail:  fmt.Sprintf("User with ID %d not found", id),
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		// query db for the page of comments
+		var comments []comment
+		err = db.SelectContext(
+			ctx,
+			&comments,
+			`
+			SELECT id, user_id, blog_id, message, created_at
+			FROM comments
+			WHERE user_id = $1::int
+			ORDER BY created_at, id
+			LIMIT $2 OFFSET $3
+			`,
+			id,
+			limit,
+			offset,
+		)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to query comments", slog.String("error", err.Error()))
+			_ = encodeResponse(w, r, http.StatusInternalServerError, problemDetail{
+				Title:   "Internal Server Error",
+				Status:  http.StatusInternalServerError,
+				Detail:  "An unexpected error occurred.",
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		_ = encodeResponse(w, r, http.StatusOK, toCommentResponses(comments))
+	}
+}
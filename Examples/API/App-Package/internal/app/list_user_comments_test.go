@@ -0,0 +1,131 @@
+package app
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestListUserComments(t *testing.T) {
+	t.Parallel()
+	type mockDB struct {
+		mockExistsCalled bool
+		mockUserExists   bool
+		mockCalled       bool
+		mockInputArgs    []driver.Value
+		mockRows         *sqlmock.Rows
+	}
+
+	createdAt := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+	columns := []string{"id", "user_id", "blog_id", "message", "created_at"}
+
+	testcases := map[string]struct {
+		mockDB
+		id           string
+		wantStatus   int
+		wantComments []commentResponse
+	}{
+		"success": {
+			mockDB: mockDB{
+				mockExistsCalled: true,
+				mockUserExists:   true,
+				mockCalled:       true,
+				mockInputArgs:    []driver.Value{2, defaultPageLimit, 0},
+				mockRows:         sqlmock.NewRows(columns).AddRow(2, 2, 11, "I agree.", createdAt),
+			},
+			id:         "2",
+			wantStatus: http.StatusOK,
+			wantComments: []commentResponse{
+				{ID: 2, UserID: 2, BlogID: 11, Message: "I agree.", CreatedAt: createdAt},
+			},
+		},
+		"invalid_id": {
+			mockDB:     mockDB{},
+			id:         "abc",
+			wantStatus: http.StatusBadRequest,
+		},
+		"user_not_found": {
+			mockDB: mockDB{
+				mockExistsCalled: true,
+				mockUserExists:   false,
+			},
+			id:         "99",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			logger := slog.New(slog.DiscardHandler)
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("unexpected error when opening stub db: %v", err)
+			}
+
+			defer db.Close()
+
+			sqlxDB := sqlx.NewDb(db, "pgx")
+
+			if tc.mockExistsCalled {
+				mock.
+					ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM users WHERE id = $1::int)`)).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(tc.mockUserExists))
+			}
+
+			if tc.mockCalled {
+				mock.
+					ExpectQuery(regexp.QuoteMeta(`
+						SELECT id, user_id, blog_id, message, created_at
+						FROM comments
+						WHERE user_id = $1::int
+						ORDER BY created_at, id
+						LIMIT $2 OFFSET $3
+					`)).
+					WithArgs(tc.mockInputArgs...).
+					WillReturnRows(tc.mockRows)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/user/"+tc.id+"/comments", nil)
+			req.SetPathValue("id", tc.id)
+
+			rec := httptest.NewRecorder()
+			handler := listUserComments(logger, sqlxDB)
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Errorf("want status %d, got %d", tc.wantStatus, rec.Code)
+			}
+
+			if tc.wantStatus == http.StatusOK {
+				var gotComments []commentResponse
+				if err := json.NewDecoder(rec.Body).Decode(&gotComments); err != nil {
+					t.Errorf("failed to decode response body: %v", err)
+				}
+
+				if len(gotComments) != len(tc.wantComments) {
+					t.Errorf("want %d comments, got %d", len(tc.wantComments), len(gotComments))
+				}
+
+				for i := range gotComments {
+					if gotComments[i] != tc.wantComments[i] {
+						t.Errorf("want comment %+v, got %+v", tc.wantComments[i], gotComments[i])
+					}
+				}
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled db expectations: %v", err)
+			}
+		})
+	}
+}
@@ -7,14 +7,21 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
-// listUsers is an HTTP handler function that retrieves a list of all users from
-// the database.
+// listUsers is an HTTP handler function that retrieves a page of users from the database, ordered by id.
+// Pages are keyed on the last id seen, which the client passes back as an opaque cursor.
 //
 //	@Summary		List Users
-//	@Description	List all users
+//	@Description	List a page of users. Pass the returned next cursor to fetch the following page.
 //	@Tags			user
 //	@Produce		json
-//	@Success		200			{array}		user
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
+//	@Param			limit		query		int		false	"Page size (1-100, default 20)"
+//	@Param			cursor		query		string	false	"Opaque cursor returned as next by the previous page"
+//	@Success		200			{object}	listUsersResponse
+//	@Failure		400			{object}	problemDetailValidation
+//	@Failure		406			{object}	problemDetail
 //	@Failure		500			{object}	problemDetail
 //	@Router			/api/user	[GET]
 func listUsers(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
@@ -24,23 +31,54 @@ func listUsers(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
-		logger = logger.With(getTraceIDAsAttr(ctx))
+		logger := logger.With(getTraceIDAsAttr(ctx))
 
-		logger.InfoContext(ctx, "Listing all users")
+		// read the page from query parameters
+		limit, cursor, problems := parseCursorPagination[usersCursor](r)
+		if len(problems) > 0 {
+			logger.ErrorContext(
+				ctx,
+				"Validation error",
+				slog.Any("validation_errors", problems),
+			)
 
-		// query db to get all users
+			_ = encodeResponse(w, r, http.StatusBadRequest, problemDetailValidation{
+				problemDetail: problemDetail{
+					Title:   "Bad Request",
+					Status:  http.StatusBadRequest,
+					Detail:  "The request contains invalid parameters.",
+					TraceID: getTraceID(ctx),
+				},
+				InvalidParams: problems,
+			})
+
+			return
+		}
+
+		logger.InfoContext(
+			ctx, "Listing users",
+			slog.Uint64("after_id", uint64(cursor.AfterID)),
+			slog.Int("limit", limit),
+		)
+
+		// query db for the page of users, plus one more to find out whether there is a next page
 		var users []user
 		err := db.SelectContext(
 			ctx,
 			&users,
 			`
-            SELECT id, name, email, password
-            FROM users
-            `,
+			SELECT id, name, email
+			FROM users
+			WHERE id > $1::int
+			ORDER BY id
+			LIMIT $2
+			`,
+			cursor.AfterID,
+			limit+1,
 		)
 		if err != nil {
 			logger.ErrorContext(ctx, "failed to query users", slog.String("error", err.Error()))
-			_ = encodeResponseJSON(w, http.StatusInternalServerError, problemDetail{
+			_ = encodeResponse(w, r, http.StatusInternalServerError, problemDetail{
 				Title:   "Internal Server Error",
 				Status:  http.StatusInternalServerError,
 				Detail:  "An unexpected error occurred.",
@@ -50,16 +88,39 @@ func listUsers(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
 			return
 		}
 
+		response := listUsersResponse{
+			Users: make([]userResponse, 0, min(len(users), limit)),
+			Limit: limit,
+		}
+
+		if len(users) > limit {
+			users = users[:limit]
+
+			next, err := encodeCursor(usersCursor{AfterID: users[limit-1].ID})
+			if err != nil {
+				logger.ErrorContext(ctx, "failed to encode next cursor", slog.String("error", err.Error()))
+				_ = encodeResponse(w, r, http.StatusInternalServerError, problemDetail{
+					Title:   "Internal Server Error",
+					Status:  http.StatusInternalServerError,
+					Detail:  "An unexpected error occurred.",
+					TraceID: getTraceID(ctx),
+				})
+
+				return
+			}
+
+			response.Next = next
+		}
+
 		// Convert []user to []userResponse to exclude password
-		userResponses := make([]userResponse, 0, len(users))
 		for _, u := range users {
-			userResponses = append(userResponses, userResponse{
+			response.Users = append(response.Users, userResponse{
 				ID:    u.ID,
 				Name:  u.Name,
 				Email: u.Email,
 			})
 		}
 
-		_ = encodeResponseJSON(w, http.StatusOK, userResponses)
+		_ = encodeResponse(w, r, http.StatusOK, response)
 	}
 }
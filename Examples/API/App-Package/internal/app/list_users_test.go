@@ -1,6 +1,7 @@
 package app
 
 import (
+	"database/sql/driver"
 	"encoding/json"
 	"errors"
 	"log/slog"
@@ -16,52 +17,104 @@ import (
 func TestListUsers(t *testing.T) {
 	t.Parallel()
 	type mockDB struct {
-		mockRows  *sqlmock.Rows
-		mockError error
+		mockCalled bool
+		mockArgs   []driver.Value
+		mockRows   *sqlmock.Rows
+		mockError  error
+	}
+
+	secondPage, err := encodeCursor(usersCursor{AfterID: 2})
+	if err != nil {
+		t.Fatalf("failed to encode cursor: %v", err)
 	}
 
 	testcases := map[string]struct {
 		mockDB
-		wantStatus int
-		wantUsers  []userResponse
+		query        string
+		wantStatus   int
+		wantUsers    []userResponse
+		wantNext     string
+		wantProblems []string
 	}{
 		"success": {
 			mockDB: mockDB{
-				mockRows: sqlmock.NewRows([]string{"id", "name", "email", "password"}).
-					AddRow(1, "Alice", "alice@example.com", "pw1").
-					AddRow(2, "Bob", "bob@example.com", "pw2"),
-				mockError: nil,
+				mockCalled: true,
+				mockArgs:   []driver.Value{0, defaultPageLimit + 1},
+				mockRows: sqlmock.NewRows([]string{"id", "name", "email"}).
+					AddRow(1, "Alice", "alice@example.com").
+					AddRow(2, "Bob", "bob@example.com"),
+			},
+			wantStatus: http.StatusOK,
+			wantUsers: []userResponse{
+				{ID: 1, Name: "Alice", Email: "alice@example.com"},
+				{ID: 2, Name: "Bob", Email: "bob@example.com"},
+			},
+		},
+		"page_with_next": {
+			mockDB: mockDB{
+				mockCalled: true,
+				mockArgs:   []driver.Value{0, 3},
+				mockRows: sqlmock.NewRows([]string{"id", "name", "email"}).
+					AddRow(1, "Alice", "alice@example.com").
+					AddRow(2, "Bob", "bob@example.com").
+					AddRow(3, "Carol", "carol@example.com"),
 			},
+			query:      "?limit=2",
 			wantStatus: http.StatusOK,
 			wantUsers: []userResponse{
 				{ID: 1, Name: "Alice", Email: "alice@example.com"},
 				{ID: 2, Name: "Bob", Email: "bob@example.com"},
 			},
+			wantNext: secondPage,
+		},
+		"page_from_cursor": {
+			mockDB: mockDB{
+				mockCalled: true,
+				mockArgs:   []driver.Value{2, 3},
+				mockRows: sqlmock.NewRows([]string{"id", "name", "email"}).
+					AddRow(3, "Carol", "carol@example.com"),
+			},
+			query:      "?limit=2&cursor=" + secondPage,
+			wantStatus: http.StatusOK,
+			wantUsers: []userResponse{
+				{ID: 3, Name: "Carol", Email: "carol@example.com"},
+			},
 		},
 		"empty": {
 			mockDB: mockDB{
-				mockRows:  sqlmock.NewRows([]string{"id", "name", "email", "password"}),
-				mockError: nil,
+				mockCalled: true,
+				mockArgs:   []driver.Value{0, defaultPageLimit + 1},
+				mockRows:   sqlmock.NewRows([]string{"id", "name", "email"}),
 			},
 			wantStatus: http.StatusOK,
 			wantUsers:  []userResponse{},
 		},
+		"invalid_limit": {
+			query:        "?limit=101",
+			wantStatus:   http.StatusBadRequest,
+			wantProblems: []string{"limit"},
+		},
+		"invalid_cursor": {
+			query:        "?cursor=not-a-cursor",
+			wantStatus:   http.StatusBadRequest,
+			wantProblems: []string{"cursor"},
+		},
 		"scan_error": {
 			mockDB: mockDB{
-				mockRows: sqlmock.NewRows([]string{"id", "name", "email", "password"}).
-					AddRow("bad_id", "Charlie", "charlie@example.com", "pw3"),
-				mockError: nil,
+				mockCalled: true,
+				mockArgs:   []driver.Value{0, defaultPageLimit + 1},
+				mockRows: sqlmock.NewRows([]string{"id", "name", "email"}).
+					AddRow("bad_id", "Charlie", "charlie@example.com"),
 			},
 			wantStatus: http.StatusInternalServerError,
-			wantUsers:  nil,
 		},
 		"db_error": {
 			mockDB: mockDB{
-				mockRows:  nil,
-				mockError: errors.New("db error"),
+				mockCalled: true,
+				mockArgs:   []driver.Value{0, defaultPageLimit + 1},
+				mockError:  errors.New("db error"),
 			},
 			wantStatus: http.StatusInternalServerError,
-			wantUsers:  nil,
 		},
 	}
 
@@ -79,17 +132,22 @@ func TestListUsers(t *testing.T) {
 
 			sqlxDB := sqlx.NewDb(db, "pgx")
 
-			expect := mock.ExpectQuery(regexp.QuoteMeta(`
-						SELECT id, name, email, password
-						FROM users
-					`))
-			if tc.mockRows != nil {
-				expect.WillReturnRows(tc.mockRows)
-			}
+			if tc.mockCalled {
+				expect := mock.ExpectQuery(regexp.QuoteMeta(`
+					SELECT id, name, email
+					FROM users
+					WHERE id > $1::int
+					ORDER BY id
+					LIMIT $2
+				`)).WithArgs(tc.mockArgs...)
+				if tc.mockRows != nil {
+					expect.WillReturnRows(tc.mockRows)
+				}
 
-			expect.WillReturnError(tc.mockError)
+				expect.WillReturnError(tc.mockError)
+			}
 
-			req := httptest.NewRequest(http.MethodGet, "/user", nil)
+			req := httptest.NewRequest(http.MethodGet, "/user"+tc.query, nil)
 			rec := httptest.NewRecorder()
 			handler := listUsers(logger, sqlxDB)
 			handler.ServeHTTP(rec, req)
@@ -98,22 +156,46 @@ func TestListUsers(t *testing.T) {
 				t.Errorf("want status %d, got %d", tc.wantStatus, rec.Code)
 			}
 
-			if tc.wantStatus == http.StatusOK {
-				var gotUsers []userResponse
-				if err := json.NewDecoder(rec.Body).Decode(&gotUsers); err != nil {
+			switch tc.wantStatus {
+			case http.StatusOK:
+				var got listUsersResponse
+				if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+					t.Errorf("failed to decode response body: %v", err)
+				}
+
+				if len(got.Users) != len(tc.wantUsers) {
+					t.Fatalf("want %d users, got %d", len(tc.wantUsers), len(got.Users))
+				}
+
+				for i := range got.Users {
+					if got.Users[i] != tc.wantUsers[i] {
+						t.Errorf("want user %+v, got %+v", tc.wantUsers[i], got.Users[i])
+					}
+				}
+
+				if got.Next != tc.wantNext {
+					t.Errorf("want next %q, got %q", tc.wantNext, got.Next)
+				}
+			case http.StatusBadRequest:
+				var got problemDetailValidation
+				if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
 					t.Errorf("failed to decode response body: %v", err)
 				}
 
-				if len(gotUsers) != len(tc.wantUsers) {
-					t.Errorf("want %d users, got %d", len(tc.wantUsers), len(gotUsers))
+				if len(got.InvalidParams) != len(tc.wantProblems) {
+					t.Fatalf("want %d invalid params, got %+v", len(tc.wantProblems), got.InvalidParams)
 				}
 
-				for i := range gotUsers {
-					if gotUsers[i] != tc.wantUsers[i] {
-						t.Errorf("want user %+v, got %+v", tc.wantUsers[i], gotUsers[i])
+				for i, field := range tc.wantProblems {
+					if got.InvalidParams[i].Field != field {
+						t.Errorf("want invalid param %q, got %q", field, got.InvalidParams[i].Field)
 					}
 				}
 			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unmet sqlmock expectations: %v", err)
+			}
 		})
 	}
 }
@@ -1,12 +1,10 @@
 package app
 
 import (
-	"encoding/json"
-	"errors"
+	"encoding/xml"
 	"fmt"
 	"net/http"
-
-	"github.com/go-playground/validator/v10"
+	"time"
 )
 
 // user represents a user entity in the application.
@@ -17,55 +15,157 @@ type user struct {
 	Password string
 }
 
-// userRequest represents the structure for creating or updating a user.
+// createUserRequest represents the structure for creating a user. Unlike an update, its email must not be taken by
+// any user.
+type createUserRequest struct {
+	XMLName  xml.Name `json:"-"        xml:"user"     swaggerignore:"true"`
+	Name     string   `json:"name"     xml:"name"     validate:"required,min=2,max=50"`
+	Email    string   `json:"email"    xml:"email"    validate:"required,email,email_domain,unique_email"`
+	Password string   `json:"password" xml:"password" validate:"required,min=8,max=30,password"`
+}
+
+// userRequest represents the structure for updating a user.
 type userRequest struct {
-	ID       uint   `json:"id"`
-	Name     string `json:"name"     validate:"required,min=2,max=50"`
-	Email    string `json:"email"    validate:"required,email"`
-	Password string `json:"password" validate:"required,min=8,max=30"`
+	XMLName  xml.Name `json:"-"        xml:"user"     swaggerignore:"true"`
+	ID       uint     `json:"id"       xml:"id"`
+	Name     string   `json:"name"     xml:"name"     validate:"required,min=2,max=50"`
+	Email    string   `json:"email"    xml:"email"    validate:"required,email,email_domain"`
+	Password string   `json:"password" xml:"password" validate:"required,min=8,max=30,password"`
 }
 
 // userResponse represents the structure for returning user data in API responses.
 // It excludes the password field for security reasons.
 type userResponse struct {
-	ID    uint   `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	XMLName xml.Name `json:"-"     xml:"user"  swaggerignore:"true"`
+	ID      uint     `json:"id"    xml:"id"`
+	Name    string   `json:"name"  xml:"name"`
+	Email   string   `json:"email" xml:"email"`
+}
+
+// usersCursor represents the page position encoded in a users list cursor.
+type usersCursor struct {
+	AfterID uint `json:"afterId"`
+}
+
+// listUsersResponse represents a page of users.
+// Next is the cursor for the following page and is omitted on the last page.
+type listUsersResponse struct {
+	XMLName xml.Name       `json:"-"              xml:"userPage"       swaggerignore:"true"`
+	Users   []userResponse `json:"users"          xml:"users>user"`
+	Limit   int            `json:"limit"          xml:"limit"`
+	Next    string         `json:"next,omitempty" xml:"next,omitempty"`
+}
+
+// blog represents a blog entity in the application.
+type blog struct {
+	ID       uint    `db:"id"`
+	AuthorID uint    `db:"author_id"`
+	Title    string  `db:"title"`
+	Score    float32 `db:"score"`
+}
+
+// blogRequest represents the structure for creating or updating a blog.
+type blogRequest struct {
+	XMLName  xml.Name `json:"-"        xml:"blog"     swaggerignore:"true"`
+	AuthorID uint     `json:"authorId" xml:"authorId" validate:"required"`
+	Title    string   `json:"title"    xml:"title"    validate:"required,min=1,max=200"`
+}
+
+// blogResponse represents the structure for returning blog data in API responses.
+type blogResponse struct {
+	XMLName  xml.Name `json:"-"        xml:"blog"     swaggerignore:"true"`
+	ID       uint     `json:"id"       xml:"id"`
+	AuthorID uint     `json:"authorId" xml:"authorId"`
+	Title    string   `json:"title"    xml:"title"`
+	Score    float32  `json:"score"    xml:"score"`
+}
+
+// comment represents a comment left by a user on a blog.
+type comment struct {
+	ID        uint      `db:"id"`
+	UserID    uint      `db:"user_id"`
+	BlogID    uint      `db:"blog_id"`
+	Message   string    `db:"message"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// commentRequest represents the structure for creating a comment on a blog.
+type commentRequest struct {
+	XMLName xml.Name `json:"-"       xml:"comment" swaggerignore:"true"`
+	UserID  uint     `json:"userId"  xml:"userId"  validate:"required"`
+	Message string   `json:"message" xml:"message" validate:"required,min=1,max=1000"`
 }
 
-// problemDetail represents the structure for problem details as per RFC 7807.
+// commentResponse represents the structure for returning comment data in API responses.
+type commentResponse struct {
+	XMLName   xml.Name  `json:"-"         xml:"comment"   swaggerignore:"true"`
+	ID        uint      `json:"id"        xml:"id"`
+	UserID    uint      `json:"userId"    xml:"userId"`
+	BlogID    uint      `json:"blogId"    xml:"blogId"`
+	Message   string    `json:"message"   xml:"message"`
+	CreatedAt time.Time `json:"createdAt" xml:"createdAt"`
+}
+
+// problemDetail represents the structure for problem details as per RFC 9457. Type is the URI of one of the
+// problemTypes and Instance the path of the request, both filled in by encodeResponse when left empty. In XML it is
+// encoded in the RFC's format.
 type problemDetail struct {
-	Title   string `json:"title"`             // A short, human-readable summary of the problem.
-	Status  int    `json:"status"`            // The HTTP status code generated by the origin server.
-	Detail  string `json:"detail"`            // A human-readable explanation about the occurrence of the problem.
-	TraceID string `json:"traceId,omitempty"` // An optional trace ID for debugging purposes.
+	XMLName xml.Name `json:"-" xml:"urn:ietf:rfc:7807 problem" swaggerignore:"true"`
+
+	// A URI identifying the problem type.
+	Type string `json:"type" xml:"type"`
+
+	// A short, human-readable summary of the problem.
+	Title string `json:"title" xml:"title"`
+
+	// The HTTP status code generated by the origin server.
+	Status int `json:"status" xml:"status"`
+
+	// A human-readable explanation about the occurrence of the problem.
+	Detail string `json:"detail" xml:"detail"`
+
+	// The path of the request the problem occurred on.
+	Instance string `json:"instance,omitempty" xml:"instance,omitempty"`
+
+	// An optional trace ID for debugging purposes.
+	TraceID string `json:"traceId,omitempty" xml:"traceId,omitempty"`
 }
 
 // problemDetailValidation extends problemDetail to include invalid parameters for validation errors.
 type problemDetailValidation struct {
 	problemDetail
-	InvalidParams []validationProblem `json:"invalidParams"` // A list of invalid parameters with error details.
+	// A list of invalid parameters with error details.
+	InvalidParams []validationProblem `json:"invalidParams" xml:"invalidParams>i"`
 }
 
 // validationProblem describes a single validation error for a field.
 type validationProblem struct {
-	Field   string `json:"field"`
-	Code    string `json:"code"`
-	Message string `json:"message"`
+	Field   string `json:"field"   xml:"field"`
+	Code    string `json:"code"    xml:"code"`
+	Message string `json:"message" xml:"message"`
 }
 
-// decodeValid decodes a model from an http request and performs validation on it.
+// decodeValid decodes a model from an http request, in the media type given by its Content-Type header, and
+// performs validation on it with validate. Validation messages are in the language the Accept-Language header prefers.
 // Returns the decoded model, a slice of validation problems, and an error if decoding or validation fails.
-func decodeValid[T any](r *http.Request) (T, []validationProblem, error) {
+func decodeValid[T any](r *http.Request, validate *Validator) (T, []validationProblem, error) {
 	var v T
-	if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+	c, ok := requestCodec(r)
+	if !ok {
+		return v, []validationProblem{}, fmt.Errorf(
+			"[in handlers.decodeValid] %w: %s",
+			errUnsupportedMediaType,
+			r.Header.Get("Content-Type"),
+		)
+	}
+	if err := c.decode(r.Body, &v); err != nil {
 		return v, []validationProblem{}, fmt.Errorf(
 			"[in handlers.decodeValid] decode body failed: %w",
 			err,
 		)
 	}
 
-	problems, err := validate(&v)
+	problems, err := validate.validateStruct(r.Context(), &v)
 	if err != nil {
 		return v, []validationProblem{}, fmt.Errorf(
 			"[in handlers.decodeValid] validate failed: %w",
@@ -74,12 +174,13 @@ func decodeValid[T any](r *http.Request) (T, []validationProblem, error) {
 	}
 
 	if len(problems) > 0 {
+		trans := translatorFor(r)
 		validationProblems := make([]validationProblem, len(problems))
 		for i, problem := range problems {
 			validationProblems[i] = validationProblem{
 				Field:   problem.Field(),
 				Code:    problem.Tag(),
-				Message: problem.Error(),
+				Message: problem.Translate(trans),
 			}
 		}
 
@@ -88,21 +189,3 @@ func decodeValid[T any](r *http.Request) (T, []validationProblem, error) {
 
 	return v, []validationProblem{}, nil
 }
-
-// validate validates the struct using the go-playground/validator package.
-// Returns a slice of FieldError if validation fails, or an error if an invalid validation occurs.
-func validate[T any](data *T, options ...validator.Option) ([]validator.FieldError, error) {
-	v := validator.New(options...)
-	if err := v.Struct(data); err != nil {
-		var invalidValidationError *validator.InvalidValidationError
-		if errors.As(err, &invalidValidationError) {
-			return []validator.FieldError{}, fmt.Errorf(
-				"[in handlers.validate] invalid validation error: %w",
-				err,
-			)
-		}
-		return err.(validator.ValidationErrors), nil
-	}
-
-	return []validator.FieldError{}, nil
-}
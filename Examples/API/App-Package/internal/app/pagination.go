@@ -0,0 +1,104 @@
+package app
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+const (
+	// defaultPageLimit is the page size used when no limit is requested.
+	defaultPageLimit = 20
+	// maxPageLimit is the largest page size a client may request.
+	maxPageLimit = 100
+)
+
+// parsePagination reads the limit and offset query parameters from the request.
+// Missing parameters fall back to their defaults; invalid ones are returned as validation problems.
+func parsePagination(r *http.Request) (limit int, offset int, problems []validationProblem) {
+	limit, problems = parseLimit(r)
+
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		v, err := strconv.Atoi(offsetStr)
+		if err != nil || v < 0 {
+			problems = append(problems, validationProblem{
+				Field:   "offset",
+				Code:    "min",
+				Message: "offset must be a non-negative integer",
+			})
+		} else {
+			offset = v
+		}
+	}
+
+	return limit, offset, problems
+}
+
+// parseLimit reads the limit query parameter from the request, falling back to the default page size if it is missing.
+// An invalid limit is returned as a validation problem.
+func parseLimit(r *http.Request) (limit int, problems []validationProblem) {
+	limitStr := r.URL.Query().Get("limit")
+	if limitStr == "" {
+		return defaultPageLimit, nil
+	}
+
+	v, err := strconv.Atoi(limitStr)
+	if err != nil || v < 1 || v > maxPageLimit {
+		return defaultPageLimit, []validationProblem{{
+			Field:   "limit",
+			Code:    "range",
+			Message: fmt.Sprintf("limit must be an integer between 1 and %d", maxPageLimit),
+		}}
+	}
+
+	return v, nil
+}
+
+// parseCursorPagination reads the limit and cursor query parameters from the request.
+// A missing cursor leaves the zero value of T, which starts at the first page; invalid parameters are returned as
+// validation problems.
+func parseCursorPagination[T any](r *http.Request) (limit int, cursor T, problems []validationProblem) {
+	limit, problems = parseLimit(r)
+
+	if cursorStr := r.URL.Query().Get("cursor"); cursorStr != "" {
+		if err := decodeCursor(cursorStr, &cursor); err != nil {
+			problems = append(problems, validationProblem{
+				Field:   "cursor",
+				Code:    "invalid",
+				Message: "cursor must be a value returned as next by a previous request",
+			})
+		}
+	}
+
+	return limit, cursor, problems
+}
+
+// encodeCursor encodes a page position as an opaque cursor.
+// Clients should pass the cursor back unchanged rather than relying on its contents.
+func encodeCursor(position any) (string, error) {
+	b, err := json.Marshal(position)
+	if err != nil {
+		return "", fmt.Errorf("[in app.encodeCursor] failed to marshal cursor: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// decodeCursor decodes an opaque cursor created by encodeCursor into the page position.
+func decodeCursor(cursor string, position any) error {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return fmt.Errorf("[in app.decodeCursor] failed to decode cursor: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(position); err != nil {
+		return fmt.Errorf("[in app.decodeCursor] failed to unmarshal cursor: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,18 @@
+package app
+
+import (
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// hashPassword returns the bcrypt hash of the provided plaintext password using the provided
+// cost. Only the hash is ever written to the database.
+func hashPassword(password string, cost int) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+	if err != nil {
+		return "", fmt.Errorf("[in app.hashPassword] failed to hash password: %w", err)
+	}
+
+	return string(hash), nil
+}
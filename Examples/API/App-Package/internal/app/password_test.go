@@ -0,0 +1,67 @@
+package app
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// bcryptHash is a sqlmock.Argument matching a bcrypt hash of password.
+type bcryptHash struct {
+	password string
+}
+
+// Match satisfies sqlmock.Argument.
+func (h bcryptHash) Match(v driver.Value) bool {
+	hash, ok := v.(string)
+	if !ok {
+		return false
+	}
+
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(h.password)) == nil
+}
+
+func TestHashPassword(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		password string
+		cost     int
+		wantErr  bool
+	}{
+		"success": {
+			password: "supersecret",
+			cost:     bcrypt.MinCost,
+			wantErr:  false,
+		},
+		"invalid_cost": {
+			password: "supersecret",
+			cost:     bcrypt.MaxCost + 1,
+			wantErr:  true,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			hash, err := hashPassword(tc.password, tc.cost)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("hashPassword() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			if tc.wantErr {
+				return
+			}
+
+			if hash == tc.password {
+				t.Error("hashPassword() returned the plaintext password")
+			}
+
+			if !(bcryptHash{password: tc.password}).Match(hash) {
+				t.Errorf("hashPassword() = %q, does not match password", hash)
+			}
+		})
+	}
+}
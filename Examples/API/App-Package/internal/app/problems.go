@@ -0,0 +1,122 @@
+package app
+
+import (
+	"net/http"
+)
+
+// problemTypesPath is the path under which the documentation of every problem type is served, and so the prefix
+// of every problem type URI.
+const problemTypesPath = "/api/problems/"
+
+// problemType is a problem type as described by RFC 9457. Every problem detail the API returns names one of these
+// types in its type member, so that clients can tell problems apart by a stable URI rather than by its title.
+type problemType struct {
+	Slug        string
+	Title       string
+	Status      int
+	Description string
+}
+
+// URI returns the URI of the problem type, or about:blank for the zero problemType, which RFC 9457 defines as a
+// problem with no semantics beyond its status code.
+func (t problemType) URI() string {
+	if t.Slug == "" {
+		return "about:blank"
+	}
+
+	return problemTypesPath + t.Slug
+}
+
+// The problem types of the API.
+var (
+	badRequestProblem = problemType{
+		Slug:        "bad-request",
+		Title:       "Bad Request",
+		Status:      http.StatusBadRequest,
+		Description: "The request could not be understood, such as a malformed body or an ID that is not a number.",
+	}
+	invalidParametersProblem = problemType{
+		Slug:   "invalid-parameters",
+		Title:  "Bad Request",
+		Status: http.StatusBadRequest,
+		Description: "One or more parameters of the request failed validation. " +
+			"The invalidParams member lists each of them.",
+	}
+	notFoundProblem = problemType{
+		Slug:        "not-found",
+		Title:       "Not Found",
+		Status:      http.StatusNotFound,
+		Description: "The requested resource, or one the request refers to, does not exist.",
+	}
+	notAcceptableProblem = problemType{
+		Slug:        "not-acceptable",
+		Title:       "Not Acceptable",
+		Status:      http.StatusNotAcceptable,
+		Description: "The response cannot be encoded in any media type the Accept header of the request accepts.",
+	}
+	conflictProblem = problemType{
+		Slug:   "conflict",
+		Title:  "Conflict",
+		Status: http.StatusConflict,
+		Description: "The request conflicts with the current state of a resource, such as a value that is already " +
+			"taken. The invalidParams member names the offending field.",
+	}
+	unsupportedMediaTypeProblem = problemType{
+		Slug:        "unsupported-media-type",
+		Title:       "Unsupported Media Type",
+		Status:      http.StatusUnsupportedMediaType,
+		Description: "The body of the request is in a media type the route cannot decode.",
+	}
+	unprocessableProblem = problemType{
+		Slug:   "unprocessable",
+		Title:  "Unprocessable Entity",
+		Status: http.StatusUnprocessableEntity,
+		Description: "The request is well formed but cannot be applied, such as a reference to a deleted resource. " +
+			"The invalidParams member names the offending field.",
+	}
+	internalProblem = problemType{
+		Slug:        "internal",
+		Title:       "Internal Server Error",
+		Status:      http.StatusInternalServerError,
+		Description: "An unexpected error occurred. Quote the traceId when reporting it.",
+	}
+)
+
+// problemTypes is the registry of every problem type, ordered by status.
+var problemTypes = []problemType{
+	badRequestProblem,
+	invalidParametersProblem,
+	notFoundProblem,
+	notAcceptableProblem,
+	conflictProblem,
+	unsupportedMediaTypeProblem,
+	unprocessableProblem,
+	internalProblem,
+}
+
+// lookupProblemType returns the problem type with slug. The boolean is false if there is none.
+func lookupProblemType(slug string) (problemType, bool) {
+	for _, t := range problemTypes {
+		if t.Slug == slug {
+			return t, true
+		}
+	}
+
+	return problemType{}, false
+}
+
+// problemTypeForStatus returns the type of a problem with status that names no type of its own, or the zero
+// problemType, about:blank, if the status has none. Problems with invalid parameters have a type of their own.
+func problemTypeForStatus(status int, invalidParams bool) problemType {
+	if invalidParams && status == http.StatusBadRequest {
+		return invalidParametersProblem
+	}
+
+	for _, t := range problemTypes {
+		if t.Status == status {
+			return t
+		}
+	}
+
+	return problemType{}
+}
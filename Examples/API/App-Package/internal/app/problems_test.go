@@ -0,0 +1,128 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProblemTypes(t *testing.T) {
+	t.Parallel()
+
+	seen := map[string]bool{}
+	for i, pt := range problemTypes {
+		assert.NotEmpty(t, pt.Slug)
+		assert.NotEmpty(t, pt.Title)
+		assert.NotEmpty(t, pt.Description)
+		assert.False(t, seen[pt.Slug], "duplicate slug %q", pt.Slug)
+		seen[pt.Slug] = true
+
+		if i > 0 {
+			assert.LessOrEqual(t, problemTypes[i-1].Status, pt.Status, "problem types must be ordered by status")
+		}
+
+		got, ok := lookupProblemType(pt.Slug)
+		assert.True(t, ok)
+		assert.Equal(t, pt, got)
+	}
+
+	_, ok := lookupProblemType("does-not-exist")
+	assert.False(t, ok)
+	assert.Equal(t, "about:blank", problemType{}.URI())
+}
+
+func TestProblemTypeForStatus(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		status        int
+		invalidParams bool
+		want          string
+	}{
+		"bad_request":        {status: http.StatusBadRequest, want: "/api/problems/bad-request"},
+		"invalid_parameters": {status: http.StatusBadRequest, invalidParams: true, want: "/api/problems/invalid-parameters"},
+		"conflict":           {status: http.StatusConflict, invalidParams: true, want: "/api/problems/conflict"},
+		"not_found":          {status: http.StatusNotFound, want: "/api/problems/not-found"},
+		"unregistered":       {status: http.StatusTeapot, want: "about:blank"},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.want, problemTypeForStatus(tc.status, tc.invalidParams).URI())
+		})
+	}
+}
+
+func TestEncodeResponseProblem(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		status       int
+		data         any
+		wantType     string
+		wantInstance string
+	}{
+		"filled_in_from_status": {
+			status: http.StatusNotFound,
+			data: problemDetail{
+				Title:  "Blog Not Found",
+				Status: http.StatusNotFound,
+				Detail: "Blog with ID 7 not found",
+			},
+			wantType:     "/api/problems/not-found",
+			wantInstance: "/api/blog/7",
+		},
+		"validation": {
+			status: http.StatusBadRequest,
+			data: problemDetailValidation{
+				problemDetail: problemDetail{
+					Title:  "Bad Request",
+					Status: http.StatusBadRequest,
+					Detail: "The request contains invalid parameters.",
+				},
+				InvalidParams: []validationProblem{{Field: "title", Code: "required", Message: "title is required"}},
+			},
+			wantType:     "/api/problems/invalid-parameters",
+			wantInstance: "/api/blog/7",
+		},
+		"set_by_handler": {
+			status: http.StatusConflict,
+			data: problemDetail{
+				Type:     conflictProblem.URI(),
+				Title:    "Conflict",
+				Status:   http.StatusConflict,
+				Instance: "/api/blog",
+			},
+			wantType:     "/api/problems/conflict",
+			wantInstance: "/api/blog",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPut, "/api/blog/7", nil)
+			rec := httptest.NewRecorder()
+
+			if err := encodeResponse(rec, req, tc.status, tc.data); err != nil {
+				t.Fatalf("failed to encode response: %v", err)
+			}
+
+			assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+
+			var got problemDetail
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				t.Fatalf("failed to decode response body: %v", err)
+			}
+
+			assert.Equal(t, tc.wantType, got.Type)
+			assert.Equal(t, tc.wantInstance, got.Instance)
+		})
+	}
+}
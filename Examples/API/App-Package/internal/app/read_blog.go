@@ -0,0 +1,115 @@
+package app
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// readBlog is an HTTP handler function that retrieves a blog by ID from the database.
+//
+//	@Summary		Read Blog
+//	@Description	Read Blog by ID
+//	@Tags			blog
+//	@Accept			json
+//	@Produce		json
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
+//	@Param			id				path		string	true	"Blog ID"
+//	@Success		200				{object}	blogResponse
+//	@Failure		400				{object}	problemDetail
+//	@Failure		404				{object}	problemDetail
+//	@Failure		406				{object}	problemDetail
+//	@Failure		500				{object}	problemDetail
+//	@Router			/api/blog/{id}	[GET]
+func readBlog(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
+	const funcName = "app.readBlog"
+	logger = logger.With(slog.String("func", funcName))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		logger := logger.With(getTraceIDAsAttr(ctx))
+
+		// read id from path parameters
+		idStr := r.PathValue("id")
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to parse id from url",
+				slog.String("id", idStr),
+				slog.String("error", err.Error()),
+			)
+
+			_ = encodeResponse(w, r, http.StatusBadRequest, problemDetail{
+				Title:   "Bad Request",
+				Status:  http.StatusBadRequest,
+				Detail:  "The provided ID is not a valid integer.",
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		// read the blog
+		logger.InfoContext(ctx, "Reading blog", slog.Int("id", id))
+
+		var blog blog
+		err = db.GetContext(
+			ctx,
+			&blog,
+			`
+			SELECT id,
+				author_id,
+				title,
+				score
+			FROM blogs
+			WHERE id = $1::int
+			`,
+			id,
+		)
+		if err != nil {
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				_ = encodeResponse(w, r, http.StatusNotFound, problemDetail{
+					Title:   "Blog Not Found",
+					Status:  http.StatusNotFound,
+					Detail:  fmt.Sprintf("Blog with ID %d not found", id),
+					TraceID: getTraceID(ctx),
+				})
+
+				return
+
+			default:
+				logger.ErrorContext(
+					ctx,
+					"failed to read blog",
+					slog.String("error", err.Error()),
+				)
+
+				_ = encodeResponse(w, r, http.StatusInternalServerError, problemDetail{
+					Title:   "Internal Server Error",
+					Status:  http.StatusInternalServerError,
+					Detail:  "An unexpected error occurred.",
+					TraceID: getTraceID(ctx),
+				})
+
+				return
+			}
+		}
+
+		_ = encodeResponse(w, r, http.StatusOK, blogResponse{
+			ID:       blog.ID,
+			AuthorID: blog.AuthorID,
+			Title:    blog.Title,
+			Score:    blog.Score,
+		})
+	}
+}
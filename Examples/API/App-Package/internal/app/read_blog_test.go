@@ -0,0 +1,131 @@
+package app
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestReadBlog(t *testing.T) {
+	t.Parallel()
+	type mockDB struct {
+		mockCalled    bool
+		mockInputArgs []driver.Value
+		mockRows      *sqlmock.Rows
+		mockError     error
+	}
+
+	testcases := map[string]struct {
+		mockDB
+		id         string
+		wantStatus int
+		wantBlog   blogResponse
+	}{
+		"success": {
+			mockDB: mockDB{
+				mockCalled:    true,
+				mockInputArgs: []driver.Value{1},
+				mockRows: sqlmock.NewRows([]string{"id", "author_id", "title", "score"}).
+					AddRow(1, 1, "First Blog Post", 8.5),
+				mockError: nil,
+			},
+			id:         "1",
+			wantStatus: http.StatusOK,
+			wantBlog:   blogResponse{ID: 1, AuthorID: 1, Title: "First Blog Post", Score: 8.5},
+		},
+		"invalid_id": {
+			mockDB: mockDB{
+				mockCalled:    false,
+				mockInputArgs: nil,
+				mockRows:      nil,
+				mockError:     nil,
+			},
+			id:         "abc",
+			wantStatus: http.StatusBadRequest,
+			wantBlog:   blogResponse{},
+		},
+		"not_found": {
+			mockDB: mockDB{
+				mockCalled:    true,
+				mockInputArgs: []driver.Value{2},
+				mockRows:      sqlmock.NewRows([]string{"id", "author_id", "title", "score"}),
+				mockError:     sql.ErrNoRows,
+			},
+			id:         "2",
+			wantStatus: http.StatusNotFound,
+			wantBlog:   blogResponse{},
+		},
+		"db_error": {
+			mockDB: mockDB{
+				mockCalled:    true,
+				mockInputArgs: []driver.Value{3},
+				mockRows:      sqlmock.NewRows([]string{"id", "author_id", "title", "score"}),
+				mockError:     errors.New("db error"),
+			},
+			id:         "3",
+			wantStatus: http.StatusInternalServerError,
+			wantBlog:   blogResponse{},
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			logger := slog.New(slog.DiscardHandler)
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("unexpected error when opening stub db: %v", err)
+			}
+
+			defer db.Close()
+
+			sqlxDB := sqlx.NewDb(db, "pgx")
+
+			if tc.mockCalled {
+				mock.ExpectQuery(regexp.QuoteMeta(`
+						SELECT id,
+							   author_id,
+							   title,
+							   score
+						FROM blogs
+						WHERE id = $1::int
+					`)).
+					WithArgs(tc.mockInputArgs...).
+					WillReturnRows(tc.mockRows).
+					WillReturnError(tc.mockError)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/blog/"+tc.id, nil)
+			req.SetPathValue("id", tc.id)
+
+			rec := httptest.NewRecorder()
+			handler := readBlog(logger, sqlxDB)
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Errorf("want status %d, got %d", tc.wantStatus, rec.Code)
+			}
+
+			if tc.wantStatus == http.StatusOK {
+				var gotBlog blogResponse
+				if err := json.NewDecoder(rec.Body).Decode(&gotBlog); err != nil {
+					t.Errorf("failed to decode response body: %v", err)
+				}
+
+				if gotBlog != tc.wantBlog {
+					t.Errorf("want blog %+v, got %+v", tc.wantBlog, gotBlog)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"html/template"
+	"log/slog"
+	"net/http"
+)
+
+// problemTypeTemplate renders the documentation of a problem type.
+var problemTypeTemplate = template.Must(template.New("problem_type").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} ({{.Slug}})</title>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<dl>
+<dt>Type</dt><dd><code>{{.URI}}</code></dd>
+<dt>Status</dt><dd>{{.Status}}</dd>
+</dl>
+<p>{{.Description}}</p>
+<p>Besides <code>type</code>, <code>title</code>, <code>status</code>, <code>detail</code> and
+<code>instance</code>, problems of this type carry <code>traceId</code>, the ID of the trace of the request.</p>
+<p><a href="` + problemTypesPath + `">All problem types</a></p>
+</body>
+</html>
+`))
+
+// readProblemType is an HTTP handler function that serves the human-readable documentation of a problem type at its
+// URI.
+//
+//	@Summary		Read Problem Type
+//	@Description	Human-readable documentation of a problem type
+//	@Tags			problem
+//	@Produce		html
+//	@Param			type					path		string	true	"Problem type slug"
+//	@Success		200						{string}	string
+//	@Failure		404						{object}	problemDetail
+//	@Router			/api/problems/{type}	[GET]
+func readProblemType(logger *slog.Logger) http.HandlerFunc {
+	const funcName = "app.readProblemType"
+	logger = logger.With(slog.String("func", funcName))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		t, ok := lookupProblemType(r.PathValue("type"))
+		if !ok {
+			_ = encodeResponse(w, r, http.StatusNotFound, problemDetail{
+				Title:   "Not Found",
+				Status:  http.StatusNotFound,
+				Detail:  "The problem type does not exist.",
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := problemTypeTemplate.Execute(w, t); err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to render problem type",
+				getTraceIDAsAttr(ctx),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
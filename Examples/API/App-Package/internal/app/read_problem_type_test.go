@@ -0,0 +1,51 @@
+package app
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadProblemType(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		slug            string
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+	}{
+		"success": {
+			slug:            "not-found",
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/html; charset=utf-8",
+			wantBody:        "<code>/api/problems/not-found</code>",
+		},
+		"not_found": {
+			slug:            "does-not-exist",
+			wantStatus:      http.StatusNotFound,
+			wantContentType: "application/problem+json",
+			wantBody:        `"type":"/api/problems/not-found"`,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/problems/"+tc.slug, nil)
+			req.SetPathValue("type", tc.slug)
+
+			rec := httptest.NewRecorder()
+			handler := readProblemType(slog.New(slog.DiscardHandler))
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.wantStatus, rec.Code)
+			assert.Equal(t, tc.wantContentType, rec.Header().Get("Content-Type"))
+			assert.Contains(t, rec.Body.String(), tc.wantBody)
+		})
+	}
+}
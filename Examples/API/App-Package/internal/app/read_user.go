@@ -18,10 +18,14 @@ import (
 //	@Tags			user
 //	@Accept			json
 //	@Produce		json
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
 //	@Param			id				path		string	true	"User ID"
 //	@Success		200				{object}	userResponse
 //	@Failure		400				{object}	problemDetail
 //	@Failure		404				{object}	problemDetail
+//	@Failure		406				{object}	problemDetail
 //	@Failure		500				{object}	problemDetail
 //	@Router			/api/user/{id}	[GET]
 func readUser(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
@@ -31,7 +35,7 @@ func readUser(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
-		logger = logger.With(getTraceIDAsAttr(ctx))
+		logger := logger.With(getTraceIDAsAttr(ctx))
 
 		// read id from path parameters
 		idStr := r.PathValue("id")
@@ -44,7 +48,7 @@ func readUser(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
 				slog.String("error", err.Error()),
 			)
 
-			_ = encodeResponseJSON(w, http.StatusBadRequest, problemDetail{
+			_ = encodeResponse(w, r, http.StatusBadRequest, problemDetail{
 				Title:   "Bad Request",
 				Status:  http.StatusBadRequest,
 				Detail:  "The provided ID is not a valid integer.",
@@ -74,7 +78,7 @@ func readUser(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
 		if err != nil {
 			switch {
 			case errors.Is(err, sql.ErrNoRows):
-				_ = encodeResponseJSON(w, http.StatusNotFound, problemDetail{
+				_ = encodeResponse(w, r, http.StatusNotFound, problemDetail{
 					Title:   "User Not Found",
 					Status:  http.StatusNotFound,
 					Detail:  fmt.Sprintf("User with ID %d not found", id),
@@ -90,7 +94,7 @@ func readUser(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
 					slog.String("error", err.Error()),
 				)
 
-				_ = encodeResponseJSON(w, http.StatusInternalServerError, problemDetail{
+				_ = encodeResponse(w, r, http.StatusInternalServerError, problemDetail{
 					Title:   "Internal Server Error",
 					Status:  http.StatusInternalServerError,
 					Detail:  "An unexpected error occurred.",
@@ -102,7 +106,7 @@ func readUser(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
 		}
 
 		// respond with userResponse (no password)
-		_ = encodeResponseJSON(w, http.StatusOK, userResponse{
+		_ = encodeResponse(w, r, http.StatusOK, userResponse{
 			ID:    user.ID,
 			Name:  user.Name,
 			Email: user.Email,
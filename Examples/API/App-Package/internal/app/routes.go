@@ -11,14 +11,35 @@ import (
 	_ "example.com/examples/api/app-package/cmd/api/docs" // import for Swagger docs generation
 )
 
-// addRoutes registers all HTTP API routes for user operations to the provided ServeMux.
-// It wires each route to its corresponding handler, passing the logger and database connection.
-func addRoutes(mux *http.ServeMux, logger *slog.Logger, db *sqlx.DB, enableSwagger bool) {
+// addRoutes registers all HTTP API routes for user, blog and comment operations to the provided ServeMux.
+// It wires each route to its corresponding handler, passing the logger, database connection and request validator.
+func addRoutes(
+	mux *http.ServeMux,
+	logger *slog.Logger,
+	db *sqlx.DB,
+	validate *Validator,
+	passwordCost int,
+	enableSwagger bool,
+) {
 	mux.Handle("GET /api/user/{id}", readUser(logger, db))
-	mux.Handle("POST /api/user", createUser(logger, db))
-	mux.Handle("PUT /api/user/{id}", updateUser(logger, db))
+	mux.Handle("POST /api/user", createUser(logger, db, validate, passwordCost))
+	mux.Handle("PUT /api/user/{id}", updateUser(logger, db, validate, passwordCost))
 	mux.Handle("DELETE /api/user/{id}", deleteUser(logger, db))
 	mux.Handle("GET /api/user", listUsers(logger, db))
+	mux.Handle("GET /api/user/{id}/comments", listUserComments(logger, db))
+
+	mux.Handle("GET /api/blog/{id}", readBlog(logger, db))
+	mux.Handle("POST /api/blog", createBlog(logger, db, validate))
+	mux.Handle("PUT /api/blog/{id}", updateBlog(logger, db, validate))
+	mux.Handle("DELETE /api/blog/{id}", deleteBlog(logger, db))
+	mux.Handle("GET /api/blog", listBlogs(logger, db))
+
+	mux.Handle("GET /api/blog/{id}/comments", listBlogComments(logger, db))
+	mux.Handle("POST /api/blog/{id}/comments", createComment(logger, db, validate))
+	mux.Handle("DELETE /api/blog/{id}/comments/{commentId}", deleteComment(logger, db))
+
+	mux.Handle("GET /api/problems/{$}", listProblemTypes(logger))
+	mux.Handle("GET /api/problems/{type}", readProblemType(logger))
 
 	mux.Handle("GET /health", HandleHealthCheck(logger, db))
 
@@ -32,7 +53,7 @@ func addRoutes(mux *http.ServeMux, logger *slog.Logger, db *sqlx.DB, enableSwagg
 
 	// catch-all route for 404 Not Found with problem detail
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {		
-		_ = encodeResponseJSON(w, http.StatusNotFound, problemDetail{
+		_ = encodeResponse(w, r, http.StatusNotFound, problemDetail{
 					Title:   "Path Not Found",
 					Status:  http.StatusNotFound,
 					Detail:  "The requested path does not exist.",
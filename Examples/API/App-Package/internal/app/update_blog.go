@@ -0,0 +1,194 @@
+package app
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// updateBlog is an HTTP handler function that updates an existing blog by ID in the database.
+//
+//	@Summary		Update Blog
+//	@Description	Update a blog by ID
+//	@Tags			blog
+//	@Accept			json
+//	@Accept			xml
+//	@Accept			application/msgpack
+//	@Accept			application/cbor
+//	@Produce		json
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
+//	@Param			id		path		string		true	"Blog ID"
+//	@Param			blog	body		blogRequest	true	"Blog data"
+//	@Success		200		{object}	blogResponse
+//	@Failure		400		{object}	problemDetailValidation
+//	@Failure		404		{object}	problemDetail
+//	@Failure		406		{object}	problemDetail
+//	@Failure		415		{object}	problemDetail
+//	@Failure		500		{object}	problemDetail
+//	@Router			/api/blog/{id} [PUT]
+func updateBlog(logger *slog.Logger, db *sqlx.DB, validate *Validator) http.HandlerFunc {
+	const funcName = "app.updateBlog"
+	logger = logger.With(slog.String("func", funcName))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		logger := logger.With(getTraceIDAsAttr(ctx))
+
+		// read id from path parameters
+		idStr := r.PathValue("id")
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to parse id from url",
+				slog.String("id", idStr),
+				slog.String("error", err.Error()),
+			)
+
+			_ = encodeResponse(w, r, http.StatusBadRequest, problemDetail{
+				Title:   "Invalid ID",
+				Status:  http.StatusBadRequest,
+				Detail:  "The provided ID is not a valid integer.",
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		// content negotiation
+		if !acceptable(w, r) || !decodable(w, r) {
+			return
+		}
+
+		// request validation
+		req, problems, err := decodeValid[blogRequest](r, validate)
+		if err != nil && len(problems) == 0 {
+			logger.ErrorContext(
+				ctx,
+				"failed to decode request",
+				slog.String("error", err.Error()),
+			)
+
+			_ = encodeResponse(w, r, http.StatusBadRequest, problemDetail{
+				Title:   "Bad Request",
+				Status:  http.StatusBadRequest,
+				Detail:  "Invalid request body.",
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		if len(problems) > 0 {
+			logger.ErrorContext(
+				ctx,
+				"Validation error",
+				slog.Any("validation_errors", problems),
+			)
+
+			_ = encodeResponse(w, r, http.StatusBadRequest, problemDetailValidation{
+				problemDetail: problemDetail{
+					Title:   "Bad Request",
+					Status:  http.StatusBadRequest,
+					Detail:  "The request contains invalid parameters.",
+					TraceID: getTraceID(ctx),
+				},
+				InvalidParams: problems,
+			})
+
+			return
+		}
+
+		logger.InfoContext(
+			ctx, "Updating blog",
+			slog.Int("id", id),
+			slog.Uint64("author_id", uint64(req.AuthorID)),
+			slog.String("title", req.Title),
+		)
+
+		// make sure the author exists before writing the blog
+		exists, err := userExists(ctx, db, req.AuthorID)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to check author", slog.String("error", err.Error()))
+			_ = encodeResponse(w, r, http.StatusInternalServerError, problemDetail{
+				Title:   "Internal Server Error",
+				Status:  http.StatusInternalServerError,
+				Detail:  "An unexpected error occurred.",
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		if !exists {
+			_ = encodeResponse(w, r, http.StatusNotFound, problemDetail{
+				Title:   "Author Not Found",
+				Status:  http.StatusNotFound,
+				Detail:  fmt.Sprintf("User with ID %d not found", req.AuthorID),
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		// update blog in db
+		var updatedBlog blog
+		err = db.GetContext(
+			ctx,
+			&updatedBlog,
+			`
+			UPDATE blogs
+			SET author_id  = $1,
+			    title      = $2,
+			    updated_at = CURRENT_TIMESTAMP
+			WHERE id = $3
+			RETURNING id, author_id, title, score
+			`,
+			req.AuthorID,
+			req.Title,
+			id,
+		)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				_ = encodeResponse(w, r, http.StatusNotFound, problemDetail{
+					Title:   "Blog Not Found",
+					Status:  http.StatusNotFound,
+					Detail:  fmt.Sprintf("Blog with ID %d not found", id),
+					TraceID: getTraceID(ctx),
+				})
+
+				return
+			}
+
+			logger.ErrorContext(ctx, "failed to update blog", slog.String("error", err.Error()))
+			_ = encodeResponse(w, r, http.StatusInternalServerError, problemDetail{
+				Title:   "Internal Server Error",
+				Status:  http.StatusInternalServerError,
+				Detail:  "An unexpected error occurred.",
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		logger.InfoContext(
+			ctx, "Blog updated successfully",
+			slog.Uint64("id", uint64(updatedBlog.ID)),
+			slog.String("title", updatedBlog.Title),
+		)
+
+		_ = encodeResponse(w, r, http.StatusOK, blogResponse{
+			ID:       updatedBlog.ID,
+			AuthorID: updatedBlog.AuthorID,
+			Title:    updatedBlog.Title,
+			Score:    updatedBlog.Score,
+		})
+	}
+}
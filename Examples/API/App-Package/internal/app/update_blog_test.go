@@ -0,0 +1,166 @@
+package app
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestUpdateBlog(t *testing.T) {
+	t.Parallel()
+	type mockDB struct {
+		mockExistsCalled bool
+		mockAuthorExists bool
+		mockCalled       bool
+		mockInputArgs    []driver.Value
+		mockOutput       *sqlmock.Rows
+		mockError        error
+	}
+
+	testcases := map[string]struct {
+		mockDB
+		id         string
+		inputJSON  string
+		wantStatus int
+		wantBlog   blogResponse
+	}{
+		"success": {
+			mockDB: mockDB{
+				mockExistsCalled: true,
+				mockAuthorExists: true,
+				mockCalled:       true,
+				mockInputArgs:    []driver.Value{2, "Edited Title", 1},
+				mockOutput: sqlmock.NewRows([]string{"id", "author_id", "title", "score"}).
+					AddRow(1, 2, "Edited Title", 8.5),
+				mockError: nil,
+			},
+			id:         "1",
+			inputJSON:  `{"authorId":2,"title":"Edited Title"}`,
+			wantStatus: http.StatusOK,
+			wantBlog:   blogResponse{ID: 1, AuthorID: 2, Title: "Edited Title", Score: 8.5},
+		},
+		"invalid_id": {
+			mockDB:     mockDB{},
+			id:         "abc",
+			inputJSON:  `{"authorId":2,"title":"Edited Title"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		"request_validation_error": {
+			mockDB:     mockDB{},
+			id:         "1",
+			inputJSON:  `{"title":"Edited Title"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		"author_not_found": {
+			mockDB: mockDB{
+				mockExistsCalled: true,
+				mockAuthorExists: false,
+			},
+			id:         "1",
+			inputJSON:  `{"authorId":99,"title":"Edited Title"}`,
+			wantStatus: http.StatusNotFound,
+		},
+		"blog_not_found": {
+			mockDB: mockDB{
+				mockExistsCalled: true,
+				mockAuthorExists: true,
+				mockCalled:       true,
+				mockInputArgs:    []driver.Value{2, "Edited Title", 99},
+				mockOutput:       sqlmock.NewRows([]string{"id", "author_id", "title", "score"}),
+				mockError:        sql.ErrNoRows,
+			},
+			id:         "99",
+			inputJSON:  `{"authorId":2,"title":"Edited Title"}`,
+			wantStatus: http.StatusNotFound,
+		},
+		"db_error": {
+			mockDB: mockDB{
+				mockExistsCalled: true,
+				mockAuthorExists: true,
+				mockCalled:       true,
+				mockInputArgs:    []driver.Value{2, "Edited Title", 1},
+				mockOutput:       sqlmock.NewRows([]string{"id", "author_id", "title", "score"}),
+				mockError:        sqlmock.ErrCancelled,
+			},
+			id:         "1",
+			inputJSON:  `{"authorId":2,"title":"Edited Title"}`,
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			logger := slog.New(slog.DiscardHandler)
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("unexpected error when opening stub db: %v", err)
+			}
+
+			defer db.Close()
+
+			sqlxDB := sqlx.NewDb(db, "pgx")
+
+			if tc.mockExistsCalled {
+				mock.
+					ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM users WHERE id = $1::int)`)).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(tc.mockAuthorExists))
+			}
+
+			if tc.mockCalled {
+				mock.
+					ExpectQuery(regexp.QuoteMeta(`
+						UPDATE blogs
+						SET author_id  = $1,
+							title      = $2,
+							updated_at = CURRENT_TIMESTAMP
+						WHERE id = $3
+						RETURNING id, author_id, title, score
+					`)).
+					WithArgs(tc.mockInputArgs...).
+					WillReturnRows(tc.mockOutput).
+					WillReturnError(tc.mockError)
+			}
+
+			req := httptest.NewRequest(
+				http.MethodPut,
+				"/blog/"+tc.id,
+				bytes.NewBufferString(tc.inputJSON),
+			)
+			req.SetPathValue("id", tc.id)
+
+			rec := httptest.NewRecorder()
+			handler := updateBlog(logger, sqlxDB, newTestValidator(t, sqlxDB))
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Errorf("want status %d, got %d", tc.wantStatus, rec.Code)
+			}
+
+			if tc.wantStatus == http.StatusOK {
+				var gotBlog blogResponse
+				if err := json.NewDecoder(rec.Body).Decode(&gotBlog); err != nil {
+					t.Errorf("failed to decode response body: %v", err)
+				}
+
+				if gotBlog != tc.wantBlog {
+					t.Errorf("want blog %+v, got %+v", tc.wantBlog, gotBlog)
+				}
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled db expectations: %v", err)
+			}
+		})
+	}
+}
@@ -17,21 +17,30 @@ import (
 //	@Description	Update user fields by ID
 //	@Tags			user
 //	@Accept			json
+//	@Accept			xml
+//	@Accept			application/msgpack
+//	@Accept			application/cbor
 //	@Produce		json
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
 //	@Param			id		path		string	true	"User ID"
 //	@Param			user	body		user	true	"User data"
 //	@Success		200		{object}	userResponse
 //	@Failure		400		{object}	problemDetailValidation
 //	@Failure		404		{object}	problemDetail
+//	@Failure		406		{object}	problemDetail
+//	@Failure		409		{object}	problemDetailValidation
+//	@Failure		415		{object}	problemDetail
 //	@Failure		500		{object}	problemDetail
 //	@Router			/api/user/{id} [PUT]
-func updateUser(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
+func updateUser(logger *slog.Logger, db *sqlx.DB, validate *Validator, passwordCost int) http.HandlerFunc {
 	const funcName = "app.updateUser"
 	logger = logger.With(slog.String("func", funcName))
 
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
-		logger = logger.With(getTraceIDAsAttr(ctx))
+		logger := logger.With(getTraceIDAsAttr(ctx))
 
 		// read id from path parameters
 		idStr := r.PathValue("id")
@@ -44,7 +53,7 @@ func updateUser(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
 				slog.String("error", err.Error()),
 			)
 
-			_ = encodeResponseJSON(w, http.StatusBadRequest, problemDetail{
+			_ = encodeResponse(w, r, http.StatusBadRequest, problemDetail{
 				Title:   "Invalid ID",
 				Status:  http.StatusBadRequest,
 				Detail:  "The provided ID is not a valid integer.",
@@ -54,8 +63,13 @@ func updateUser(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
 			return
 		}
 
+		// content negotiation
+		if !acceptable(w, r) || !decodable(w, r) {
+			return
+		}
+
 		// request validation
-		req, problems, err := decodeValid[userRequest](r)
+		req, problems, err := decodeValid[userRequest](r, validate)
 		if err != nil && len(problems) == 0 {
 			logger.ErrorContext(
 				ctx,
@@ -63,8 +77,8 @@ func updateUser(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
 				slog.String("error", err.Error()),
 			)
 
-			_ = encodeResponseJSON(
-				w, http.StatusBadRequest, problemDetail{
+			_ = encodeResponse(
+				w, r, http.StatusBadRequest, problemDetail{
 					Title:   "Bad Request",
 					Status:  http.StatusBadRequest,
 					Detail:  "Invalid request body.",
@@ -82,7 +96,7 @@ func updateUser(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
 				slog.Any("validation_errors", problems),
 			)
 
-			_ = encodeResponseJSON(w, http.StatusBadRequest, problemDetailValidation{
+			_ = encodeResponse(w, r, http.StatusBadRequest, problemDetailValidation{
 				problemDetail: problemDetail{
 					Title:   "Bad Request",
 					Status:  http.StatusBadRequest,
@@ -102,7 +116,20 @@ func updateUser(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
 			slog.String("email", req.Email),
 		)
 
-		// update user in db
+		hash, err := hashPassword(req.Password, passwordCost)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to hash password", slog.String("error", err.Error()))
+			_ = encodeResponse(w, r, http.StatusInternalServerError, problemDetail{
+				Title:   "Internal Server Error",
+				Status:  http.StatusInternalServerError,
+				Detail:  "An unexpected error occurred.",
+				TraceID: getTraceID(ctx),
+			})
+
+			return
+		}
+
+		// update user in db, storing only the password hash
 		var updatedUser user
 		err = db.GetContext(
 			ctx,
@@ -113,16 +140,16 @@ func updateUser(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
                 email    = $2,
                 password = $3
             WHERE id = $4
-            RETURNING id, name, email, password
+            RETURNING id, name, email
             `,
 			req.Name,
 			req.Email,
-			req.Password,
+			hash,
 			id,
 		)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
-				_ = encodeResponseJSON(w, http.StatusNotFound, problemDetail{
+				_ = encodeResponse(w, r, http.StatusNotFound, problemDetail{
 					Title:   "User Not Found",
 					Status:  http.StatusNotFound,
 					Detail:  fmt.Sprintf("User with ID %d not found", id),
@@ -132,8 +159,15 @@ func updateUser(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
 				return
 			}
 
+			if problem, ok := constraintViolation(ctx, translatorFor(r), err); ok {
+				logger.InfoContext(ctx, "constraint violated", slog.String("error", err.Error()))
+				_ = encodeResponse(w, r, problem.Status, problem)
+
+				return
+			}
+
 			logger.ErrorContext(ctx, "failed to update user", slog.String("error", err.Error()))
-			_ = encodeResponseJSON(w, http.StatusInternalServerError, problemDetail{
+			_ = encodeResponse(w, r, http.StatusInternalServerError, problemDetail{
 				Title:   "Internal Server Error",
 				Status:  http.StatusInternalServerError,
 				Detail:  "An unexpected error occurred.",
@@ -151,7 +185,7 @@ func updateUser(logger *slog.Logger, db *sqlx.DB) http.HandlerFunc {
 		)
 
 		// respond with updated user (without password)
-		_ = encodeResponseJSON(w, http.StatusOK, userResponse{
+		_ = encodeResponse(w, r, http.StatusOK, userResponse{
 			ID:    updatedUser.ID,
 			Name:  updatedUser.Name,
 			Email: updatedUser.Email,
@@ -13,7 +13,9 @@ import (
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jmoiron/sqlx"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestUpdateUser(t *testing.T) {
@@ -35,9 +37,9 @@ func TestUpdateUser(t *testing.T) {
 		"success": {
 			mockDB: mockDB{
 				mockCalled: true,
-				mockArgs:   []driver.Value{"Alice", "alice@new.com", "password123", 1},
-				mockRow: sqlmock.NewRows([]string{"id", "name", "email", "password"}).
-					AddRow(1, "Alice", "alice@new.com", "password123"),
+				mockArgs:   []driver.Value{"Alice", "alice@new.com", bcryptHash{"password123"}, 1},
+				mockRow: sqlmock.NewRows([]string{"id", "name", "email"}).
+					AddRow(1, "Alice", "alice@new.com"),
 				mockError: nil,
 			},
 			id:         "1",
@@ -61,6 +63,14 @@ func TestUpdateUser(t *testing.T) {
 			body:       userRequest{Name: "Bob", Email: "bob@new.com", Password: "pw"},
 			wantStatus: http.StatusBadRequest,
 		},
+		"weak_password": {
+			mockDB: mockDB{
+				mockCalled: false,
+			},
+			id:         "1",
+			body:       userRequest{Name: "Bob", Email: "bob@new.com", Password: "passwordonly"},
+			wantStatus: http.StatusBadRequest,
+		},
 		"bad_json": {
 			mockDB: mockDB{
 				mockCalled: false,
@@ -72,8 +82,8 @@ func TestUpdateUser(t *testing.T) {
 		"not_found": {
 			mockDB: mockDB{
 				mockCalled: true,
-				mockArgs:   []driver.Value{"Carol", "carol@new.com", "password123", 3},
-				mockRow:    sqlmock.NewRows([]string{"id", "name", "email", "password"}),
+				mockArgs:   []driver.Value{"Carol", "carol@new.com", bcryptHash{"password123"}, 3},
+				mockRow:    sqlmock.NewRows([]string{"id", "name", "email"}),
 				mockError:  sql.ErrNoRows,
 			},
 			id:         "3",
@@ -83,14 +93,25 @@ func TestUpdateUser(t *testing.T) {
 		"db_error": {
 			mockDB: mockDB{
 				mockCalled: true,
-				mockArgs:   []driver.Value{"Dave", "dave@new.com", "password123", 4},
-				mockRow:    sqlmock.NewRows([]string{"id", "name", "email", "password"}),
+				mockArgs:   []driver.Value{"Dave", "dave@new.com", bcryptHash{"password123"}, 4},
+				mockRow:    sqlmock.NewRows([]string{"id", "name", "email"}),
 				mockError:  errors.New("db error"),
 			},
 			id:         "4",
 			body:       userRequest{Name: "Dave", Email: "dave@new.com", Password: "password123"},
 			wantStatus: http.StatusInternalServerError,
 		},
+		"duplicate_email": {
+			mockDB: mockDB{
+				mockCalled: true,
+				mockArgs:   []driver.Value{"Dave", "alice@example.com", bcryptHash{"password123"}, 4},
+				mockRow:    sqlmock.NewRows([]string{"id", "name", "email"}),
+				mockError:  &pgconn.PgError{Code: pgUniqueViolation, ConstraintName: "users_email_key"},
+			},
+			id:         "4",
+			body:       userRequest{Name: "Dave", Email: "alice@example.com", Password: "password123"},
+			wantStatus: http.StatusConflict,
+		},
 	}
 
 	for name, tc := range testcases {
@@ -112,7 +133,7 @@ func TestUpdateUser(t *testing.T) {
 					UPDATE users
 					SET name = $1, email = $2, password = $3
 					WHERE id = $4
-					RETURNING id, name, email, password
+					RETURNING id, name, email
 				`)).WithArgs(tc.mockArgs...)
 				if tc.mockRow != nil {
 					expect.WillReturnRows(tc.mockRow)
@@ -135,7 +156,7 @@ func TestUpdateUser(t *testing.T) {
 			req.SetPathValue("id", tc.id)
 
 			rec := httptest.NewRecorder()
-			handler := updateUser(logger, sqlxDB)
+			handler := updateUser(logger, sqlxDB, newTestValidator(t, sqlxDB), bcrypt.MinCost)
 			handler.ServeHTTP(rec, req)
 
 			if rec.Code != tc.wantStatus {
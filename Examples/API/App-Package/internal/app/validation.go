@@ -0,0 +1,126 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/jmoiron/sqlx"
+)
+
+// Validator validates request models. One Validator is built at startup and shared by every request, which keeps
+// the validator's cache of struct metadata and registers the API's own tags besides the validator's built-in ones:
+//
+//	password      a password with at least one letter and one digit
+//	email_domain  an email address at one of the allowed domains
+//	unique_email  an email address no user has registered yet
+//
+// Its messages are translated by the catalogue and name fields by their JSON names.
+type Validator struct {
+	validate *validator.Validate
+}
+
+// NewValidator creates a Validator with the API's tags. Email addresses must be at one of allowedEmailDomains, unless
+// it is empty, and unique_email looks addresses up in db. logger records failed uniqueness checks.
+func NewValidator(logger *slog.Logger, db *sqlx.DB, allowedEmailDomains []string) (*Validator, error) {
+	v := validator.New()
+	if err := registerValidatorMessages(v); err != nil {
+		return nil, fmt.Errorf("[in app.NewValidator] %w", err)
+	}
+
+	rules := []struct {
+		tag  string
+		key  string
+		rule validator.FuncCtx
+	}{
+		{tag: "password", key: msgWeakPassword, rule: passwordRule},
+		{tag: "email_domain", key: msgDomainNotAllowed, rule: emailDomainRule(allowedEmailDomains)},
+		{tag: "unique_email", key: msgAlreadyTaken, rule: uniqueEmailRule(logger, db)},
+	}
+	for _, r := range rules {
+		if err := v.RegisterValidationCtx(r.tag, r.rule); err != nil {
+			return nil, fmt.Errorf("[in app.NewValidator] register %s failed: %w", r.tag, err)
+		}
+		if err := registerTagMessage(v, r.tag, r.key); err != nil {
+			return nil, fmt.Errorf("[in app.NewValidator] %w", err)
+		}
+	}
+
+	return &Validator{validate: v}, nil
+}
+
+// validateStruct validates the fields of data, which must be a pointer to a struct, and returns the errors of those
+// that are invalid. The error is only non-nil if data cannot be validated at all.
+func (v *Validator) validateStruct(ctx context.Context, data any) ([]validator.FieldError, error) {
+	if err := v.validate.StructCtx(ctx, data); err != nil {
+		var fieldErrors validator.ValidationErrors
+		if !errors.As(err, &fieldErrors) {
+			return []validator.FieldError{}, fmt.Errorf(
+				"[in app.Validator.validateStruct] invalid validation error: %w",
+				err,
+			)
+		}
+
+		return fieldErrors, nil
+	}
+
+	return []validator.FieldError{}, nil
+}
+
+// passwordRule reports whether a password contains at least one letter and one digit. Its length is left to the min
+// and max tags.
+func passwordRule(_ context.Context, fl validator.FieldLevel) bool {
+	s := fl.Field().String()
+
+	return strings.ContainsFunc(s, unicode.IsLetter) && strings.ContainsFunc(s, unicode.IsDigit)
+}
+
+// emailDomainRule returns a rule reporting whether an email address is at one of domains, compared
+// case-insensitively. Every domain is allowed if domains is empty.
+func emailDomainRule(domains []string) validator.FuncCtx {
+	allowed := make([]string, len(domains))
+	for i, domain := range domains {
+		allowed[i] = strings.ToLower(strings.TrimSpace(domain))
+	}
+
+	return func(_ context.Context, fl validator.FieldLevel) bool {
+		if len(allowed) == 0 {
+			return true
+		}
+
+		i := strings.LastIndex(fl.Field().String(), "@")
+		if i < 0 {
+			return false
+		}
+
+		return slices.Contains(allowed, strings.ToLower(fl.Field().String()[i+1:]))
+	}
+}
+
+// uniqueEmailRule returns a rule reporting whether no user has registered an email address. The check only gives
+// clients an early, field-level answer: the unique constraint of the database still guards the write, so an address
+// that cannot be checked is let through rather than failing the request.
+func uniqueEmailRule(logger *slog.Logger, db *sqlx.DB) validator.FuncCtx {
+	const funcName = "app.uniqueEmailRule"
+	logger = logger.With(slog.String("func", funcName))
+
+	return func(ctx context.Context, fl validator.FieldLevel) bool {
+		taken, err := emailTaken(ctx, db, fl.Field().String())
+		if err != nil {
+			logger.WarnContext(
+				ctx,
+				"failed to check whether email is taken",
+				slog.String("error", err.Error()),
+			)
+
+			return true
+		}
+
+		return !taken
+	}
+}
@@ -0,0 +1,167 @@
+package app
+
+import (
+	"log/slog"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-playground/validator/v10"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestValidator creates a Validator that allows every email domain and looks email addresses up in db.
+func newTestValidator(t *testing.T, db *sqlx.DB) *Validator {
+	t.Helper()
+
+	validate, err := NewValidator(slog.New(slog.DiscardHandler), db, nil)
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+
+	return validate
+}
+
+func TestValidator(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		request   createUserRequest
+		taken     bool
+		checkErr  error
+		wantCodes map[string]string
+	}{
+		"valid": {
+			request:   createUserRequest{Name: "John", Email: "john@example.com", Password: "password123"},
+			wantCodes: map[string]string{},
+		},
+		"password_without_digit": {
+			request:   createUserRequest{Name: "John", Email: "john@example.com", Password: "password"},
+			wantCodes: map[string]string{"password": "password"},
+		},
+		"password_without_letter": {
+			request:   createUserRequest{Name: "John", Email: "john@example.com", Password: "12345678"},
+			wantCodes: map[string]string{"password": "password"},
+		},
+		"domain_not_allowed": {
+			request:   createUserRequest{Name: "John", Email: "john@elsewhere.org", Password: "password123"},
+			wantCodes: map[string]string{"email": "email_domain"},
+		},
+		"domain_case_insensitive": {
+			request:   createUserRequest{Name: "John", Email: "john@EXAMPLE.com", Password: "password123"},
+			wantCodes: map[string]string{},
+		},
+		"email_taken": {
+			request:   createUserRequest{Name: "John", Email: "john@example.com", Password: "password123"},
+			taken:     true,
+			wantCodes: map[string]string{"email": "unique_email"},
+		},
+		"email_check_failed": {
+			request:   createUserRequest{Name: "John", Email: "john@example.com", Password: "password123"},
+			checkErr:  sqlmock.ErrCancelled,
+			wantCodes: map[string]string{},
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("unexpected error when opening stub db: %v", err)
+			}
+
+			defer db.Close()
+
+			sqlxDB := sqlx.NewDb(db, "pgx")
+
+			if _, ok := tc.wantCodes["email"]; !ok || tc.wantCodes["email"] == "unique_email" {
+				expect := mock.
+					ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM users WHERE email = $1)`)).
+					WithArgs(tc.request.Email)
+				if tc.checkErr != nil {
+					expect.WillReturnError(tc.checkErr)
+				} else {
+					expect.WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(tc.taken))
+				}
+			}
+
+			validate, err := NewValidator(slog.New(slog.DiscardHandler), sqlxDB, []string{" Example.com "})
+			if err != nil {
+				t.Fatalf("failed to create validator: %v", err)
+			}
+
+			problems, err := validate.validateStruct(t.Context(), &tc.request)
+			if err != nil {
+				t.Fatalf("failed to validate: %v", err)
+			}
+
+			gotCodes := map[string]string{}
+			for _, problem := range problems {
+				gotCodes[problem.Field()] = problem.Tag()
+			}
+
+			assert.Equal(t, tc.wantCodes, gotCodes)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestValidatorMessages(t *testing.T) {
+	t.Parallel()
+
+	validate := newTestValidator(t, nil)
+
+	problems, err := validate.validateStruct(
+		t.Context(),
+		&userRequest{Name: "John", Email: "john@example.com", Password: "password"},
+	)
+	if err != nil {
+		t.Fatalf("failed to validate: %v", err)
+	}
+
+	if assert.Len(t, problems, 1) {
+		assert.Equal(t, "password must contain at least one letter and one digit", problems[0].Translate(translators["en"]))
+		assert.Equal(t, "password debe contener al menos una letra y un dígito", problems[0].Translate(translators["es"]))
+	}
+}
+
+func TestValidatorNotStruct(t *testing.T) {
+	t.Parallel()
+
+	validate := newTestValidator(t, nil)
+
+	_, err := validate.validateStruct(t.Context(), "not a struct")
+
+	assert.Error(t, err)
+}
+
+// BenchmarkValidate compares building a validator for every request, as the validator's cache of struct metadata
+// is then thrown away, with the shared Validator.
+func BenchmarkValidate(b *testing.B) {
+	request := userRequest{Name: "John", Email: "john@example.com", Password: "password123"}
+
+	b.Run("new_per_request", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			v := validator.New()
+			_ = v.RegisterValidation("email_domain", func(validator.FieldLevel) bool { return true })
+			_ = v.RegisterValidation("password", func(validator.FieldLevel) bool { return true })
+			_ = v.Struct(&request)
+		}
+	})
+
+	b.Run("shared", func(b *testing.B) {
+		validate, err := NewValidator(slog.New(slog.DiscardHandler), nil, nil)
+		if err != nil {
+			b.Fatalf("failed to create validator: %v", err)
+		}
+
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = validate.validateStruct(b.Context(), &request)
+		}
+	})
+}
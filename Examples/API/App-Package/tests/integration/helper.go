@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 
 	"github.com/jmoiron/sqlx"
+	"golang.org/x/crypto/bcrypt"
 
 	_ "github.com/mattn/go-sqlite3" // import SQLite driver for sqlx
 
@@ -13,7 +14,7 @@ import (
 )
 
 // newTestDB creates and returns an in-memory SQLite database
-// pre-populated with a 'users' table and some test user data.
+// pre-populated with 'users', 'blogs' and 'comments' tables and some test data.
 // This is useful for integration tests that require a database.
 func newTestDB() (*sqlx.DB, error) {
 	db, err := sqlx.Open("sqlite3", ":memory:")
@@ -33,6 +34,29 @@ func newTestDB() (*sqlx.DB, error) {
         ('Bob', 'bob@example.com', 'securepass456'),
         ('Carol', 'carol@example.com', 'carolpass789'),
         ('Dave', 'dave@example.com', 'davepass321');
+
+    CREATE TABLE blogs (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        author_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+        title TEXT NOT NULL,
+        score REAL NOT NULL DEFAULT 0,
+        created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );
+    INSERT INTO blogs (author_id, title, score) VALUES
+        (1, 'First Blog Post', 8.5),
+        (2, 'Travel Adventures', 7.2);
+
+    CREATE TABLE comments (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+        blog_id INTEGER NOT NULL REFERENCES blogs (id) ON DELETE CASCADE,
+        message TEXT NOT NULL,
+        created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );
+    INSERT INTO comments (user_id, blog_id, message, created_at) VALUES
+        (2, 1, 'Great post!', '2024-05-15 12:00:00'),
+        (1, 1, 'Thanks for reading.', '2024-05-15 13:00:00');
     `
 
 	// execute schema creation and data insertion
@@ -56,8 +80,13 @@ func newTestServer() (*httptest.Server, *sqlx.DB, error) {
 
 	logger := slog.Default()
 
+	validate, err := app.NewValidator(logger, db, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create validator: %w", err)
+	}
+
 	// create handler and wrap in middleware
-	handler := app.NewHandler(logger, db, false)
+	handler := app.NewHandler(logger, db, validate, bcrypt.MinCost, false)
 	wrappedHandler := app.WrapHandler(
 		handler,
 		app.TraceIDMiddleware(),
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // TestReadUser verifies that the API correctly returns user details for each user ID.
@@ -70,7 +71,7 @@ func TestReadUser(t *testing.T) { //nolint: tparallel
 	}
 }
 
-// TestListUsers verifies that the API returns the complete list of users.
+// TestListUsers verifies that the API returns the first page of users.
 // It checks that the number of users and their details match the expected values.
 func TestListUsers(t *testing.T) {
 	t.Parallel()
@@ -111,16 +112,22 @@ func TestListUsers(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status code 200 OK")
 
-	var users []struct {
-		ID    int    `json:"id"`
-		Name  string `json:"name"`
-		Email string `json:"email"`
+	var page struct {
+		Users []struct {
+			ID    int    `json:"id"`
+			Name  string `json:"name"`
+			Email string `json:"email"`
+		} `json:"users"`
+		Next string `json:"next"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&users); err != nil {
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
 		t.Fatalf("Failed to decode response: %v", err)
 	}
 
+	users := page.Users
+	assert.Empty(t, page.Next, "Expected every user to fit on the first page")
+
 	assert.Len(t, users, len(expected), "Expected number of users does not match")
 
 	for i, exp := range expected {
@@ -131,6 +138,61 @@ func TestListUsers(t *testing.T) {
 	}
 }
 
+// TestListUsersPagination verifies that following the next cursor walks every user exactly once, in id order.
+func TestListUsersPagination(t *testing.T) {
+	t.Parallel()
+
+	server, _, err := newTestServer()
+	if err != nil {
+		t.Fatalf("Failed to create test server: %v", err)
+	}
+
+	t.Cleanup(server.Close)
+
+	var ids []int
+	url := server.URL + "/api/user?limit=3"
+	for pages := 0; url != ""; pages++ {
+		if pages > 2 {
+			t.Fatalf("Expected pagination to finish, still following %s", url)
+		}
+
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, url, nil)
+		if err != nil {
+			t.Fatalf("Failed to create GET request: %v", err)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to make GET request: %v", err)
+		}
+
+		var page struct {
+			Users []struct {
+				ID int `json:"id"`
+			} `json:"users"`
+			Next string `json:"next"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status code 200 OK")
+
+		for _, u := range page.Users {
+			ids = append(ids, u.ID)
+		}
+
+		url = ""
+		if page.Next != "" {
+			url = server.URL + "/api/user?limit=3&cursor=" + page.Next
+		}
+	}
+
+	assert.Equal(t, []int{1, 2, 3, 4}, ids, "Expected every user exactly once, in id order")
+}
+
 // TestCreateUser verifies that a new user can be created via the API.
 // It checks that the response contains the correct user data and that the user is inserted into the database.
 func TestCreateUser(t *testing.T) {
@@ -209,10 +271,67 @@ func TestCreateUser(t *testing.T) {
 
 	assert.Equal(t, newUser.Name, dbUser.Name, "DB user name mismatch")
 	assert.Equal(t, newUser.Email, dbUser.Email, "DB user email mismatch")
-	assert.Equal(t, newUser.Password, dbUser.Password, "DB user password mismatch")
+	assert.NotEqual(t, newUser.Password, dbUser.Password, "DB user password stored in plaintext")
+	assert.NoError(
+		t,
+		bcrypt.CompareHashAndPassword([]byte(dbUser.Password), []byte(newUser.Password)),
+		"DB user password hash mismatch",
+	)
 	assert.Equal(t, createdUser.ID, dbUser.ID, "DB user ID mismatch with API response")
 }
 
+// TestCreateUserEmailTaken verifies that creating a user with an email address another user has registered is
+// rejected by validation, naming the email field, before anything is written.
+func TestCreateUserEmailTaken(t *testing.T) {
+	t.Parallel()
+
+	server, _, err := newTestServer()
+	if err != nil {
+		t.Fatalf("Failed to create test server: %v", err)
+	}
+
+	t.Cleanup(server.Close)
+
+	body := `{"name": "Alice", "email": "alice@example.com", "password": "password789"}`
+	req, err := http.NewRequestWithContext(
+		t.Context(),
+		http.MethodPost,
+		server.URL+"/api/user",
+		bytes.NewBufferString(body),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create POST request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to make POST request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "Expected status code 400 Bad Request")
+
+	var problem struct {
+		InvalidParams []struct {
+			Field   string `json:"field"`
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"invalidParams"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&problem); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if assert.Len(t, problem.InvalidParams, 1) {
+		assert.Equal(t, "email", problem.InvalidParams[0].Field)
+		assert.Equal(t, "unique_email", problem.InvalidParams[0].Code)
+		assert.Equal(t, "email is already taken", problem.InvalidParams[0].Message)
+	}
+}
+
 // TestUpdateUser verifies that an existing user's details can be updated via the API.
 // It checks that the response and the database reflect the updated user information.
 func TestUpdateUser(t *testing.T) {
@@ -291,7 +410,12 @@ func TestUpdateUser(t *testing.T) {
 
 	assert.Equal(t, updatedUser.Name, dbUser.Name, "DB user name mismatch after update")
 	assert.Equal(t, updatedUser.Email, dbUser.Email, "DB user email mismatch after update")
-	assert.Equal(t, updatedUser.Password, dbUser.Password, "DB user password mismatch after update")
+	assert.NotEqual(t, updatedUser.Password, dbUser.Password, "DB user password stored in plaintext after update")
+	assert.NoError(
+		t,
+		bcrypt.CompareHashAndPassword([]byte(dbUser.Password), []byte(updatedUser.Password)),
+		"DB user password hash mismatch after update",
+	)
 	assert.Equal(t, 1, dbUser.ID, "DB user ID mismatch after update")
 }
 
@@ -366,20 +490,40 @@ func TestNotFoundRoute(t *testing.T) {
 	defer resp.Body.Close()
 
 	assert.Equal(t, http.StatusNotFound, resp.StatusCode, "Expected status code 404 Not Found")
+	assert.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
 
 	var problem struct {
-		Title  string `json:"title"`
-		Status int    `json:"status"`
-		Detail string `json:"detail"`
+		Type     string `json:"type"`
+		Title    string `json:"title"`
+		Status   int    `json:"status"`
+		Detail   string `json:"detail"`
+		Instance string `json:"instance"`
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&problem); err != nil {
 		t.Fatalf("Failed to decode problem detail: %v", err)
 	}
 
+	assert.Equal(t, "/api/problems/not-found", problem.Type)
 	assert.Equal(t, "Path Not Found", problem.Title)
 	assert.Equal(t, http.StatusNotFound, problem.Status)
 	assert.NotEmpty(t, problem.Detail)
+	assert.Equal(t, "/does-not-exist", problem.Instance)
+
+	// the type URI serves the documentation of the type
+	req, err = http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL+problem.Type, nil)
+	if err != nil {
+		t.Fatalf("Failed to create GET request: %v", err)
+	}
+
+	typeResp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to make GET request: %v", err)
+	}
+	defer typeResp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, typeResp.StatusCode)
+	assert.Equal(t, "text/html; charset=utf-8", typeResp.Header.Get("Content-Type"))
 }
 
 // TestHealthCheck verifies that the /health endpoint returns the correct health status and details.
@@ -427,3 +571,147 @@ func TestHealthCheck(t *testing.T) {
 	assert.Equal(t, "db", health.Details[0].Name)
 	assert.Equal(t, "healthy", health.Details[0].Status)
 }
+
+// TestCreateBlog verifies that a blog can be created for an existing author and that
+// creating one for a missing author returns 404 without writing anything.
+func TestCreateBlog(t *testing.T) {
+	t.Parallel()
+
+	server, db, err := newTestServer()
+	if err != nil {
+		t.Fatalf("Failed to create test server: %v", err)
+	}
+
+	t.Cleanup(server.Close)
+
+	tests := map[string]struct {
+		authorID   int
+		wantStatus int
+		wantCount  int
+	}{
+		"existing_author": {3, http.StatusCreated, 1},
+		"missing_author":  {99, http.StatusNotFound, 0},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			body, err := json.Marshal(map[string]any{
+				"authorId": tc.authorID,
+				"title":    "Integration Blog",
+			})
+			if err != nil {
+				t.Fatalf("Failed to marshal blog: %v", err)
+			}
+
+			req, err := http.NewRequestWithContext(
+				t.Context(),
+				http.MethodPost,
+				server.URL+"/api/blog",
+				bytes.NewReader(body),
+			)
+			if err != nil {
+				t.Fatalf("Failed to create POST request: %v", err)
+			}
+
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Failed to make POST request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			assert.Equal(t, tc.wantStatus, resp.StatusCode, "Status code mismatch")
+
+			var count int
+			err = db.Get(&count, "SELECT COUNT(*) FROM blogs WHERE author_id = ?", tc.authorID)
+			if err != nil {
+				t.Fatalf("Failed to query blogs from DB: %v", err)
+			}
+
+			assert.Equal(t, tc.wantCount, count, "DB blog count mismatch")
+		})
+	}
+}
+
+// TestBlogComments verifies that comments can be created, listed oldest first and
+// deleted through a blog, and listed for the user who wrote them.
+func TestBlogComments(t *testing.T) {
+	t.Parallel()
+
+	server, _, err := newTestServer()
+	if err != nil {
+		t.Fatalf("Failed to create test server: %v", err)
+	}
+
+	t.Cleanup(server.Close)
+
+	do := func(method string, path string, body string) *http.Response {
+		req, err := http.NewRequestWithContext(
+			t.Context(),
+			method,
+			server.URL+path,
+			bytes.NewBufferString(body),
+		)
+		if err != nil {
+			t.Fatalf("Failed to create %s request: %v", method, err)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to make %s request: %v", method, err)
+		}
+
+		t.Cleanup(func() { _ = resp.Body.Close() })
+
+		return resp
+	}
+
+	messages := func(resp *http.Response) []string {
+		var comments []struct {
+			Message string `json:"message"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&comments); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+
+		got := make([]string, 0, len(comments))
+		for _, c := range comments {
+			got = append(got, c.Message)
+		}
+
+		return got
+	}
+
+	resp := do(http.MethodGet, "/api/blog/1/comments", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status code 200 OK")
+	assert.Equal(t, []string{"Great post!", "Thanks for reading."}, messages(resp))
+
+	resp = do(http.MethodGet, "/api/blog/1/comments?limit=1&offset=1", "")
+	assert.Equal(t, []string{"Thanks for reading."}, messages(resp))
+
+	resp = do(http.MethodGet, "/api/blog/99/comments", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode, "Expected status code 404 Not Found")
+
+	resp = do(http.MethodPost, "/api/blog/2/comments", `{"userId":3,"message":"Integration comment"}`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode, "Expected status code 201 Created")
+
+	var created struct {
+		ID int `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	resp = do(http.MethodGet, "/api/user/3/comments", "")
+	assert.Equal(t, []string{"Integration comment"}, messages(resp))
+
+	resp = do(http.MethodDelete, "/api/blog/1/comments/"+strconv.Itoa(created.ID), "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode, "Expected comment to be scoped to its blog")
+
+	resp = do(http.MethodDelete, "/api/blog/2/comments/"+strconv.Itoa(created.ID), "")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode, "Expected status code 204 No Content")
+
+	resp = do(http.MethodGet, "/api/user/3/comments", "")
+	assert.Equal(t, []string{}, messages(resp))
+}
@@ -23,6 +23,7 @@ import (
 	"example.com/examples/api/layered/internal/routes"
 	"example.com/examples/api/layered/internal/services"
 	"example.com/examples/api/layered/internal/telemetry"
+	"example.com/examples/api/layered/internal/validation"
 )
 
 func main() {
@@ -50,6 +51,7 @@ func run(ctx context.Context) error {
 				},
 			),
 			ctxhandler.WithAttrFunc(middleware.GetTraceIDAsAttr),
+			ctxhandler.WithAttrFunc(middleware.GetUserIDAsAttr),
 		),
 	)
 
@@ -93,27 +95,90 @@ func run(ctx context.Context) error {
 
 	logger.InfoContext(ctx, "Connected successfully to the database")
 
-	rdb := redis.NewClient(
-		&redis.Options{
-			Addr:     fmt.Sprintf("%s:%d", cfg.CacheHost, cfg.CachePort),
-			Password: cfg.CachePassword,
-			DB:       cfg.CacheDB,
-		},
+	// Choose the cache. Redis also keeps idempotency keys and rate limit
+	// counts; without it they are kept in memory, by each instance on its own.
+	var (
+		cache            services.Cache
+		idempotencyStore middleware.IdempotencyStore
+		rateLimiter      *middleware.RateLimiter
 	)
+	switch cfg.CacheBackend {
+	case services.CacheBackendRedis:
+		rdb := redis.NewClient(
+			&redis.Options{
+				Addr:     fmt.Sprintf("%s:%d", cfg.CacheHost, cfg.CachePort),
+				Password: cfg.CachePassword,
+				DB:       cfg.CacheDB,
+			},
+		)
+		cache = services.NewRedisCache(rdb)
+		idempotencyStore = rdb
+		rateLimiter = middleware.NewRateLimiter(logger, rdb)
+	case services.CacheBackendMemory:
+		cache = services.NewMemoryCache(cfg.CacheSize)
+	default:
+		cache = services.NoopCache{}
+	}
+	if idempotencyStore == nil {
+		idempotencyStore = middleware.NewLocalIdempotencyStore()
+		rateLimiter = middleware.NewLocalRateLimiter(logger)
+	}
+
+	logger.InfoContext(ctx, "Using cache", slog.String("backend", cfg.CacheBackend))
 
 	// Create a new users service
 	usersService := services.NewUsersService(
 		logger,
 		db,
-		rdb,
+		cache,
+		time.Duration(cfg.CacheExpiration)*time.Second,
+		time.Duration(cfg.CacheLockTTL)*time.Millisecond,
+		cfg.PasswordCost,
+	)
+
+	// Create a new blogs service
+	blogsService := services.NewBlogsService(
+		logger,
+		db,
+		cache,
 		time.Duration(cfg.CacheExpiration)*time.Second,
 	)
 
+	// Create a new comments service
+	commentsService := services.NewCommentsService(logger, db)
+
+	// Create a new auth service
+	authService := services.NewAuthService(
+		logger,
+		db,
+		usersService,
+		[]byte(cfg.JWTSecret),
+		time.Duration(cfg.JWTAccessExpiration)*time.Second,
+		time.Duration(cfg.JWTRefreshExpiration)*time.Second,
+	)
+
+	// Create the validator shared by every request
+	validate, err := validation.New(logger, cfg.AllowedEmailDomains, usersService)
+	if err != nil {
+		return fmt.Errorf("[in main.run] failed to create validator: %w", err)
+	}
+
 	// Create a serve mux to act as our route multiplexer
 	mux := telemetry.InstrumentServeMux(http.NewServeMux())
 
 	// Add our routes to the mux
-	routes.AddRoutes(mux, logger, usersService, cfg.SwaggerEnabled)
+	routes.AddRoutes(
+		mux,
+		logger,
+		usersService,
+		blogsService,
+		commentsService,
+		authService,
+		validate,
+		middleware.Idempotency(logger, idempotencyStore, time.Duration(cfg.IdempotencyTTL)*time.Second),
+		rateLimiter,
+		cfg.SwaggerEnabled,
+	)
 
 	// add middleware
 	mux.AddMiddleware(middleware.TraceID())
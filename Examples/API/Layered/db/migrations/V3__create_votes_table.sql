@@ -0,0 +1,23 @@
+-- Create votes table
+CREATE TABLE "votes"
+(
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT    NOT NULL,
+    blog_id    BIGINT    NOT NULL,
+    value      SMALLINT  NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_vote_user
+        FOREIGN KEY (user_id)
+            REFERENCES "users" (id)
+            ON DELETE CASCADE,
+    CONSTRAINT fk_vote_blog
+        FOREIGN KEY (blog_id)
+            REFERENCES "blogs" (id)
+            ON DELETE CASCADE,
+    CONSTRAINT uq_vote_blog_user
+        UNIQUE (blog_id, user_id),
+    CONSTRAINT chk_vote_value
+        CHECK (value IN (-1, 1))
+);
+
+CREATE INDEX idx_votes_user_id ON "votes" (user_id);
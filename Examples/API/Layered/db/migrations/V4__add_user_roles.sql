@@ -0,0 +1,10 @@
+-- Add a role to users. Every user is a regular user unless promoted.
+ALTER TABLE "users"
+    ADD COLUMN role TEXT NOT NULL DEFAULT 'user',
+    ADD CONSTRAINT chk_user_role
+        CHECK (role IN ('admin', 'user'));
+
+-- Promote the first seeded user to admin
+UPDATE "users"
+SET role = 'admin'
+WHERE email = 'john@example.com';
@@ -0,0 +1,5 @@
+-- Add a row version to users. It is incremented on every update and exposed
+-- as the ETag of the user, so that conditional requests can detect
+-- concurrent changes.
+ALTER TABLE "users"
+    ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
@@ -1,30 +1,41 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 
 	"github.com/caarlos0/env/v11"
 	"github.com/joho/godotenv"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // Config holds the application configuration settings. The configuration is loaded from
 // environment variables.
 type Config struct {
-	DBHost          string     `env:"DATABASE_HOST,required"`
-	DBUserName      string     `env:"DATABASE_USER,required"`
-	DBUserPassword  string     `env:"DATABASE_PASSWORD,required"`
-	DBName          string     `env:"DATABASE_NAME,required"`
-	DBPort          string     `env:"DATABASE_PORT,required"`
-	Host            string     `env:"HOST,required"`
-	Port            string     `env:"PORT,required"`
-	LogLevel        slog.Level `env:"LOG_LEVEL,required"`
-	CacheHost       string     `env:"CACHE_HOST,required"`
-	CachePort       int        `env:"CACHE_PORT,required"`
-	CacheDB         int        `env:"CACHE_DB,required"`
-	CachePassword   string     `env:"CACHE_PASSWORD,required"`
-	CacheExpiration int        `env:"CACHE_EXPIRATION,required"`
-	SwaggerEnabled  bool       `env:"SWAGGER_ENABLED"            envDefault:"false"`
+	DBHost               string     `env:"DATABASE_HOST,required"`
+	DBUserName           string     `env:"DATABASE_USER,required"`
+	DBUserPassword       string     `env:"DATABASE_PASSWORD,required"`
+	DBName               string     `env:"DATABASE_NAME,required"`
+	DBPort               string     `env:"DATABASE_PORT,required"`
+	Host                 string     `env:"HOST,required"`
+	Port                 string     `env:"PORT,required"`
+	LogLevel             slog.Level `env:"LOG_LEVEL,required"`
+	CacheBackend         string     `env:"CACHE_BACKEND"              envDefault:"redis"`
+	CacheHost            string     `env:"CACHE_HOST"`
+	CachePort            int        `env:"CACHE_PORT"`
+	CacheDB              int        `env:"CACHE_DB"`
+	CachePassword        string     `env:"CACHE_PASSWORD"`
+	CacheExpiration      int        `env:"CACHE_EXPIRATION,required"`
+	CacheSize            int        `env:"CACHE_SIZE"                 envDefault:"10000"`
+	CacheLockTTL         int        `env:"CACHE_LOCK_TTL_MS"          envDefault:"0"`
+	SwaggerEnabled       bool       `env:"SWAGGER_ENABLED"            envDefault:"false"`
+	PasswordCost         int        `env:"PASSWORD_HASH_COST"         envDefault:"10"`
+	JWTSecret            string     `env:"JWT_SECRET,required"`
+	JWTAccessExpiration  int        `env:"JWT_ACCESS_EXPIRATION"      envDefault:"900"`
+	JWTRefreshExpiration int        `env:"JWT_REFRESH_EXPIRATION"     envDefault:"604800"`
+	IdempotencyTTL       int        `env:"IDEMPOTENCY_TTL"            envDefault:"86400"`
+	AllowedEmailDomains  []string   `env:"ALLOWED_EMAIL_DOMAINS"      envSeparator:","`
 }
 
 // New loads configuration from environment variables and a .env file, and returns a
@@ -43,5 +54,30 @@ func New() (Config, error) {
 		return Config{}, fmt.Errorf("[in config.New] failed to parse config: %w", err)
 	}
 
+	// Redis is only needed, and so only configured, when it is the cache
+	switch cfg.CacheBackend {
+	case "redis":
+		if cfg.CacheHost == "" || cfg.CachePort == 0 {
+			return Config{}, errors.New("[in config.New] CACHE_HOST and CACHE_PORT are required by the redis cache")
+		}
+	case "memory", "none":
+	default:
+		return Config{}, fmt.Errorf(
+			"[in config.New] unknown CACHE_BACKEND %q, want redis, memory or none",
+			cfg.CacheBackend,
+		)
+	}
+
+	// bcrypt refuses costs above its maximum and silently raises those below
+	// its minimum, which would hash passwords at a cost other than the one set
+	if cfg.PasswordCost < bcrypt.MinCost || cfg.PasswordCost > bcrypt.MaxCost {
+		return Config{}, fmt.Errorf(
+			"[in config.New] PASSWORD_HASH_COST %d is out of range, want %d to %d",
+			cfg.PasswordCost,
+			bcrypt.MinCost,
+			bcrypt.MaxCost,
+		)
+	}
+
 	return cfg, nil
 }
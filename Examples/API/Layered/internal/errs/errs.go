@@ -0,0 +1,128 @@
+// Package errs defines the domain error model shared by services and
+// handlers. Every error a service returns either is, or wraps, an *Error whose
+// Kind says what went wrong in terms a handler can act on, so that handlers
+// can choose a response from the kind alone without knowing which service or
+// query failed:
+//
+//	var ErrUserNotFound = errs.New(errs.NotFound, "user not found")
+//
+//	if err := cache.Get(ctx, key).Err(); err != nil {
+//		return errs.E(errs.Unavailable, err)
+//	}
+//
+// Errors without a kind are treated as Internal.
+package errs
+
+import (
+	"errors"
+	"strings"
+)
+
+// Kind classifies an error by what went wrong.
+type Kind uint8
+
+const (
+	// Internal is an unexpected failure of the service itself.
+	Internal Kind = iota
+	// NotFound means a requested or referenced resource does not exist.
+	NotFound
+	// Conflict means a request conflicts with the current state of a
+	// resource, such as a duplicate value or a stale version.
+	Conflict
+	// Invalid means a request is well formed but cannot be applied, such as
+	// a reference to a resource that has since been deleted.
+	Invalid
+	// Unauthorized means the caller could not be authenticated.
+	Unauthorized
+	// Unavailable means a dependency, such as the cache, is temporarily
+	// unavailable and the request may succeed if retried.
+	Unavailable
+)
+
+// String returns the name of the kind.
+func (k Kind) String() string {
+	switch k {
+	case NotFound:
+		return "not_found"
+	case Conflict:
+		return "conflict"
+	case Invalid:
+		return "invalid"
+	case Unauthorized:
+		return "unauthorized"
+	case Unavailable:
+		return "unavailable"
+	default:
+		return "internal"
+	}
+}
+
+// Error is an error of a particular Kind. Message describes the error in
+// terms that are safe to show to clients, and Err is the underlying cause, if
+// any.
+type Error struct {
+	Kind    Kind
+	Message string
+	Err     error
+}
+
+// New creates an *Error of kind with message and no cause, for use as a
+// sentinel error.
+func New(kind Kind, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+// E creates an *Error of kind that wraps err. It returns nil if err is nil.
+func E(kind Kind, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &Error{Kind: kind, Err: err}
+}
+
+// Error satisfies the error interface.
+func (e *Error) Error() string {
+	switch {
+	case e.Err == nil:
+		return e.Message
+	case e.Message == "":
+		return e.Err.Error()
+	default:
+		return e.Message + ": " + e.Err.Error()
+	}
+}
+
+// Unwrap returns the cause of the error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// KindOf returns the kind of the first *Error in err's tree, or Internal if
+// there is none.
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+
+	return Internal
+}
+
+// Message returns the first message of an *Error in err's tree, written as a
+// sentence, or an empty string if there is none. It is safe to show to
+// clients.
+func Message(err error) string {
+	for err != nil {
+		var e *Error
+		if !errors.As(err, &e) {
+			return ""
+		}
+		if e.Message != "" {
+			return strings.ToUpper(e.Message[:1]) + e.Message[1:] + "."
+		}
+		err = e.Err
+	}
+
+	return ""
+}
@@ -0,0 +1,72 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError(t *testing.T) {
+	errNotFound := New(NotFound, "user not found")
+	errCause := errors.New("connection refused")
+
+	tests := map[string]struct {
+		err         error
+		wantKind    Kind
+		wantMessage string
+		wantError   string
+	}{
+		"sentinel": {
+			err:         errNotFound,
+			wantKind:    NotFound,
+			wantMessage: "User not found.",
+			wantError:   "user not found",
+		},
+		"wrapped sentinel": {
+			err:         fmt.Errorf("[in services.UsersService.ReadUser] user 1: %w", errNotFound),
+			wantKind:    NotFound,
+			wantMessage: "User not found.",
+			wantError:   "[in services.UsersService.ReadUser] user 1: user not found",
+		},
+		"cause without a message": {
+			err:       fmt.Errorf("read: %w", E(Unavailable, errCause)),
+			wantKind:  Unavailable,
+			wantError: "read: connection refused",
+		},
+		"message and cause": {
+			err:         &Error{Kind: Conflict, Message: "version mismatch", Err: errCause},
+			wantKind:    Conflict,
+			wantMessage: "Version mismatch.",
+			wantError:   "version mismatch: connection refused",
+		},
+		"outer kind wins": {
+			err:         E(Unavailable, errNotFound),
+			wantKind:    Unavailable,
+			wantMessage: "User not found.",
+			wantError:   "user not found",
+		},
+		"no kind": {
+			err:       errCause,
+			wantKind:  Internal,
+			wantError: "connection refused",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.wantKind, KindOf(tc.err))
+			assert.Equal(t, tc.wantMessage, Message(tc.err))
+			assert.EqualError(t, tc.err, tc.wantError)
+		})
+	}
+}
+
+func TestE(t *testing.T) {
+	assert.NoError(t, E(Unavailable, nil))
+
+	cause := errors.New("connection refused")
+	err := E(Unavailable, cause)
+	assert.ErrorIs(t, err, cause)
+	assert.Equal(t, "unavailable", KindOf(err).String())
+}
@@ -9,7 +9,7 @@ import (
 // HandleCatchAll handles all unmatched routes and returns a 404 Not Found response.
 func HandleCatchAll() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		_ = encodeResponseJSON(w, http.StatusNotFound, ProblemDetail{
+		_ = encodeResponse(w, r, http.StatusNotFound, ProblemDetail{
 			Title:   "Path Not Found",
 			Status:  http.StatusNotFound,
 			Detail:  "The requested path does not exist.",
@@ -51,6 +51,8 @@ func TestHandleCatchAll(t *testing.T) {
 				t.Fatalf("failed to decode response: %v", err)
 			}
 
+			assert.Equal(t, "/api/problems/not-found", pd.Type, "Problem type mismatch")
+			assert.Equal(t, tc.path, pd.Instance, "Problem instance mismatch")
 			assert.Equal(t, tc.wantTitle, pd.Title, "Problem title mismatch")
 			assert.Equal(t, tc.wantDetail, pd.Detail, "Problem detail mismatch")
 			assert.Equal(t, tc.wantStatus, pd.Status, "Problem status mismatch")
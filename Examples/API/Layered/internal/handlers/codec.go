@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+var (
+	// errUnsupportedMediaType is returned when a request body has a media type
+	// none of the codecs can decode.
+	errUnsupportedMediaType = errors.New("unsupported media type")
+
+	// errMalformedBody is returned when a request body cannot be decoded,
+	// such as when it is truncated or is not of the shape a handler expects.
+	errMalformedBody = errors.New("malformed body")
+)
+
+// codec encodes response bodies to, and decodes request bodies from, a media
+// type. Problem details are sent as problemMediaType, which for JSON and XML
+// is the problem media type RFC 9457 registers.
+type codec struct {
+	mediaType        string
+	problemMediaType string
+	encode           func(w io.Writer, v any) error
+	decode           func(r io.Reader, v any) error
+}
+
+// codecs are the codecs for request and response bodies, in order of
+// preference. JSON comes first, so it is used when a request states no
+// preference. MessagePack and CBOR use the JSON names of fields, and XML
+// elements are named by xml tags matching them, so every format has the same
+// field names.
+var codecs = []codec{
+	{
+		mediaType:        "application/json",
+		problemMediaType: "application/problem+json",
+		encode: func(w io.Writer, v any) error {
+			return json.NewEncoder(w).Encode(v)
+		},
+		decode: func(r io.Reader, v any) error {
+			return json.NewDecoder(r).Decode(v)
+		},
+	},
+	{
+		mediaType:        "application/xml",
+		problemMediaType: "application/problem+xml",
+		encode: func(w io.Writer, v any) error {
+			if _, err := io.WriteString(w, xml.Header); err != nil {
+				return err
+			}
+
+			return xml.NewEncoder(w).Encode(v)
+		},
+		decode: func(r io.Reader, v any) error {
+			return xml.NewDecoder(r).Decode(v)
+		},
+	},
+	{
+		mediaType:        "application/msgpack",
+		problemMediaType: "application/msgpack",
+		encode: func(w io.Writer, v any) error {
+			encoder := msgpack.NewEncoder(w)
+			encoder.SetCustomStructTag("json")
+
+			return encoder.Encode(v)
+		},
+		decode: func(r io.Reader, v any) error {
+			decoder := msgpack.NewDecoder(r)
+			decoder.SetCustomStructTag("json")
+
+			return decoder.Decode(v)
+		},
+	},
+	{
+		mediaType:        "application/cbor",
+		problemMediaType: "application/cbor",
+		encode: func(w io.Writer, v any) error {
+			return cbor.NewEncoder(w).Encode(v)
+		},
+		decode: func(r io.Reader, v any) error {
+			return cbor.NewDecoder(r).Decode(v)
+		},
+	},
+}
+
+// codecMediaTypes returns the media types of the codecs, in order of
+// preference.
+func codecMediaTypes() []string {
+	mediaTypes := make([]string, len(codecs))
+	for i, c := range codecs {
+		mediaTypes[i] = c.mediaType
+	}
+
+	return mediaTypes
+}
+
+// responseCodec returns the codec for the media type a request's Accept
+// header prefers. The boolean is false if it accepts none of them.
+func responseCodec(r *http.Request) (codec, bool) {
+	mediaType := negotiate(r.Header.Get("Accept"), codecMediaTypes()...)
+	for _, c := range codecs {
+		if c.mediaType == mediaType {
+			return c, true
+		}
+	}
+
+	return codec{}, false
+}
+
+// requestCodec returns the codec for the media type of a request's body,
+// given by its Content-Type header. A body without a Content-Type is taken to
+// be JSON. The boolean is false if no codec supports the media type.
+func requestCodec(r *http.Request) (codec, bool) {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return codecs[0], true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return codec{}, false
+	}
+	for _, c := range codecs {
+		if c.mediaType == mediaType {
+			return c, true
+		}
+	}
+
+	return codec{}, false
+}
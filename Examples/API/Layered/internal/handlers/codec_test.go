@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodecs(t *testing.T) {
+	want := BlogResponse{ID: 1, AuthorID: 1, Title: "My first blog", Score: 8.5}
+
+	for _, c := range codecs {
+		t.Run(c.mediaType, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, c.encode(&buf, want))
+
+			var got BlogResponse
+			require.NoError(t, c.decode(&buf, &got))
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestCodecs_xmlUser(t *testing.T) {
+	accept := httptest.NewRequest(http.MethodGet, "/", nil)
+	accept.Header.Set("Accept", "application/xml")
+	c, ok := responseCodec(accept)
+	require.True(t, ok)
+
+	// XML elements are named as JSON members are
+	createdAt := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+	want := UserResponse{
+		ID:        1,
+		Name:      "john",
+		Email:     "john@mail.com",
+		Role:      "user",
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt.Add(time.Hour),
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, c.encode(&buf, want))
+	assert.Equal(
+		t,
+		xml.Header+"<UserResponse><id>1</id><name>john</name><email>john@mail.com</email><role>user</role>"+
+			"<createdAt>2024-05-15T12:00:00Z</createdAt><updatedAt>2024-05-15T13:00:00Z</updatedAt></UserResponse>",
+		buf.String(),
+	)
+
+	var got UserResponse
+	require.NoError(t, c.decode(&buf, &got))
+	assert.Equal(t, want, got)
+
+	var request UserRequest
+	require.NoError(t, c.decode(
+		strings.NewReader(`<user><name>john</name><email>john@mail.com</email><password>password123!</password></user>`),
+		&request,
+	))
+	assert.Equal(t, UserRequest{Name: "john", Email: "john@mail.com", Password: "password123!"}, request)
+}
+
+func TestEncodeResponse_negotiation(t *testing.T) {
+	tests := map[string]struct {
+		accept          string
+		status          int
+		wantStatus      int
+		wantContentType string
+	}{
+		"no accept header": {
+			status:          http.StatusOK,
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+		},
+		"xml": {
+			accept:          "application/xml",
+			status:          http.StatusOK,
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/xml",
+		},
+		"msgpack": {
+			accept:          "application/msgpack",
+			status:          http.StatusOK,
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/msgpack",
+		},
+		"cbor preferred": {
+			accept:          "application/json;q=0.5, application/cbor",
+			status:          http.StatusOK,
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/cbor",
+		},
+		"nothing acceptable": {
+			accept:          "text/plain",
+			status:          http.StatusOK,
+			wantStatus:      http.StatusNotAcceptable,
+			wantContentType: "application/problem+json",
+		},
+		"errors are still sent": {
+			accept:          "text/plain",
+			status:          http.StatusNotFound,
+			wantStatus:      http.StatusNotFound,
+			wantContentType: "application/json",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept", tc.accept)
+			rec := httptest.NewRecorder()
+
+			require.NoError(t, encodeResponse(rec, req, tc.status, BlogResponse{ID: 1, AuthorID: 1, Title: "My first blog"}))
+
+			assert.Equal(t, tc.wantStatus, rec.Code)
+			assert.Equal(t, tc.wantContentType, rec.Header().Get("Content-Type"))
+			assert.Equal(t, "Accept", rec.Header().Get("Vary"))
+		})
+	}
+}
+
+func TestDecodeValid_contentType(t *testing.T) {
+	tests := map[string]struct {
+		contentType string
+		body        func(t *testing.T) []byte
+		wantErr     error
+	}{
+		"no content type is json": {
+			body: func(_ *testing.T) []byte { return []byte(`{"title":"My first blog"}`) },
+		},
+		"msgpack": {
+			contentType: "application/msgpack",
+			body: func(t *testing.T) []byte {
+				accept := httptest.NewRequest(http.MethodGet, "/", nil)
+				accept.Header.Set("Accept", "application/msgpack")
+				c, ok := responseCodec(accept)
+				require.True(t, ok)
+
+				var buf bytes.Buffer
+				require.NoError(t, c.encode(&buf, BlogRequest{Title: "My first blog"}))
+
+				return buf.Bytes()
+			},
+		},
+		"malformed json": {
+			body:    func(_ *testing.T) []byte { return []byte(`{"title":`) },
+			wantErr: errMalformedBody,
+		},
+		"malformed xml": {
+			contentType: "application/xml",
+			body:        func(_ *testing.T) []byte { return []byte(`<BlogRequest><title>My first`) },
+			wantErr:     errMalformedBody,
+		},
+		"malformed msgpack": {
+			contentType: "application/msgpack",
+			body:        func(_ *testing.T) []byte { return []byte{0x82, 0xa5} },
+			wantErr:     errMalformedBody,
+		},
+		"malformed cbor": {
+			contentType: "application/cbor",
+			body:        func(_ *testing.T) []byte { return []byte{0xa2, 0x65} },
+			wantErr:     errMalformedBody,
+		},
+		"unsupported": {
+			contentType: "text/plain",
+			body:        func(_ *testing.T) []byte { return []byte("My first blog") },
+			wantErr:     errUnsupportedMediaType,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(tc.body(t)))
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+
+			got, problems, err := decodeValid[BlogRequest](req, newTestValidator(t))
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+
+				return
+			}
+			require.NoError(t, err)
+			assert.Empty(t, problems)
+			assert.Equal(t, BlogRequest{Title: "My first blog"}, got)
+		})
+	}
+}
+
+func TestDecodable(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("title"))
+	req.Header.Set("Content-Type", "text/plain")
+	rec := httptest.NewRecorder()
+
+	assert.False(t, decodable(rec, req))
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+
+	var problem ProblemDetail
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusUnsupportedMediaType, problem.Status)
+}
+
+func TestEncodeResponse_problemXML(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/blog/7", nil)
+	req.Header.Set("Accept", "application/xml")
+	rec := httptest.NewRecorder()
+
+	require.NoError(t, encodeResponse(rec, req, http.StatusBadRequest, NewValidationBadRequest(
+		t.Context(),
+		[]validationProblem{{Field: "title", Code: "required", Message: "title is required"}},
+	)))
+
+	assert.Equal(t, "application/problem+xml", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), `<problem xmlns="urn:ietf:rfc:7807">`)
+	assert.Contains(t, rec.Body.String(), "<type>/api/problems/invalid-parameters</type>")
+	assert.Contains(t, rec.Body.String(), "<instance>/api/blog/7</instance>")
+	assert.Contains(t, rec.Body.String(), "<invalidParams><i><field>title</field>")
+}
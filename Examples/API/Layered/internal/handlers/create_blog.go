@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"go.opentelemetry.io/otel/codes"
+
+	"example.com/examples/api/layered/internal/middleware"
+	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/validation"
+)
+
+// blogCreator represents a type capable of creating a blog in storage and
+// returning it or an error.
+type blogCreator interface {
+	CreateBlog(ctx context.Context, blog models.Blog) (models.Blog, error)
+}
+
+// HandleCreateBlog handles the creation of a new blog, written by the
+// authenticated user.
+//
+//	@Summary		Create Blog
+//	@Description	Creates a Blog
+//	@Tags			blog
+//	@Accept			json
+//	@Accept			xml
+//	@Accept			application/msgpack
+//	@Accept			application/cbor
+//	@Produce		json
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
+//	@Param			request			body		BlogRequest	true	"Blog to Create"
+//	@Param			Idempotency-Key	header		string		false	"Key that makes retries of the request safe"
+//	@Success		201				{object}	BlogResponse
+//	@Failure		400				{object}	ProblemDetailValidation
+//	@Failure		401				{object}	ProblemDetail
+//	@Failure		404				{object}	ProblemDetail
+//	@Failure		406				{object}	ProblemDetail
+//	@Failure		409				{object}	ProblemDetail
+//	@Failure		415				{object}	ProblemDetail
+//	@Failure		422				{object}	ProblemDetailValidation
+//	@Failure		429				{object}	ProblemDetail
+//	@Failure		500				{object}	ProblemDetail
+//	@Failure		503				{object}	ProblemDetail
+//	@Security		BearerAuth
+//	@Router			/blog  [POST]
+func HandleCreateBlog(
+	logger *slog.Logger,
+	validate *validation.Validator,
+	blogCreator blogCreator,
+) http.HandlerFunc {
+	const name = "handlers.HandleCreateBlog"
+	logger = logger.With(slog.String("func", name))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracer.Start(r.Context(), name)
+		defer span.End()
+
+		// The authenticated user writes the blog
+		principal, ok := middleware.GetPrincipal(ctx)
+		if !ok {
+			encodeError(ctx, w, r, logger, "missing principal", errNoPrincipal)
+
+			return
+		}
+
+		// Content negotiation
+		if !acceptable(w, r) || !decodable(w, r) {
+			return
+		}
+
+		// Request validation
+		request, problems, err := decodeValid[BlogRequest](r, validate)
+		if err != nil && len(problems) == 0 {
+			encodeDecodeError(ctx, w, r, logger, err)
+
+			return
+		}
+		if len(problems) > 0 {
+			validationError := "validation failed"
+			logger.ErrorContext(
+				ctx,
+				validationError,
+				slog.Any("validation_errors", problems),
+			)
+			span.SetStatus(codes.Error, validationError)
+			span.RecordError(errors.New(validationError))
+
+			_ = encodeResponse(w, r, http.StatusBadRequest, NewValidationBadRequest(ctx, problems))
+
+			return
+		}
+
+		modelRequest := models.Blog{
+			AuthorID: principal.UserID,
+			Title:    request.Title,
+		}
+
+		// Create the blog
+		blog, err := blogCreator.CreateBlog(ctx, modelRequest)
+		if err != nil {
+			encodeError(ctx, w, r, logger, "failed to create blog", err)
+
+			return
+		}
+
+		// Convert our models.Blog domain model into a response model.
+		_ = encodeResponse(
+			w, r, http.StatusCreated, BlogResponse{
+				ID:       blog.ID,
+				AuthorID: blog.AuthorID,
+				Title:    blog.Title,
+				Score:    blog.Score,
+			},
+		)
+	}
+}
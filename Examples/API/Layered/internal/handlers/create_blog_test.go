@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/examples/api/layered/internal/middleware"
+	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/services"
+)
+
+func TestHandleCreateBlog(t *testing.T) {
+	tests := map[string]struct {
+		body       string
+		anonymous  bool
+		mockBlog   models.Blog
+		mockErr    error
+		wantStatus int
+		wantBody   BlogResponse
+	}{
+		"happy path": {
+			body:       `{"title":"First Blog Post"}`,
+			mockBlog:   models.Blog{ID: 1, AuthorID: 2, Title: "First Blog Post"},
+			mockErr:    nil,
+			wantStatus: http.StatusCreated,
+			wantBody:   BlogResponse{ID: 1, AuthorID: 2, Title: "First Blog Post"},
+		},
+		"naming another author": {
+			// The blog is still written by the authenticated user
+			body:       `{"authorId":1,"title":"First Blog Post"}`,
+			mockBlog:   models.Blog{ID: 1, AuthorID: 2, Title: "First Blog Post"},
+			wantStatus: http.StatusCreated,
+			wantBody:   BlogResponse{ID: 1, AuthorID: 2, Title: "First Blog Post"},
+		},
+		"missing title": {
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		"unauthenticated": {
+			body:       `{"title":"First Blog Post"}`,
+			anonymous:  true,
+			wantStatus: http.StatusUnauthorized,
+		},
+		"author not found": {
+			body:       `{"title":"First Blog Post"}`,
+			mockErr:    fmt.Errorf("author 2: %w", services.ErrUserNotFound),
+			wantStatus: http.StatusNotFound,
+		},
+		"author deleted while creating": {
+			body: `{"title":"First Blog Post"}`,
+			mockErr: fmt.Errorf("creating: %w", &services.ConstraintError{
+				Kind:       services.ErrForeignKeyViolation,
+				Constraint: "fk_author",
+				Field:      "authorId",
+				Err:        errors.New("insert or update on table violates foreign key constraint"),
+			}),
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(
+			name, func(t *testing.T) {
+				// Create a new request
+				req := httptest.NewRequest(http.MethodPost, "/blog", strings.NewReader(tc.body))
+				if !tc.anonymous {
+					req = req.WithContext(middleware.WithPrincipal(req.Context(), models.Principal{UserID: 2}))
+				}
+
+				// Create a new response recorder
+				rec := httptest.NewRecorder()
+
+				// Create a new ctxhandler
+				logger := slog.Default()
+
+				mockedBlogCreator := &moqblogCreator{
+					CreateBlogFunc: func(_ context.Context, blog models.Blog) (models.Blog, error) {
+						assert.Equal(t, models.Blog{AuthorID: 2, Title: "First Blog Post"}, blog)
+
+						return tc.mockBlog, tc.mockErr
+					},
+				}
+
+				// Call the handler
+				handler := HandleCreateBlog(logger, newTestValidator(t), mockedBlogCreator)
+
+				handler.ServeHTTP(rec, req)
+				// Check the status code
+				assert.Equal(t, tc.wantStatus, rec.Code)
+
+				// Check the body
+				if tc.wantStatus == http.StatusCreated {
+					var respBody BlogResponse
+					_ = json.Unmarshal(rec.Body.Bytes(), &respBody)
+
+					assert.Equal(t, tc.wantBody, respBody)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"go.opentelemetry.io/otel/codes"
+
+	"example.com/examples/api/layered/internal/middleware"
+	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/validation"
+)
+
+// commentCreator represents a type capable of creating a comment in storage
+// and returning it or an error.
+type commentCreator interface {
+	CreateComment(ctx context.Context, comment models.Comment) (models.Comment, error)
+}
+
+// HandleCreateComment handles the creation of a new comment on a blog, written
+// by the authenticated user.
+//
+//	@Summary		Create Comment
+//	@Description	Creates a Comment on a Blog
+//	@Tags			comment
+//	@Accept			json
+//	@Accept			xml
+//	@Accept			application/msgpack
+//	@Accept			application/cbor
+//	@Produce		json
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
+//	@Param			id				path		string			true	"Blog ID"
+//	@Param			request			body		CommentRequest	true	"Comment to Create"
+//	@Param			Idempotency-Key	header		string			false	"Key that makes retries of the request safe"
+//	@Success		201				{object}	CommentResponse
+//	@Failure		400				{object}	ProblemDetailValidation
+//	@Failure		401				{object}	ProblemDetail
+//	@Failure		404				{object}	ProblemDetail
+//	@Failure		406				{object}	ProblemDetail
+//	@Failure		409				{object}	ProblemDetail
+//	@Failure		415				{object}	ProblemDetail
+//	@Failure		422				{object}	ProblemDetailValidation
+//	@Failure		429				{object}	ProblemDetail
+//	@Failure		500				{object}	ProblemDetail
+//	@Failure		503				{object}	ProblemDetail
+//	@Security		BearerAuth
+//	@Router			/blog/{id}/comments  [POST]
+func HandleCreateComment(
+	logger *slog.Logger,
+	validate *validation.Validator,
+	commentCreator commentCreator,
+) http.HandlerFunc {
+	const name = "handlers.HandleCreateComment"
+	logger = logger.With(slog.String("func", name))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracer.Start(r.Context(), name)
+		defer span.End()
+
+		// Read blog id from path parameters
+		idStr := r.PathValue("id")
+
+		// Convert the ID from string to int
+		blogID, err := strconv.Atoi(idStr)
+		if err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to parse id from url",
+				slog.String("id", idStr),
+				slog.String("error", err.Error()),
+			)
+			span.SetStatus(codes.Error, "ID conversion failed")
+			span.RecordError(err)
+
+			_ = encodeResponse(w, r, http.StatusBadRequest, ProblemDetail{
+				Title:   "Invalid ID",
+				Status:  http.StatusBadRequest,
+				Detail:  "The provided ID is not a valid integer.",
+				TraceID: middleware.GetTraceID(ctx),
+			})
+
+			return
+		}
+
+		// The authenticated user acts on the blog
+		principal, ok := middleware.GetPrincipal(ctx)
+		if !ok {
+			encodeError(ctx, w, r, logger, "missing principal", errNoPrincipal)
+
+			return
+		}
+
+		// Content negotiation
+		if !acceptable(w, r) || !decodable(w, r) {
+			return
+		}
+
+		// Request validation
+		request, problems, err := decodeValid[CommentRequest](r, validate)
+		if err != nil && len(problems) == 0 {
+			encodeDecodeError(ctx, w, r, logger, err)
+
+			return
+		}
+		if len(problems) > 0 {
+			validationError := "validation failed"
+			logger.ErrorContext(
+				ctx,
+				validationError,
+				slog.Any("validation_errors", problems),
+			)
+			span.SetStatus(codes.Error, validationError)
+			span.RecordError(errors.New(validationError))
+
+			_ = encodeResponse(w, r, http.StatusBadRequest, NewValidationBadRequest(ctx, problems))
+
+			return
+		}
+
+		modelRequest := models.Comment{
+			UserID:  principal.UserID,
+			BlogID:  uint(blogID),
+			Message: request.Message,
+		}
+
+		// Create the comment
+		comment, err := commentCreator.CreateComment(ctx, modelRequest)
+		if err != nil {
+			encodeError(ctx, w, r, logger, "failed to create comment", err)
+
+			return
+		}
+
+		// Encode the response model as JSON
+		_ = encodeResponse(w, r, http.StatusCreated, CommentResponse{
+			ID:        comment.ID,
+			UserID:    comment.UserID,
+			BlogID:    comment.BlogID,
+			Message:   comment.Message,
+			CreatedAt: comment.CreatedAt,
+		})
+	}
+}
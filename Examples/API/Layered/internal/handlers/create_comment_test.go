@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/examples/api/layered/internal/middleware"
+	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/services"
+)
+
+func TestHandleCreateComment(t *testing.T) {
+	createdAt := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		id          string
+		anonymous   bool
+		input       CommentRequest
+		mockComment models.Comment
+		mockErr     error
+		wantStatus  int
+		wantBody    CommentResponse
+	}{
+		"happy path": {
+			id:    "1",
+			input: CommentRequest{Message: "Great post!"},
+			mockComment: models.Comment{
+				ID:        1,
+				UserID:    2,
+				BlogID:    1,
+				Message:   "Great post!",
+				CreatedAt: createdAt,
+			},
+			wantStatus: http.StatusCreated,
+			wantBody: CommentResponse{
+				ID:        1,
+				UserID:    2,
+				BlogID:    1,
+				Message:   "Great post!",
+				CreatedAt: createdAt,
+			},
+		},
+		"invalid id": {
+			id:         "abc",
+			input:      CommentRequest{Message: "Great post!"},
+			wantStatus: http.StatusBadRequest,
+		},
+		"missing message": {
+			id:         "1",
+			input:      CommentRequest{},
+			wantStatus: http.StatusBadRequest,
+		},
+		"blog not found": {
+			id:         "99",
+			input:      CommentRequest{Message: "Great post!"},
+			mockErr:    fmt.Errorf("blog 99: %w", services.ErrBlogNotFound),
+			wantStatus: http.StatusNotFound,
+		},
+		"unauthenticated": {
+			id:         "1",
+			anonymous:  true,
+			input:      CommentRequest{Message: "Great post!"},
+			wantStatus: http.StatusUnauthorized,
+		},
+		"user not found": {
+			id:         "1",
+			input:      CommentRequest{Message: "Great post!"},
+			mockErr:    fmt.Errorf("user 99: %w", services.ErrUserNotFound),
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(
+			name, func(t *testing.T) {
+				// Create a new request
+				reqBody, _ := json.Marshal(tc.input)
+				req := httptest.NewRequest(
+					http.MethodPost,
+					"/blog/"+tc.id+"/comments",
+					bytes.NewBuffer(reqBody),
+				)
+				req.SetPathValue("id", tc.id)
+				if !tc.anonymous {
+					req = req.WithContext(middleware.WithPrincipal(req.Context(), models.Principal{UserID: 2}))
+				}
+
+				// Create a new response recorder
+				rec := httptest.NewRecorder()
+
+				// Create a new ctxhandler
+				logger := slog.Default()
+
+				mockedCommentCreator := &moqcommentCreator{
+					CreateCommentFunc: func(_ context.Context, comment models.Comment) (models.Comment, error) {
+						assert.Equal(t, uint(2), comment.UserID)
+
+						return tc.mockComment, tc.mockErr
+					},
+				}
+
+				// Call the handler
+				handler := HandleCreateComment(logger, newTestValidator(t), mockedCommentCreator)
+
+				handler.ServeHTTP(rec, req)
+				// Check the status code
+				assert.Equal(t, tc.wantStatus, rec.Code)
+
+				// Check the body
+				if tc.wantStatus == http.StatusCreated {
+					var respBody CommentResponse
+					_ = json.Unmarshal(rec.Body.Bytes(), &respBody)
+
+					assert.Equal(t, tc.wantBody, respBody)
+				}
+			},
+		)
+	}
+}
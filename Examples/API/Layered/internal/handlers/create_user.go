@@ -8,8 +8,8 @@ import (
 
 	"go.opentelemetry.io/otel/codes"
 
-	"example.com/examples/api/layered/internal/middleware"
 	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/validation"
 )
 
 // userCreator represents a type capable of reading a user from storage and
@@ -24,14 +24,31 @@ type userCreator interface {
 //	@Description	Creates a User
 //	@Tags			user
 //	@Accept			json
+//	@Accept			xml
+//	@Accept			application/msgpack
+//	@Accept			application/cbor
 //	@Produce		json
-//	@Param			request	body		UserRequest	true	"User to Create"
-//	@Success		201		{object}	uint
-//	@Failure		400		{object}	string
-//	@Failure		404		{object}	string
-//	@Failure		500		{object}	string
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
+//	@Param			request			body		CreateUserRequest	true	"User to Create"
+//	@Param			Idempotency-Key	header		string				false	"Key that makes retries of the request safe"
+//	@Success		201				{object}	uint
+//	@Failure		400				{object}	string
+//	@Failure		404				{object}	string
+//	@Failure		406				{object}	ProblemDetail
+//	@Failure		409				{object}	ProblemDetailValidation
+//	@Failure		415				{object}	ProblemDetail
+//	@Failure		422				{object}	ProblemDetail
+//	@Failure		429				{object}	ProblemDetail
+//	@Failure		500				{object}	string
+//	@Failure		503				{object}	ProblemDetail
 //	@Router			/user  [POST]
-func HandleCreateUser(logger *slog.Logger, userCreator userCreator) http.HandlerFunc {
+func HandleCreateUser(
+	logger *slog.Logger,
+	validate *validation.Validator,
+	userCreator userCreator,
+) http.HandlerFunc {
 	const name = "handlers.HandleCreateUser"
 	logger = logger.With(slog.String("func", name))
 
@@ -39,26 +56,15 @@ func HandleCreateUser(logger *slog.Logger, userCreator userCreator) http.Handler
 		ctx, span := tracer.Start(r.Context(), name)
 		defer span.End()
 
+		// Content negotiation
+		if !acceptable(w, r) || !decodable(w, r) {
+			return
+		}
+
 		// Request validation
-		request, problems, err := decodeValid[UserRequest](r)
+		request, problems, err := decodeValid[CreateUserRequest](r, validate)
 		if err != nil && len(problems) == 0 {
-			logger.ErrorContext(
-				ctx,
-				"failed to decode request",
-				slog.String("error", err.Error()),
-			)
-			// Otel set error info
-			span.SetStatus(codes.Error, "decoding request failed")
-			span.RecordError(err)
-
-			_ = encodeResponseJSON(
-				w, http.StatusInternalServerError, ProblemDetail{
-					Title:   "Bad Request",
-					Status:  http.StatusBadRequest,
-					Detail:  "Invalid request body.",
-					TraceID: middleware.GetTraceID(ctx),
-				},
-			)
+			encodeDecodeError(ctx, w, r, logger, err)
 
 			return
 		}
@@ -72,7 +78,7 @@ func HandleCreateUser(logger *slog.Logger, userCreator userCreator) http.Handler
 			span.SetStatus(codes.Error, validationError)
 			span.RecordError(errors.New(validationError))
 
-			_ = encodeResponseJSON(w, http.StatusBadRequest, NewValidationBadRequest(ctx, problems))
+			_ = encodeResponse(w, r, http.StatusBadRequest, NewValidationBadRequest(ctx, problems))
 
 			return
 		}
@@ -86,25 +92,20 @@ func HandleCreateUser(logger *slog.Logger, userCreator userCreator) http.Handler
 		// Read the user
 		user, err := userCreator.CreateUser(ctx, modelRequest)
 		if err != nil {
-			logger.ErrorContext(
-				ctx,
-				"failed to create user",
-				slog.String("error", err.Error()),
-			)
-			span.SetStatus(codes.Error, "creating user failed")
-			span.RecordError(err)
-
-			_ = encodeResponseJSON(w, http.StatusInternalServerError, NewInternalServerError(ctx))
+			encodeError(ctx, w, r, logger, "failed to create user", err)
 
 			return
 		}
 
 		// Convert our models.User domain model into a response model.
-		_ = encodeResponseJSON(
-			w, http.StatusCreated, UserResponse{
-				ID:    user.ID,
-				Name:  user.Name,
-				Email: user.Email,
+		_ = encodeResponse(
+			w, r, http.StatusCreated, UserResponse{
+				ID:        user.ID,
+				Name:      user.Name,
+				Email:     user.Email,
+				Role:      string(user.Role),
+				CreatedAt: user.CreatedAt,
+				UpdatedAt: user.UpdatedAt,
 			},
 		)
 	}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
@@ -12,13 +14,22 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/services"
 )
 
 func TestHandleCreateUser(t *testing.T) {
 	tests := map[string]struct {
-		wantStatus int
-		wantBody   models.User
-		input      models.User
+		wantStatus  int
+		wantBody    models.User
+		wantField   string
+		wantTitle   string
+		wantMessage string
+		input       UserRequest
+		accept      string
+		contentType string
+		language    string
+		takenEmails []string
+		mockErr     error
 	}{
 		"happy path": {
 			wantStatus: 201,
@@ -28,12 +39,77 @@ func TestHandleCreateUser(t *testing.T) {
 				Email:    "john@mail.com",
 				Password: "password123!",
 			},
-			input: models.User{
+			input: UserRequest{
 				Name:     "john",
 				Email:    "john@mail.com",
 				Password: "password123!",
 			},
 		},
+		"not acceptable": {
+			wantStatus: http.StatusNotAcceptable,
+			input: UserRequest{
+				Name:     "john",
+				Email:    "john@mail.com",
+				Password: "password123!",
+			},
+			accept: "text/plain",
+		},
+		"unsupported media type": {
+			wantStatus: http.StatusUnsupportedMediaType,
+			input: UserRequest{
+				Name:     "john",
+				Email:    "john@mail.com",
+				Password: "password123!",
+			},
+			contentType: "text/plain",
+		},
+		"malformed body": {
+			wantStatus: http.StatusBadRequest,
+			input: UserRequest{
+				Name:     "john",
+				Email:    "john@mail.com",
+				Password: "password123!",
+			},
+			// The body is JSON, which cannot be decoded as XML
+			contentType: "application/xml",
+		},
+		"invalid email in spanish": {
+			wantStatus:  http.StatusBadRequest,
+			wantField:   "email",
+			wantTitle:   "Solicitud incorrecta",
+			wantMessage: "email debe ser una dirección de correo electrónico válida",
+			input: UserRequest{
+				Name:     "john",
+				Email:    "john",
+				Password: "password123!",
+			},
+			language: "es-ES, en;q=0.5",
+		},
+		"email taken": {
+			wantStatus: http.StatusBadRequest,
+			wantField:  "email",
+			input: UserRequest{
+				Name:     "john",
+				Email:    "john@mail.com",
+				Password: "password123!",
+			},
+			takenEmails: []string{"john@mail.com"},
+		},
+		"duplicate email": {
+			wantStatus: http.StatusConflict,
+			wantField:  "email",
+			input: UserRequest{
+				Name:     "john",
+				Email:    "john@mail.com",
+				Password: "password123!",
+			},
+			mockErr: fmt.Errorf("creating: %w", &services.ConstraintError{
+				Kind:       services.ErrUniqueViolation,
+				Constraint: "users_email_key",
+				Field:      "email",
+				Err:        errors.New("duplicate key value violates unique constraint"),
+			}),
+		},
 	}
 	for name, tc := range tests {
 		t.Run(
@@ -41,6 +117,9 @@ func TestHandleCreateUser(t *testing.T) {
 				// Create a new request
 				reqBody, _ := json.Marshal(tc.input)
 				req := httptest.NewRequest(http.MethodPost, "/users", bytes.NewBuffer(reqBody))
+				req.Header.Set("Accept", tc.accept)
+				req.Header.Set("Content-Type", tc.contentType)
+				req.Header.Set("Accept-Language", tc.language)
 
 				// Create a new response recorder
 				rec := httptest.NewRecorder()
@@ -53,18 +132,40 @@ func TestHandleCreateUser(t *testing.T) {
 						models.User,
 						error,
 					) {
-						return tc.wantBody, nil
+						return tc.wantBody, tc.mockErr
 					},
 				}
 
 				// Call the handler
-				handler := HandleCreateUser(logger, mockedUserCreator)
+				handler := HandleCreateUser(logger, newTestValidator(t, tc.takenEmails...), mockedUserCreator)
 
 				handler.ServeHTTP(rec, req)
 				// Check the status code
 				assert.Equal(t, tc.wantStatus, rec.Code)
+				if tc.accept != "" || tc.contentType != "" {
+					// The user is not created when the content cannot be negotiated
+					assert.Empty(t, mockedUserCreator.CreateUserCalls())
+
+					return
+				}
 
 				// Check the body
+				if tc.wantField != "" {
+					var respBody ProblemDetailValidation
+					_ = json.Unmarshal(rec.Body.Bytes(), &respBody)
+
+					if assert.Len(t, respBody.InvalidParams, 1) {
+						assert.Equal(t, tc.wantField, respBody.InvalidParams[0].Field)
+					}
+					if tc.language != "" {
+						assert.Equal(t, "es", rec.Header().Get("Content-Language"))
+						assert.Equal(t, tc.wantTitle, respBody.Title)
+						assert.Equal(t, tc.wantMessage, respBody.InvalidParams[0].Message)
+					}
+
+					return
+				}
+
 				var respBody models.User
 				_ = json.Unmarshal(rec.Body.Bytes(), &respBody)
 
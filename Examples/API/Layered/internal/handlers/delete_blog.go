@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"go.opentelemetry.io/otel/codes"
+
+	"example.com/examples/api/layered/internal/middleware"
+)
+
+// blogDeleter represents a type capable of deleting a blog from storage
+type blogDeleter interface {
+	DeleteBlog(ctx context.Context, id uint64) error
+}
+
+// HandleDeleteBlog handles the deletion of a blog by ID.
+//
+//	@Summary		Delete Blog
+//	@Description	Delete Blog by ID
+//	@Tags			blog
+//	@Accept			json
+//	@Produce		json
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
+//	@Param			id	path	string	true	"Blog ID"
+//	@Success		204
+//	@Failure		400	{object}	ProblemDetail
+//	@Failure		401	{object}	ProblemDetail
+//	@Failure		403	{object}	ProblemDetail
+//	@Failure		404	{object}	ProblemDetail
+//	@Failure		429	{object}	ProblemDetail
+//	@Failure		500	{object}	ProblemDetail
+//	@Security		BearerAuth
+//	@Router			/blog/{id}  [DELETE]
+func HandleDeleteBlog(logger *slog.Logger, blogDeleter blogDeleter) http.HandlerFunc {
+	const name = "handlers.HandleDeleteBlog"
+	logger = logger.With(slog.String("func", name))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracer.Start(r.Context(), name)
+		defer span.End()
+
+		// Read id from path parameters
+		idStr := r.PathValue("id")
+
+		// Convert the ID from string to int
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to parse id from url",
+				slog.String("id", idStr),
+				slog.String("error", err.Error()),
+			)
+			span.SetStatus(codes.Error, "ID conversion failed")
+			span.RecordError(err)
+
+			_ = encodeResponse(
+				w, r, http.StatusBadRequest, ProblemDetail{
+					Title:   "Invalid ID",
+					Status:  http.StatusBadRequest,
+					Detail:  "The provided ID is not a valid integer.",
+					TraceID: middleware.GetTraceID(ctx),
+				},
+			)
+
+			return
+		}
+
+		// Delete the blog
+		err = blogDeleter.DeleteBlog(ctx, uint64(id))
+		if err != nil {
+			encodeError(ctx, w, r, logger, "failed to delete blog", err)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/examples/api/layered/internal/services"
+)
+
+func TestHandleDeleteBlog(t *testing.T) {
+	tests := map[string]struct {
+		id         string
+		mockErr    error
+		wantStatus int
+	}{
+		"happy path": {
+			id:         "1",
+			wantStatus: http.StatusNoContent,
+		},
+		"invalid id": {
+			id:         "abc",
+			wantStatus: http.StatusBadRequest,
+		},
+		"not found": {
+			id:         "2",
+			mockErr:    fmt.Errorf("blog 2: %w", services.ErrBlogNotFound),
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(
+			name, func(t *testing.T) {
+				// Create a new request
+				req := httptest.NewRequest(http.MethodDelete, "/blog/"+tc.id, nil)
+				req.SetPathValue("id", tc.id)
+
+				// Create a new response recorder
+				rec := httptest.NewRecorder()
+
+				// Create a new ctxhandler
+				logger := slog.Default()
+
+				mockedBlogDeleter := &moqblogDeleter{
+					DeleteBlogFunc: func(_ context.Context, _ uint64) error {
+						return tc.mockErr
+					},
+				}
+
+				// Call the handler
+				handler := HandleDeleteBlog(logger, mockedBlogDeleter)
+
+				handler.ServeHTTP(rec, req)
+				// Check the status code
+				assert.Equal(t, tc.wantStatus, rec.Code)
+			},
+		)
+	}
+}
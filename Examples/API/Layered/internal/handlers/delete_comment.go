@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"go.opentelemetry.io/otel/codes"
+
+	"example.com/examples/api/layered/internal/middleware"
+)
+
+// commentDeleter represents a type capable of deleting a comment from storage
+type commentDeleter interface {
+	DeleteComment(ctx context.Context, blogID uint64, id uint64) error
+}
+
+// HandleDeleteComment handles the deletion of a comment on a blog by ID.
+//
+//	@Summary		Delete Comment
+//	@Description	Delete Comment on a Blog by ID
+//	@Tags			comment
+//	@Accept			json
+//	@Produce		json
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
+//	@Param			id			path	string	true	"Blog ID"
+//	@Param			commentId	path	string	true	"Comment ID"
+//	@Success		204
+//	@Failure		400	{object}	ProblemDetail
+//	@Failure		401	{object}	ProblemDetail
+//	@Failure		403	{object}	ProblemDetail
+//	@Failure		404	{object}	ProblemDetail
+//	@Failure		429	{object}	ProblemDetail
+//	@Failure		500	{object}	ProblemDetail
+//	@Security		BearerAuth
+//	@Router			/blog/{id}/comments/{commentId}  [DELETE]
+func HandleDeleteComment(logger *slog.Logger, commentDeleter commentDeleter) http.HandlerFunc {
+	const name = "handlers.HandleDeleteComment"
+	logger = logger.With(slog.String("func", name))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracer.Start(r.Context(), name)
+		defer span.End()
+
+		// Read ids from path parameters
+		blogIDStr := r.PathValue("id")
+		idStr := r.PathValue("commentId")
+
+		// Convert the IDs from string to int
+		blogID, blogErr := strconv.Atoi(blogIDStr)
+		id, idErr := strconv.Atoi(idStr)
+		if err := errors.Join(blogErr, idErr); err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to parse id from url",
+				slog.String("id", blogIDStr),
+				slog.String("comment_id", idStr),
+				slog.String("error", err.Error()),
+			)
+			span.SetStatus(codes.Error, "ID conversion failed")
+			span.RecordError(err)
+
+			_ = encodeResponse(
+				w, r, http.StatusBadRequest, ProblemDetail{
+					Title:   "Invalid ID",
+					Status:  http.StatusBadRequest,
+					Detail:  "The provided ID is not a valid integer.",
+					TraceID: middleware.GetTraceID(ctx),
+				},
+			)
+
+			return
+		}
+
+		// Delete the comment
+		err := commentDeleter.DeleteComment(ctx, uint64(blogID), uint64(id))
+		if err != nil {
+			encodeError(ctx, w, r, logger, "failed to delete comment", err)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
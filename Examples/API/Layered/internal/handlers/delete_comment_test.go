@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/examples/api/layered/internal/services"
+)
+
+func TestHandleDeleteComment(t *testing.T) {
+	tests := map[string]struct {
+		blogID     string
+		id         string
+		mockErr    error
+		wantStatus int
+	}{
+		"happy path": {
+			blogID:     "1",
+			id:         "8",
+			wantStatus: http.StatusNoContent,
+		},
+		"invalid blog id": {
+			blogID:     "abc",
+			id:         "8",
+			wantStatus: http.StatusBadRequest,
+		},
+		"invalid comment id": {
+			blogID:     "1",
+			id:         "abc",
+			wantStatus: http.StatusBadRequest,
+		},
+		"not found": {
+			blogID:     "1",
+			id:         "2",
+			mockErr:    fmt.Errorf("comment 2 on blog 1: %w", services.ErrCommentNotFound),
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(
+			name, func(t *testing.T) {
+				// Create a new request
+				req := httptest.NewRequest(
+					http.MethodDelete,
+					"/blog/"+tc.blogID+"/comments/"+tc.id,
+					nil,
+				)
+				req.SetPathValue("id", tc.blogID)
+				req.SetPathValue("commentId", tc.id)
+
+				// Create a new response recorder
+				rec := httptest.NewRecorder()
+
+				// Create a new ctxhandler
+				logger := slog.Default()
+
+				mockedCommentDeleter := &moqcommentDeleter{
+					DeleteCommentFunc: func(_ context.Context, _ uint64, _ uint64) error {
+						return tc.mockErr
+					},
+				}
+
+				// Call the handler
+				handler := HandleDeleteComment(logger, mockedCommentDeleter)
+
+				handler.ServeHTTP(rec, req)
+				// Check the status code
+				assert.Equal(t, tc.wantStatus, rec.Code)
+			},
+		)
+	}
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -13,21 +14,32 @@ import (
 
 // userDeleter represents a type capable of deleting a user from storage
 type userDeleter interface {
-	DeleteUser(ctx context.Context, id uint64) error
+	DeleteUser(ctx context.Context, id uint64, version uint64) error
 }
 
-// HandleDeleteUser handles the deletion of a user by ID.
+// HandleDeleteUser handles the deletion of a user by ID. If the request has an
+// If-Match header, the user is only deleted while its ETag matches.
 //
 //	@Summary		Delete User
 //	@Description	Delete User by ID
 //	@Tags			user
 //	@Accept			json
 //	@Produce		json
-//	@Param			id	path	string	true	"User ID"
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
+//	@Param			id			path	string	true	"User ID"
+//	@Param			If-Match	header	string	false	"ETag of the user version to delete"
 //	@Success		204
 //	@Failure		400	{object}	string
+//	@Failure		401	{object}	string
+//	@Failure		403	{object}	string
 //	@Failure		404	{object}	string
+//	@Failure		412	{object}	ProblemDetail
+//	@Failure		429	{object}	ProblemDetail
 //	@Failure		500	{object}	string
+//	@Failure		503	{object}	ProblemDetail
+//	@Security		BearerAuth
 //	@Router			/user/{id}  [DELETE]
 func HandleDeleteUser(logger *slog.Logger, userDeleter userDeleter) http.HandlerFunc {
 	const name = "handlers.HandleDeleteUser"
@@ -52,8 +64,8 @@ func HandleDeleteUser(logger *slog.Logger, userDeleter userDeleter) http.Handler
 			span.SetStatus(codes.Error, "ID conversion failed")
 			span.RecordError(err)
 
-			_ = encodeResponseJSON(
-				w, http.StatusBadRequest, ProblemDetail{
+			_ = encodeResponse(
+				w, r, http.StatusBadRequest, ProblemDetail{
 					Title:   "Invalid ID",
 					Status:  http.StatusBadRequest,
 					Detail:  "The provided ID is not a valid integer.",
@@ -64,18 +76,21 @@ func HandleDeleteUser(logger *slog.Logger, userDeleter userDeleter) http.Handler
 			return
 		}
 
-		// Delete the user
-		err = userDeleter.DeleteUser(ctx, uint64(id))
-		if err != nil {
-			logger.ErrorContext(
+		// Only the version of the user named by If-Match may be changed
+		version, ok := parseIfMatch(r)
+		if !ok {
+			_ = encodeResponse(w, r, http.StatusPreconditionFailed, NewPreconditionFailed(
 				ctx,
-				"failed to read user",
-				slog.String("error", err.Error()),
-			)
-			span.SetStatus(codes.Error, "user deletion failed")
-			span.RecordError(err)
+				fmt.Sprintf("User with ID %d does not match the If-Match header.", id),
+			))
 
-			_ = encodeResponseJSON(w, http.StatusInternalServerError, NewInternalServerError(ctx))
+			return
+		}
+
+		// Delete the user
+		err = userDeleter.DeleteUser(ctx, uint64(id), version)
+		if err != nil {
+			encodeError(ctx, w, r, logger, "failed to delete user", err)
 
 			return
 		}
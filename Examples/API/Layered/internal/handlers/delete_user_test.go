@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
@@ -12,17 +13,44 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/services"
 )
 
 func TestHandleDeleteUser(t *testing.T) {
 	tests := map[string]struct {
-		wantStatus int
-		wantBody   models.User
-		input      models.User
+		ifMatch       string
+		mockErr       error
+		wantVersion   uint64
+		wantStatus    int
+		wantBody      models.User
+		wantNotCalled bool
+		input         models.User
 	}{
 		"happy path": {
 			wantStatus: 204,
 		},
+		"matching version": {
+			ifMatch:     `"4-json"`,
+			wantVersion: 4,
+			wantStatus:  http.StatusNoContent,
+		},
+		"stale version": {
+			ifMatch:     `"3-json"`,
+			mockErr:     fmt.Errorf("deleting: %w", services.ErrVersionMismatch),
+			wantVersion: 3,
+			wantStatus:  http.StatusPreconditionFailed,
+		},
+		"malformed if-match": {
+			ifMatch:       "3",
+			wantStatus:    http.StatusPreconditionFailed,
+			wantNotCalled: true,
+		},
+		"conditional delete of missing user": {
+			ifMatch:     `"3-json"`,
+			mockErr:     fmt.Errorf("deleting: %w", services.ErrUserNotFound),
+			wantVersion: 3,
+			wantStatus:  http.StatusNotFound,
+		},
 	}
 	for name, tc := range tests {
 		t.Run(
@@ -31,6 +59,9 @@ func TestHandleDeleteUser(t *testing.T) {
 				reqBody, _ := json.Marshal(tc.input)
 				req := httptest.NewRequest(http.MethodDelete, "/users", bytes.NewBuffer(reqBody))
 				req.SetPathValue("id", "1")
+				if tc.ifMatch != "" {
+					req.Header.Set("If-Match", tc.ifMatch)
+				}
 
 				// Create a new response recorder
 				rec := httptest.NewRecorder()
@@ -39,8 +70,10 @@ func TestHandleDeleteUser(t *testing.T) {
 				logger := slog.Default()
 
 				mockedUserDeleter := &moquserDeleter{
-					DeleteUserFunc: func(ctx context.Context, id uint64) error {
-						return nil
+					DeleteUserFunc: func(ctx context.Context, id uint64, version uint64) error {
+						assert.Equal(t, tc.wantVersion, version)
+
+						return tc.mockErr
 					},
 				}
 
@@ -51,6 +84,10 @@ func TestHandleDeleteUser(t *testing.T) {
 				// Check the status code
 				assert.Equal(t, tc.wantStatus, rec.Code)
 
+				if tc.wantNotCalled {
+					assert.Empty(t, mockedUserDeleter.DeleteUserCalls())
+				}
+
 				// Check the body
 				var respBody models.User
 				_ = json.Unmarshal(rec.Body.Bytes(), &respBody)
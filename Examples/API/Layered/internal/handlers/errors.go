@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"example.com/examples/api/layered/internal/errs"
+	"example.com/examples/api/layered/internal/i18n"
+	"example.com/examples/api/layered/internal/problemtype"
+	"example.com/examples/api/layered/internal/services"
+)
+
+// errNoPrincipal is returned for a request that acts as the authenticated
+// user but reached its handler without one, such as through a route that was
+// registered without authentication.
+var errNoPrincipal = errs.New(errs.Unauthorized, "a bearer access token is required")
+
+// writeErrorProblem writes the problem detail for an error returned by a
+// service. The problem type, and so the status code, is chosen from the kind
+// of the error and the detail from its message. Constraint violations name
+// the offending field, and a version mismatch is a failed precondition, since
+// versions are only ever compared when a request is conditional. Errors
+// without a message, including every internal error, get a generic detail so
+// that nothing about their cause is leaked to the client.
+func writeErrorProblem(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) {
+	var constraintErr *services.ConstraintError
+	switch {
+	case errors.As(err, &constraintErr):
+		problem := NewConstraintViolation(ctx, i18n.FromRequest(r), constraintErr)
+		_ = encodeResponse(w, r, problem.Status, problem)
+
+		return
+	case errors.Is(err, services.ErrVersionMismatch):
+		_ = encodeResponse(w, r, http.StatusPreconditionFailed, NewPreconditionFailed(
+			ctx,
+			"The resource does not match the If-Match header.",
+		))
+
+		return
+	}
+
+	kind := errs.KindOf(err)
+	if kind == errs.Internal {
+		_ = encodeResponse(w, r, http.StatusInternalServerError, NewInternalServerError(ctx))
+
+		return
+	}
+
+	t := problemtype.ForKind(kind)
+	detail := errs.Message(err)
+	if detail == "" {
+		detail = "The request could not be completed."
+		if kind == errs.Unavailable {
+			detail = "The service is temporarily unavailable, please try again later."
+		}
+	}
+
+	_ = encodeResponse(w, r, t.Status, newProblem(ctx, t, detail))
+}
+
+// encodeError logs an error returned by a service, records it on the current
+// span and writes its problem detail to w. Internal and unavailable errors are
+// failures of the server, so they are logged as errors and mark the span as
+// failed. Every other kind is the client's to correct, so it is logged at info
+// level (warn for failed authentication) and leaves the span status unset.
+func encodeError(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	logger *slog.Logger,
+	msg string,
+	err error,
+) {
+	kind := errs.KindOf(err)
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.String("error.kind", kind.String()))
+
+	switch kind {
+	case errs.Internal, errs.Unavailable:
+		logger.ErrorContext(
+			ctx,
+			msg,
+			slog.String("kind", kind.String()),
+			slog.String("error", err.Error()),
+		)
+		span.SetStatus(codes.Error, msg)
+		span.RecordError(err)
+	case errs.Unauthorized:
+		logger.WarnContext(
+			ctx,
+			msg,
+			slog.String("kind", kind.String()),
+			slog.String("error", err.Error()),
+		)
+	default:
+		logger.InfoContext(
+			ctx,
+			msg,
+			slog.String("kind", kind.String()),
+			slog.String("error", err.Error()),
+		)
+	}
+
+	writeErrorProblem(ctx, w, r, err)
+}
+
+// encodeDecodeError logs an error returned while decoding and validating a
+// request body and writes its problem detail to w. A body that could not be
+// decoded is the client's to correct, so it is logged at info level and
+// answered with a 400, while any other failure, such as a validation rule
+// unable to reach the database, is handled by encodeError.
+func encodeDecodeError(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	logger *slog.Logger,
+	err error,
+) {
+	if !errors.Is(err, errMalformedBody) {
+		encodeError(ctx, w, r, logger, "failed to validate request", err)
+
+		return
+	}
+
+	logger.InfoContext(ctx, "failed to decode request", slog.String("error", err.Error()))
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("error.kind", errs.Invalid.String()))
+
+	_ = encodeResponse(w, r, http.StatusBadRequest, newProblem(
+		ctx,
+		problemtype.BadRequest,
+		"The request body could not be decoded.",
+	))
+}
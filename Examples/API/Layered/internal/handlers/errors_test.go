@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/examples/api/layered/internal/errs"
+	"example.com/examples/api/layered/internal/services"
+)
+
+func TestEncodeError(t *testing.T) {
+	tests := map[string]struct {
+		err        error
+		wantStatus int
+		wantType   string
+		wantTitle  string
+		wantDetail string
+	}{
+		"not found": {
+			err:        fmt.Errorf("[in services.BlogsService.ReadBlog] blog 7: %w", services.ErrBlogNotFound),
+			wantStatus: http.StatusNotFound,
+			wantType:   "/api/problems/not-found",
+			wantTitle:  "Not Found",
+			wantDetail: "Blog not found.",
+		},
+		"conflict": {
+			err:        services.ErrAlreadyVoted,
+			wantStatus: http.StatusConflict,
+			wantType:   "/api/problems/conflict",
+			wantTitle:  "Conflict",
+			wantDetail: "User has already voted on blog.",
+		},
+		"invalid": {
+			err:        errs.E(errs.Invalid, errors.New("bad input")),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantType:   "/api/problems/unprocessable",
+			wantTitle:  "Unprocessable Entity",
+			wantDetail: "The request could not be completed.",
+		},
+		"unauthorized": {
+			err:        services.ErrInvalidCredentials,
+			wantStatus: http.StatusUnauthorized,
+			wantType:   "/api/problems/unauthorized",
+			wantTitle:  "Unauthorized",
+			wantDetail: "The email or password is incorrect.",
+		},
+		"unavailable": {
+			err:        fmt.Errorf("cache: %w", errs.E(errs.Unavailable, errors.New("connection refused"))),
+			wantStatus: http.StatusServiceUnavailable,
+			wantType:   "/api/problems/unavailable",
+			wantTitle:  "Service Unavailable",
+			wantDetail: "The service is temporarily unavailable, please try again later.",
+		},
+		"version mismatch": {
+			err:        services.ErrVersionMismatch,
+			wantStatus: http.StatusPreconditionFailed,
+			wantType:   "/api/problems/precondition-failed",
+			wantTitle:  "Precondition Failed",
+			wantDetail: "The resource does not match the If-Match header.",
+		},
+		"constraint violation": {
+			err: &services.ConstraintError{
+				Kind:       services.ErrUniqueViolation,
+				Constraint: "users_email_key",
+				Field:      "email",
+				Err:        errors.New("duplicate key value"),
+			},
+			wantStatus: http.StatusConflict,
+			wantType:   "/api/problems/conflict",
+			wantTitle:  "Conflict",
+			wantDetail: "The request conflicts with an existing resource.",
+		},
+		"internal does not leak its cause": {
+			err:        errors.New("pq: relation \"users\" does not exist"),
+			wantStatus: http.StatusInternalServerError,
+			wantType:   "/api/problems/internal",
+			wantTitle:  "Internal Server Error",
+			wantDetail: "An unexpected error occurred.",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(
+			name, func(t *testing.T) {
+				rec := httptest.NewRecorder()
+
+				req := httptest.NewRequest(http.MethodGet, "/api/blog/7", nil)
+
+				encodeError(t.Context(), rec, req, slog.Default(), "failed", tc.err)
+
+				assert.Equal(t, tc.wantStatus, rec.Code)
+
+				var problem ProblemDetail
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+				assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+				assert.Equal(t, tc.wantStatus, problem.Status)
+				assert.Equal(t, tc.wantType, problem.Type)
+				assert.Equal(t, "/api/blog/7", problem.Instance)
+				assert.Equal(t, tc.wantTitle, problem.Title)
+				assert.Equal(t, tc.wantDetail, problem.Detail)
+			},
+		)
+	}
+}
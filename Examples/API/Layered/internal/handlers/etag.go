@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// versionETag returns the strong entity tag identifying the provided version
+// of a record encoded as the provided media type, e.g. "3-json". Each media
+// type encodes the version to different bytes, so a strong tag must name it.
+func versionETag(version uint64, mediaType string) string {
+	return `"` + strconv.FormatUint(version, 10) + "-" + etagSuffix(mediaType) + `"`
+}
+
+// etagSuffix returns the part of a version ETag naming the provided media
+// type: its subtype.
+func etagSuffix(mediaType string) string {
+	_, subtype, _ := strings.Cut(mediaType, "/")
+
+	return subtype
+}
+
+// contentETag returns a weak entity tag derived from the JSON encoding of the
+// provided value, for responses such as lists that have no version of their
+// own.
+func contentETag(v any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("[in handlers.contentETag] failed to marshal value: %w", err)
+	}
+
+	sum := sha256.Sum256(b)
+
+	return `W/"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`, nil
+}
+
+// parseIfMatch reads the If-Match header of the provided request and returns
+// the version it requires. A missing header or "*" requires no particular
+// version, which is returned as zero. Only a single strong entity tag, as
+// returned by versionETag for any of our codecs, can match a version; ok is
+// false for anything else, in which case the precondition has failed. The
+// media type of the tag does not matter, as every representation of a version
+// is of the same state.
+func parseIfMatch(r *http.Request) (version uint64, ok bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return 0, true
+	}
+
+	tag, quoted := strings.CutPrefix(header, `"`)
+	tag, closed := strings.CutSuffix(tag, `"`)
+	if !quoted || !closed {
+		return 0, false
+	}
+
+	tag, suffix, _ := strings.Cut(tag, "-")
+	if !slices.ContainsFunc(codecs, func(c codec) bool { return etagSuffix(c.mediaType) == suffix }) {
+		return 0, false
+	}
+
+	version, err := strconv.ParseUint(tag, 10, 64)
+	if err != nil || version == 0 {
+		return 0, false
+	}
+
+	return version, true
+}
+
+// setValidators sets the ETag and, unless it is zero, the Last-Modified
+// headers of a response. The response may be stored but must be revalidated
+// before it is reused, so that clients never see a stale copy; private keeps
+// authenticated responses out of shared caches.
+func setValidators(w http.ResponseWriter, etag string, lastModified time.Time) {
+	w.Header().Set("ETag", etag)
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	w.Header().Set("Cache-Control", "private, no-cache")
+}
+
+// notModified reports whether the copy the client of a GET request holds is
+// still current, given the validators of the current representation. As in
+// RFC 9110, If-None-Match is evaluated with weak comparison and takes
+// precedence over If-Modified-Since, which is only compared to the second.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		for _, tag := range strings.Split(header, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if lastModified.IsZero() {
+		return false
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	return !lastModified.Truncate(time.Second).After(since)
+}
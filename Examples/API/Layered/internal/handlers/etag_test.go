@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	tests := map[string]struct {
+		header      string
+		wantVersion uint64
+		wantOK      bool
+	}{
+		"missing":        {header: "", wantOK: true},
+		"any":            {header: "*", wantOK: true},
+		"strong tag":     {header: versionETag(3, "application/json"), wantVersion: 3, wantOK: true},
+		"other codec":    {header: versionETag(3, "application/cbor"), wantVersion: 3, wantOK: true},
+		"padded":         {header: ` "3-json" `, wantVersion: 3, wantOK: true},
+		"weak tag":       {header: `W/"3-json"`},
+		"unquoted":       {header: "3-json"},
+		"no media type":  {header: `"3"`},
+		"unknown media":  {header: `"3-html"`},
+		"not a version":  {header: `"abc-json"`},
+		"zero":           {header: `"0-json"`},
+		"list of tags":   {header: `"3-json", "4-json"`},
+		"unclosed quote": {header: `"3-json`},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/user/1", nil)
+			if tc.header != "" {
+				req.Header.Set("If-Match", tc.header)
+			}
+
+			version, ok := parseIfMatch(req)
+			assert.Equal(t, tc.wantVersion, version)
+			assert.Equal(t, tc.wantOK, ok)
+		})
+	}
+}
+
+func TestVersionETag(t *testing.T) {
+	tags := map[string]bool{}
+	for _, c := range codecs {
+		tags[versionETag(3, c.mediaType)] = true
+	}
+
+	assert.Equal(t, `"3-json"`, versionETag(3, "application/json"))
+	assert.Len(t, tags, len(codecs), "each media type has a tag of its own")
+}
+
+func TestContentETag(t *testing.T) {
+	first, err := contentETag(listUsersResponse{Users: []UserResponse{{ID: 1, Name: "john"}}, Limit: 20})
+	require.NoError(t, err)
+	same, err := contentETag(listUsersResponse{Users: []UserResponse{{ID: 1, Name: "john"}}, Limit: 20})
+	require.NoError(t, err)
+	changed, err := contentETag(listUsersResponse{Users: []UserResponse{{ID: 1, Name: "jim"}}, Limit: 20})
+	require.NoError(t, err)
+
+	assert.Regexp(t, `^W/"[A-Za-z0-9_-]+"$`, first)
+	assert.Equal(t, first, same)
+	assert.NotEqual(t, first, changed)
+}
+
+func TestNotModified(t *testing.T) {
+	lastModified := time.Date(2024, 5, 1, 12, 30, 0, 500, time.UTC)
+
+	tests := map[string]struct {
+		ifNoneMatch     string
+		ifModifiedSince string
+		etag            string
+		lastModified    time.Time
+		want            bool
+	}{
+		"unconditional": {
+			etag:         `"3"`,
+			lastModified: lastModified,
+		},
+		"matching etag": {
+			ifNoneMatch: `"3"`,
+			etag:        `"3"`,
+			want:        true,
+		},
+		"etag in a list": {
+			ifNoneMatch: `"2", "3"`,
+			etag:        `"3"`,
+			want:        true,
+		},
+		"weak comparison": {
+			ifNoneMatch: `"abc"`,
+			etag:        `W/"abc"`,
+			want:        true,
+		},
+		"any etag": {
+			ifNoneMatch: "*",
+			etag:        `"3"`,
+			want:        true,
+		},
+		"stale etag": {
+			ifNoneMatch: `"2"`,
+			etag:        `"3"`,
+		},
+		"etag takes precedence over date": {
+			ifNoneMatch:     `"2"`,
+			ifModifiedSince: lastModified.Format(http.TimeFormat),
+			etag:            `"3"`,
+			lastModified:    lastModified,
+		},
+		"unmodified since": {
+			ifModifiedSince: lastModified.Format(http.TimeFormat),
+			etag:            `"3"`,
+			lastModified:    lastModified,
+			want:            true,
+		},
+		"modified since": {
+			ifModifiedSince: lastModified.Add(-time.Second).Format(http.TimeFormat),
+			etag:            `"3"`,
+			lastModified:    lastModified,
+		},
+		"unknown modification time": {
+			ifModifiedSince: lastModified.Format(http.TimeFormat),
+			etag:            `"3"`,
+		},
+		"invalid date": {
+			ifModifiedSince: "yesterday",
+			etag:            `"3"`,
+			lastModified:    lastModified,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/user/1", nil)
+			if tc.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tc.ifNoneMatch)
+			}
+			if tc.ifModifiedSince != "" {
+				req.Header.Set("If-Modified-Since", tc.ifModifiedSince)
+			}
+
+			assert.Equal(t, tc.want, notModified(req, tc.etag, tc.lastModified))
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+
+	"example.com/examples/api/layered/internal/models"
+)
+
+// exportFlushInterval is the number of users written to an export between
+// flushes to the client.
+const exportFlushInterval = 100
+
+// usersExporter represents a type capable of reading every user from storage
+// one at a time.
+type usersExporter interface {
+	ExportUsers(ctx context.Context) iter.Seq2[models.User, error]
+}
+
+// exportEncoder represents a type capable of writing users to an export in a
+// particular format.
+type exportEncoder interface {
+	Encode(user UserResponse) error
+	Flush() error
+}
+
+// ndjsonExportEncoder writes users as NDJSON, one UserResponse object per line.
+type ndjsonExportEncoder struct {
+	encoder *json.Encoder
+}
+
+// Encode writes a user as a line of JSON.
+func (e ndjsonExportEncoder) Encode(user UserResponse) error {
+	return e.encoder.Encode(user)
+}
+
+// Flush is a no-op, as users are written as they are encoded.
+func (e ndjsonExportEncoder) Flush() error {
+	return nil
+}
+
+// csvExportEncoder writes users as CSV, after a header naming the columns.
+type csvExportEncoder struct {
+	writer *csv.Writer
+}
+
+// newCSVExportEncoder creates a csvExportEncoder and writes the header.
+func newCSVExportEncoder(w io.Writer) (csvExportEncoder, error) {
+	e := csvExportEncoder{writer: csv.NewWriter(w)}
+	err := e.writer.Write([]string{"id", "name", "email", "role", "created_at", "updated_at"})
+
+	return e, err
+}
+
+// Encode writes a user as a CSV record. Timestamps are written in RFC 3339
+// format.
+func (e csvExportEncoder) Encode(user UserResponse) error {
+	return e.writer.Write([]string{
+		strconv.FormatUint(uint64(user.ID), 10),
+		user.Name,
+		user.Email,
+		user.Role,
+		user.CreatedAt.Format(time.RFC3339),
+		user.UpdatedAt.Format(time.RFC3339),
+	})
+}
+
+// Flush writes any buffered records.
+func (e csvExportEncoder) Flush() error {
+	e.writer.Flush()
+
+	return e.writer.Error()
+}
+
+// HandleExportUsers handles the export of every user as NDJSON or CSV, chosen
+// by the Accept header and defaulting to NDJSON. Users are streamed to the
+// client as they are read and flushed regularly, so exports of any size use
+// constant memory. Passwords are never exported. If reading fails part way
+// through, the response is aborted so that the client cannot mistake a
+// truncated export for a complete one.
+//
+//	@Summary		Export Users
+//	@Description	Stream every User as NDJSON or CSV, chosen by the Accept header. Passwords are never included.
+//	@Tags			user
+//	@Produce		application/x-ndjson
+//	@Produce		text/csv
+//	@Success		200	{object}	UserResponse	"One user per line"
+//	@Failure		401	{object}	ProblemDetail
+//	@Failure		403	{object}	ProblemDetail
+//	@Failure		406	{object}	ProblemDetail
+//	@Failure		429	{object}	ProblemDetail
+//	@Failure		500	{object}	ProblemDetail
+//	@Security		BearerAuth
+//	@Router			/user/export  [GET]
+func HandleExportUsers(logger *slog.Logger, usersExporter usersExporter) http.HandlerFunc {
+	const name = "handlers.HandleExportUsers"
+	logger = logger.With(slog.String("func", name))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracer.Start(r.Context(), name)
+		defer span.End()
+
+		// Pick the format the client accepts
+		mediaType := negotiate(r.Header.Get("Accept"), ndjsonMediaType, csvMediaType)
+		if mediaType == "" {
+			logger.ErrorContext(
+				ctx,
+				"no acceptable media type",
+				slog.String("accept", r.Header.Get("Accept")),
+			)
+			span.SetStatus(codes.Error, "no acceptable media type")
+
+			_ = encodeResponse(w, r, http.StatusNotAcceptable, NewNotAcceptable(
+				ctx,
+				fmt.Sprintf("Users can only be exported as %s or %s.", ndjsonMediaType, csvMediaType),
+			))
+
+			return
+		}
+
+		// The response is only started once the first user has been read, so
+		// that a failure to read any users can still be reported.
+		var encoder exportEncoder
+		start := func() error {
+			extension := "ndjson"
+			if mediaType == csvMediaType {
+				extension = "csv"
+			}
+			w.Header().Set("Content-Type", mediaType)
+			w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="users.%s"`, extension))
+			w.WriteHeader(http.StatusOK)
+
+			if mediaType == csvMediaType {
+				csvEncoder, err := newCSVExportEncoder(w)
+				encoder = csvEncoder
+
+				return err
+			}
+			encoder = ndjsonExportEncoder{encoder: json.NewEncoder(w)}
+
+			return nil
+		}
+
+		// Stream the users
+		rc := http.NewResponseController(w)
+		exported := 0
+		for user, err := range usersExporter.ExportUsers(ctx) {
+			if err != nil {
+				logger.ErrorContext(
+					ctx,
+					"failed to export users",
+					slog.String("error", err.Error()),
+					slog.Int("exported", exported),
+				)
+				span.SetStatus(codes.Error, "user export failed")
+				span.RecordError(err)
+
+				if encoder == nil {
+					_ = encodeResponse(w, r, http.StatusInternalServerError, NewInternalServerError(ctx))
+
+					return
+				}
+
+				// The status has already been sent, so aborting is the only way
+				// left to tell the client that the export is incomplete.
+				panic(http.ErrAbortHandler)
+			}
+
+			if encoder == nil {
+				err = start()
+			}
+			if err == nil {
+				err = encoder.Encode(UserResponse{
+					ID:        user.ID,
+					Name:      user.Name,
+					Email:     user.Email,
+					Role:      string(user.Role),
+					CreatedAt: user.CreatedAt,
+					UpdatedAt: user.UpdatedAt,
+				})
+			}
+			if err != nil {
+				// The client has gone away
+				logger.WarnContext(
+					ctx,
+					"failed to write export",
+					slog.String("error", err.Error()),
+					slog.Int("exported", exported),
+				)
+
+				return
+			}
+
+			exported++
+			if exported%exportFlushInterval == 0 {
+				_ = encoder.Flush()
+				_ = rc.Flush()
+			}
+		}
+
+		// An export with no users is still a valid, empty, export
+		if encoder == nil {
+			_ = start()
+		}
+		_ = encoder.Flush()
+	}
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/examples/api/layered/internal/models"
+)
+
+func TestHandleExportUsers(t *testing.T) {
+	createdAt := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	updatedAt := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	users := []models.User{
+		{
+			ID:        1,
+			Name:      "john",
+			Email:     "john@mail.com",
+			Password:  "hash-of-john",
+			Role:      models.RoleAdmin,
+			CreatedAt: createdAt,
+			UpdatedAt: updatedAt,
+		},
+		{
+			ID:        2,
+			Name:      "Smith, Jane",
+			Email:     "jane@mail.com",
+			Password:  "hash-of-jane",
+			Role:      models.RoleUser,
+			CreatedAt: createdAt,
+			UpdatedAt: createdAt,
+		},
+	}
+	errRead := errors.New("connection reset")
+
+	tests := map[string]struct {
+		accept          string
+		users           []models.User
+		mockErr         error
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+		wantAbort       bool
+	}{
+		"ndjson by default": {
+			users:           users,
+			wantStatus:      http.StatusOK,
+			wantContentType: ndjsonMediaType,
+			wantBody: `{"id":1,"name":"john","email":"john@mail.com","role":"admin",` +
+				`"createdAt":"2024-04-01T09:00:00Z","updatedAt":"2024-05-01T12:30:00Z"}` + "\n" +
+				`{"id":2,"name":"Smith, Jane","email":"jane@mail.com","role":"user",` +
+				`"createdAt":"2024-04-01T09:00:00Z","updatedAt":"2024-04-01T09:00:00Z"}` + "\n",
+		},
+		"csv": {
+			accept:          "text/csv",
+			users:           users,
+			wantStatus:      http.StatusOK,
+			wantContentType: csvMediaType,
+			wantBody: "id,name,email,role,created_at,updated_at\n" +
+				"1,john,john@mail.com,admin,2024-04-01T09:00:00Z,2024-05-01T12:30:00Z\n" +
+				`2,"Smith, Jane",jane@mail.com,user,2024-04-01T09:00:00Z,2024-04-01T09:00:00Z` + "\n",
+		},
+		"empty csv has a header": {
+			accept:          "text/csv",
+			wantStatus:      http.StatusOK,
+			wantContentType: csvMediaType,
+			wantBody:        "id,name,email,role,created_at,updated_at\n",
+		},
+		"empty ndjson": {
+			accept:          ndjsonMediaType,
+			wantStatus:      http.StatusOK,
+			wantContentType: ndjsonMediaType,
+		},
+		"not acceptable": {
+			accept:          "application/json",
+			wantStatus:      http.StatusNotAcceptable,
+			wantContentType: "application/problem+json",
+		},
+		"failure before the first user": {
+			mockErr:         errRead,
+			wantStatus:      http.StatusInternalServerError,
+			wantContentType: "application/problem+json",
+		},
+		"failure part way through aborts": {
+			users:     users,
+			mockErr:   errRead,
+			wantAbort: true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(
+			name, func(t *testing.T) {
+				// Create a new request
+				req := httptest.NewRequest(http.MethodGet, "/user/export", nil)
+				if tc.accept != "" {
+					req.Header.Set("Accept", tc.accept)
+				}
+
+				// Create a new response recorder
+				rec := httptest.NewRecorder()
+
+				// Create a new ctxhandler
+				logger := slog.Default()
+
+				mockedUsersExporter := &moqusersExporter{
+					ExportUsersFunc: func(_ context.Context) iter.Seq2[models.User, error] {
+						return func(yield func(models.User, error) bool) {
+							for _, user := range tc.users {
+								if !yield(user, nil) {
+									return
+								}
+							}
+							if tc.mockErr != nil {
+								yield(models.User{}, tc.mockErr)
+							}
+						}
+					},
+				}
+
+				// Call the handler
+				handler := HandleExportUsers(logger, mockedUsersExporter)
+
+				if tc.wantAbort {
+					assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+						handler.ServeHTTP(rec, req)
+					})
+
+					return
+				}
+
+				handler.ServeHTTP(rec, req)
+				// Check the status code
+				assert.Equal(t, tc.wantStatus, rec.Code)
+				assert.Equal(t, tc.wantContentType, rec.Header().Get("Content-Type"))
+
+				// Check the body
+				if tc.wantStatus == http.StatusOK {
+					assert.Equal(t, tc.wantBody, rec.Body.String())
+					assert.NotContains(t, rec.Body.String(), "hash-of")
+				}
+			},
+		)
+	}
+}
@@ -1,68 +1,178 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"net/http"
+	"slices"
+	"time"
 
+	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
 	"go.opentelemetry.io/otel"
 
+	"example.com/examples/api/layered/internal/i18n"
 	"example.com/examples/api/layered/internal/middleware"
+	"example.com/examples/api/layered/internal/problemtype"
+	"example.com/examples/api/layered/internal/services"
+	"example.com/examples/api/layered/internal/validation"
 )
 
 const name = "example.com/examples/api/layered/internal/handlers"
 
 var tracer = otel.Tracer(name)
 
-// UserRequest represents the request for creating a user.
+// CreateUserRequest represents the request for creating a user. Unlike an
+// update, its email must not be registered by any user.
+type CreateUserRequest struct {
+	Name     string `json:"name"     xml:"name"     validate:"required,min=2,max=50"`
+	Email    string `json:"email"    xml:"email"    validate:"required,email,email_domain,unique_email"`
+	Password string `json:"password" xml:"password" validate:"required,min=8,max=30,password"`
+}
+
+// UserRequest represents the request for updating or importing a user.
 type UserRequest struct {
-	Name     string `json:"name"     validate:"required,min=2,max=50"`
-	Email    string `json:"email"    validate:"required,email"`
-	Password string `json:"password" validate:"required,min=8,max=30"`
+	Name     string `json:"name"     xml:"name"     validate:"required,min=2,max=50"`
+	Email    string `json:"email"    xml:"email"    validate:"required,email,email_domain"`
+	Password string `json:"password" xml:"password" validate:"required,min=8,max=30,password"`
 }
 
-// UserResponse represents the response for creating a user.
+// UserPatchRequest represents a JSON Merge Patch of a user. Only the fields
+// present are validated and updated.
+type UserPatchRequest struct {
+	Name     *string `json:"name"     validate:"omitnil,min=2,max=50"`
+	Email    *string `json:"email"    validate:"omitnil,email,email_domain"`
+	Password *string `json:"password" validate:"omitnil,min=8,max=30,password"`
+}
+
+// UserResponse represents the response for a user. Timestamps are encoded in
+// RFC 3339 format.
 type UserResponse struct {
-	ID    uint   `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	ID        uint      `json:"id"        xml:"id"`
+	Name      string    `json:"name"      xml:"name"`
+	Email     string    `json:"email"     xml:"email"`
+	Role      string    `json:"role"      xml:"role"`
+	CreatedAt time.Time `json:"createdAt" xml:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt" xml:"updatedAt"`
+}
+
+// LoginRequest represents the request for logging in with an email and
+// password.
+type LoginRequest struct {
+	Email    string `json:"email"    xml:"email"    validate:"required,email"`
+	Password string `json:"password" xml:"password" validate:"required"`
+}
+
+// RefreshRequest represents the request for exchanging a refresh token for a
+// new token pair.
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken" xml:"refreshToken" validate:"required"`
+}
+
+// TokenResponse represents the response for a successful login or refresh.
+type TokenResponse struct {
+	TokenType             string    `json:"tokenType"             xml:"tokenType"`
+	AccessToken           string    `json:"accessToken"           xml:"accessToken"`
+	AccessTokenExpiresAt  time.Time `json:"accessTokenExpiresAt"  xml:"accessTokenExpiresAt"`
+	RefreshToken          string    `json:"refreshToken"          xml:"refreshToken"`
+	RefreshTokenExpiresAt time.Time `json:"refreshTokenExpiresAt" xml:"refreshTokenExpiresAt"`
+}
+
+// BlogRequest represents the request for creating or updating a blog. A blog
+// is written by the authenticated user and keeps its author when updated.
+type BlogRequest struct {
+	Title string `json:"title" xml:"title" validate:"required,min=1,max=200"`
+}
+
+// BlogResponse represents the response for a blog.
+type BlogResponse struct {
+	ID       uint    `json:"id"       xml:"id"`
+	AuthorID uint    `json:"authorId" xml:"authorId"`
+	Title    string  `json:"title"    xml:"title"`
+	Score    float32 `json:"score"    xml:"score"`
+}
+
+// VoteRequest represents the request for voting on a blog. The vote is cast
+// by the authenticated user.
+type VoteRequest struct {
+	Direction string `json:"direction" xml:"direction" validate:"required,oneof=up down"`
+}
+
+// CommentRequest represents the request for creating a comment on a blog. The
+// comment is written by the authenticated user.
+type CommentRequest struct {
+	Message string `json:"message" xml:"message" validate:"required,min=1,max=1000"`
+}
+
+// CommentResponse represents the response for a comment.
+type CommentResponse struct {
+	ID        uint      `json:"id"        xml:"id"`
+	UserID    uint      `json:"userId"    xml:"userId"`
+	BlogID    uint      `json:"blogId"    xml:"blogId"`
+	Message   string    `json:"message"   xml:"message"`
+	CreatedAt time.Time `json:"createdAt" xml:"createdAt"`
 }
 
-// ProblemDetail represents the structure for problem details as per RFC 7807.
+// ProblemDetail represents a problem detail as per RFC 9457. Type is the URI
+// of one of the types in the problemtype registry and Instance the path of
+// the request, both filled in by encodeResponse when left empty. TraceID is an
+// extension member; problems with more extension members embed ProblemDetail,
+// as ProblemDetailValidation does. In XML it is encoded in the RFC's format.
 type ProblemDetail struct {
-	Title   string `json:"title"`
-	Status  int    `json:"status"`
-	Detail  string `json:"detail"`
-	TraceID string `json:"traceId,omitempty"`
+	XMLName  xml.Name `json:"-"                  xml:"urn:ietf:rfc:7807 problem" swaggerignore:"true"`
+	Type     string   `json:"type"               xml:"type"`
+	Title    string   `json:"title"              xml:"title"`
+	Status   int      `json:"status"             xml:"status"`
+	Detail   string   `json:"detail"             xml:"detail"`
+	Instance string   `json:"instance,omitempty" xml:"instance,omitempty"`
+	TraceID  string   `json:"traceId,omitempty"  xml:"traceId,omitempty"`
 }
 
-// validationProblem represents a single validation error detail.
+// validationProblem represents a single validation error detail. Position is
+// set for problems in filter and sort expressions and is the 1-based position
+// of the offending token.
 type validationProblem struct {
-	Field   string `json:"field"`
-	Code    string `json:"code"`
-	Message string `json:"message"`
+	Field    string `json:"field"              xml:"field"`
+	Code     string `json:"code"               xml:"code"`
+	Message  string `json:"message"            xml:"message"`
+	Position int    `json:"position,omitempty" xml:"position,omitempty"`
 }
 
 // ProblemDetailValidation extends ProblemDetail to include validation errors.
 type ProblemDetailValidation struct {
 	ProblemDetail
-	InvalidParams []validationProblem `json:"invalidParams"` // A list of invalid parameters with error details.
+	// A list of invalid parameters with error details.
+	InvalidParams []validationProblem `json:"invalidParams" xml:"invalidParams>i"`
 }
 
-// decodeValid decodes a model from an http request and performs validation
-// on it.
-func decodeValid[T any](r *http.Request) (T, []validationProblem, error) {
+// decodeValid decodes a model from an http request, in the media type given
+// by its Content-Type header, and performs validation on it with validate. A
+// body that cannot be decoded is reported as errMalformedBody.
+// Validation messages are in the language the Accept-Language header prefers.
+func decodeValid[T any](r *http.Request, validate *validation.Validator) (T, []validationProblem, error) {
 	var v T
-	if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+	c, ok := requestCodec(r)
+	if !ok {
 		return v, []validationProblem{}, fmt.Errorf(
-			"[in handlers.decodeValid] decode body failed: %w",
+			"[in handlers.decodeValid] %w: %s",
+			errUnsupportedMediaType,
+			r.Header.Get("Content-Type"),
+		)
+	}
+	if err := c.decode(r.Body, &v); err != nil {
+		return v, []validationProblem{}, fmt.Errorf(
+			"[in handlers.decodeValid] decode body failed: %w: %w",
+			errMalformedBody,
 			err,
 		)
 	}
-	problems, err := validate(&v)
+	problems, err := validate.Struct(r.Context(), &v)
 	if err != nil {
 		return v, []validationProblem{}, fmt.Errorf(
 			"[in handlers.decodeValid] validate failed: %w",
@@ -70,35 +180,114 @@ func decodeValid[T any](r *http.Request) (T, []validationProblem, error) {
 		)
 	}
 	if len(problems) > 0 {
-		validationProblems := make([]validationProblem, len(problems))
-		for i, problem := range problems {
-			validationProblems[i] = validationProblem{
-				Field:   problem.Field(),
-				Code:    problem.Tag(),
-				Message: problem.Error(),
-			}
+		return v, toValidationProblems(problems, i18n.FromRequest(r)), nil
+	}
+
+	return v, []validationProblem{}, nil
+}
+
+// decodable reports whether the body of a request has a media type one of
+// our codecs can decode, and writes a 415 problem detail if it does not.
+func decodable(w http.ResponseWriter, r *http.Request) bool {
+	if _, ok := requestCodec(r); ok {
+		return true
+	}
+
+	_ = encodeResponse(w, r, http.StatusUnsupportedMediaType, NewUnsupportedMediaType(
+		r.Context(),
+		fmt.Sprintf("The request body must be one of %v.", codecMediaTypes()),
+	))
+
+	return false
+}
+
+// decodeMergePatch decodes a JSON Merge Patch (RFC 7396) from an http request
+// into a model whose fields are pointers, and validates only the fields the
+// patch sets. A field set to null would remove it, which no field of our
+// models allows, so nulls are reported as validation problems. A body that is
+// not a JSON object is reported as errMalformedBody, since a patch of any
+// other value would replace the whole resource.
+// Messages are in the language the Accept-Language header prefers.
+func decodeMergePatch[T any](r *http.Request, validate *validation.Validator) (T, []validationProblem, error) {
+	var v T
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return v, []validationProblem{}, fmt.Errorf(
+			"[in handlers.decodeMergePatch] read body failed: %w: %w",
+			errMalformedBody,
+			err,
+		)
+	}
+
+	// A merge patch must be an object; check for nulls before they are lost
+	// by decoding into pointers.
+	var members map[string]json.RawMessage
+	if err = json.Unmarshal(body, &members); err != nil {
+		return v, []validationProblem{}, fmt.Errorf(
+			"[in handlers.decodeMergePatch] decode body failed: %w: %w",
+			errMalformedBody,
+			err,
+		)
+	}
+	if members == nil {
+		return v, []validationProblem{}, fmt.Errorf(
+			"[in handlers.decodeMergePatch] %w: body is null",
+			errMalformedBody,
+		)
+	}
+
+	trans := i18n.FromRequest(r)
+	var problems []validationProblem
+	for _, member := range slices.Sorted(maps.Keys(members)) {
+		if bytes.Equal(bytes.TrimSpace(members[member]), []byte("null")) {
+			problems = append(problems, validationProblem{
+				Field:   member,
+				Code:    "required",
+				Message: i18n.Message(trans, i18n.CannotBeRemoved, member),
+			})
 		}
+	}
+	if len(problems) > 0 {
+		return v, problems, nil
+	}
 
-		return v, validationProblems, nil
+	if err = json.Unmarshal(body, &v); err != nil {
+		return v, []validationProblem{}, fmt.Errorf(
+			"[in handlers.decodeMergePatch] decode body failed: %w: %w",
+			errMalformedBody,
+			err,
+		)
+	}
+
+	fieldErrors, err := validate.Struct(r.Context(), &v)
+	if err != nil {
+		return v, []validationProblem{}, fmt.Errorf(
+			"[in handlers.decodeMergePatch] validate failed: %w",
+			err,
+		)
+	}
+	if len(fieldErrors) > 0 {
+		return v, toValidationProblems(fieldErrors, trans), nil
 	}
 
 	return v, []validationProblem{}, nil
 }
 
-// validate validates the provided data using the validator package.
-func validate[T any](data *T, options ...validator.Option) ([]validator.FieldError, error) {
-	v := validator.New(options...)
-	if err := v.Struct(data); err != nil {
-		var invalidValidationError *validator.InvalidValidationError
-		if errors.As(err, &invalidValidationError) {
-			return []validator.FieldError{}, fmt.Errorf(
-				"[in handlers.validate] invalid validation error: %w",
-				err,
-			)
+// toValidationProblems converts validator field errors into validation
+// problems, with messages translated by trans. Fields are named by their JSON
+// names.
+func toValidationProblems(fieldErrors []validator.FieldError, trans ut.Translator) []validationProblem {
+	problems := make([]validationProblem, len(fieldErrors))
+	for i, fieldError := range fieldErrors {
+		problems[i] = validationProblem{
+			Field:   fieldError.Field(),
+			Code:    fieldError.Tag(),
+			Message: fieldError.Translate(trans),
 		}
-		return err.(validator.ValidationErrors), nil
 	}
-	return []validator.FieldError{}, nil
+
+	return problems
 }
 
 // NewValidationBadRequest creates a ProblemDetailValidation instance for a 400 Bad Request validation error.
@@ -107,22 +296,71 @@ func NewValidationBadRequest(
 	invalidParams []validationProblem,
 ) ProblemDetailValidation {
 	return ProblemDetailValidation{
-		ProblemDetail: ProblemDetail{
-			Title:   "Bad Request",
-			Status:  http.StatusBadRequest,
-			Detail:  "The request contains invalid parameters.",
-			TraceID: middleware.GetTraceID(ctx),
-		},
+		ProblemDetail: newProblem(ctx, problemtype.InvalidParameters, "The request contains invalid parameters."),
 		InvalidParams: invalidParams,
 	}
 }
 
-// NewNotFound is a helper that creates a ProblemDetail instance for a 500 error.
+// NewConstraintViolation creates a ProblemDetailValidation instance naming the
+// field of a request that violated a database constraint. A duplicate value
+// is a 409 Conflict, while a reference to a missing record or a disallowed
+// value is a 422 Unprocessable Entity. Its message is translated by trans.
+func NewConstraintViolation(
+	ctx context.Context,
+	trans ut.Translator,
+	err *services.ConstraintError,
+) ProblemDetailValidation {
+	problem := validationProblem{Field: err.Field}
+	t, detail := problemtype.Unprocessable, "The request could not be applied."
+	switch {
+	case errors.Is(err, services.ErrUniqueViolation):
+		t, detail = problemtype.Conflict, "The request conflicts with an existing resource."
+		problem.Code = "unique"
+		problem.Message = i18n.Message(trans, i18n.AlreadyTaken, err.Field)
+	case errors.Is(err, services.ErrForeignKeyViolation):
+		problem.Code = "exists"
+		problem.Message = i18n.Message(trans, i18n.NoSuchResource, err.Field)
+	default:
+		problem.Code = "check"
+		problem.Message = i18n.Message(trans, i18n.NotAllowed, err.Field)
+	}
+
+	return ProblemDetailValidation{
+		ProblemDetail: newProblem(ctx, t, detail),
+		InvalidParams: []validationProblem{problem},
+	}
+}
+
+// NewNotAcceptable is a helper that creates a ProblemDetail instance for a
+// 406 error.
+func NewNotAcceptable(ctx context.Context, detail string) ProblemDetail {
+	return newProblem(ctx, problemtype.NotAcceptable, detail)
+}
+
+// NewUnsupportedMediaType is a helper that creates a ProblemDetail instance
+// for a 415 error.
+func NewUnsupportedMediaType(ctx context.Context, detail string) ProblemDetail {
+	return newProblem(ctx, problemtype.UnsupportedMediaType, detail)
+}
+
+// NewPreconditionFailed is a helper that creates a ProblemDetail instance for
+// a 412 error.
+func NewPreconditionFailed(ctx context.Context, detail string) ProblemDetail {
+	return newProblem(ctx, problemtype.PreconditionFailed, detail)
+}
+
+// NewInternalServerError is a helper that creates a ProblemDetail instance for a 500 error.
 func NewInternalServerError(ctx context.Context) ProblemDetail {
+	return newProblem(ctx, problemtype.Internal, "An unexpected error occurred.")
+}
+
+// newProblem creates a ProblemDetail of type t with detail.
+func newProblem(ctx context.Context, t problemtype.Type, detail string) ProblemDetail {
 	return ProblemDetail{
-		Title:   "Internal Server Error",
-		Status:  http.StatusInternalServerError,
-		Detail:  "An unexpected error occurred.",
+		Type:    t.URI(),
+		Title:   t.Title,
+		Status:  t.Status,
+		Detail:  detail,
 		TraceID: middleware.GetTraceID(ctx),
 	}
 }
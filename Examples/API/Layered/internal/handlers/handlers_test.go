@@ -1,16 +1,45 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"example.com/examples/api/layered/internal/i18n"
+	"example.com/examples/api/layered/internal/services"
+	"example.com/examples/api/layered/internal/validation"
 )
 
-func TestEncodeResponseJSON(t *testing.T) {
+// takenEmails is a validation.EmailChecker for which only its emails are
+// taken.
+type takenEmails []string
+
+func (e takenEmails) EmailTaken(_ context.Context, email string) (bool, error) {
+	return slices.Contains(e, email), nil
+}
+
+// newTestValidator creates a validator allowing any email domain, for which
+// only the provided emails are taken.
+func newTestValidator(t *testing.T, taken ...string) *validation.Validator {
+	t.Helper()
+
+	validate, err := validation.New(slog.New(slog.DiscardHandler), nil, takenEmails(taken))
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+
+	return validate
+}
+
+func TestEncodeResponse(t *testing.T) {
 	t.Parallel()
 
 	type fields struct {
@@ -96,7 +125,9 @@ func TestEncodeResponseJSON(t *testing.T) {
 			t.Parallel()
 			rec := httptest.NewRecorder()
 
-			_ = encodeResponseJSON(rec, tc.fields.status, tc.fields.data)
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+			_ = encodeResponse(rec, req, tc.fields.status, tc.fields.data)
 
 			res := rec.Result()
 			defer res.Body.Close()
@@ -118,3 +149,58 @@ func TestEncodeResponseJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestNewConstraintViolation(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		kind        error
+		field       string
+		language    string
+		wantStatus  int
+		wantCode    string
+		wantMessage string
+	}{
+		"unique": {
+			kind:        services.ErrUniqueViolation,
+			field:       "email",
+			wantStatus:  409,
+			wantCode:    "unique",
+			wantMessage: "email is already taken",
+		},
+		"foreign key": {
+			kind:        services.ErrForeignKeyViolation,
+			field:       "blogId",
+			language:    "es",
+			wantStatus:  422,
+			wantCode:    "exists",
+			wantMessage: "blogId no hace referencia a un recurso existente",
+		},
+		"check": {
+			kind:        services.ErrCheckViolation,
+			field:       "role",
+			language:    "fr",
+			wantStatus:  422,
+			wantCode:    "check",
+			wantMessage: "role n'est pas une valeur autorisée",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			problem := NewConstraintViolation(t.Context(), i18n.Translator(tc.language), &services.ConstraintError{
+				Kind:  tc.kind,
+				Field: tc.field,
+				Err:   errors.New("violated"),
+			})
+
+			assert.Equal(t, tc.wantStatus, problem.Status)
+			if assert.Len(t, problem.InvalidParams, 1) {
+				assert.Equal(t, tc.field, problem.InvalidParams[0].Field)
+				assert.Equal(t, tc.wantCode, problem.InvalidParams[0].Code)
+				assert.Equal(t, tc.wantMessage, problem.InvalidParams[0].Message)
+			}
+		})
+	}
+}
@@ -17,8 +17,8 @@ type healthChecker interface {
 
 // healthResponse represents the response for the health check.
 type healthResponse struct {
-	Status        string                  `json:"status"`
-	HealthDetails []services.HealthStatus `json:"details"`
+	Status        string                  `json:"status"  xml:"status"`
+	HealthDetails []services.HealthStatus `json:"details" xml:"details"`
 }
 
 // HandleHealthCheck handles the deep health check endpoint.
@@ -28,7 +28,11 @@ type healthResponse struct {
 //	@Tags			health
 //	@Accept			json
 //	@Produce		json
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
 //	@Success		200		{object}	healthResponse
+//	@Failure		406		{object}	ProblemDetail
 //	@Failure		500		{object}	healthResponse
 //	@Router			/health	[GET]
 func HandleHealthCheck(logger *slog.Logger, userHealth healthChecker) http.HandlerFunc {
@@ -65,8 +69,8 @@ func HandleHealthCheck(logger *slog.Logger, userHealth healthChecker) http.Handl
 			}
 		}
 
-		_ = encodeResponseJSON(
-			w, code, healthResponse{
+		_ = encodeResponse(
+			w, r, code, healthResponse{
 				Status:        status,
 				HealthDetails: checks,
 			},
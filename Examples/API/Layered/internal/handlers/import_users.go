@@ -0,0 +1,386 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"log/slog"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/codes"
+
+	"example.com/examples/api/layered/internal/i18n"
+	"example.com/examples/api/layered/internal/middleware"
+	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/validation"
+)
+
+// Media types accepted by the bulk import of users.
+const (
+	csvMediaType    = "text/csv"
+	ndjsonMediaType = "application/x-ndjson"
+)
+
+// maxImportLineSize is the longest line accepted in an NDJSON import.
+const maxImportLineSize = 64 * 1024
+
+// usersImporter represents a type capable of creating users in bulk and
+// reporting the result or an error.
+type usersImporter interface {
+	ImportUsers(
+		ctx context.Context,
+		rows iter.Seq2[models.UserImportRow, error],
+		dryRun bool,
+	) (models.UserImportResult, error)
+}
+
+// importRecord represents a single line of an import, decoded into a
+// UserRequest, or the problems that prevented decoding it.
+type importRecord struct {
+	Line     int
+	Request  UserRequest
+	Problems []validationProblem
+}
+
+// importRejection represents a line of an import that was not imported and
+// why.
+type importRejection struct {
+	Line     int                 `json:"line"          xml:"line"`
+	Problems []validationProblem `json:"invalidParams" xml:"invalidParams>i"`
+}
+
+// importUsersResponse represents the response for a bulk import of users.
+type importUsersResponse struct {
+	DryRun   bool              `json:"dryRun"   xml:"dryRun"`
+	Imported int               `json:"imported" xml:"imported"` // Users created, or that would have been by a dry run.
+	Rejected []importRejection `json:"rejected" xml:"rejected"`
+}
+
+// HandleImportUsers handles the bulk creation of users from a CSV or NDJSON
+// body. A CSV body starts with a header naming its name, email and password
+// columns, in any order; an NDJSON body has a UserRequest object on each line.
+// The body is streamed into storage, so imports of any size can be made. Each
+// line is validated as if it were created on its own, and lines that are
+// invalid or whose email is already taken are rejected and reported by line
+// number while the rest are imported. A dry run validates the import and
+// reports what would have been imported without creating any users.
+//
+//	@Summary		Import Users
+//	@Description	Create Users in bulk from CSV (header: name,email,password) or NDJSON. Invalid lines are reported.
+//	@Tags			user
+//	@Accept			text/csv
+//	@Accept			application/x-ndjson
+//	@Produce		json
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
+//	@Param			request	body		string	true	"Users to import, one per line"
+//	@Param			dryRun	query		bool	false	"Validate the import without creating any users"
+//	@Success		200		{object}	importUsersResponse
+//	@Failure		400		{object}	ProblemDetailValidation
+//	@Failure		401		{object}	ProblemDetail
+//	@Failure		403		{object}	ProblemDetail
+//	@Failure		406		{object}	ProblemDetail
+//	@Failure		415		{object}	ProblemDetail
+//	@Failure		429		{object}	ProblemDetail
+//	@Failure		500		{object}	ProblemDetail
+//	@Security		BearerAuth
+//	@Router			/user/import  [POST]
+func HandleImportUsers(
+	logger *slog.Logger,
+	validate *validation.Validator,
+	usersImporter usersImporter,
+) http.HandlerFunc {
+	const name = "handlers.HandleImportUsers"
+	logger = logger.With(slog.String("func", name))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracer.Start(r.Context(), name)
+		defer span.End()
+
+		// Read the dry run flag from query parameters
+		var problems []validationProblem
+		dryRun := false
+		if dryRunStr := r.URL.Query().Get("dryRun"); dryRunStr != "" {
+			var err error
+			if dryRun, err = strconv.ParseBool(dryRunStr); err != nil {
+				problems = append(problems, validationProblem{
+					Field:   "dryRun",
+					Code:    "boolean",
+					Message: "dryRun must be true or false",
+				})
+			}
+		}
+
+		// Content negotiation
+		if !acceptable(w, r) {
+			return
+		}
+
+		// Only CSV and NDJSON bodies are accepted
+		var records iter.Seq2[importRecord, error]
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		switch {
+		case err != nil:
+		case mediaType == csvMediaType:
+			var headerProblems []validationProblem
+			records, headerProblems = csvImportRecords(r.Body)
+			problems = append(problems, headerProblems...)
+		case mediaType == ndjsonMediaType || mediaType == "application/ndjson":
+			records = ndjsonImportRecords(r.Body)
+		}
+		if records == nil && len(problems) == 0 {
+			logger.ErrorContext(
+				ctx,
+				"unsupported content type",
+				slog.String("content_type", r.Header.Get("Content-Type")),
+			)
+			span.SetStatus(codes.Error, "unsupported content type")
+
+			_ = encodeResponse(w, r, http.StatusUnsupportedMediaType, NewUnsupportedMediaType(
+				ctx,
+				fmt.Sprintf("The request body must be %s or %s.", csvMediaType, ndjsonMediaType),
+			))
+
+			return
+		}
+		if len(problems) > 0 {
+			validationError := "validation failed"
+			logger.ErrorContext(
+				ctx,
+				validationError,
+				slog.Any("validation_errors", problems),
+			)
+			span.SetStatus(codes.Error, validationError)
+			span.RecordError(errors.New(validationError))
+
+			_ = encodeResponse(w, r, http.StatusBadRequest, NewValidationBadRequest(ctx, problems))
+
+			return
+		}
+
+		// Validate each line as it is read, passing on only the valid ones
+		trans := i18n.FromRequest(r)
+		rejected := []importRejection{}
+		var readErr error
+		rows := func(yield func(models.UserImportRow, error) bool) {
+			for record, err := range records {
+				if err != nil {
+					readErr = err
+					yield(models.UserImportRow{}, err)
+
+					return
+				}
+
+				if len(record.Problems) == 0 {
+					fieldErrors, err := validate.Struct(ctx, &record.Request)
+					if err != nil {
+						yield(models.UserImportRow{}, err)
+
+						return
+					}
+					record.Problems = toValidationProblems(fieldErrors, trans)
+				}
+				if len(record.Problems) > 0 {
+					rejected = append(rejected, importRejection{Line: record.Line, Problems: record.Problems})
+
+					continue
+				}
+
+				row := models.UserImportRow{
+					Line: record.Line,
+					User: models.User{
+						Name:     record.Request.Name,
+						Email:    record.Request.Email,
+						Password: record.Request.Password,
+					},
+				}
+				if !yield(row, nil) {
+					return
+				}
+			}
+		}
+
+		// Import the users
+		result, err := usersImporter.ImportUsers(ctx, rows, dryRun)
+		if err != nil {
+			if readErr != nil {
+				logger.ErrorContext(
+					ctx,
+					"failed to read import",
+					slog.String("error", err.Error()),
+				)
+				span.SetStatus(codes.Error, "reading import failed")
+				span.RecordError(err)
+
+				_ = encodeResponse(w, r, http.StatusBadRequest, ProblemDetail{
+					Title:   "Bad Request",
+					Status:  http.StatusBadRequest,
+					Detail:  "The request body could not be read.",
+					TraceID: middleware.GetTraceID(ctx),
+				})
+
+				return
+			}
+
+			encodeError(ctx, w, r, logger, "failed to import users", err)
+
+			return
+		}
+
+		for _, line := range result.DuplicateLines {
+			rejected = append(rejected, importRejection{
+				Line: line,
+				Problems: []validationProblem{{
+					Field:   "email",
+					Code:    "unique",
+					Message: i18n.Message(trans, i18n.AlreadyTaken, "email"),
+				}},
+			})
+		}
+		slices.SortStableFunc(rejected, func(a, b importRejection) int {
+			return a.Line - b.Line
+		})
+
+		// Encode the response model as JSON
+		_ = encodeResponse(w, r, http.StatusOK, importUsersResponse{
+			DryRun:   dryRun,
+			Imported: result.Imported,
+			Rejected: rejected,
+		})
+	}
+}
+
+// csvImportRecords reads the header of a CSV import and returns the records
+// that follow it. The header must name the name, email and password columns
+// and no others; if it does not, problems describing it are returned instead.
+// Records that are not valid CSV, or have the wrong number of fields, are
+// returned with problems.
+func csvImportRecords(body io.Reader) (iter.Seq2[importRecord, error], []validationProblem) {
+	reader := csv.NewReader(body)
+	reader.ReuseRecord = true
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, []validationProblem{{
+			Field:   "header",
+			Code:    "required",
+			Message: "the import must start with a header of name, email and password",
+		}}
+	}
+
+	columns := map[string]int{"name": -1, "email": -1, "password": -1}
+	var problems []validationProblem
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if index, ok := columns[column]; !ok || index != -1 {
+			problems = append(problems, validationProblem{
+				Field:   "header",
+				Code:    "unknown",
+				Message: fmt.Sprintf("column %d (%q) is unknown or repeated", i+1, header[i]),
+			})
+
+			continue
+		}
+		columns[column] = i
+	}
+	for _, column := range []string{"name", "email", "password"} {
+		if columns[column] == -1 {
+			problems = append(problems, validationProblem{
+				Field:   "header",
+				Code:    "required",
+				Message: fmt.Sprintf("the %s column is missing", column),
+			})
+		}
+	}
+	if len(problems) > 0 {
+		return nil, problems
+	}
+
+	return func(yield func(importRecord, error) bool) {
+		for {
+			fields, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				record := importRecord{
+					Line: parseErr.StartLine,
+					Problems: []validationProblem{{
+						Field:   "line",
+						Code:    "invalid_csv",
+						Message: parseErr.Err.Error(),
+					}},
+				}
+				if !yield(record, nil) {
+					return
+				}
+
+				continue
+			}
+			if err != nil {
+				yield(importRecord{}, err)
+
+				return
+			}
+
+			line, _ := reader.FieldPos(0)
+			record := importRecord{
+				Line: line,
+				Request: UserRequest{
+					Name:     fields[columns["name"]],
+					Email:    fields[columns["email"]],
+					Password: fields[columns["password"]],
+				},
+			}
+			if !yield(record, nil) {
+				return
+			}
+		}
+	}, nil
+}
+
+// ndjsonImportRecords returns the records of an NDJSON import, one for each
+// line that is not blank. Lines that are not a JSON object are returned with
+// problems.
+func ndjsonImportRecords(body io.Reader) iter.Seq2[importRecord, error] {
+	return func(yield func(importRecord, error) bool) {
+		scanner := bufio.NewScanner(body)
+		scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxImportLineSize)
+
+		line := 0
+		for scanner.Scan() {
+			line++
+			if len(strings.TrimSpace(scanner.Text())) == 0 {
+				continue
+			}
+
+			record := importRecord{Line: line}
+			if err := json.Unmarshal(scanner.Bytes(), &record.Request); err != nil {
+				record.Problems = []validationProblem{{
+					Field:   "line",
+					Code:    "invalid_json",
+					Message: err.Error(),
+				}}
+			}
+			if !yield(record, nil) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			yield(importRecord{}, err)
+		}
+	}
+}
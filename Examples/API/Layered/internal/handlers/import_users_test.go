@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"iter"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/examples/api/layered/internal/models"
+)
+
+func TestHandleImportUsers(t *testing.T) {
+	tests := map[string]struct {
+		contentType  string
+		query        string
+		body         string
+		duplicates   []int
+		mockErr      error
+		wantRows     []models.UserImportRow
+		wantDryRun   bool
+		wantStatus   int
+		wantBody     importUsersResponse
+		wantProblems []string
+	}{
+		"csv": {
+			contentType: "text/csv; charset=utf-8",
+			body: "email,name,password\n" +
+				"john@mail.com,john,password123\n" +
+				"jane@mail.com,jane,password456\n",
+			wantRows: []models.UserImportRow{
+				{Line: 2, User: models.User{Name: "john", Email: "john@mail.com", Password: "password123"}},
+				{Line: 3, User: models.User{Name: "jane", Email: "jane@mail.com", Password: "password456"}},
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   importUsersResponse{Imported: 2, Rejected: []importRejection{}},
+		},
+		"ndjson": {
+			contentType: ndjsonMediaType,
+			body: `{"name":"john","email":"john@mail.com","password":"password123"}` + "\n" +
+				"\n" +
+				`{"name":"jane","email":"jane@mail.com","password":"password456"}` + "\n",
+			wantRows: []models.UserImportRow{
+				{Line: 1, User: models.User{Name: "john", Email: "john@mail.com", Password: "password123"}},
+				{Line: 3, User: models.User{Name: "jane", Email: "jane@mail.com", Password: "password456"}},
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   importUsersResponse{Imported: 2, Rejected: []importRejection{}},
+		},
+		"dry run": {
+			contentType: "text/csv",
+			query:       "?dryRun=true",
+			body:        "name,email,password\njohn,john@mail.com,password123\n",
+			wantRows: []models.UserImportRow{
+				{Line: 2, User: models.User{Name: "john", Email: "john@mail.com", Password: "password123"}},
+			},
+			wantDryRun: true,
+			wantStatus: http.StatusOK,
+			wantBody:   importUsersResponse{DryRun: true, Imported: 1, Rejected: []importRejection{}},
+		},
+		"invalid and duplicate lines are rejected": {
+			contentType: ndjsonMediaType,
+			body: `{"name":"john","email":"john@mail.com","password":"password123"}` + "\n" +
+				`{"name":"j","email":"jane@mail.com","password":"password456"}` + "\n" +
+				`not json` + "\n" +
+				`{"name":"jim","email":"john@mail.com","password":"password789"}` + "\n",
+			duplicates: []int{4},
+			wantRows: []models.UserImportRow{
+				{Line: 1, User: models.User{Name: "john", Email: "john@mail.com", Password: "password123"}},
+				{Line: 4, User: models.User{Name: "jim", Email: "john@mail.com", Password: "password789"}},
+			},
+			wantStatus: http.StatusOK,
+			wantBody: importUsersResponse{
+				Imported: 1,
+				Rejected: []importRejection{
+					{Line: 2, Problems: []validationProblem{{
+						Field:   "name",
+						Code:    "min",
+						Message: "name must be at least 2 characters in length",
+					}}},
+					{Line: 3, Problems: []validationProblem{{
+						Field:   "line",
+						Code:    "invalid_json",
+						Message: "invalid character 'o' in literal null (expecting 'u')",
+					}}},
+					{Line: 4, Problems: []validationProblem{{
+						Field:   "email",
+						Code:    "unique",
+						Message: "email is already taken",
+					}}},
+				},
+			},
+		},
+		"csv line with the wrong number of fields": {
+			contentType: "text/csv",
+			body:        "name,email,password\njohn,john@mail.com\n",
+			wantStatus:  http.StatusOK,
+			wantBody: importUsersResponse{
+				Rejected: []importRejection{
+					{Line: 2, Problems: []validationProblem{{
+						Field:   "line",
+						Code:    "invalid_csv",
+						Message: "wrong number of fields",
+					}}},
+				},
+			},
+		},
+		"csv header missing a column": {
+			contentType:  "text/csv",
+			body:         "name,email,role\njohn,john@mail.com,admin\n",
+			wantStatus:   http.StatusBadRequest,
+			wantProblems: []string{"header", "header"},
+		},
+		"empty csv": {
+			contentType:  "text/csv",
+			wantStatus:   http.StatusBadRequest,
+			wantProblems: []string{"header"},
+		},
+		"invalid dry run": {
+			contentType:  ndjsonMediaType,
+			query:        "?dryRun=maybe",
+			wantStatus:   http.StatusBadRequest,
+			wantProblems: []string{"dryRun"},
+		},
+		"unsupported content type": {
+			contentType: "application/json",
+			body:        `[]`,
+			wantStatus:  http.StatusUnsupportedMediaType,
+		},
+		"import fails": {
+			contentType: ndjsonMediaType,
+			body:        `{"name":"john","email":"john@mail.com","password":"password123"}` + "\n",
+			mockErr:     errors.New("connection reset"),
+			wantRows: []models.UserImportRow{
+				{Line: 1, User: models.User{Name: "john", Email: "john@mail.com", Password: "password123"}},
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+		"line too long": {
+			contentType: ndjsonMediaType,
+			body:        `{"name":"` + strings.Repeat("j", maxImportLineSize) + `"}` + "\n",
+			wantStatus:  http.StatusBadRequest,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(
+			name, func(t *testing.T) {
+				// Create a new request
+				req := httptest.NewRequest(http.MethodPost, "/user/import"+tc.query, strings.NewReader(tc.body))
+				req.Header.Set("Content-Type", tc.contentType)
+
+				// Create a new response recorder
+				rec := httptest.NewRecorder()
+
+				// Create a new ctxhandler
+				logger := slog.Default()
+
+				mockedUsersImporter := &moqusersImporter{
+					ImportUsersFunc: func(
+						_ context.Context,
+						rows iter.Seq2[models.UserImportRow, error],
+						dryRun bool,
+					) (models.UserImportResult, error) {
+						assert.Equal(t, tc.wantDryRun, dryRun)
+
+						var gotRows []models.UserImportRow
+						for row, err := range rows {
+							if err != nil {
+								return models.UserImportResult{}, err
+							}
+							gotRows = append(gotRows, row)
+						}
+						assert.Equal(t, tc.wantRows, gotRows)
+
+						return models.UserImportResult{
+							Imported:       len(gotRows) - len(tc.duplicates),
+							DuplicateLines: tc.duplicates,
+						}, tc.mockErr
+					},
+				}
+
+				// Call the handler
+				handler := HandleImportUsers(logger, newTestValidator(t), mockedUsersImporter)
+
+				handler.ServeHTTP(rec, req)
+				// Check the status code
+				assert.Equal(t, tc.wantStatus, rec.Code)
+
+				// Check the body
+				switch {
+				case tc.wantStatus == http.StatusOK:
+					var respBody importUsersResponse
+					_ = json.Unmarshal(rec.Body.Bytes(), &respBody)
+
+					assert.Equal(t, tc.wantBody, respBody)
+				case len(tc.wantProblems) > 0:
+					var respBody ProblemDetailValidation
+					_ = json.Unmarshal(rec.Body.Bytes(), &respBody)
+
+					fields := make([]string, 0, len(respBody.InvalidParams))
+					for _, problem := range respBody.InvalidParams {
+						fields = append(fields, problem.Field)
+					}
+					assert.Equal(t, tc.wantProblems, fields)
+					assert.Empty(t, mockedUsersImporter.ImportUsersCalls())
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"go.opentelemetry.io/otel/codes"
+
+	"example.com/examples/api/layered/internal/middleware"
+	"example.com/examples/api/layered/internal/models"
+)
+
+// blogCommentsLister represents a type capable of listing a page of the
+// comments on a blog and returning them or an error.
+type blogCommentsLister interface {
+	ListBlogComments(
+		ctx context.Context,
+		blogID uint64,
+		limit int,
+		offset int,
+	) ([]models.Comment, error)
+}
+
+// listCommentsResponse represents the response for listing comments.
+type listCommentsResponse struct {
+	Comments []CommentResponse
+	Limit    int `json:"limit"  xml:"limit"`
+	Offset   int `json:"offset" xml:"offset"`
+}
+
+// HandleListBlogComments handles the listing of the comments on a blog,
+// oldest first.
+//
+//	@Summary		List Blog Comments
+//	@Description	List the Comments on a Blog, oldest first
+//	@Tags			comment
+//	@Accept			json
+//	@Produce		json
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
+//	@Param			id		path		string	true	"Blog ID"
+//	@Param			limit	query		int		false	"Page size (1-100, default 20)"
+//	@Param			offset	query		int		false	"Number of comments to skip"
+//	@Success		200		{object}	listCommentsResponse
+//	@Failure		400		{object}	ProblemDetailValidation
+//	@Failure		404		{object}	ProblemDetail
+//	@Failure		406		{object}	ProblemDetail
+//	@Failure		429		{object}	ProblemDetail
+//	@Failure		500		{object}	ProblemDetail
+//	@Router			/blog/{id}/comments  [GET]
+func HandleListBlogComments(
+	logger *slog.Logger,
+	blogCommentsLister blogCommentsLister,
+) http.HandlerFunc {
+	const name = "handlers.HandleListBlogComments"
+	logger = logger.With(slog.String("func", name))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracer.Start(r.Context(), name)
+		defer span.End()
+
+		// Read blog id from path parameters
+		idStr := r.PathValue("id")
+
+		// Convert the ID from string to int
+		blogID, err := strconv.Atoi(idStr)
+		if err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to parse id from url",
+				slog.String("id", idStr),
+				slog.String("error", err.Error()),
+			)
+			span.SetStatus(codes.Error, "ID conversion failed")
+			span.RecordError(err)
+
+			_ = encodeResponse(w, r, http.StatusBadRequest, ProblemDetail{
+				Title:   "Invalid ID",
+				Status:  http.StatusBadRequest,
+				Detail:  "The provided ID is not a valid integer.",
+				TraceID: middleware.GetTraceID(ctx),
+			})
+
+			return
+		}
+
+		// Read the page from query parameters
+		limit, offset, problems := parsePagination(r)
+		if len(problems) > 0 {
+			validationError := "validation failed"
+			logger.ErrorContext(
+				ctx,
+				validationError,
+				slog.Any("validation_errors", problems),
+			)
+			span.SetStatus(codes.Error, validationError)
+			span.RecordError(errors.New(validationError))
+
+			_ = encodeResponse(w, r, http.StatusBadRequest, NewValidationBadRequest(ctx, problems))
+
+			return
+		}
+
+		// List the comments
+		comments, err := blogCommentsLister.ListBlogComments(ctx, uint64(blogID), limit, offset)
+		if err != nil {
+			encodeError(ctx, w, r, logger, "failed to list blog comments", err)
+
+			return
+		}
+
+		// Encode the response model as JSON
+		_ = encodeResponse(w, r, http.StatusOK, newListCommentsResponse(comments, limit, offset))
+	}
+}
+
+// newListCommentsResponse converts our models.Comment domain models into a
+// listCommentsResponse.
+func newListCommentsResponse(
+	comments []models.Comment,
+	limit int,
+	offset int,
+) listCommentsResponse {
+	response := listCommentsResponse{
+		Comments: make([]CommentResponse, 0, len(comments)),
+		Limit:    limit,
+		Offset:   offset,
+	}
+
+	for _, comment := range comments {
+		response.Comments = append(response.Comments, CommentResponse{
+			ID:        comment.ID,
+			UserID:    comment.UserID,
+			BlogID:    comment.BlogID,
+			Message:   comment.Message,
+			CreatedAt: comment.CreatedAt,
+		})
+	}
+
+	return response
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/services"
+)
+
+func TestHandleListBlogComments(t *testing.T) {
+	createdAt := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		id           string
+		query        string
+		mockComments []models.Comment
+		mockErr      error
+		wantLimit    int
+		wantOffset   int
+		wantStatus   int
+		wantBody     listCommentsResponse
+	}{
+		"happy path": {
+			id:    "1",
+			query: "?limit=2&offset=4",
+			mockComments: []models.Comment{
+				{ID: 8, UserID: 1, BlogID: 1, Message: "Great post!", CreatedAt: createdAt},
+			},
+			wantLimit:  2,
+			wantOffset: 4,
+			wantStatus: http.StatusOK,
+			wantBody: listCommentsResponse{
+				Comments: []CommentResponse{
+					{ID: 8, UserID: 1, BlogID: 1, Message: "Great post!", CreatedAt: createdAt},
+				},
+				Limit:  2,
+				Offset: 4,
+			},
+		},
+		"default page": {
+			id:         "1",
+			wantLimit:  defaultPageLimit,
+			wantOffset: 0,
+			wantStatus: http.StatusOK,
+			wantBody: listCommentsResponse{
+				Comments: []CommentResponse{},
+				Limit:    defaultPageLimit,
+				Offset:   0,
+			},
+		},
+		"invalid id": {
+			id:         "abc",
+			wantStatus: http.StatusBadRequest,
+		},
+		"invalid limit": {
+			id:         "1",
+			query:      "?limit=0",
+			wantStatus: http.StatusBadRequest,
+		},
+		"blog not found": {
+			id:         "99",
+			wantLimit:  defaultPageLimit,
+			mockErr:    fmt.Errorf("blog 99: %w", services.ErrBlogNotFound),
+			wantStatus: http.StatusNotFound,
+		},
+		"service error": {
+			id:         "1",
+			wantLimit:  defaultPageLimit,
+			mockErr:    errors.New("db down"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(
+			name, func(t *testing.T) {
+				// Create a new request
+				req := httptest.NewRequest(http.MethodGet, "/blog/"+tc.id+"/comments"+tc.query, nil)
+				req.SetPathValue("id", tc.id)
+
+				// Create a new response recorder
+				rec := httptest.NewRecorder()
+
+				// Create a new ctxhandler
+				logger := slog.Default()
+
+				mockedBlogCommentsLister := &moqblogCommentsLister{
+					ListBlogCommentsFunc: func(
+						_ context.Context,
+						_ uint64,
+						limit int,
+						offset int,
+					) ([]models.Comment, error) {
+						assert.Equal(t, tc.wantLimit, limit)
+						assert.Equal(t, tc.wantOffset, offset)
+
+						return tc.mockComments, tc.mockErr
+					},
+				}
+
+				// Call the handler
+				handler := HandleListBlogComments(logger, mockedBlogCommentsLister)
+
+				handler.ServeHTTP(rec, req)
+				// Check the status code
+				assert.Equal(t, tc.wantStatus, rec.Code)
+
+				// Check the body
+				if tc.wantStatus == http.StatusOK {
+					var resp listCommentsResponse
+					_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+
+					assert.Equal(t, tc.wantBody, resp)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"go.opentelemetry.io/otel/codes"
+
+	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/query"
+	"example.com/examples/api/layered/internal/services"
+)
+
+// blogsLister represents a type capable of listing filtered, sorted blogs from
+// storage and returning them or an error.
+type blogsLister interface {
+	ListBlogs(ctx context.Context, list query.List) ([]models.Blog, error)
+}
+
+// listBlogsResponse represents the response for listing blogs.
+type listBlogsResponse struct {
+	Blogs []BlogResponse
+}
+
+// HandleListBlogs handles the listing of blogs. Blogs can be filtered and
+// sorted on id, author_id, title, score and created_at.
+//
+//	@Summary		List Blogs
+//	@Description	List Blogs, optionally filtered and sorted
+//	@Tags			blog
+//	@Accept			json
+//	@Produce		json
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
+//	@Param			filter	query		string	false	"Filter expression, e.g. score ge 5 and author_id eq 1"
+//	@Param			sort	query		string	false	"Comma separated fields, - for descending, e.g. -score"
+//	@Success		200		{object}	listBlogsResponse
+//	@Failure		400		{object}	ProblemDetailValidation
+//	@Failure		406		{object}	ProblemDetail
+//	@Failure		429		{object}	ProblemDetail
+//	@Failure		500		{object}	ProblemDetail
+//	@Router			/blog  [GET]
+func HandleListBlogs(logger *slog.Logger, blogsLister blogsLister) http.HandlerFunc {
+	const name = "handlers.HandleListBlogs"
+	logger = logger.With(slog.String("func", name))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracer.Start(r.Context(), name)
+		defer span.End()
+
+		// Read the filter and sort from query parameters
+		list, problems := parseListQuery(r, services.BlogsListSchema)
+		if len(problems) > 0 {
+			validationError := "validation failed"
+			logger.ErrorContext(
+				ctx,
+				validationError,
+				slog.Any("validation_errors", problems),
+			)
+			span.SetStatus(codes.Error, validationError)
+			span.RecordError(errors.New(validationError))
+
+			_ = encodeResponse(w, r, http.StatusBadRequest, NewValidationBadRequest(ctx, problems))
+
+			return
+		}
+
+		// List the blogs
+		blogs, err := blogsLister.ListBlogs(ctx, list)
+		if err != nil {
+			encodeError(ctx, w, r, logger, "failed to list blogs", err)
+
+			return
+		}
+
+		// Convert our models.Blog domain models into response models.
+		response := listBlogsResponse{
+			Blogs: make([]BlogResponse, 0, len(blogs)),
+		}
+
+		for _, blog := range blogs {
+			response.Blogs = append(response.Blogs, BlogResponse{
+				ID:       blog.ID,
+				AuthorID: blog.AuthorID,
+				Title:    blog.Title,
+				Score:    blog.Score,
+			})
+		}
+
+		// Encode the response model as JSON
+		_ = encodeResponse(w, r, http.StatusOK, response)
+	}
+}
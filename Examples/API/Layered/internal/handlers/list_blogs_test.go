@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/query"
+)
+
+func TestHandleListBlogs(t *testing.T) {
+	tests := map[string]struct {
+		query        string
+		mockBlogs    []models.Blog
+		mockErr      error
+		wantSort     string
+		wantStatus   int
+		wantBody     listBlogsResponse
+		wantProblems []validationProblem
+	}{
+		"happy path": {
+			mockBlogs: []models.Blog{
+				{ID: 1, AuthorID: 1, Title: "First Blog Post", Score: 8.5},
+				{ID: 2, AuthorID: 2, Title: "Travel Adventures", Score: 7.2},
+			},
+			wantSort:   "id",
+			wantStatus: http.StatusOK,
+			wantBody: listBlogsResponse{
+				Blogs: []BlogResponse{
+					{ID: 1, AuthorID: 1, Title: "First Blog Post", Score: 8.5},
+					{ID: 2, AuthorID: 2, Title: "Travel Adventures", Score: 7.2},
+				},
+			},
+		},
+		"filtered and sorted": {
+			query:      `?filter=score+ge+8&sort=-score`,
+			mockBlogs:  []models.Blog{{ID: 1, AuthorID: 1, Title: "First Blog Post", Score: 8.5}},
+			wantSort:   "-score,id",
+			wantStatus: http.StatusOK,
+			wantBody: listBlogsResponse{
+				Blogs: []BlogResponse{{ID: 1, AuthorID: 1, Title: "First Blog Post", Score: 8.5}},
+			},
+		},
+		"no blogs": {
+			mockBlogs:  nil,
+			wantSort:   "id",
+			wantStatus: http.StatusOK,
+			wantBody:   listBlogsResponse{Blogs: []BlogResponse{}},
+		},
+		"invalid filter and sort": {
+			query:      `?filter=score+gt+high&sort=author`,
+			wantStatus: http.StatusBadRequest,
+			wantProblems: []validationProblem{
+				{Field: "filter", Code: "invalid_value", Message: `expected a number, got "high"`, Position: 10},
+				{Field: "sort", Code: "unknown_field", Message: `unknown field "author"`, Position: 1},
+			},
+		},
+		"service error": {
+			mockErr:    errors.New("db down"),
+			wantSort:   "id",
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(
+			name, func(t *testing.T) {
+				// Create a new request
+				req := httptest.NewRequest(http.MethodGet, "/blog"+tc.query, nil)
+
+				// Create a new response recorder
+				rec := httptest.NewRecorder()
+
+				// Create a new ctxhandler
+				logger := slog.Default()
+
+				mockedBlogsLister := &moqblogsLister{
+					ListBlogsFunc: func(_ context.Context, list query.List) ([]models.Blog, error) {
+						assert.Equal(t, tc.wantSort, query.FormatSort(list.Sort))
+
+						return tc.mockBlogs, tc.mockErr
+					},
+				}
+
+				// Call the handler
+				handler := HandleListBlogs(logger, mockedBlogsLister)
+
+				handler.ServeHTTP(rec, req)
+				// Check the status code
+				assert.Equal(t, tc.wantStatus, rec.Code)
+
+				// Check the body
+				switch tc.wantStatus {
+				case http.StatusOK:
+					var resp listBlogsResponse
+					_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+
+					assert.Equal(t, tc.wantBody, resp)
+				case http.StatusBadRequest:
+					var resp ProblemDetailValidation
+					_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+
+					assert.Equal(t, tc.wantProblems, resp.InvalidParams)
+					assert.Empty(t, mockedBlogsLister.ListBlogsCalls())
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"html/template"
+	"log/slog"
+	"net/http"
+
+	"go.opentelemetry.io/otel/codes"
+
+	"example.com/examples/api/layered/internal/problemtype"
+)
+
+// problemTypesTemplate renders the index of every problem type.
+var problemTypesTemplate = template.Must(template.New("problem_types").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Problem Types</title>
+</head>
+<body>
+<h1>Problem Types</h1>
+<p>Errors are returned as problem details (RFC 9457), as <code>application/problem+json</code> by default.
+The <code>type</code> member names one of the types below and <code>instance</code> is the path of the
+request. Every problem also carries <code>{{.TraceID.Name}}</code>: {{.TraceID.Description}}</p>
+<table>
+<thead><tr><th>Status</th><th>Type</th><th>Title</th><th>Description</th></tr></thead>
+<tbody>
+{{- range .Types}}
+<tr>
+<td>{{.Status}}</td><td><a href="{{.URI}}"><code>{{.Slug}}</code></a></td>
+<td>{{.Title}}</td><td>{{.Description}}</td>
+</tr>
+{{- end}}
+</tbody>
+</table>
+</body>
+</html>
+`))
+
+// HandleListProblemTypes handles the index of the documentation of every
+// problem type.
+//
+//	@Summary		List Problem Types
+//	@Description	Human-readable index of every problem type
+//	@Tags			problem
+//	@Produce		html
+//	@Success		200	{string}	string
+//	@Failure		429	{object}	ProblemDetail
+//	@Router			/problems/  [GET]
+func HandleListProblemTypes(logger *slog.Logger) http.HandlerFunc {
+	const name = "handlers.HandleListProblemTypes"
+	logger = logger.With(slog.String("func", name))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracer.Start(r.Context(), name)
+		defer span.End()
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		err := problemTypesTemplate.Execute(w, struct {
+			TraceID problemtype.Extension
+			Types   []problemtype.Type
+		}{
+			TraceID: problemtype.TraceID,
+			Types:   problemtype.All(),
+		})
+		if err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to render problem types",
+				slog.String("error", err.Error()),
+			)
+			span.SetStatus(codes.Error, "render failed")
+			span.RecordError(err)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/examples/api/layered/internal/problemtype"
+)
+
+func TestHandleListProblemTypes(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/problems/", nil)
+	rec := httptest.NewRecorder()
+
+	HandleListProblemTypes(slog.Default()).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+	for _, pt := range problemtype.All() {
+		assert.Contains(t, rec.Body.String(), `href="`+pt.URI()+`"`)
+	}
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"example.com/examples/api/layered/internal/query"
+)
+
+// listCursor represents the position encoded in a list cursor: the sort it
+// was issued for and the sort values of the last row of the page.
+type listCursor struct {
+	Sort  string `json:"sort"`
+	After []any  `json:"after"`
+}
+
+// parseListQuery reads the filter and sort query parameters from the provided
+// request and parses them against the provided schema. Any problems are
+// returned as validation problems pointing at the offending token.
+func parseListQuery[T any](r *http.Request, schema query.Schema[T]) (query.List, []validationProblem) {
+	var (
+		list     query.List
+		problems []validationProblem
+		err      error
+	)
+
+	list.Filter, err = schema.ParseFilter(r.URL.Query().Get("filter"))
+	problems = append(problems, queryProblems(err)...)
+
+	list.Sort, err = schema.ParseSort(r.URL.Query().Get("sort"))
+	problems = append(problems, queryProblems(err)...)
+
+	return list, problems
+}
+
+// parseListPage reads the filter, sort, limit and cursor query parameters
+// from the provided request. A cursor is only accepted with the sort it was
+// issued for. Any problems are returned as validation problems.
+func parseListPage[T any](r *http.Request, schema query.Schema[T]) (query.List, []validationProblem) {
+	list, problems := parseListQuery(r, schema)
+
+	limit, cursor, pageProblems := parseCursorPagination[listCursor](r)
+	problems = append(problems, pageProblems...)
+	list.Limit = limit
+
+	if len(problems) > 0 || cursor.After == nil {
+		return list, problems
+	}
+
+	if cursor.Sort != query.FormatSort(list.Sort) {
+		return list, []validationProblem{{
+			Field:   "cursor",
+			Code:    "sort_mismatch",
+			Message: "cursor was issued for a different sort",
+		}}
+	}
+
+	after, err := schema.After(list.Sort, cursor.After)
+	if err != nil {
+		return list, []validationProblem{{
+			Field:   "cursor",
+			Code:    "invalid",
+			Message: "cursor must be a value returned as next by a previous request",
+		}}
+	}
+	list.After = after
+
+	return list, nil
+}
+
+// encodeNextCursor encodes a cursor that resumes the provided list after the
+// provided row.
+func encodeNextCursor[T any](schema query.Schema[T], list query.List, row T) (string, error) {
+	after, err := schema.Cursor(row, list.Sort)
+	if err != nil {
+		return "", fmt.Errorf("[in handlers.encodeNextCursor] failed to read cursor values: %w", err)
+	}
+
+	return encodeCursor(listCursor{Sort: query.FormatSort(list.Sort), After: after})
+}
+
+// queryProblems converts the errors returned when parsing a filter or sort
+// into validation problems.
+func queryProblems(err error) []validationProblem {
+	var errs query.Errors
+	if !errors.As(err, &errs) {
+		return nil
+	}
+
+	problems := make([]validationProblem, len(errs))
+	for i, e := range errs {
+		problems[i] = validationProblem{
+			Field:    e.Param,
+			Code:     e.Code,
+			Message:  e.Message,
+			Position: e.Position,
+		}
+	}
+
+	return problems
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"go.opentelemetry.io/otel/codes"
+
+	"example.com/examples/api/layered/internal/middleware"
+	"example.com/examples/api/layered/internal/models"
+)
+
+// userCommentsLister represents a type capable of listing a page of the
+// comments written by a user and returning them or an error.
+type userCommentsLister interface {
+	ListUserComments(
+		ctx context.Context,
+		userID uint64,
+		limit int,
+		offset int,
+	) ([]models.Comment, error)
+}
+
+// HandleListUserComments handles the listing of the comments written by a
+// user, oldest first.
+//
+//	@Summary		List User Comments
+//	@Description	List the Comments written by a User, oldest first
+//	@Tags			comment
+//	@Accept			json
+//	@Produce		json
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
+//	@Param			id		path		string	true	"User ID"
+//	@Param			limit	query		int		false	"Page size (1-100, default 20)"
+//	@Param			offset	query		int		false	"Number of comments to skip"
+//	@Success		200		{object}	listCommentsResponse
+//	@Failure		400		{object}	ProblemDetailValidation
+//	@Failure		401		{object}	ProblemDetail
+//	@Failure		404		{object}	ProblemDetail
+//	@Failure		406		{object}	ProblemDetail
+//	@Failure		429		{object}	ProblemDetail
+//	@Failure		500		{object}	ProblemDetail
+//	@Security		BearerAuth
+//	@Router			/user/{id}/comments  [GET]
+func HandleListUserComments(
+	logger *slog.Logger,
+	userCommentsLister userCommentsLister,
+) http.HandlerFunc {
+	const name = "handlers.HandleListUserComments"
+	logger = logger.With(slog.String("func", name))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracer.Start(r.Context(), name)
+		defer span.End()
+
+		// Read user id from path parameters
+		idStr := r.PathValue("id")
+
+		// Convert the ID from string to int
+		userID, err := strconv.Atoi(idStr)
+		if err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to parse id from url",
+				slog.String("id", idStr),
+				slog.String("error", err.Error()),
+			)
+			span.SetStatus(codes.Error, "ID conversion failed")
+			span.RecordError(err)
+
+			_ = encodeResponse(w, r, http.StatusBadRequest, ProblemDetail{
+				Title:   "Invalid ID",
+				Status:  http.StatusBadRequest,
+				Detail:  "The provided ID is not a valid integer.",
+				TraceID: middleware.GetTraceID(ctx),
+			})
+
+			return
+		}
+
+		// Read the page from query parameters
+		limit, offset, problems := parsePagination(r)
+		if len(problems) > 0 {
+			validationError := "validation failed"
+			logger.ErrorContext(
+				ctx,
+				validationError,
+				slog.Any("validation_errors", problems),
+			)
+			span.SetStatus(codes.Error, validationError)
+			span.RecordError(errors.New(validationError))
+
+			_ = encodeResponse(w, r, http.StatusBadRequest, NewValidationBadRequest(ctx, problems))
+
+			return
+		}
+
+		// List the comments
+		comments, err := userCommentsLister.ListUserComments(ctx, uint64(userID), limit, offset)
+		if err != nil {
+			encodeError(ctx, w, r, logger, "failed to list user comments", err)
+
+			return
+		}
+
+		// Encode the response model as JSON
+		_ = encodeResponse(w, r, http.StatusOK, newListCommentsResponse(comments, limit, offset))
+	}
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/services"
+)
+
+func TestHandleListUserComments(t *testing.T) {
+	createdAt := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		id           string
+		mockComments []models.Comment
+		mockErr      error
+		wantStatus   int
+		wantBody     listCommentsResponse
+	}{
+		"happy path": {
+			id: "2",
+			mockComments: []models.Comment{
+				{ID: 2, UserID: 2, BlogID: 11, Message: "I agree.", CreatedAt: createdAt},
+			},
+			wantStatus: http.StatusOK,
+			wantBody: listCommentsResponse{
+				Comments: []CommentResponse{
+					{ID: 2, UserID: 2, BlogID: 11, Message: "I agree.", CreatedAt: createdAt},
+				},
+				Limit:  defaultPageLimit,
+				Offset: 0,
+			},
+		},
+		"invalid id": {
+			id:         "abc",
+			wantStatus: http.StatusBadRequest,
+		},
+		"user not found": {
+			id:         "99",
+			mockErr:    fmt.Errorf("user 99: %w", services.ErrUserNotFound),
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(
+			name, func(t *testing.T) {
+				// Create a new request
+				req := httptest.NewRequest(http.MethodGet, "/user/"+tc.id+"/comments", nil)
+				req.SetPathValue("id", tc.id)
+
+				// Create a new response recorder
+				rec := httptest.NewRecorder()
+
+				// Create a new ctxhandler
+				logger := slog.Default()
+
+				mockedUserCommentsLister := &moquserCommentsLister{
+					ListUserCommentsFunc: func(
+						_ context.Context,
+						_ uint64,
+						_ int,
+						_ int,
+					) ([]models.Comment, error) {
+						return tc.mockComments, tc.mockErr
+					},
+				}
+
+				// Call the handler
+				handler := HandleListUserComments(logger, mockedUserCommentsLister)
+
+				handler.ServeHTTP(rec, req)
+				// Check the status code
+				assert.Equal(t, tc.wantStatus, rec.Code)
+
+				// Check the body
+				if tc.wantStatus == http.StatusOK {
+					var resp listCommentsResponse
+					_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+
+					assert.Equal(t, tc.wantBody, resp)
+				}
+			},
+		)
+	}
+}
@@ -2,36 +2,61 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"go.opentelemetry.io/otel/codes"
 
 	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/query"
+	"example.com/examples/api/layered/internal/services"
 )
 
-// userReader represents a type capable of reading a user from storage and
-// returning it or an error.
+// usersLister represents a type capable of listing a filtered, sorted page of
+// users from storage and returning them or an error.
 type usersLister interface {
-	ListUsers(ctx context.Context) ([]models.User, error)
+	ListUsers(ctx context.Context, list query.List) ([]models.User, error)
 }
 
 // listUsersResponse represents the response for listing users.
 type listUsersResponse struct {
 	Users []UserResponse
+	Limit int    `json:"limit"          xml:"limit"`
+	Next  string `json:"next,omitempty" xml:"next,omitempty"` // Cursor for the next page; omitted on the last page.
 }
 
-// HandleListUsers handles the listing of all users.
+// HandleListUsers handles the listing of users, a page at a time. Users can be
+// filtered and sorted on id, name, email, role, created_at and updated_at. Each
+// page has a weak ETag derived from its content, and a conditional request
+// whose copy is still current is answered with 304 Not Modified. Pages have no
+// Last-Modified: a user deleted from a page, or users moving between pages,
+// would not change it.
 //
 //	@Summary		List Users
-//	@Description	List All Users
+//	@Description	List a filtered, sorted page of Users. Pass the returned next cursor to fetch the following page.
 //	@Tags			user
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{array}		models.User
-//	@Failure		400	{object}	string
-//	@Failure		404	{object}	string
-//	@Failure		500	{object}	string
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
+//	@Param			filter			query		string	false	"Filter expression, e.g. created_at gt 2024-01-01"
+//	@Param			sort			query		string	false	"Comma separated fields, - for descending, e.g. -created_at"
+//	@Param			limit			query		int		false	"Page size (1-100, default 20)"
+//	@Param			cursor			query		string	false	"Opaque cursor returned as next by the previous page"
+//	@Param			If-None-Match	header		string	false	"ETag of the page held by the client"
+//	@Success		200				{object}	listUsersResponse
+//	@Header			200				{string}	ETag	"Weak ETag of the page"
+//	@Success		304
+//	@Failure		400	{object}	ProblemDetailValidation
+//	@Failure		401	{object}	ProblemDetail
+//	@Failure		403	{object}	ProblemDetail
+//	@Failure		406	{object}	ProblemDetail
+//	@Failure		429	{object}	ProblemDetail
+//	@Failure		500	{object}	ProblemDetail
+//	@Security		BearerAuth
 //	@Router			/user  [GET]
 func HandleListUsers(logger *slog.Logger, usersLister usersLister) http.HandlerFunc {
 	const name = "handlers.HandleListUsers"
@@ -41,18 +66,36 @@ func HandleListUsers(logger *slog.Logger, usersLister usersLister) http.HandlerF
 		ctx, span := tracer.Start(r.Context(), name)
 		defer span.End()
 
-		// Read the user
-		users, err := usersLister.ListUsers(ctx)
-		if err != nil {
+		// Read the filter, sort and page from query parameters
+		list, problems := parseListPage(r, services.UsersListSchema)
+		if len(problems) > 0 {
+			validationError := "validation failed"
 			logger.ErrorContext(
 				ctx,
-				"failed to list users",
-				slog.String("error", err.Error()),
+				validationError,
+				slog.Any("validation_errors", problems),
 			)
-			span.SetStatus(codes.Error, "listing users failed")
-			span.RecordError(err)
+			span.SetStatus(codes.Error, validationError)
+			span.RecordError(errors.New(validationError))
 
-			_ = encodeResponseJSON(w, http.StatusInternalServerError, NewInternalServerError(ctx))
+			_ = encodeResponse(w, r, http.StatusBadRequest, NewValidationBadRequest(ctx, problems))
+
+			return
+		}
+
+		// Content negotiation, before the validators of the page are set
+		if !acceptable(w, r) {
+			return
+		}
+
+		// Read one user more than requested to find out whether there is a
+		// next page.
+		limit := list.Limit
+		list.Limit++
+
+		users, err := usersLister.ListUsers(ctx, list)
+		if err != nil {
+			encodeError(ctx, w, r, logger, "failed to list users", err)
 
 			return
 		}
@@ -60,18 +103,65 @@ func HandleListUsers(logger *slog.Logger, usersLister usersLister) http.HandlerF
 		// Convert our models.User domain model into a response model.
 		response := listUsersResponse{
 			Users: []UserResponse{},
+			Limit: limit,
+		}
+
+		if len(users) > limit {
+			users = users[:limit]
+
+			next, err := encodeNextCursor(services.UsersListSchema, list, users[limit-1])
+			if err != nil {
+				logger.ErrorContext(
+					ctx,
+					"failed to encode next cursor",
+					slog.String("error", err.Error()),
+				)
+				span.SetStatus(codes.Error, "encoding next cursor failed")
+				span.RecordError(err)
+
+				_ = encodeResponse(w, r, http.StatusInternalServerError, NewInternalServerError(ctx))
+
+				return
+			}
+			response.Next = next
 		}
 
 		for _, user := range users {
 			newUser := UserResponse{
-				ID:    user.ID,
-				Name:  user.Name,
-				Email: user.Email,
+				ID:        user.ID,
+				Name:      user.Name,
+				Email:     user.Email,
+				Role:      string(user.Role),
+				CreatedAt: user.CreatedAt,
+				UpdatedAt: user.UpdatedAt,
 			}
 			response.Users = append(response.Users, newUser)
 		}
 
+		etag, err := contentETag(response)
+		if err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to compute etag",
+				slog.String("error", err.Error()),
+			)
+			span.SetStatus(codes.Error, "computing etag failed")
+			span.RecordError(err)
+
+			_ = encodeResponse(w, r, http.StatusInternalServerError, NewInternalServerError(ctx))
+
+			return
+		}
+
+		// Answer from the validators alone if the client's copy is current
+		setValidators(w, etag, time.Time{})
+		if notModified(r, etag, time.Time{}) {
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
 		// Encode the response model as JSON
-		_ = encodeResponseJSON(w, http.StatusOK, response)
+		_ = encodeResponse(w, r, http.StatusOK, response)
 	}
 }
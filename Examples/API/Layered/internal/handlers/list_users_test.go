@@ -3,38 +3,107 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/query"
 )
 
 func TestHandleListUser(t *testing.T) {
+	byID := []query.Order{{Field: "id", Column: "id"}}
+	byName := []query.Order{{Field: "name", Column: "name"}, {Field: "id", Column: "id"}}
+	users := []models.User{
+		{ID: 1, Name: "john", Email: "john@mail.com", Role: models.RoleAdmin},
+		{ID: 2, Name: "jane", Email: "jane@mail.com", Role: models.RoleUser},
+		{ID: 3, Name: "jim", Email: "jim@mail.com", Role: models.RoleUser},
+	}
+	secondPage, _ := encodeCursor(listCursor{Sort: "id", After: []any{2}})
+	secondPageByName, _ := encodeCursor(listCursor{Sort: "name,id", After: []any{"jane", 2}})
+
 	tests := map[string]struct {
-		wantStatus int
-		wantBody   []models.User
+		query         string
+		listerUsers   []models.User
+		listerErr     error
+		wantList      query.List
+		wantStatus    int
+		wantIDs       []uint
+		wantNext      string
+		wantProblems  []string
+		wantNotCalled bool
 	}{
-		"happy path": {
-			wantStatus: 200,
-			wantBody: []models.User{
-				{
-					ID:       1,
-					Name:     "john",
-					Email:    "john@mail.com",
-					Password: "password123!",
-				},
-			},
+		"default page": {
+			query:       "",
+			listerUsers: users,
+			wantList:    query.List{Sort: byID, Limit: defaultPageLimit + 1},
+			wantStatus:  http.StatusOK,
+			wantIDs:     []uint{1, 2, 3},
+		},
+		"page with next cursor": {
+			query:       "?limit=2",
+			listerUsers: users,
+			wantList:    query.List{Sort: byID, Limit: 3},
+			wantStatus:  http.StatusOK,
+			wantIDs:     []uint{1, 2},
+			wantNext:    secondPage,
+		},
+		"page from cursor": {
+			query:       "?limit=2&cursor=" + secondPage,
+			listerUsers: users[2:],
+			wantList:    query.List{Sort: byID, After: []any{int64(2)}, Limit: 3},
+			wantStatus:  http.StatusOK,
+			wantIDs:     []uint{3},
+		},
+		"sorted page from cursor": {
+			query:       "?sort=name&limit=2&cursor=" + secondPageByName,
+			listerUsers: users[2:],
+			wantList:    query.List{Sort: byName, After: []any{"jane", int64(2)}, Limit: 3},
+			wantStatus:  http.StatusOK,
+			wantIDs:     []uint{3},
+		},
+		"invalid filter": {
+			query:         `?filter=name+co+smith`,
+			wantStatus:    http.StatusBadRequest,
+			wantProblems:  []string{"filter"},
+			wantNotCalled: true,
+		},
+		"cursor for another sort": {
+			query:         "?sort=-name&cursor=" + secondPageByName,
+			wantStatus:    http.StatusBadRequest,
+			wantProblems:  []string{"cursor"},
+			wantNotCalled: true,
+		},
+		"invalid limit": {
+			query:         "?limit=0",
+			wantStatus:    http.StatusBadRequest,
+			wantProblems:  []string{"limit"},
+			wantNotCalled: true,
+		},
+		"invalid cursor": {
+			query:         "?cursor=not-a-cursor",
+			wantStatus:    http.StatusBadRequest,
+			wantProblems:  []string{"cursor"},
+			wantNotCalled: true,
+		},
+		"lister error": {
+			query:      "",
+			listerErr:  errors.New("database down"),
+			wantList:   query.List{Sort: byID, Limit: defaultPageLimit + 1},
+			wantStatus: http.StatusInternalServerError,
 		},
 	}
 	for name, tc := range tests {
 		t.Run(
 			name, func(t *testing.T) {
 				// Create a new request
-				req := httptest.NewRequest(http.MethodGet, "/users", nil)
+				req := httptest.NewRequest(http.MethodGet, "/users"+tc.query, nil)
 
 				// Create a new response recorder
 				rec := httptest.NewRecorder()
@@ -43,8 +112,10 @@ func TestHandleListUser(t *testing.T) {
 				logger := slog.Default()
 
 				mockedUserLister := &moqusersLister{
-					ListUsersFunc: func(_ context.Context) ([]models.User, error) {
-						return tc.wantBody, nil
+					ListUsersFunc: func(_ context.Context, list query.List) ([]models.User, error) {
+						assert.Equal(t, tc.wantList, list)
+
+						return tc.listerUsers, tc.listerErr
 					},
 				}
 
@@ -55,18 +126,86 @@ func TestHandleListUser(t *testing.T) {
 				// Check the status code
 				assert.Equal(t, tc.wantStatus, rec.Code)
 
-				// Check the body
-				type usersResponse struct {
-					Users []models.User `json:"Users"`
+				if tc.wantNotCalled {
+					assert.Empty(t, mockedUserLister.ListUsersCalls())
 				}
 
-				// Check the body
-				var resp usersResponse
-				_ = json.Unmarshal(rec.Body.Bytes(), &resp)
-				respBody := resp.Users
+				switch tc.wantStatus {
+				case http.StatusOK:
+					var resp listUsersResponse
+					require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+
+					ids := make([]uint, 0, len(resp.Users))
+					for _, user := range resp.Users {
+						ids = append(ids, user.ID)
+					}
+					assert.Equal(t, tc.wantIDs, ids)
+					assert.Equal(t, tc.wantNext, resp.Next)
+				case http.StatusBadRequest:
+					var resp ProblemDetailValidation
+					require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
 
-				assert.ObjectsAreEqualValues(tc.wantBody, respBody)
+					fields := make([]string, 0, len(resp.InvalidParams))
+					for _, problem := range resp.InvalidParams {
+						fields = append(fields, problem.Field)
+					}
+					assert.ElementsMatch(t, tc.wantProblems, fields)
+				}
 			},
 		)
 	}
 }
+
+func TestHandleListUsersConditional(t *testing.T) {
+	updatedAt := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	users := []models.User{
+		{ID: 1, Name: "john", Email: "john@mail.com", Role: models.RoleAdmin, UpdatedAt: updatedAt},
+		{ID: 2, Name: "jane", Email: "jane@mail.com", Role: models.RoleUser, UpdatedAt: updatedAt.Add(-time.Hour)},
+	}
+
+	logger := slog.Default()
+	mockedUserLister := &moqusersLister{
+		ListUsersFunc: func(_ context.Context, _ query.List) ([]models.User, error) {
+			return users, nil
+		},
+	}
+	handler := HandleListUsers(logger, mockedUserLister)
+
+	get := func(header string, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/users", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	// The first response carries the validators of the page
+	rec := get("", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	etag := rec.Header().Get("ETag")
+	assert.Regexp(t, `^W/".+"$`, etag)
+	assert.Empty(t, rec.Header().Get("Last-Modified"))
+
+	// The ETag of an unchanged page yields a bodiless 304
+	rec = get("If-None-Match", etag)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	// A change to any user on the page changes its ETag
+	users[1].Name = "janet"
+	rec = get("If-None-Match", etag)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEqual(t, etag, rec.Header().Get("ETag"))
+	etag = rec.Header().Get("ETag")
+
+	// Deleting a user leaves the newest update on the page as it was, so
+	// If-Modified-Since is not honoured, while the ETag still changes
+	users = users[:1]
+	rec = get("If-Modified-Since", "Wed, 01 May 2024 12:30:00 GMT")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = get("If-None-Match", etag)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
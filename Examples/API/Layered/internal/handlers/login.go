@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"go.opentelemetry.io/otel/codes"
+
+	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/validation"
+)
+
+// loginer represents a type capable of checking an email and password and
+// issuing a token pair or returning an error.
+type loginer interface {
+	Login(ctx context.Context, email string, password string) (models.TokenPair, error)
+}
+
+// HandleLogin handles logging a user in with an email and password, issuing
+// signed access and refresh tokens.
+//
+//	@Summary		Login
+//	@Description	Exchange an email and password for access and refresh tokens
+//	@Tags			auth
+//	@Accept			json
+//	@Accept			xml
+//	@Accept			application/msgpack
+//	@Accept			application/cbor
+//	@Produce		json
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
+//	@Param			request	body		LoginRequest	true	"Credentials"
+//	@Success		200		{object}	TokenResponse
+//	@Failure		400		{object}	ProblemDetailValidation
+//	@Failure		401		{object}	ProblemDetail
+//	@Failure		406		{object}	ProblemDetail
+//	@Failure		415		{object}	ProblemDetail
+//	@Failure		429		{object}	ProblemDetail
+//	@Failure		500		{object}	ProblemDetail
+//	@Router			/auth/login  [POST]
+func HandleLogin(
+	logger *slog.Logger,
+	validate *validation.Validator,
+	loginer loginer,
+) http.HandlerFunc {
+	const name = "handlers.HandleLogin"
+	logger = logger.With(slog.String("func", name))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracer.Start(r.Context(), name)
+		defer span.End()
+
+		// Content negotiation
+		if !acceptable(w, r) || !decodable(w, r) {
+			return
+		}
+
+		// Request validation
+		request, problems, err := decodeValid[LoginRequest](r, validate)
+		if err != nil && len(problems) == 0 {
+			encodeDecodeError(ctx, w, r, logger, err)
+
+			return
+		}
+		if len(problems) > 0 {
+			validationError := "validation failed"
+			logger.ErrorContext(
+				ctx,
+				validationError,
+				slog.Any("validation_errors", problems),
+			)
+			span.SetStatus(codes.Error, validationError)
+			span.RecordError(errors.New(validationError))
+
+			_ = encodeResponse(w, r, http.StatusBadRequest, NewValidationBadRequest(ctx, problems))
+
+			return
+		}
+
+		// Log the user in
+		tokens, err := loginer.Login(ctx, request.Email, request.Password)
+		if err != nil {
+			encodeError(ctx, w, r, logger, "failed to log in", err)
+
+			return
+		}
+
+		_ = encodeResponse(w, r, http.StatusOK, newTokenResponse(tokens))
+	}
+}
+
+// newTokenResponse converts a models.TokenPair into a TokenResponse.
+func newTokenResponse(tokens models.TokenPair) TokenResponse {
+	return TokenResponse{
+		TokenType:             "Bearer",
+		AccessToken:           tokens.AccessToken,
+		AccessTokenExpiresAt:  tokens.AccessTokenExpiresAt,
+		RefreshToken:          tokens.RefreshToken,
+		RefreshTokenExpiresAt: tokens.RefreshTokenExpiresAt,
+	}
+}
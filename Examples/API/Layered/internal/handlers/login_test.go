@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/services"
+)
+
+func TestHandleLogin(t *testing.T) {
+	expiresAt := time.Date(2024, 5, 15, 12, 15, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		input      LoginRequest
+		mockCalled bool
+		mockTokens models.TokenPair
+		mockErr    error
+		wantStatus int
+		wantBody   TokenResponse
+	}{
+		"happy path": {
+			input:      LoginRequest{Email: "john@mail.com", Password: "password123!"},
+			mockCalled: true,
+			mockTokens: models.TokenPair{
+				AccessToken:           "access",
+				AccessTokenExpiresAt:  expiresAt,
+				RefreshToken:          "refresh",
+				RefreshTokenExpiresAt: expiresAt.Add(time.Hour),
+			},
+			wantStatus: http.StatusOK,
+			wantBody: TokenResponse{
+				TokenType:             "Bearer",
+				AccessToken:           "access",
+				AccessTokenExpiresAt:  expiresAt,
+				RefreshToken:          "refresh",
+				RefreshTokenExpiresAt: expiresAt.Add(time.Hour),
+			},
+		},
+		"invalid email": {
+			input:      LoginRequest{Email: "john", Password: "password123!"},
+			mockCalled: false,
+			wantStatus: http.StatusBadRequest,
+		},
+		"invalid credentials": {
+			input:      LoginRequest{Email: "john@mail.com", Password: "wrong"},
+			mockCalled: true,
+			mockErr:    fmt.Errorf("login: %w", services.ErrInvalidCredentials),
+			wantStatus: http.StatusUnauthorized,
+		},
+		"service error": {
+			input:      LoginRequest{Email: "john@mail.com", Password: "password123!"},
+			mockCalled: true,
+			mockErr:    errors.New("db down"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(
+			name, func(t *testing.T) {
+				// Create a new request
+				reqBody, _ := json.Marshal(tc.input)
+				req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewBuffer(reqBody))
+
+				// Create a new response recorder
+				rec := httptest.NewRecorder()
+
+				// Create a new ctxhandler
+				logger := slog.Default()
+
+				mockedLoginer := &moqloginer{
+					LoginFunc: func(_ context.Context, email string, password string) (models.TokenPair, error) {
+						assert.Equal(t, tc.input.Email, email)
+						assert.Equal(t, tc.input.Password, password)
+
+						return tc.mockTokens, tc.mockErr
+					},
+				}
+
+				// Call the handler
+				handler := HandleLogin(logger, newTestValidator(t), mockedLoginer)
+
+				handler.ServeHTTP(rec, req)
+				// Check the status code
+				assert.Equal(t, tc.wantStatus, rec.Code)
+				assert.Equal(t, tc.mockCalled, len(mockedLoginer.LoginCalls()) == 1)
+
+				// Check the body
+				if tc.wantStatus == http.StatusOK {
+					var respBody TokenResponse
+					_ = json.Unmarshal(rec.Body.Bytes(), &respBody)
+
+					assert.Equal(t, tc.wantBody, respBody)
+				}
+			},
+		)
+	}
+}
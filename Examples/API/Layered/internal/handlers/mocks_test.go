@@ -6,12 +6,158 @@ package handlers
 
 import (
 	"context"
+	"iter"
 	"sync"
 
 	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/query"
 	"example.com/examples/api/layered/internal/services"
 )
 
+// Ensure that moqblogCreator does implement blogCreator.
+// If this is not the case, regenerate this file with mockery.
+var _ blogCreator = &moqblogCreator{}
+
+// moqblogCreator is a mock implementation of blogCreator.
+//
+//	func TestSomethingThatUsesblogCreator(t *testing.T) {
+//
+//		// make and configure a mocked blogCreator
+//		mockedblogCreator := &moqblogCreator{
+//			CreateBlogFunc: func(ctx context.Context, blog models.Blog) (models.Blog, error) {
+//				panic("mock out the CreateBlog method")
+//			},
+//		}
+//
+//		// use mockedblogCreator in code that requires blogCreator
+//		// and then make assertions.
+//
+//	}
+type moqblogCreator struct {
+	// CreateBlogFunc mocks the CreateBlog method.
+	CreateBlogFunc func(ctx context.Context, blog models.Blog) (models.Blog, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// CreateBlog holds details about calls to the CreateBlog method.
+		CreateBlog []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Blog is the blog argument value.
+			Blog models.Blog
+		}
+	}
+	lockCreateBlog sync.RWMutex
+}
+
+// CreateBlog calls CreateBlogFunc.
+func (mock *moqblogCreator) CreateBlog(ctx context.Context, blog models.Blog) (models.Blog, error) {
+	if mock.CreateBlogFunc == nil {
+		panic("moqblogCreator.CreateBlogFunc: method is nil but blogCreator.CreateBlog was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Blog models.Blog
+	}{
+		Ctx:  ctx,
+		Blog: blog,
+	}
+	mock.lockCreateBlog.Lock()
+	mock.calls.CreateBlog = append(mock.calls.CreateBlog, callInfo)
+	mock.lockCreateBlog.Unlock()
+	return mock.CreateBlogFunc(ctx, blog)
+}
+
+// CreateBlogCalls gets all the calls that were made to CreateBlog.
+// Check the length with:
+//
+//	len(mockedblogCreator.CreateBlogCalls())
+func (mock *moqblogCreator) CreateBlogCalls() []struct {
+	Ctx  context.Context
+	Blog models.Blog
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Blog models.Blog
+	}
+	mock.lockCreateBlog.RLock()
+	calls = mock.calls.CreateBlog
+	mock.lockCreateBlog.RUnlock()
+	return calls
+}
+
+// Ensure that moqcommentCreator does implement commentCreator.
+// If this is not the case, regenerate this file with mockery.
+var _ commentCreator = &moqcommentCreator{}
+
+// moqcommentCreator is a mock implementation of commentCreator.
+//
+//	func TestSomethingThatUsescommentCreator(t *testing.T) {
+//
+//		// make and configure a mocked commentCreator
+//		mockedcommentCreator := &moqcommentCreator{
+//			CreateCommentFunc: func(ctx context.Context, comment models.Comment) (models.Comment, error) {
+//				panic("mock out the CreateComment method")
+//			},
+//		}
+//
+//		// use mockedcommentCreator in code that requires commentCreator
+//		// and then make assertions.
+//
+//	}
+type moqcommentCreator struct {
+	// CreateCommentFunc mocks the CreateComment method.
+	CreateCommentFunc func(ctx context.Context, comment models.Comment) (models.Comment, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// CreateComment holds details about calls to the CreateComment method.
+		CreateComment []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Comment is the comment argument value.
+			Comment models.Comment
+		}
+	}
+	lockCreateComment sync.RWMutex
+}
+
+// CreateComment calls CreateCommentFunc.
+func (mock *moqcommentCreator) CreateComment(ctx context.Context, comment models.Comment) (models.Comment, error) {
+	if mock.CreateCommentFunc == nil {
+		panic("moqcommentCreator.CreateCommentFunc: method is nil but commentCreator.CreateComment was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Comment models.Comment
+	}{
+		Ctx:     ctx,
+		Comment: comment,
+	}
+	mock.lockCreateComment.Lock()
+	mock.calls.CreateComment = append(mock.calls.CreateComment, callInfo)
+	mock.lockCreateComment.Unlock()
+	return mock.CreateCommentFunc(ctx, comment)
+}
+
+// CreateCommentCalls gets all the calls that were made to CreateComment.
+// Check the length with:
+//
+//	len(mockedcommentCreator.CreateCommentCalls())
+func (mock *moqcommentCreator) CreateCommentCalls() []struct {
+	Ctx     context.Context
+	Comment models.Comment
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Comment models.Comment
+	}
+	mock.lockCreateComment.RLock()
+	calls = mock.calls.CreateComment
+	mock.lockCreateComment.RUnlock()
+	return calls
+}
+
 // Ensure that moquserCreator does implement userCreator.
 // If this is not the case, regenerate this file with mockery.
 var _ userCreator = &moquserCreator{}
@@ -48,243 +194,1126 @@ type moquserCreator struct {
 	lockCreateUser sync.RWMutex
 }
 
-// CreateUser calls CreateUserFunc.
-func (mock *moquserCreator) CreateUser(ctx context.Context, user models.User) (models.User, error) {
-	if mock.CreateUserFunc == nil {
-		panic("moquserCreator.CreateUserFunc: method is nil but userCreator.CreateUser was just called")
+// CreateUser calls CreateUserFunc.
+func (mock *moquserCreator) CreateUser(ctx context.Context, user models.User) (models.User, error) {
+	if mock.CreateUserFunc == nil {
+		panic("moquserCreator.CreateUserFunc: method is nil but userCreator.CreateUser was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		User models.User
+	}{
+		Ctx:  ctx,
+		User: user,
+	}
+	mock.lockCreateUser.Lock()
+	mock.calls.CreateUser = append(mock.calls.CreateUser, callInfo)
+	mock.lockCreateUser.Unlock()
+	return mock.CreateUserFunc(ctx, user)
+}
+
+// CreateUserCalls gets all the calls that were made to CreateUser.
+// Check the length with:
+//
+//	len(mockeduserCreator.CreateUserCalls())
+func (mock *moquserCreator) CreateUserCalls() []struct {
+	Ctx  context.Context
+	User models.User
+} {
+	var calls []struct {
+		Ctx  context.Context
+		User models.User
+	}
+	mock.lockCreateUser.RLock()
+	calls = mock.calls.CreateUser
+	mock.lockCreateUser.RUnlock()
+	return calls
+}
+
+// Ensure that moqblogDeleter does implement blogDeleter.
+// If this is not the case, regenerate this file with mockery.
+var _ blogDeleter = &moqblogDeleter{}
+
+// moqblogDeleter is a mock implementation of blogDeleter.
+//
+//	func TestSomethingThatUsesblogDeleter(t *testing.T) {
+//
+//		// make and configure a mocked blogDeleter
+//		mockedblogDeleter := &moqblogDeleter{
+//			DeleteBlogFunc: func(ctx context.Context, id uint64) error {
+//				panic("mock out the DeleteBlog method")
+//			},
+//		}
+//
+//		// use mockedblogDeleter in code that requires blogDeleter
+//		// and then make assertions.
+//
+//	}
+type moqblogDeleter struct {
+	// DeleteBlogFunc mocks the DeleteBlog method.
+	DeleteBlogFunc func(ctx context.Context, id uint64) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// DeleteBlog holds details about calls to the DeleteBlog method.
+		DeleteBlog []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID uint64
+		}
+	}
+	lockDeleteBlog sync.RWMutex
+}
+
+// DeleteBlog calls DeleteBlogFunc.
+func (mock *moqblogDeleter) DeleteBlog(ctx context.Context, id uint64) error {
+	if mock.DeleteBlogFunc == nil {
+		panic("moqblogDeleter.DeleteBlogFunc: method is nil but blogDeleter.DeleteBlog was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  uint64
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockDeleteBlog.Lock()
+	mock.calls.DeleteBlog = append(mock.calls.DeleteBlog, callInfo)
+	mock.lockDeleteBlog.Unlock()
+	return mock.DeleteBlogFunc(ctx, id)
+}
+
+// DeleteBlogCalls gets all the calls that were made to DeleteBlog.
+// Check the length with:
+//
+//	len(mockedblogDeleter.DeleteBlogCalls())
+func (mock *moqblogDeleter) DeleteBlogCalls() []struct {
+	Ctx context.Context
+	ID  uint64
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  uint64
+	}
+	mock.lockDeleteBlog.RLock()
+	calls = mock.calls.DeleteBlog
+	mock.lockDeleteBlog.RUnlock()
+	return calls
+}
+
+// Ensure that moqcommentDeleter does implement commentDeleter.
+// If this is not the case, regenerate this file with mockery.
+var _ commentDeleter = &moqcommentDeleter{}
+
+// moqcommentDeleter is a mock implementation of commentDeleter.
+//
+//	func TestSomethingThatUsescommentDeleter(t *testing.T) {
+//
+//		// make and configure a mocked commentDeleter
+//		mockedcommentDeleter := &moqcommentDeleter{
+//			DeleteCommentFunc: func(ctx context.Context, blogID uint64, id uint64) error {
+//				panic("mock out the DeleteComment method")
+//			},
+//		}
+//
+//		// use mockedcommentDeleter in code that requires commentDeleter
+//		// and then make assertions.
+//
+//	}
+type moqcommentDeleter struct {
+	// DeleteCommentFunc mocks the DeleteComment method.
+	DeleteCommentFunc func(ctx context.Context, blogID uint64, id uint64) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// DeleteComment holds details about calls to the DeleteComment method.
+		DeleteComment []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// BlogID is the blogID argument value.
+			BlogID uint64
+			// ID is the id argument value.
+			ID uint64
+		}
+	}
+	lockDeleteComment sync.RWMutex
+}
+
+// DeleteComment calls DeleteCommentFunc.
+func (mock *moqcommentDeleter) DeleteComment(ctx context.Context, blogID uint64, id uint64) error {
+	if mock.DeleteCommentFunc == nil {
+		panic("moqcommentDeleter.DeleteCommentFunc: method is nil but commentDeleter.DeleteComment was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		BlogID uint64
+		ID     uint64
+	}{
+		Ctx:    ctx,
+		BlogID: blogID,
+		ID:     id,
+	}
+	mock.lockDeleteComment.Lock()
+	mock.calls.DeleteComment = append(mock.calls.DeleteComment, callInfo)
+	mock.lockDeleteComment.Unlock()
+	return mock.DeleteCommentFunc(ctx, blogID, id)
+}
+
+// DeleteCommentCalls gets all the calls that were made to DeleteComment.
+// Check the length with:
+//
+//	len(mockedcommentDeleter.DeleteCommentCalls())
+func (mock *moqcommentDeleter) DeleteCommentCalls() []struct {
+	Ctx    context.Context
+	BlogID uint64
+	ID     uint64
+} {
+	var calls []struct {
+		Ctx    context.Context
+		BlogID uint64
+		ID     uint64
+	}
+	mock.lockDeleteComment.RLock()
+	calls = mock.calls.DeleteComment
+	mock.lockDeleteComment.RUnlock()
+	return calls
+}
+
+// Ensure that moquserDeleter does implement userDeleter.
+// If this is not the case, regenerate this file with mockery.
+var _ userDeleter = &moquserDeleter{}
+
+// moquserDeleter is a mock implementation of userDeleter.
+//
+//	func TestSomethingThatUsesuserDeleter(t *testing.T) {
+//
+//		// make and configure a mocked userDeleter
+//		mockeduserDeleter := &moquserDeleter{
+//			DeleteUserFunc: func(ctx context.Context, id uint64, version uint64) error {
+//				panic("mock out the DeleteUser method")
+//			},
+//		}
+//
+//		// use mockeduserDeleter in code that requires userDeleter
+//		// and then make assertions.
+//
+//	}
+type moquserDeleter struct {
+	// DeleteUserFunc mocks the DeleteUser method.
+	DeleteUserFunc func(ctx context.Context, id uint64, version uint64) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// DeleteUser holds details about calls to the DeleteUser method.
+		DeleteUser []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID uint64
+			// Version is the version argument value.
+			Version uint64
+		}
+	}
+	lockDeleteUser sync.RWMutex
+}
+
+// DeleteUser calls DeleteUserFunc.
+func (mock *moquserDeleter) DeleteUser(ctx context.Context, id uint64, version uint64) error {
+	if mock.DeleteUserFunc == nil {
+		panic("moquserDeleter.DeleteUserFunc: method is nil but userDeleter.DeleteUser was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		ID      uint64
+		Version uint64
+	}{
+		Ctx:     ctx,
+		ID:      id,
+		Version: version,
+	}
+	mock.lockDeleteUser.Lock()
+	mock.calls.DeleteUser = append(mock.calls.DeleteUser, callInfo)
+	mock.lockDeleteUser.Unlock()
+	return mock.DeleteUserFunc(ctx, id, version)
+}
+
+// DeleteUserCalls gets all the calls that were made to DeleteUser.
+// Check the length with:
+//
+//	len(mockeduserDeleter.DeleteUserCalls())
+func (mock *moquserDeleter) DeleteUserCalls() []struct {
+	Ctx     context.Context
+	ID      uint64
+	Version uint64
+} {
+	var calls []struct {
+		Ctx     context.Context
+		ID      uint64
+		Version uint64
+	}
+	mock.lockDeleteUser.RLock()
+	calls = mock.calls.DeleteUser
+	mock.lockDeleteUser.RUnlock()
+	return calls
+}
+
+// Ensure that moqusersExporter does implement usersExporter.
+// If this is not the case, regenerate this file with mockery.
+var _ usersExporter = &moqusersExporter{}
+
+// moqusersExporter is a mock implementation of usersExporter.
+//
+//	func TestSomethingThatUsesusersExporter(t *testing.T) {
+//
+//		// make and configure a mocked usersExporter
+//		mockedusersExporter := &moqusersExporter{
+//			ExportUsersFunc: func(ctx context.Context) iter.Seq2[models.User, error] {
+//				panic("mock out the ExportUsers method")
+//			},
+//		}
+//
+//		// use mockedusersExporter in code that requires usersExporter
+//		// and then make assertions.
+//
+//	}
+type moqusersExporter struct {
+	// ExportUsersFunc mocks the ExportUsers method.
+	ExportUsersFunc func(ctx context.Context) iter.Seq2[models.User, error]
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// ExportUsers holds details about calls to the ExportUsers method.
+		ExportUsers []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+	}
+	lockExportUsers sync.RWMutex
+}
+
+// ExportUsers calls ExportUsersFunc.
+func (mock *moqusersExporter) ExportUsers(ctx context.Context) iter.Seq2[models.User, error] {
+	if mock.ExportUsersFunc == nil {
+		panic("moqusersExporter.ExportUsersFunc: method is nil but usersExporter.ExportUsers was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockExportUsers.Lock()
+	mock.calls.ExportUsers = append(mock.calls.ExportUsers, callInfo)
+	mock.lockExportUsers.Unlock()
+	return mock.ExportUsersFunc(ctx)
+}
+
+// ExportUsersCalls gets all the calls that were made to ExportUsers.
+// Check the length with:
+//
+//	len(mockedusersExporter.ExportUsersCalls())
+func (mock *moqusersExporter) ExportUsersCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockExportUsers.RLock()
+	calls = mock.calls.ExportUsers
+	mock.lockExportUsers.RUnlock()
+	return calls
+}
+
+// Ensure that moqexportEncoder does implement exportEncoder.
+// If this is not the case, regenerate this file with mockery.
+var _ exportEncoder = &moqexportEncoder{}
+
+// moqexportEncoder is a mock implementation of exportEncoder.
+//
+//	func TestSomethingThatUsesexportEncoder(t *testing.T) {
+//
+//		// make and configure a mocked exportEncoder
+//		mockedexportEncoder := &moqexportEncoder{
+//			EncodeFunc: func(user UserResponse) error {
+//				panic("mock out the Encode method")
+//			},
+//			FlushFunc: func() error {
+//				panic("mock out the Flush method")
+//			},
+//		}
+//
+//		// use mockedexportEncoder in code that requires exportEncoder
+//		// and then make assertions.
+//
+//	}
+type moqexportEncoder struct {
+	// EncodeFunc mocks the Encode method.
+	EncodeFunc func(user UserResponse) error
+
+	// FlushFunc mocks the Flush method.
+	FlushFunc func() error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Encode holds details about calls to the Encode method.
+		Encode []struct {
+			// User is the user argument value.
+			User UserResponse
+		}
+		// Flush holds details about calls to the Flush method.
+		Flush []struct {
+		}
+	}
+	lockEncode sync.RWMutex
+	lockFlush  sync.RWMutex
+}
+
+// Encode calls EncodeFunc.
+func (mock *moqexportEncoder) Encode(user UserResponse) error {
+	if mock.EncodeFunc == nil {
+		panic("moqexportEncoder.EncodeFunc: method is nil but exportEncoder.Encode was just called")
+	}
+	callInfo := struct {
+		User UserResponse
+	}{
+		User: user,
+	}
+	mock.lockEncode.Lock()
+	mock.calls.Encode = append(mock.calls.Encode, callInfo)
+	mock.lockEncode.Unlock()
+	return mock.EncodeFunc(user)
+}
+
+// EncodeCalls gets all the calls that were made to Encode.
+// Check the length with:
+//
+//	len(mockedexportEncoder.EncodeCalls())
+func (mock *moqexportEncoder) EncodeCalls() []struct {
+	User UserResponse
+} {
+	var calls []struct {
+		User UserResponse
+	}
+	mock.lockEncode.RLock()
+	calls = mock.calls.Encode
+	mock.lockEncode.RUnlock()
+	return calls
+}
+
+// Flush calls FlushFunc.
+func (mock *moqexportEncoder) Flush() error {
+	if mock.FlushFunc == nil {
+		panic("moqexportEncoder.FlushFunc: method is nil but exportEncoder.Flush was just called")
+	}
+	callInfo := struct {
+	}{}
+	mock.lockFlush.Lock()
+	mock.calls.Flush = append(mock.calls.Flush, callInfo)
+	mock.lockFlush.Unlock()
+	return mock.FlushFunc()
+}
+
+// FlushCalls gets all the calls that were made to Flush.
+// Check the length with:
+//
+//	len(mockedexportEncoder.FlushCalls())
+func (mock *moqexportEncoder) FlushCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockFlush.RLock()
+	calls = mock.calls.Flush
+	mock.lockFlush.RUnlock()
+	return calls
+}
+
+// Ensure that moqhealthChecker does implement healthChecker.
+// If this is not the case, regenerate this file with mockery.
+var _ healthChecker = &moqhealthChecker{}
+
+// moqhealthChecker is a mock implementation of healthChecker.
+//
+//	func TestSomethingThatUseshealthChecker(t *testing.T) {
+//
+//		// make and configure a mocked healthChecker
+//		mockedhealthChecker := &moqhealthChecker{
+//			DeepHealthCheckFunc: func(ctx context.Context) ([]services.HealthStatus, error) {
+//				panic("mock out the DeepHealthCheck method")
+//			},
+//		}
+//
+//		// use mockedhealthChecker in code that requires healthChecker
+//		// and then make assertions.
+//
+//	}
+type moqhealthChecker struct {
+	// DeepHealthCheckFunc mocks the DeepHealthCheck method.
+	DeepHealthCheckFunc func(ctx context.Context) ([]services.HealthStatus, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// DeepHealthCheck holds details about calls to the DeepHealthCheck method.
+		DeepHealthCheck []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+	}
+	lockDeepHealthCheck sync.RWMutex
+}
+
+// DeepHealthCheck calls DeepHealthCheckFunc.
+func (mock *moqhealthChecker) DeepHealthCheck(ctx context.Context) ([]services.HealthStatus, error) {
+	if mock.DeepHealthCheckFunc == nil {
+		panic("moqhealthChecker.DeepHealthCheckFunc: method is nil but healthChecker.DeepHealthCheck was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockDeepHealthCheck.Lock()
+	mock.calls.DeepHealthCheck = append(mock.calls.DeepHealthCheck, callInfo)
+	mock.lockDeepHealthCheck.Unlock()
+	return mock.DeepHealthCheckFunc(ctx)
+}
+
+// DeepHealthCheckCalls gets all the calls that were made to DeepHealthCheck.
+// Check the length with:
+//
+//	len(mockedhealthChecker.DeepHealthCheckCalls())
+func (mock *moqhealthChecker) DeepHealthCheckCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockDeepHealthCheck.RLock()
+	calls = mock.calls.DeepHealthCheck
+	mock.lockDeepHealthCheck.RUnlock()
+	return calls
+}
+
+// Ensure that moqusersImporter does implement usersImporter.
+// If this is not the case, regenerate this file with mockery.
+var _ usersImporter = &moqusersImporter{}
+
+// moqusersImporter is a mock implementation of usersImporter.
+//
+//	func TestSomethingThatUsesusersImporter(t *testing.T) {
+//
+//		// make and configure a mocked usersImporter
+//		mockedusersImporter := &moqusersImporter{
+//			ImportUsersFunc: func(ctx context.Context, rows iter.Seq2[models.UserImportRow, error], dryRun bool) (models.UserImportResult, error) {
+//				panic("mock out the ImportUsers method")
+//			},
+//		}
+//
+//		// use mockedusersImporter in code that requires usersImporter
+//		// and then make assertions.
+//
+//	}
+type moqusersImporter struct {
+	// ImportUsersFunc mocks the ImportUsers method.
+	ImportUsersFunc func(ctx context.Context, rows iter.Seq2[models.UserImportRow, error], dryRun bool) (models.UserImportResult, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// ImportUsers holds details about calls to the ImportUsers method.
+		ImportUsers []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Rows is the rows argument value.
+			Rows iter.Seq2[models.UserImportRow, error]
+			// DryRun is the dryRun argument value.
+			DryRun bool
+		}
+	}
+	lockImportUsers sync.RWMutex
+}
+
+// ImportUsers calls ImportUsersFunc.
+func (mock *moqusersImporter) ImportUsers(ctx context.Context, rows iter.Seq2[models.UserImportRow, error], dryRun bool) (models.UserImportResult, error) {
+	if mock.ImportUsersFunc == nil {
+		panic("moqusersImporter.ImportUsersFunc: method is nil but usersImporter.ImportUsers was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Rows   iter.Seq2[models.UserImportRow, error]
+		DryRun bool
+	}{
+		Ctx:    ctx,
+		Rows:   rows,
+		DryRun: dryRun,
+	}
+	mock.lockImportUsers.Lock()
+	mock.calls.ImportUsers = append(mock.calls.ImportUsers, callInfo)
+	mock.lockImportUsers.Unlock()
+	return mock.ImportUsersFunc(ctx, rows, dryRun)
+}
+
+// ImportUsersCalls gets all the calls that were made to ImportUsers.
+// Check the length with:
+//
+//	len(mockedusersImporter.ImportUsersCalls())
+func (mock *moqusersImporter) ImportUsersCalls() []struct {
+	Ctx    context.Context
+	Rows   iter.Seq2[models.UserImportRow, error]
+	DryRun bool
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Rows   iter.Seq2[models.UserImportRow, error]
+		DryRun bool
+	}
+	mock.lockImportUsers.RLock()
+	calls = mock.calls.ImportUsers
+	mock.lockImportUsers.RUnlock()
+	return calls
+}
+
+// Ensure that moqblogCommentsLister does implement blogCommentsLister.
+// If this is not the case, regenerate this file with mockery.
+var _ blogCommentsLister = &moqblogCommentsLister{}
+
+// moqblogCommentsLister is a mock implementation of blogCommentsLister.
+//
+//	func TestSomethingThatUsesblogCommentsLister(t *testing.T) {
+//
+//		// make and configure a mocked blogCommentsLister
+//		mockedblogCommentsLister := &moqblogCommentsLister{
+//			ListBlogCommentsFunc: func(ctx context.Context, blogID uint64, limit int, offset int) ([]models.Comment, error) {
+//				panic("mock out the ListBlogComments method")
+//			},
+//		}
+//
+//		// use mockedblogCommentsLister in code that requires blogCommentsLister
+//		// and then make assertions.
+//
+//	}
+type moqblogCommentsLister struct {
+	// ListBlogCommentsFunc mocks the ListBlogComments method.
+	ListBlogCommentsFunc func(ctx context.Context, blogID uint64, limit int, offset int) ([]models.Comment, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// ListBlogComments holds details about calls to the ListBlogComments method.
+		ListBlogComments []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// BlogID is the blogID argument value.
+			BlogID uint64
+			// Limit is the limit argument value.
+			Limit int
+			// Offset is the offset argument value.
+			Offset int
+		}
+	}
+	lockListBlogComments sync.RWMutex
+}
+
+// ListBlogComments calls ListBlogCommentsFunc.
+func (mock *moqblogCommentsLister) ListBlogComments(ctx context.Context, blogID uint64, limit int, offset int) ([]models.Comment, error) {
+	if mock.ListBlogCommentsFunc == nil {
+		panic("moqblogCommentsLister.ListBlogCommentsFunc: method is nil but blogCommentsLister.ListBlogComments was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		BlogID uint64
+		Limit  int
+		Offset int
+	}{
+		Ctx:    ctx,
+		BlogID: blogID,
+		Limit:  limit,
+		Offset: offset,
+	}
+	mock.lockListBlogComments.Lock()
+	mock.calls.ListBlogComments = append(mock.calls.ListBlogComments, callInfo)
+	mock.lockListBlogComments.Unlock()
+	return mock.ListBlogCommentsFunc(ctx, blogID, limit, offset)
+}
+
+// ListBlogCommentsCalls gets all the calls that were made to ListBlogComments.
+// Check the length with:
+//
+//	len(mockedblogCommentsLister.ListBlogCommentsCalls())
+func (mock *moqblogCommentsLister) ListBlogCommentsCalls() []struct {
+	Ctx    context.Context
+	BlogID uint64
+	Limit  int
+	Offset int
+} {
+	var calls []struct {
+		Ctx    context.Context
+		BlogID uint64
+		Limit  int
+		Offset int
+	}
+	mock.lockListBlogComments.RLock()
+	calls = mock.calls.ListBlogComments
+	mock.lockListBlogComments.RUnlock()
+	return calls
+}
+
+// Ensure that moqblogsLister does implement blogsLister.
+// If this is not the case, regenerate this file with mockery.
+var _ blogsLister = &moqblogsLister{}
+
+// moqblogsLister is a mock implementation of blogsLister.
+//
+//	func TestSomethingThatUsesblogsLister(t *testing.T) {
+//
+//		// make and configure a mocked blogsLister
+//		mockedblogsLister := &moqblogsLister{
+//			ListBlogsFunc: func(ctx context.Context, list query.List) ([]models.Blog, error) {
+//				panic("mock out the ListBlogs method")
+//			},
+//		}
+//
+//		// use mockedblogsLister in code that requires blogsLister
+//		// and then make assertions.
+//
+//	}
+type moqblogsLister struct {
+	// ListBlogsFunc mocks the ListBlogs method.
+	ListBlogsFunc func(ctx context.Context, list query.List) ([]models.Blog, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// ListBlogs holds details about calls to the ListBlogs method.
+		ListBlogs []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// List is the list argument value.
+			List query.List
+		}
+	}
+	lockListBlogs sync.RWMutex
+}
+
+// ListBlogs calls ListBlogsFunc.
+func (mock *moqblogsLister) ListBlogs(ctx context.Context, list query.List) ([]models.Blog, error) {
+	if mock.ListBlogsFunc == nil {
+		panic("moqblogsLister.ListBlogsFunc: method is nil but blogsLister.ListBlogs was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		List query.List
+	}{
+		Ctx:  ctx,
+		List: list,
+	}
+	mock.lockListBlogs.Lock()
+	mock.calls.ListBlogs = append(mock.calls.ListBlogs, callInfo)
+	mock.lockListBlogs.Unlock()
+	return mock.ListBlogsFunc(ctx, list)
+}
+
+// ListBlogsCalls gets all the calls that were made to ListBlogs.
+// Check the length with:
+//
+//	len(mockedblogsLister.ListBlogsCalls())
+func (mock *moqblogsLister) ListBlogsCalls() []struct {
+	Ctx  context.Context
+	List query.List
+} {
+	var calls []struct {
+		Ctx  context.Context
+		List query.List
+	}
+	mock.lockListBlogs.RLock()
+	calls = mock.calls.ListBlogs
+	mock.lockListBlogs.RUnlock()
+	return calls
+}
+
+// Ensure that moquserCommentsLister does implement userCommentsLister.
+// If this is not the case, regenerate this file with mockery.
+var _ userCommentsLister = &moquserCommentsLister{}
+
+// moquserCommentsLister is a mock implementation of userCommentsLister.
+//
+//	func TestSomethingThatUsesuserCommentsLister(t *testing.T) {
+//
+//		// make and configure a mocked userCommentsLister
+//		mockeduserCommentsLister := &moquserCommentsLister{
+//			ListUserCommentsFunc: func(ctx context.Context, userID uint64, limit int, offset int) ([]models.Comment, error) {
+//				panic("mock out the ListUserComments method")
+//			},
+//		}
+//
+//		// use mockeduserCommentsLister in code that requires userCommentsLister
+//		// and then make assertions.
+//
+//	}
+type moquserCommentsLister struct {
+	// ListUserCommentsFunc mocks the ListUserComments method.
+	ListUserCommentsFunc func(ctx context.Context, userID uint64, limit int, offset int) ([]models.Comment, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// ListUserComments holds details about calls to the ListUserComments method.
+		ListUserComments []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// UserID is the userID argument value.
+			UserID uint64
+			// Limit is the limit argument value.
+			Limit int
+			// Offset is the offset argument value.
+			Offset int
+		}
+	}
+	lockListUserComments sync.RWMutex
+}
+
+// ListUserComments calls ListUserCommentsFunc.
+func (mock *moquserCommentsLister) ListUserComments(ctx context.Context, userID uint64, limit int, offset int) ([]models.Comment, error) {
+	if mock.ListUserCommentsFunc == nil {
+		panic("moquserCommentsLister.ListUserCommentsFunc: method is nil but userCommentsLister.ListUserComments was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		UserID uint64
+		Limit  int
+		Offset int
+	}{
+		Ctx:    ctx,
+		UserID: userID,
+		Limit:  limit,
+		Offset: offset,
+	}
+	mock.lockListUserComments.Lock()
+	mock.calls.ListUserComments = append(mock.calls.ListUserComments, callInfo)
+	mock.lockListUserComments.Unlock()
+	return mock.ListUserCommentsFunc(ctx, userID, limit, offset)
+}
+
+// ListUserCommentsCalls gets all the calls that were made to ListUserComments.
+// Check the length with:
+//
+//	len(mockeduserCommentsLister.ListUserCommentsCalls())
+func (mock *moquserCommentsLister) ListUserCommentsCalls() []struct {
+	Ctx    context.Context
+	UserID uint64
+	Limit  int
+	Offset int
+} {
+	var calls []struct {
+		Ctx    context.Context
+		UserID uint64
+		Limit  int
+		Offset int
+	}
+	mock.lockListUserComments.RLock()
+	calls = mock.calls.ListUserComments
+	mock.lockListUserComments.RUnlock()
+	return calls
+}
+
+// Ensure that moqusersLister does implement usersLister.
+// If this is not the case, regenerate this file with mockery.
+var _ usersLister = &moqusersLister{}
+
+// moqusersLister is a mock implementation of usersLister.
+//
+//	func TestSomethingThatUsesusersLister(t *testing.T) {
+//
+//		// make and configure a mocked usersLister
+//		mockedusersLister := &moqusersLister{
+//			ListUsersFunc: func(ctx context.Context, list query.List) ([]models.User, error) {
+//				panic("mock out the ListUsers method")
+//			},
+//		}
+//
+//		// use mockedusersLister in code that requires usersLister
+//		// and then make assertions.
+//
+//	}
+type moqusersLister struct {
+	// ListUsersFunc mocks the ListUsers method.
+	ListUsersFunc func(ctx context.Context, list query.List) ([]models.User, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// ListUsers holds details about calls to the ListUsers method.
+		ListUsers []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// List is the list argument value.
+			List query.List
+		}
+	}
+	lockListUsers sync.RWMutex
+}
+
+// ListUsers calls ListUsersFunc.
+func (mock *moqusersLister) ListUsers(ctx context.Context, list query.List) ([]models.User, error) {
+	if mock.ListUsersFunc == nil {
+		panic("moqusersLister.ListUsersFunc: method is nil but usersLister.ListUsers was just called")
 	}
 	callInfo := struct {
 		Ctx  context.Context
-		User models.User
+		List query.List
 	}{
 		Ctx:  ctx,
-		User: user,
+		List: list,
 	}
-	mock.lockCreateUser.Lock()
-	mock.calls.CreateUser = append(mock.calls.CreateUser, callInfo)
-	mock.lockCreateUser.Unlock()
-	return mock.CreateUserFunc(ctx, user)
+	mock.lockListUsers.Lock()
+	mock.calls.ListUsers = append(mock.calls.ListUsers, callInfo)
+	mock.lockListUsers.Unlock()
+	return mock.ListUsersFunc(ctx, list)
 }
 
-// CreateUserCalls gets all the calls that were made to CreateUser.
+// ListUsersCalls gets all the calls that were made to ListUsers.
 // Check the length with:
 //
-//	len(mockeduserCreator.CreateUserCalls())
-func (mock *moquserCreator) CreateUserCalls() []struct {
+//	len(mockedusersLister.ListUsersCalls())
+func (mock *moqusersLister) ListUsersCalls() []struct {
 	Ctx  context.Context
-	User models.User
+	List query.List
 } {
 	var calls []struct {
 		Ctx  context.Context
-		User models.User
+		List query.List
 	}
-	mock.lockCreateUser.RLock()
-	calls = mock.calls.CreateUser
-	mock.lockCreateUser.RUnlock()
+	mock.lockListUsers.RLock()
+	calls = mock.calls.ListUsers
+	mock.lockListUsers.RUnlock()
 	return calls
 }
 
-// Ensure that moquserDeleter does implement userDeleter.
+// Ensure that moqloginer does implement loginer.
 // If this is not the case, regenerate this file with mockery.
-var _ userDeleter = &moquserDeleter{}
+var _ loginer = &moqloginer{}
 
-// moquserDeleter is a mock implementation of userDeleter.
+// moqloginer is a mock implementation of loginer.
 //
-//	func TestSomethingThatUsesuserDeleter(t *testing.T) {
+//	func TestSomethingThatUsesloginer(t *testing.T) {
 //
-//		// make and configure a mocked userDeleter
-//		mockeduserDeleter := &moquserDeleter{
-//			DeleteUserFunc: func(ctx context.Context, id uint64) error {
-//				panic("mock out the DeleteUser method")
+//		// make and configure a mocked loginer
+//		mockedloginer := &moqloginer{
+//			LoginFunc: func(ctx context.Context, email string, password string) (models.TokenPair, error) {
+//				panic("mock out the Login method")
 //			},
 //		}
 //
-//		// use mockeduserDeleter in code that requires userDeleter
+//		// use mockedloginer in code that requires loginer
 //		// and then make assertions.
 //
 //	}
-type moquserDeleter struct {
-	// DeleteUserFunc mocks the DeleteUser method.
-	DeleteUserFunc func(ctx context.Context, id uint64) error
+type moqloginer struct {
+	// LoginFunc mocks the Login method.
+	LoginFunc func(ctx context.Context, email string, password string) (models.TokenPair, error)
 
 	// calls tracks calls to the methods.
 	calls struct {
-		// DeleteUser holds details about calls to the DeleteUser method.
-		DeleteUser []struct {
+		// Login holds details about calls to the Login method.
+		Login []struct {
 			// Ctx is the ctx argument value.
 			Ctx context.Context
-			// ID is the id argument value.
-			ID uint64
+			// Email is the email argument value.
+			Email string
+			// Password is the password argument value.
+			Password string
 		}
 	}
-	lockDeleteUser sync.RWMutex
+	lockLogin sync.RWMutex
 }
 
-// DeleteUser calls DeleteUserFunc.
-func (mock *moquserDeleter) DeleteUser(ctx context.Context, id uint64) error {
-	if mock.DeleteUserFunc == nil {
-		panic("moquserDeleter.DeleteUserFunc: method is nil but userDeleter.DeleteUser was just called")
+// Login calls LoginFunc.
+func (mock *moqloginer) Login(ctx context.Context, email string, password string) (models.TokenPair, error) {
+	if mock.LoginFunc == nil {
+		panic("moqloginer.LoginFunc: method is nil but loginer.Login was just called")
 	}
 	callInfo := struct {
-		Ctx context.Context
-		ID  uint64
+		Ctx      context.Context
+		Email    string
+		Password string
 	}{
-		Ctx: ctx,
-		ID:  id,
+		Ctx:      ctx,
+		Email:    email,
+		Password: password,
 	}
-	mock.lockDeleteUser.Lock()
-	mock.calls.DeleteUser = append(mock.calls.DeleteUser, callInfo)
-	mock.lockDeleteUser.Unlock()
-	return mock.DeleteUserFunc(ctx, id)
+	mock.lockLogin.Lock()
+	mock.calls.Login = append(mock.calls.Login, callInfo)
+	mock.lockLogin.Unlock()
+	return mock.LoginFunc(ctx, email, password)
 }
 
-// DeleteUserCalls gets all the calls that were made to DeleteUser.
+// LoginCalls gets all the calls that were made to Login.
 // Check the length with:
 //
-//	len(mockeduserDeleter.DeleteUserCalls())
-func (mock *moquserDeleter) DeleteUserCalls() []struct {
-	Ctx context.Context
-	ID  uint64
+//	len(mockedloginer.LoginCalls())
+func (mock *moqloginer) LoginCalls() []struct {
+	Ctx      context.Context
+	Email    string
+	Password string
 } {
 	var calls []struct {
-		Ctx context.Context
-		ID  uint64
+		Ctx      context.Context
+		Email    string
+		Password string
 	}
-	mock.lockDeleteUser.RLock()
-	calls = mock.calls.DeleteUser
-	mock.lockDeleteUser.RUnlock()
+	mock.lockLogin.RLock()
+	calls = mock.calls.Login
+	mock.lockLogin.RUnlock()
 	return calls
 }
 
-// Ensure that moqhealthChecker does implement healthChecker.
+// Ensure that moquserPatcher does implement userPatcher.
 // If this is not the case, regenerate this file with mockery.
-var _ healthChecker = &moqhealthChecker{}
+var _ userPatcher = &moquserPatcher{}
 
-// moqhealthChecker is a mock implementation of healthChecker.
+// moquserPatcher is a mock implementation of userPatcher.
 //
-//	func TestSomethingThatUseshealthChecker(t *testing.T) {
+//	func TestSomethingThatUsesuserPatcher(t *testing.T) {
 //
-//		// make and configure a mocked healthChecker
-//		mockedhealthChecker := &moqhealthChecker{
-//			DeepHealthCheckFunc: func(ctx context.Context) ([]services.HealthStatus, error) {
-//				panic("mock out the DeepHealthCheck method")
+//		// make and configure a mocked userPatcher
+//		mockeduserPatcher := &moquserPatcher{
+//			PatchUserFunc: func(ctx context.Context, id uint64, patch models.UserPatch, version uint64) (models.User, error) {
+//				panic("mock out the PatchUser method")
 //			},
 //		}
 //
-//		// use mockedhealthChecker in code that requires healthChecker
+//		// use mockeduserPatcher in code that requires userPatcher
 //		// and then make assertions.
 //
 //	}
-type moqhealthChecker struct {
-	// DeepHealthCheckFunc mocks the DeepHealthCheck method.
-	DeepHealthCheckFunc func(ctx context.Context) ([]services.HealthStatus, error)
+type moquserPatcher struct {
+	// PatchUserFunc mocks the PatchUser method.
+	PatchUserFunc func(ctx context.Context, id uint64, patch models.UserPatch, version uint64) (models.User, error)
 
 	// calls tracks calls to the methods.
 	calls struct {
-		// DeepHealthCheck holds details about calls to the DeepHealthCheck method.
-		DeepHealthCheck []struct {
+		// PatchUser holds details about calls to the PatchUser method.
+		PatchUser []struct {
 			// Ctx is the ctx argument value.
 			Ctx context.Context
+			// ID is the id argument value.
+			ID uint64
+			// Patch is the patch argument value.
+			Patch models.UserPatch
+			// Version is the version argument value.
+			Version uint64
 		}
 	}
-	lockDeepHealthCheck sync.RWMutex
+	lockPatchUser sync.RWMutex
 }
 
-// DeepHealthCheck calls DeepHealthCheckFunc.
-func (mock *moqhealthChecker) DeepHealthCheck(ctx context.Context) ([]services.HealthStatus, error) {
-	if mock.DeepHealthCheckFunc == nil {
-		panic("moqhealthChecker.DeepHealthCheckFunc: method is nil but healthChecker.DeepHealthCheck was just called")
+// PatchUser calls PatchUserFunc.
+func (mock *moquserPatcher) PatchUser(ctx context.Context, id uint64, patch models.UserPatch, version uint64) (models.User, error) {
+	if mock.PatchUserFunc == nil {
+		panic("moquserPatcher.PatchUserFunc: method is nil but userPatcher.PatchUser was just called")
 	}
 	callInfo := struct {
-		Ctx context.Context
+		Ctx     context.Context
+		ID      uint64
+		Patch   models.UserPatch
+		Version uint64
 	}{
-		Ctx: ctx,
+		Ctx:     ctx,
+		ID:      id,
+		Patch:   patch,
+		Version: version,
 	}
-	mock.lockDeepHealthCheck.Lock()
-	mock.calls.DeepHealthCheck = append(mock.calls.DeepHealthCheck, callInfo)
-	mock.lockDeepHealthCheck.Unlock()
-	return mock.DeepHealthCheckFunc(ctx)
+	mock.lockPatchUser.Lock()
+	mock.calls.PatchUser = append(mock.calls.PatchUser, callInfo)
+	mock.lockPatchUser.Unlock()
+	return mock.PatchUserFunc(ctx, id, patch, version)
 }
 
-// DeepHealthCheckCalls gets all the calls that were made to DeepHealthCheck.
+// PatchUserCalls gets all the calls that were made to PatchUser.
 // Check the length with:
 //
-//	len(mockedhealthChecker.DeepHealthCheckCalls())
-func (mock *moqhealthChecker) DeepHealthCheckCalls() []struct {
-	Ctx context.Context
+//	len(mockeduserPatcher.PatchUserCalls())
+func (mock *moquserPatcher) PatchUserCalls() []struct {
+	Ctx     context.Context
+	ID      uint64
+	Patch   models.UserPatch
+	Version uint64
 } {
 	var calls []struct {
-		Ctx context.Context
+		Ctx     context.Context
+		ID      uint64
+		Patch   models.UserPatch
+		Version uint64
 	}
-	mock.lockDeepHealthCheck.RLock()
-	calls = mock.calls.DeepHealthCheck
-	mock.lockDeepHealthCheck.RUnlock()
+	mock.lockPatchUser.RLock()
+	calls = mock.calls.PatchUser
+	mock.lockPatchUser.RUnlock()
 	return calls
 }
 
-// Ensure that moqusersLister does implement usersLister.
+// Ensure that moqblogReader does implement blogReader.
 // If this is not the case, regenerate this file with mockery.
-var _ usersLister = &moqusersLister{}
+var _ blogReader = &moqblogReader{}
 
-// moqusersLister is a mock implementation of usersLister.
+// moqblogReader is a mock implementation of blogReader.
 //
-//	func TestSomethingThatUsesusersLister(t *testing.T) {
+//	func TestSomethingThatUsesblogReader(t *testing.T) {
 //
-//		// make and configure a mocked usersLister
-//		mockedusersLister := &moqusersLister{
-//			ListUsersFunc: func(ctx context.Context) ([]models.User, error) {
-//				panic("mock out the ListUsers method")
+//		// make and configure a mocked blogReader
+//		mockedblogReader := &moqblogReader{
+//			ReadBlogFunc: func(ctx context.Context, id uint64) (models.Blog, error) {
+//				panic("mock out the ReadBlog method")
 //			},
 //		}
 //
-//		// use mockedusersLister in code that requires usersLister
+//		// use mockedblogReader in code that requires blogReader
 //		// and then make assertions.
 //
 //	}
-type moqusersLister struct {
-	// ListUsersFunc mocks the ListUsers method.
-	ListUsersFunc func(ctx context.Context) ([]models.User, error)
+type moqblogReader struct {
+	// ReadBlogFunc mocks the ReadBlog method.
+	ReadBlogFunc func(ctx context.Context, id uint64) (models.Blog, error)
 
 	// calls tracks calls to the methods.
 	calls struct {
-		// ListUsers holds details about calls to the ListUsers method.
-		ListUsers []struct {
+		// ReadBlog holds details about calls to the ReadBlog method.
+		ReadBlog []struct {
 			// Ctx is the ctx argument value.
 			Ctx context.Context
+			// ID is the id argument value.
+			ID uint64
 		}
 	}
-	lockListUsers sync.RWMutex
+	lockReadBlog sync.RWMutex
 }
 
-// ListUsers calls ListUsersFunc.
-func (mock *moqusersLister) ListUsers(ctx context.Context) ([]models.User, error) {
-	if mock.ListUsersFunc == nil {
-		panic("moqusersLister.ListUsersFunc: method is nil but usersLister.ListUsers was just called")
+// ReadBlog calls ReadBlogFunc.
+func (mock *moqblogReader) ReadBlog(ctx context.Context, id uint64) (models.Blog, error) {
+	if mock.ReadBlogFunc == nil {
+		panic("moqblogReader.ReadBlogFunc: method is nil but blogReader.ReadBlog was just called")
 	}
 	callInfo := struct {
 		Ctx context.Context
+		ID  uint64
 	}{
 		Ctx: ctx,
+		ID:  id,
 	}
-	mock.lockListUsers.Lock()
-	mock.calls.ListUsers = append(mock.calls.ListUsers, callInfo)
-	mock.lockListUsers.Unlock()
-	return mock.ListUsersFunc(ctx)
+	mock.lockReadBlog.Lock()
+	mock.calls.ReadBlog = append(mock.calls.ReadBlog, callInfo)
+	mock.lockReadBlog.Unlock()
+	return mock.ReadBlogFunc(ctx, id)
 }
 
-// ListUsersCalls gets all the calls that were made to ListUsers.
+// ReadBlogCalls gets all the calls that were made to ReadBlog.
 // Check the length with:
 //
-//	len(mockedusersLister.ListUsersCalls())
-func (mock *moqusersLister) ListUsersCalls() []struct {
+//	len(mockedblogReader.ReadBlogCalls())
+func (mock *moqblogReader) ReadBlogCalls() []struct {
 	Ctx context.Context
+	ID  uint64
 } {
 	var calls []struct {
 		Ctx context.Context
+		ID  uint64
 	}
-	mock.lockListUsers.RLock()
-	calls = mock.calls.ListUsers
-	mock.lockListUsers.RUnlock()
+	mock.lockReadBlog.RLock()
+	calls = mock.calls.ReadBlog
+	mock.lockReadBlog.RUnlock()
 	return calls
 }
 
@@ -360,6 +1389,156 @@ func (mock *moquserReader) ReadUserCalls() []struct {
 	return calls
 }
 
+// Ensure that moqtokenRefresher does implement tokenRefresher.
+// If this is not the case, regenerate this file with mockery.
+var _ tokenRefresher = &moqtokenRefresher{}
+
+// moqtokenRefresher is a mock implementation of tokenRefresher.
+//
+//	func TestSomethingThatUsestokenRefresher(t *testing.T) {
+//
+//		// make and configure a mocked tokenRefresher
+//		mockedtokenRefresher := &moqtokenRefresher{
+//			RefreshFunc: func(ctx context.Context, refreshToken string) (models.TokenPair, error) {
+//				panic("mock out the Refresh method")
+//			},
+//		}
+//
+//		// use mockedtokenRefresher in code that requires tokenRefresher
+//		// and then make assertions.
+//
+//	}
+type moqtokenRefresher struct {
+	// RefreshFunc mocks the Refresh method.
+	RefreshFunc func(ctx context.Context, refreshToken string) (models.TokenPair, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Refresh holds details about calls to the Refresh method.
+		Refresh []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// RefreshToken is the refreshToken argument value.
+			RefreshToken string
+		}
+	}
+	lockRefresh sync.RWMutex
+}
+
+// Refresh calls RefreshFunc.
+func (mock *moqtokenRefresher) Refresh(ctx context.Context, refreshToken string) (models.TokenPair, error) {
+	if mock.RefreshFunc == nil {
+		panic("moqtokenRefresher.RefreshFunc: method is nil but tokenRefresher.Refresh was just called")
+	}
+	callInfo := struct {
+		Ctx          context.Context
+		RefreshToken string
+	}{
+		Ctx:          ctx,
+		RefreshToken: refreshToken,
+	}
+	mock.lockRefresh.Lock()
+	mock.calls.Refresh = append(mock.calls.Refresh, callInfo)
+	mock.lockRefresh.Unlock()
+	return mock.RefreshFunc(ctx, refreshToken)
+}
+
+// RefreshCalls gets all the calls that were made to Refresh.
+// Check the length with:
+//
+//	len(mockedtokenRefresher.RefreshCalls())
+func (mock *moqtokenRefresher) RefreshCalls() []struct {
+	Ctx          context.Context
+	RefreshToken string
+} {
+	var calls []struct {
+		Ctx          context.Context
+		RefreshToken string
+	}
+	mock.lockRefresh.RLock()
+	calls = mock.calls.Refresh
+	mock.lockRefresh.RUnlock()
+	return calls
+}
+
+// Ensure that moqblogUpdater does implement blogUpdater.
+// If this is not the case, regenerate this file with mockery.
+var _ blogUpdater = &moqblogUpdater{}
+
+// moqblogUpdater is a mock implementation of blogUpdater.
+//
+//	func TestSomethingThatUsesblogUpdater(t *testing.T) {
+//
+//		// make and configure a mocked blogUpdater
+//		mockedblogUpdater := &moqblogUpdater{
+//			UpdateBlogFunc: func(ctx context.Context, id uint64, patch models.Blog) (models.Blog, error) {
+//				panic("mock out the UpdateBlog method")
+//			},
+//		}
+//
+//		// use mockedblogUpdater in code that requires blogUpdater
+//		// and then make assertions.
+//
+//	}
+type moqblogUpdater struct {
+	// UpdateBlogFunc mocks the UpdateBlog method.
+	UpdateBlogFunc func(ctx context.Context, id uint64, patch models.Blog) (models.Blog, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// UpdateBlog holds details about calls to the UpdateBlog method.
+		UpdateBlog []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID uint64
+			// Patch is the patch argument value.
+			Patch models.Blog
+		}
+	}
+	lockUpdateBlog sync.RWMutex
+}
+
+// UpdateBlog calls UpdateBlogFunc.
+func (mock *moqblogUpdater) UpdateBlog(ctx context.Context, id uint64, patch models.Blog) (models.Blog, error) {
+	if mock.UpdateBlogFunc == nil {
+		panic("moqblogUpdater.UpdateBlogFunc: method is nil but blogUpdater.UpdateBlog was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		ID    uint64
+		Patch models.Blog
+	}{
+		Ctx:   ctx,
+		ID:    id,
+		Patch: patch,
+	}
+	mock.lockUpdateBlog.Lock()
+	mock.calls.UpdateBlog = append(mock.calls.UpdateBlog, callInfo)
+	mock.lockUpdateBlog.Unlock()
+	return mock.UpdateBlogFunc(ctx, id, patch)
+}
+
+// UpdateBlogCalls gets all the calls that were made to UpdateBlog.
+// Check the length with:
+//
+//	len(mockedblogUpdater.UpdateBlogCalls())
+func (mock *moqblogUpdater) UpdateBlogCalls() []struct {
+	Ctx   context.Context
+	ID    uint64
+	Patch models.Blog
+} {
+	var calls []struct {
+		Ctx   context.Context
+		ID    uint64
+		Patch models.Blog
+	}
+	mock.lockUpdateBlog.RLock()
+	calls = mock.calls.UpdateBlog
+	mock.lockUpdateBlog.RUnlock()
+	return calls
+}
+
 // Ensure that moquserUpdater does implement userUpdater.
 // If this is not the case, regenerate this file with mockery.
 var _ userUpdater = &moquserUpdater{}
@@ -370,7 +1549,7 @@ var _ userUpdater = &moquserUpdater{}
 //
 //		// make and configure a mocked userUpdater
 //		mockeduserUpdater := &moquserUpdater{
-//			UpdateUserFunc: func(ctx context.Context, id uint64, patch models.User) (models.User, error) {
+//			UpdateUserFunc: func(ctx context.Context, id uint64, patch models.User, version uint64) (models.User, error) {
 //				panic("mock out the UpdateUser method")
 //			},
 //		}
@@ -381,7 +1560,7 @@ var _ userUpdater = &moquserUpdater{}
 //	}
 type moquserUpdater struct {
 	// UpdateUserFunc mocks the UpdateUser method.
-	UpdateUserFunc func(ctx context.Context, id uint64, patch models.User) (models.User, error)
+	UpdateUserFunc func(ctx context.Context, id uint64, patch models.User, version uint64) (models.User, error)
 
 	// calls tracks calls to the methods.
 	calls struct {
@@ -393,29 +1572,33 @@ type moquserUpdater struct {
 			ID uint64
 			// Patch is the patch argument value.
 			Patch models.User
+			// Version is the version argument value.
+			Version uint64
 		}
 	}
 	lockUpdateUser sync.RWMutex
 }
 
 // UpdateUser calls UpdateUserFunc.
-func (mock *moquserUpdater) UpdateUser(ctx context.Context, id uint64, patch models.User) (models.User, error) {
+func (mock *moquserUpdater) UpdateUser(ctx context.Context, id uint64, patch models.User, version uint64) (models.User, error) {
 	if mock.UpdateUserFunc == nil {
 		panic("moquserUpdater.UpdateUserFunc: method is nil but userUpdater.UpdateUser was just called")
 	}
 	callInfo := struct {
-		Ctx   context.Context
-		ID    uint64
-		Patch models.User
+		Ctx     context.Context
+		ID      uint64
+		Patch   models.User
+		Version uint64
 	}{
-		Ctx:   ctx,
-		ID:    id,
-		Patch: patch,
+		Ctx:     ctx,
+		ID:      id,
+		Patch:   patch,
+		Version: version,
 	}
 	mock.lockUpdateUser.Lock()
 	mock.calls.UpdateUser = append(mock.calls.UpdateUser, callInfo)
 	mock.lockUpdateUser.Unlock()
-	return mock.UpdateUserFunc(ctx, id, patch)
+	return mock.UpdateUserFunc(ctx, id, patch, version)
 }
 
 // UpdateUserCalls gets all the calls that were made to UpdateUser.
@@ -423,17 +1606,91 @@ func (mock *moquserUpdater) UpdateUser(ctx context.Context, id uint64, patch mod
 //
 //	len(mockeduserUpdater.UpdateUserCalls())
 func (mock *moquserUpdater) UpdateUserCalls() []struct {
-	Ctx   context.Context
-	ID    uint64
-	Patch models.User
+	Ctx     context.Context
+	ID      uint64
+	Patch   models.User
+	Version uint64
 } {
 	var calls []struct {
-		Ctx   context.Context
-		ID    uint64
-		Patch models.User
+		Ctx     context.Context
+		ID      uint64
+		Patch   models.User
+		Version uint64
 	}
 	mock.lockUpdateUser.RLock()
 	calls = mock.calls.UpdateUser
 	mock.lockUpdateUser.RUnlock()
 	return calls
 }
+
+// Ensure that moqblogVoter does implement blogVoter.
+// If this is not the case, regenerate this file with mockery.
+var _ blogVoter = &moqblogVoter{}
+
+// moqblogVoter is a mock implementation of blogVoter.
+//
+//	func TestSomethingThatUsesblogVoter(t *testing.T) {
+//
+//		// make and configure a mocked blogVoter
+//		mockedblogVoter := &moqblogVoter{
+//			VoteBlogFunc: func(ctx context.Context, vote models.Vote) (models.Blog, error) {
+//				panic("mock out the VoteBlog method")
+//			},
+//		}
+//
+//		// use mockedblogVoter in code that requires blogVoter
+//		// and then make assertions.
+//
+//	}
+type moqblogVoter struct {
+	// VoteBlogFunc mocks the VoteBlog method.
+	VoteBlogFunc func(ctx context.Context, vote models.Vote) (models.Blog, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// VoteBlog holds details about calls to the VoteBlog method.
+		VoteBlog []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Vote is the vote argument value.
+			Vote models.Vote
+		}
+	}
+	lockVoteBlog sync.RWMutex
+}
+
+// VoteBlog calls VoteBlogFunc.
+func (mock *moqblogVoter) VoteBlog(ctx context.Context, vote models.Vote) (models.Blog, error) {
+	if mock.VoteBlogFunc == nil {
+		panic("moqblogVoter.VoteBlogFunc: method is nil but blogVoter.VoteBlog was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Vote models.Vote
+	}{
+		Ctx:  ctx,
+		Vote: vote,
+	}
+	mock.lockVoteBlog.Lock()
+	mock.calls.VoteBlog = append(mock.calls.VoteBlog, callInfo)
+	mock.lockVoteBlog.Unlock()
+	return mock.VoteBlogFunc(ctx, vote)
+}
+
+// VoteBlogCalls gets all the calls that were made to VoteBlog.
+// Check the length with:
+//
+//	len(mockedblogVoter.VoteBlogCalls())
+func (mock *moqblogVoter) VoteBlogCalls() []struct {
+	Ctx  context.Context
+	Vote models.Vote
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Vote models.Vote
+	}
+	mock.lockVoteBlog.RLock()
+	calls = mock.calls.VoteBlog
+	mock.lockVoteBlog.RUnlock()
+	return calls
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// negotiate returns the media type in offers that best satisfies an Accept
+// header, or an empty string if none is acceptable. Each offer takes the
+// quality of the most specific media range matching it, and of offers with
+// equal quality the earliest is preferred. An empty header accepts anything.
+func negotiate(accept string, offers ...string) string {
+	if strings.TrimSpace(accept) == "" && len(offers) > 0 {
+		return offers[0]
+	}
+
+	best, bestQuality := "", 0.0
+	for _, offer := range offers {
+		quality, specificity := 0.0, 0
+		for _, mediaRange := range strings.Split(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(mediaRange)
+			if err != nil {
+				continue
+			}
+
+			var rangeSpecificity int
+			switch {
+			case mediaType == offer:
+				rangeSpecificity = 3
+			case strings.HasSuffix(mediaType, "/*") &&
+				strings.HasPrefix(offer, strings.TrimSuffix(mediaType, "*")):
+				rangeSpecificity = 2
+			case mediaType == "*/*":
+				rangeSpecificity = 1
+			default:
+				continue
+			}
+			if rangeSpecificity <= specificity {
+				continue
+			}
+
+			specificity, quality = rangeSpecificity, 1
+			if q, ok := params["q"]; ok {
+				if quality, err = strconv.ParseFloat(q, 64); err != nil {
+					quality = 0
+				}
+			}
+		}
+
+		if quality > bestQuality {
+			best, bestQuality = offer, quality
+		}
+	}
+
+	return best
+}
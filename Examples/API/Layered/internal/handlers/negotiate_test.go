@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiate(t *testing.T) {
+	offers := []string{"application/x-ndjson", "text/csv"}
+
+	tests := map[string]struct {
+		accept string
+		want   string
+	}{
+		"no accept header":               {accept: "", want: "application/x-ndjson"},
+		"anything":                       {accept: "*/*", want: "application/x-ndjson"},
+		"exact match":                    {accept: "text/csv", want: "text/csv"},
+		"type wildcard":                  {accept: "text/*", want: "text/csv"},
+		"parameters are ignored":         {accept: "text/csv; charset=utf-8", want: "text/csv"},
+		"highest quality wins":           {accept: "application/x-ndjson;q=0.5, text/csv", want: "text/csv"},
+		"equal quality prefers offers":   {accept: "text/csv, application/x-ndjson", want: "application/x-ndjson"},
+		"specific range overrides":       {accept: "*/*, application/x-ndjson;q=0", want: "text/csv"},
+		"nothing acceptable":             {accept: "application/json", want: ""},
+		"zero quality is not acceptable": {accept: "text/csv;q=0", want: ""},
+		"malformed ranges are skipped":   {accept: "text/, text/csv", want: "text/csv"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, negotiate(tc.accept, offers...))
+		})
+	}
+}
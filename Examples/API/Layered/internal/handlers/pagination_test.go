@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePagination(t *testing.T) {
+	tests := map[string]struct {
+		query        string
+		wantLimit    int
+		wantOffset   int
+		wantProblems []string
+	}{
+		"defaults": {
+			query:      "",
+			wantLimit:  defaultPageLimit,
+			wantOffset: 0,
+		},
+		"explicit values": {
+			query:      "?limit=5&offset=10",
+			wantLimit:  5,
+			wantOffset: 10,
+		},
+		"limit too large": {
+			query:        "?limit=101",
+			wantLimit:    defaultPageLimit,
+			wantProblems: []string{"limit"},
+		},
+		"invalid limit and offset": {
+			query:        "?limit=abc&offset=-1",
+			wantLimit:    defaultPageLimit,
+			wantProblems: []string{"limit", "offset"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(
+			name, func(t *testing.T) {
+				req := httptest.NewRequest(http.MethodGet, "/blog/1/comments"+tc.query, nil)
+
+				limit, offset, problems := parsePagination(req)
+
+				assert.Equal(t, tc.wantLimit, limit)
+				assert.Equal(t, tc.wantOffset, offset)
+
+				fields := make([]string, 0, len(problems))
+				for _, problem := range problems {
+					fields = append(fields, problem.Field)
+				}
+				assert.ElementsMatch(t, tc.wantProblems, fields)
+			},
+		)
+	}
+}
+
+func TestParseCursorPagination(t *testing.T) {
+	type position struct {
+		AfterID uint64 `json:"afterId"`
+	}
+	validCursor, err := encodeCursor(position{AfterID: 42})
+	if err != nil {
+		t.Fatalf("failed to encode cursor: %v", err)
+	}
+
+	tests := map[string]struct {
+		query        string
+		wantLimit    int
+		wantCursor   position
+		wantProblems []string
+	}{
+		"defaults": {
+			query:     "",
+			wantLimit: defaultPageLimit,
+		},
+		"explicit values": {
+			query:      "?limit=5&cursor=" + validCursor,
+			wantLimit:  5,
+			wantCursor: position{AfterID: 42},
+		},
+		"cursor is not base64": {
+			query:        "?cursor=%25%25%25",
+			wantLimit:    defaultPageLimit,
+			wantProblems: []string{"cursor"},
+		},
+		"cursor has unknown fields": {
+			query:        "?cursor=eyJvZmZzZXQiOjF9", // {"offset":1}
+			wantLimit:    defaultPageLimit,
+			wantProblems: []string{"cursor"},
+		},
+		"invalid limit and cursor": {
+			query:        "?limit=-1&cursor=abc",
+			wantLimit:    defaultPageLimit,
+			wantProblems: []string{"limit", "cursor"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(
+			name, func(t *testing.T) {
+				req := httptest.NewRequest(http.MethodGet, "/user"+tc.query, nil)
+
+				limit, cursor, problems := parseCursorPagination[position](req)
+
+				assert.Equal(t, tc.wantLimit, limit)
+				assert.Equal(t, tc.wantCursor, cursor)
+
+				fields := make([]string, 0, len(problems))
+				for _, problem := range problems {
+					fields = append(fields, problem.Field)
+				}
+				assert.ElementsMatch(t, tc.wantProblems, fields)
+			},
+		)
+	}
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"go.opentelemetry.io/otel/codes"
+
+	"example.com/examples/api/layered/internal/middleware"
+	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/validation"
+)
+
+// mergePatchMediaType is the media type of a JSON Merge Patch (RFC 7396).
+const mergePatchMediaType = "application/merge-patch+json"
+
+// userPatcher represents a type capable of partially updating a user and
+// returning it or an error.
+type userPatcher interface {
+	PatchUser(ctx context.Context, id uint64, patch models.UserPatch, version uint64) (models.User, error)
+}
+
+// HandlePatchUser handles the partial update of an existing user by ID. The
+// body is a JSON Merge Patch; only the fields it sets are validated and
+// updated. If the request has an If-Match header, the user is only patched
+// while its ETag matches.
+//
+//	@Summary		Patch User
+//	@Description	Partially update User by ID with a JSON Merge Patch
+//	@Tags			user
+//	@Accept			application/merge-patch+json
+//	@Produce		json
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
+//	@Param			id			path		string				true	"User ID"
+//	@Param			request		body		UserPatchRequest	true	"Fields to change"
+//	@Param			If-Match	header		string				false	"ETag of the user version to patch"
+//	@Success		200			{object}	UserResponse
+//	@Header			200			{string}	ETag	"Version of the patched user"
+//	@Failure		400			{object}	ProblemDetailValidation
+//	@Failure		401			{object}	ProblemDetail
+//	@Failure		403			{object}	ProblemDetail
+//	@Failure		404			{object}	ProblemDetail
+//	@Failure		406			{object}	ProblemDetail
+//	@Failure		412			{object}	ProblemDetail
+//	@Failure		415			{object}	ProblemDetail
+//	@Failure		409			{object}	ProblemDetailValidation
+//	@Failure		429			{object}	ProblemDetail
+//	@Failure		500			{object}	ProblemDetail
+//	@Failure		503			{object}	ProblemDetail
+//	@Security		BearerAuth
+//	@Router			/user/{id}  [PATCH]
+func HandlePatchUser(
+	logger *slog.Logger,
+	validate *validation.Validator,
+	userPatcher userPatcher,
+) http.HandlerFunc {
+	const name = "handlers.HandlePatchUser"
+	logger = logger.With(slog.String("func", name))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracer.Start(r.Context(), name)
+		defer span.End()
+
+		// Read id from path parameters
+		idStr := r.PathValue("id")
+
+		// Convert the ID from string to int
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to parse id from url",
+				slog.String("id", idStr),
+				slog.String("error", err.Error()),
+			)
+			span.SetStatus(codes.Error, "ID conversion failed")
+			span.RecordError(err)
+
+			_ = encodeResponse(w, r, http.StatusBadRequest, ProblemDetail{
+				Title:   "Invalid ID",
+				Status:  http.StatusBadRequest,
+				Detail:  "The provided ID is not a valid integer.",
+				TraceID: middleware.GetTraceID(ctx),
+			})
+
+			return
+		}
+
+		// Only the version of the user named by If-Match may be changed
+		version, ok := parseIfMatch(r)
+		if !ok {
+			_ = encodeResponse(w, r, http.StatusPreconditionFailed, NewPreconditionFailed(
+				ctx,
+				fmt.Sprintf("User with ID %d does not match the If-Match header.", id),
+			))
+
+			return
+		}
+
+		// Content negotiation
+		if !acceptable(w, r) {
+			return
+		}
+
+		// Only merge patches are accepted
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || mediaType != mergePatchMediaType {
+			logger.ErrorContext(
+				ctx,
+				"unsupported content type",
+				slog.String("content_type", r.Header.Get("Content-Type")),
+			)
+			span.SetStatus(codes.Error, "unsupported content type")
+
+			w.Header().Set("Accept-Patch", mergePatchMediaType)
+			_ = encodeResponse(w, r, http.StatusUnsupportedMediaType, NewUnsupportedMediaType(
+				ctx,
+				fmt.Sprintf("The request body must be %s.", mergePatchMediaType),
+			))
+
+			return
+		}
+
+		// Request validation
+		request, problems, err := decodeMergePatch[UserPatchRequest](r, validate)
+		if err != nil && len(problems) == 0 {
+			encodeDecodeError(ctx, w, r, logger, err)
+
+			return
+		}
+		if len(problems) > 0 {
+			validationError := "validation failed"
+			logger.ErrorContext(
+				ctx,
+				validationError,
+				slog.Any("validation_errors", problems),
+			)
+			span.SetStatus(codes.Error, validationError)
+			span.RecordError(errors.New(validationError))
+
+			_ = encodeResponse(w, r, http.StatusBadRequest, NewValidationBadRequest(ctx, problems))
+
+			return
+		}
+
+		patch := models.UserPatch{
+			Name:     request.Name,
+			Email:    request.Email,
+			Password: request.Password,
+		}
+
+		// Patch the user
+		user, err := userPatcher.PatchUser(ctx, uint64(id), patch, version)
+		if err != nil {
+			encodeError(ctx, w, r, logger, "failed to patch user", err)
+
+			return
+		}
+
+		// Encode the response model, tagged for the negotiated media type
+		c, _ := responseCodec(r)
+		setValidators(w, versionETag(user.Version, c.mediaType), user.UpdatedAt)
+		_ = encodeResponse(w, r, http.StatusOK, UserResponse{
+			ID:        user.ID,
+			Name:      user.Name,
+			Email:     user.Email,
+			Role:      string(user.Role),
+			CreatedAt: user.CreatedAt,
+			UpdatedAt: user.UpdatedAt,
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/problemtype"
+	"example.com/examples/api/layered/internal/services"
+)
+
+func TestHandlePatchUser(t *testing.T) {
+	email := "john@new.com"
+	jim := "jim"
+	createdAt := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	updatedAt := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		id           string
+		contentType  string
+		ifMatch      string
+		body         string
+		mockUser     models.User
+		mockErr      error
+		wantPatch    *models.UserPatch
+		wantVersion  uint64
+		wantStatus   int
+		wantBody     UserResponse
+		wantETag     string
+		wantProblems []string
+		wantType     string
+	}{
+		"happy path": {
+			id:          "1",
+			contentType: mergePatchMediaType,
+			body:        `{"email":"john@new.com"}`,
+			mockUser: models.User{
+				ID:        1,
+				Name:      "john",
+				Email:     email,
+				Role:      models.RoleUser,
+				CreatedAt: createdAt,
+				UpdatedAt: updatedAt,
+				Version:   2,
+			},
+			wantPatch:  &models.UserPatch{Email: &email},
+			wantStatus: http.StatusOK,
+			wantBody: UserResponse{
+				ID:        1,
+				Name:      "john",
+				Email:     email,
+				Role:      "user",
+				CreatedAt: createdAt,
+				UpdatedAt: updatedAt,
+			},
+			wantETag: `"2-json"`,
+		},
+		"matching version": {
+			id:          "1",
+			contentType: mergePatchMediaType,
+			ifMatch:     `"1-json"`,
+			body:        `{"email":"john@new.com"}`,
+			mockUser:    models.User{ID: 1, Name: "john", Email: email, Role: models.RoleUser, Version: 2},
+			wantPatch:   &models.UserPatch{Email: &email},
+			wantVersion: 1,
+			wantStatus:  http.StatusOK,
+			wantBody:    UserResponse{ID: 1, Name: "john", Email: email, Role: "user"},
+			wantETag:    `"2-json"`,
+		},
+		"stale version": {
+			id:          "1",
+			contentType: mergePatchMediaType,
+			ifMatch:     `"1-json"`,
+			body:        `{"email":"john@new.com"}`,
+			mockErr:     fmt.Errorf("patching: %w", services.ErrVersionMismatch),
+			wantPatch:   &models.UserPatch{Email: &email},
+			wantVersion: 1,
+			wantStatus:  http.StatusPreconditionFailed,
+		},
+		"content type with parameters": {
+			id:          "1",
+			contentType: mergePatchMediaType + "; charset=utf-8",
+			body:        `{}`,
+			mockUser:    models.User{ID: 1, Name: "john", Email: "john@mail.com", Role: models.RoleUser, Version: 1},
+			wantPatch:   &models.UserPatch{},
+			wantStatus:  http.StatusOK,
+			wantBody:    UserResponse{ID: 1, Name: "john", Email: "john@mail.com", Role: "user"},
+			wantETag:    `"1-json"`,
+		},
+		"only present fields are validated": {
+			id:           "1",
+			contentType:  mergePatchMediaType,
+			body:         `{"name":"j"}`,
+			wantStatus:   http.StatusBadRequest,
+			wantProblems: []string{"name"},
+		},
+		"fields cannot be removed": {
+			id:           "1",
+			contentType:  mergePatchMediaType,
+			body:         `{"password":null,"email":null}`,
+			wantStatus:   http.StatusBadRequest,
+			wantProblems: []string{"email", "password"},
+		},
+		"array body": {
+			id:          "1",
+			contentType: mergePatchMediaType,
+			body:        `[]`,
+			wantStatus:  http.StatusBadRequest,
+			wantType:    problemtype.BadRequest.URI(),
+		},
+		"string body": {
+			id:          "1",
+			contentType: mergePatchMediaType,
+			body:        `"x"`,
+			wantStatus:  http.StatusBadRequest,
+			wantType:    problemtype.BadRequest.URI(),
+		},
+		"null body": {
+			id:          "1",
+			contentType: mergePatchMediaType,
+			body:        `null`,
+			wantStatus:  http.StatusBadRequest,
+			wantType:    problemtype.BadRequest.URI(),
+		},
+		"truncated json": {
+			id:          "1",
+			contentType: mergePatchMediaType,
+			body:        `{"name":"ji`,
+			wantStatus:  http.StatusBadRequest,
+			wantType:    problemtype.BadRequest.URI(),
+		},
+		"plain json is unsupported": {
+			id:          "1",
+			contentType: "application/json",
+			body:        `{"email":"john@new.com"}`,
+			wantStatus:  http.StatusUnsupportedMediaType,
+		},
+		"invalid id": {
+			id:          "abc",
+			contentType: mergePatchMediaType,
+			body:        `{}`,
+			wantStatus:  http.StatusBadRequest,
+		},
+		"user not found": {
+			id:          "9",
+			contentType: mergePatchMediaType,
+			body:        `{"name":"jim"}`,
+			mockErr:     fmt.Errorf("patching: %w", services.ErrUserNotFound),
+			wantPatch:   &models.UserPatch{Name: &jim},
+			wantStatus:  http.StatusNotFound,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(
+			name, func(t *testing.T) {
+				// Create a new request
+				req := httptest.NewRequest(http.MethodPatch, "/user/"+tc.id, strings.NewReader(tc.body))
+				req.Header.Set("Content-Type", tc.contentType)
+				if tc.ifMatch != "" {
+					req.Header.Set("If-Match", tc.ifMatch)
+				}
+				req.SetPathValue("id", tc.id)
+
+				// Create a new response recorder
+				rec := httptest.NewRecorder()
+
+				// Create a new ctxhandler
+				logger := slog.Default()
+
+				mockedUserPatcher := &moquserPatcher{
+					PatchUserFunc: func(
+						_ context.Context,
+						_ uint64,
+						patch models.UserPatch,
+						version uint64,
+					) (models.User, error) {
+						assert.Equal(t, *tc.wantPatch, patch)
+						assert.Equal(t, tc.wantVersion, version)
+
+						return tc.mockUser, tc.mockErr
+					},
+				}
+
+				// Call the handler
+				handler := HandlePatchUser(logger, newTestValidator(t), mockedUserPatcher)
+
+				handler.ServeHTTP(rec, req)
+				// Check the status code
+				assert.Equal(t, tc.wantStatus, rec.Code)
+				assert.Equal(t, tc.wantETag, rec.Header().Get("ETag"))
+
+				// Check the body
+				switch tc.wantStatus {
+				case http.StatusOK:
+					var respBody UserResponse
+					_ = json.Unmarshal(rec.Body.Bytes(), &respBody)
+
+					assert.Equal(t, tc.wantBody, respBody)
+				case http.StatusBadRequest:
+					var respBody ProblemDetailValidation
+					_ = json.Unmarshal(rec.Body.Bytes(), &respBody)
+
+					fields := make([]string, 0, len(respBody.InvalidParams))
+					for _, problem := range respBody.InvalidParams {
+						fields = append(fields, problem.Field)
+					}
+					assert.ElementsMatch(t, tc.wantProblems, fields)
+					if tc.wantType != "" {
+						assert.Equal(t, tc.wantType, respBody.Type)
+					}
+				case http.StatusUnsupportedMediaType:
+					assert.Equal(t, mergePatchMediaType, rec.Header().Get("Accept-Patch"))
+				}
+
+				if tc.wantPatch == nil {
+					assert.Empty(t, mockedUserPatcher.PatchUserCalls())
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"go.opentelemetry.io/otel/codes"
+
+	"example.com/examples/api/layered/internal/middleware"
+	"example.com/examples/api/layered/internal/models"
+)
+
+// blogReader represents a type capable of reading a blog from storage and
+// returning it or an error.
+type blogReader interface {
+	ReadBlog(ctx context.Context, id uint64) (models.Blog, error)
+}
+
+// HandleReadBlog handles the reading of a blog by ID.
+//
+//	@Summary		Read Blog
+//	@Description	Read Blog by ID
+//	@Tags			blog
+//	@Accept			json
+//	@Produce		json
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
+//	@Param			id	path		string	true	"Blog ID"
+//	@Success		200	{object}	BlogResponse
+//	@Failure		400	{object}	ProblemDetail
+//	@Failure		404	{object}	ProblemDetail
+//	@Failure		406	{object}	ProblemDetail
+//	@Failure		429	{object}	ProblemDetail
+//	@Failure		500	{object}	ProblemDetail
+//	@Router			/blog/{id}  [GET]
+func HandleReadBlog(logger *slog.Logger, blogReader blogReader) http.HandlerFunc {
+	const name = "handlers.HandleReadBlog"
+	logger = logger.With(slog.String("func", name))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracer.Start(r.Context(), name)
+		defer span.End()
+
+		// Read id from path parameters
+		idStr := r.PathValue("id")
+
+		// Convert the ID from string to int
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to parse id from url",
+				slog.String("id", idStr),
+				slog.String("error", err.Error()),
+			)
+			span.SetStatus(codes.Error, "ID conversion failed")
+			span.RecordError(err)
+
+			_ = encodeResponse(w, r, http.StatusBadRequest, ProblemDetail{
+				Title:   "Invalid ID",
+				Status:  http.StatusBadRequest,
+				Detail:  "The provided ID is not a valid integer.",
+				TraceID: middleware.GetTraceID(ctx),
+			})
+
+			return
+		}
+
+		// Read the blog
+		blog, err := blogReader.ReadBlog(ctx, uint64(id))
+		if err != nil {
+			encodeError(ctx, w, r, logger, "failed to read blog", err)
+
+			return
+		}
+
+		// Encode the response model as JSON
+		_ = encodeResponse(w, r, http.StatusOK, BlogResponse{
+			ID:       blog.ID,
+			AuthorID: blog.AuthorID,
+			Title:    blog.Title,
+			Score:    blog.Score,
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/services"
+)
+
+func TestHandleReadBlog(t *testing.T) {
+	tests := map[string]struct {
+		id         string
+		mockBlog   models.Blog
+		mockErr    error
+		wantStatus int
+		wantBody   BlogResponse
+	}{
+		"happy path": {
+			id:         "1",
+			mockBlog:   models.Blog{ID: 1, AuthorID: 1, Title: "First Blog Post", Score: 8.5},
+			wantStatus: http.StatusOK,
+			wantBody:   BlogResponse{ID: 1, AuthorID: 1, Title: "First Blog Post", Score: 8.5},
+		},
+		"invalid id": {
+			id:         "abc",
+			wantStatus: http.StatusBadRequest,
+		},
+		"not found": {
+			id:         "2",
+			mockErr:    fmt.Errorf("blog 2: %w", services.ErrBlogNotFound),
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(
+			name, func(t *testing.T) {
+				// Create a new request
+				req := httptest.NewRequest(http.MethodGet, "/blog/"+tc.id, nil)
+				req.SetPathValue("id", tc.id)
+
+				// Create a new response recorder
+				rec := httptest.NewRecorder()
+
+				// Create a new ctxhandler
+				logger := slog.Default()
+
+				mockedBlogReader := &moqblogReader{
+					ReadBlogFunc: func(_ context.Context, _ uint64) (models.Blog, error) {
+						return tc.mockBlog, tc.mockErr
+					},
+				}
+
+				// Call the handler
+				handler := HandleReadBlog(logger, mockedBlogReader)
+
+				handler.ServeHTTP(rec, req)
+				// Check the status code
+				assert.Equal(t, tc.wantStatus, rec.Code)
+
+				// Check the body
+				if tc.wantStatus == http.StatusOK {
+					var respBody BlogResponse
+					_ = json.Unmarshal(rec.Body.Bytes(), &respBody)
+
+					assert.Equal(t, tc.wantBody, respBody)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"html/template"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"go.opentelemetry.io/otel/codes"
+
+	"example.com/examples/api/layered/internal/problemtype"
+)
+
+// problemTypeTemplate renders the documentation of a problem type.
+var problemTypeTemplate = template.Must(template.New("problem_type").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Type.Title}} ({{.Type.Slug}})</title>
+</head>
+<body>
+<h1>{{.Type.Title}}</h1>
+<dl>
+<dt>Type</dt><dd><code>{{.Type.URI}}</code></dd>
+<dt>Status</dt><dd>{{.Type.Status}}</dd>
+</dl>
+<p>{{.Type.Description}}</p>
+<h2>Members</h2>
+<p>Besides <code>type</code>, <code>title</code>, <code>status</code>, <code>detail</code> and
+<code>instance</code>, problems of this type carry:</p>
+<dl>
+{{- range .Extensions}}
+<dt><code>{{.Name}}</code></dt><dd>{{.Description}}</dd>
+{{- end}}
+</dl>
+<p><a href="{{.Index}}">All problem types</a></p>
+</body>
+</html>
+`))
+
+// HandleReadProblemType handles the documentation of a problem type, which is
+// served at the type's URI.
+//
+//	@Summary		Read Problem Type
+//	@Description	Human-readable documentation of a problem type
+//	@Tags			problem
+//	@Produce		html
+//	@Param			type	path		string	true	"Problem type slug"
+//	@Success		200		{string}	string
+//	@Failure		404		{object}	ProblemDetail
+//	@Failure		429		{object}	ProblemDetail
+//	@Router			/problems/{type}  [GET]
+func HandleReadProblemType(logger *slog.Logger) http.HandlerFunc {
+	const name = "handlers.HandleReadProblemType"
+	logger = logger.With(slog.String("func", name))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracer.Start(r.Context(), name)
+		defer span.End()
+
+		t, ok := problemtype.Lookup(r.PathValue("type"))
+		if !ok {
+			_ = encodeResponse(w, r, http.StatusNotFound, newProblem(
+				ctx,
+				problemtype.NotFound,
+				"The problem type does not exist.",
+			))
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		err := problemTypeTemplate.Execute(w, struct {
+			Type       problemtype.Type
+			Extensions []problemtype.Extension
+			Index      string
+		}{
+			Type:       t,
+			Extensions: slices.Concat(t.Extensions, []problemtype.Extension{problemtype.TraceID}),
+			Index:      problemtype.BasePath,
+		})
+		if err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to render problem type",
+				slog.String("error", err.Error()),
+			)
+			span.SetStatus(codes.Error, "render failed")
+			span.RecordError(err)
+		}
+	}
+}
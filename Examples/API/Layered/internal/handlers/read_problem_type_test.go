@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleReadProblemType(t *testing.T) {
+	tests := map[string]struct {
+		slug         string
+		wantStatus   int
+		wantContains []string
+	}{
+		"registered type": {
+			slug:         "conflict",
+			wantStatus:   http.StatusOK,
+			wantContains: []string{"<h1>Conflict</h1>", "/api/problems/conflict", "invalidParams", "traceId"},
+		},
+		"unknown type": {
+			slug:       "does-not-exist",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/problems/"+tc.slug, nil)
+			req.SetPathValue("type", tc.slug)
+			rec := httptest.NewRecorder()
+
+			HandleReadProblemType(slog.Default()).ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.wantStatus, rec.Code)
+			if tc.wantStatus != http.StatusOK {
+				var problem ProblemDetail
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+				assert.Equal(t, "/api/problems/not-found", problem.Type)
+
+				return
+			}
+
+			assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+			for _, want := range tc.wantContains {
+				assert.Contains(t, rec.Body.String(), want)
+			}
+		})
+	}
+}
@@ -18,18 +18,34 @@ type userReader interface {
 	ReadUser(ctx context.Context, id uint64) (models.User, error)
 }
 
-// HandleReadUser handles the reading of a user by ID.
+// HandleReadUser handles the reading of a user by ID. The user is returned with
+// its ETag and Last-Modified validators, and a conditional request whose copy
+// is still current is answered with 304 Not Modified. The validators come from
+// the cached user, so a warm cache answers without touching the database.
 //
 //	@Summary		Read User
 //	@Description	Read User by ID
 //	@Tags			user
 //	@Accept			json
 //	@Produce		json
-//	@Param			id	path		string	true	"User ID"
-//	@Success		200	{object}	models.User
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
+//	@Param			id					path		string	true	"User ID"
+//	@Param			If-None-Match		header		string	false	"ETag of the copy held by the client"
+//	@Param			If-Modified-Since	header		string	false	"Last-Modified of the copy held by the client"
+//	@Success		200					{object}	models.User
+//	@Header			200					{string}	ETag			"Version of the user"
+//	@Header			200					{string}	Last-Modified	"Time of the last update of the user"
+//	@Success		304
 //	@Failure		400	{object}	string
+//	@Failure		401	{object}	string
 //	@Failure		404	{object}	string
+//	@Failure		406	{object}	ProblemDetail
+//	@Failure		429	{object}	ProblemDetail
 //	@Failure		500	{object}	string
+//	@Failure		503	{object}	ProblemDetail
+//	@Security		BearerAuth
 //	@Router			/user/{id}  [GET]
 func HandleReadUser(logger *slog.Logger, userReader userReader) http.HandlerFunc {
 	const name = "handlers.HandleReadUser"
@@ -54,7 +70,7 @@ func HandleReadUser(logger *slog.Logger, userReader userReader) http.HandlerFunc
 			span.SetStatus(codes.Error, "ID conversion failed")
 			span.RecordError(err)
 
-			_ = encodeResponseJSON(w, http.StatusBadRequest, ProblemDetail{
+			_ = encodeResponse(w, r, http.StatusBadRequest, ProblemDetail{
 				Title:   "Invalid ID",
 				Status:  http.StatusBadRequest,
 				Detail:  "The provided ID is not a valid integer.",
@@ -64,27 +80,38 @@ func HandleReadUser(logger *slog.Logger, userReader userReader) http.HandlerFunc
 			return
 		}
 
+		// Content negotiation, before the validators of the negotiated
+		// representation are set
+		if !acceptable(w, r) {
+			return
+		}
+
 		// Read the user
 		user, err := userReader.ReadUser(ctx, uint64(id))
 		if err != nil {
-			logger.ErrorContext(
-				ctx,
-				"failed to read user",
-				slog.String("error", err.Error()),
-			)
-			span.SetStatus(codes.Error, "reading user failed")
-			span.RecordError(err)
+			encodeError(ctx, w, r, logger, "failed to read user", err)
+
+			return
+		}
 
-			_ = encodeResponseJSON(w, http.StatusInternalServerError, NewInternalServerError(ctx))
+		// Answer from the validators alone if the client's copy is current
+		c, _ := responseCodec(r)
+		etag := versionETag(user.Version, c.mediaType)
+		setValidators(w, etag, user.UpdatedAt)
+		if notModified(r, etag, user.UpdatedAt) {
+			w.WriteHeader(http.StatusNotModified)
 
 			return
 		}
 
 		// Encode the response model as JSON
-		_ = encodeResponseJSON(w, http.StatusOK, UserResponse{
-			ID:    user.ID,
-			Name:  user.Name,
-			Email: user.Email,
+		_ = encodeResponse(w, r, http.StatusOK, UserResponse{
+			ID:        user.ID,
+			Name:      user.Name,
+			Email:     user.Email,
+			Role:      string(user.Role),
+			CreatedAt: user.CreatedAt,
+			UpdatedAt: user.UpdatedAt,
 		})
 	}
 }
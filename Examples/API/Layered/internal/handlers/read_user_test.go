@@ -3,20 +3,35 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
+	"example.com/examples/api/layered/internal/errs"
 	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/services"
 )
 
 func TestHandleReadUser(t *testing.T) {
+	updatedAt := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	user := models.User{ID: 1, Name: "john", Email: "john@mail.com", UpdatedAt: updatedAt, Version: 3}
+
 	tests := map[string]struct {
-		wantStatus int
-		wantBody   models.User
+		accept           string
+		ifNoneMatch      string
+		ifModifiedSince  string
+		mockUser         *models.User
+		mockErr          error
+		wantStatus       int
+		wantBody         models.User
+		wantETag         string
+		wantLastModified string
 	}{
 		"happy path": {
 			wantStatus: 200,
@@ -25,7 +40,66 @@ func TestHandleReadUser(t *testing.T) {
 				Name:     "john",
 				Email:    "john@mail.com",
 				Password: "password123!",
+				Version:  3,
 			},
+			wantETag: `"3-json"`,
+		},
+		"validators": {
+			mockUser:         &user,
+			wantStatus:       http.StatusOK,
+			wantBody:         user,
+			wantETag:         `"3-json"`,
+			wantLastModified: "Wed, 01 May 2024 12:30:00 GMT",
+		},
+		"current etag": {
+			ifNoneMatch:      `"3-json"`,
+			mockUser:         &user,
+			wantStatus:       http.StatusNotModified,
+			wantETag:         `"3-json"`,
+			wantLastModified: "Wed, 01 May 2024 12:30:00 GMT",
+		},
+		"stale etag": {
+			ifNoneMatch:      `"2-json"`,
+			mockUser:         &user,
+			wantStatus:       http.StatusOK,
+			wantBody:         user,
+			wantETag:         `"3-json"`,
+			wantLastModified: "Wed, 01 May 2024 12:30:00 GMT",
+		},
+		"xml representation": {
+			accept:           "application/xml",
+			mockUser:         &user,
+			wantStatus:       http.StatusOK,
+			wantETag:         `"3-xml"`,
+			wantLastModified: "Wed, 01 May 2024 12:30:00 GMT",
+		},
+		"etag of another representation": {
+			accept:           "application/xml",
+			ifNoneMatch:      `"3-json"`,
+			mockUser:         &user,
+			wantStatus:       http.StatusOK,
+			wantETag:         `"3-xml"`,
+			wantLastModified: "Wed, 01 May 2024 12:30:00 GMT",
+		},
+		"not acceptable": {
+			accept:     "text/html",
+			mockUser:   &user,
+			wantStatus: http.StatusNotAcceptable,
+		},
+		"not modified since": {
+			ifModifiedSince:  "Wed, 01 May 2024 12:30:00 GMT",
+			mockUser:         &user,
+			wantStatus:       http.StatusNotModified,
+			wantETag:         `"3-json"`,
+			wantLastModified: "Wed, 01 May 2024 12:30:00 GMT",
+		},
+		"not found": {
+			mockErr:    fmt.Errorf("user 1: %w", services.ErrUserNotFound),
+			wantStatus: http.StatusNotFound,
+		},
+		"cache unavailable": {
+			mockErr:    errs.E(errs.Unavailable, errors.New("connection refused")),
+			wantStatus: http.StatusServiceUnavailable,
 		},
 	}
 	for name, tc := range tests {
@@ -34,6 +108,15 @@ func TestHandleReadUser(t *testing.T) {
 				// Create a new request
 				req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
 				req.SetPathValue("id", "1")
+				if tc.accept != "" {
+					req.Header.Set("Accept", tc.accept)
+				}
+				if tc.ifNoneMatch != "" {
+					req.Header.Set("If-None-Match", tc.ifNoneMatch)
+				}
+				if tc.ifModifiedSince != "" {
+					req.Header.Set("If-Modified-Since", tc.ifModifiedSince)
+				}
 
 				// Create a new response recorder
 				rec := httptest.NewRecorder()
@@ -43,6 +126,13 @@ func TestHandleReadUser(t *testing.T) {
 
 				mockedUserReader := &moquserReader{
 					ReadUserFunc: func(ctx context.Context, id uint64) (models.User, error) {
+						if tc.mockErr != nil {
+							return models.User{}, tc.mockErr
+						}
+						if tc.mockUser != nil {
+							return *tc.mockUser, nil
+						}
+
 						return tc.wantBody, nil
 					},
 				}
@@ -52,6 +142,11 @@ func TestHandleReadUser(t *testing.T) {
 				handler.ServeHTTP(rec, req)
 				// Check the status code
 				assert.Equal(t, tc.wantStatus, rec.Code)
+				assert.Equal(t, tc.wantETag, rec.Header().Get("ETag"))
+				assert.Equal(t, tc.wantLastModified, rec.Header().Get("Last-Modified"))
+				if tc.wantStatus == http.StatusNotModified {
+					assert.Empty(t, rec.Body.String())
+				}
 
 				// Check the body
 				type usersResponse struct {
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"go.opentelemetry.io/otel/codes"
+
+	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/validation"
+)
+
+// tokenRefresher represents a type capable of exchanging a refresh token for
+// a new token pair or returning an error.
+type tokenRefresher interface {
+	Refresh(ctx context.Context, refreshToken string) (models.TokenPair, error)
+}
+
+// HandleRefreshToken handles exchanging a refresh token for new access and
+// refresh tokens.
+//
+//	@Summary		Refresh Token
+//	@Description	Exchange a refresh token for new access and refresh tokens
+//	@Tags			auth
+//	@Accept			json
+//	@Accept			xml
+//	@Accept			application/msgpack
+//	@Accept			application/cbor
+//	@Produce		json
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
+//	@Param			request	body		RefreshRequest	true	"Refresh Token"
+//	@Success		200		{object}	TokenResponse
+//	@Failure		400		{object}	ProblemDetailValidation
+//	@Failure		401		{object}	ProblemDetail
+//	@Failure		406		{object}	ProblemDetail
+//	@Failure		415		{object}	ProblemDetail
+//	@Failure		429		{object}	ProblemDetail
+//	@Failure		500		{object}	ProblemDetail
+//	@Router			/auth/refresh  [POST]
+func HandleRefreshToken(
+	logger *slog.Logger,
+	validate *validation.Validator,
+	tokenRefresher tokenRefresher,
+) http.HandlerFunc {
+	const name = "handlers.HandleRefreshToken"
+	logger = logger.With(slog.String("func", name))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracer.Start(r.Context(), name)
+		defer span.End()
+
+		// Content negotiation
+		if !acceptable(w, r) || !decodable(w, r) {
+			return
+		}
+
+		// Request validation
+		request, problems, err := decodeValid[RefreshRequest](r, validate)
+		if err != nil && len(problems) == 0 {
+			encodeDecodeError(ctx, w, r, logger, err)
+
+			return
+		}
+		if len(problems) > 0 {
+			validationError := "validation failed"
+			logger.ErrorContext(
+				ctx,
+				validationError,
+				slog.Any("validation_errors", problems),
+			)
+			span.SetStatus(codes.Error, validationError)
+			span.RecordError(errors.New(validationError))
+
+			_ = encodeResponse(w, r, http.StatusBadRequest, NewValidationBadRequest(ctx, problems))
+
+			return
+		}
+
+		// Exchange the refresh token
+		tokens, err := tokenRefresher.Refresh(ctx, request.RefreshToken)
+		if err != nil {
+			encodeError(ctx, w, r, logger, "failed to refresh tokens", err)
+
+			return
+		}
+
+		_ = encodeResponse(w, r, http.StatusOK, newTokenResponse(tokens))
+	}
+}
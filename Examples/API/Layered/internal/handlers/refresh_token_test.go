@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/services"
+)
+
+func TestHandleRefreshToken(t *testing.T) {
+	expiresAt := time.Date(2024, 5, 15, 12, 15, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		input      RefreshRequest
+		mockCalled bool
+		mockTokens models.TokenPair
+		mockErr    error
+		wantStatus int
+		wantBody   TokenResponse
+	}{
+		"happy path": {
+			input:      RefreshRequest{RefreshToken: "refresh"},
+			mockCalled: true,
+			mockTokens: models.TokenPair{
+				AccessToken:           "new-access",
+				AccessTokenExpiresAt:  expiresAt,
+				RefreshToken:          "new-refresh",
+				RefreshTokenExpiresAt: expiresAt.Add(time.Hour),
+			},
+			wantStatus: http.StatusOK,
+			wantBody: TokenResponse{
+				TokenType:             "Bearer",
+				AccessToken:           "new-access",
+				AccessTokenExpiresAt:  expiresAt,
+				RefreshToken:          "new-refresh",
+				RefreshTokenExpiresAt: expiresAt.Add(time.Hour),
+			},
+		},
+		"missing token": {
+			input:      RefreshRequest{},
+			mockCalled: false,
+			wantStatus: http.StatusBadRequest,
+		},
+		"invalid token": {
+			input:      RefreshRequest{RefreshToken: "expired"},
+			mockCalled: true,
+			mockErr:    fmt.Errorf("refresh: %w", services.ErrInvalidToken),
+			wantStatus: http.StatusUnauthorized,
+		},
+		"service error": {
+			input:      RefreshRequest{RefreshToken: "refresh"},
+			mockCalled: true,
+			mockErr:    errors.New("db down"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(
+			name, func(t *testing.T) {
+				// Create a new request
+				reqBody, _ := json.Marshal(tc.input)
+				req := httptest.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewBuffer(reqBody))
+
+				// Create a new response recorder
+				rec := httptest.NewRecorder()
+
+				// Create a new ctxhandler
+				logger := slog.Default()
+
+				mockedTokenRefresher := &moqtokenRefresher{
+					RefreshFunc: func(_ context.Context, refreshToken string) (models.TokenPair, error) {
+						assert.Equal(t, tc.input.RefreshToken, refreshToken)
+
+						return tc.mockTokens, tc.mockErr
+					},
+				}
+
+				// Call the handler
+				handler := HandleRefreshToken(logger, newTestValidator(t), mockedTokenRefresher)
+
+				handler.ServeHTTP(rec, req)
+				// Check the status code
+				assert.Equal(t, tc.wantStatus, rec.Code)
+				assert.Equal(t, tc.mockCalled, len(mockedTokenRefresher.RefreshCalls()) == 1)
+
+				// Check the body
+				if tc.wantStatus == http.StatusOK {
+					var respBody TokenResponse
+					_ = json.Unmarshal(rec.Body.Bytes(), &respBody)
+
+					assert.Equal(t, tc.wantBody, respBody)
+				}
+			},
+		)
+	}
+}
@@ -1,20 +1,100 @@
 package handlers
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
+
+	"example.com/examples/api/layered/internal/i18n"
+	"example.com/examples/api/layered/internal/problemtype"
 )
 
-// encodeResponseJSON encodes data as a JSON response.
+// encodeResponse encodes data in the media type the request's Accept header
+// prefers. A successful response to a request that accepts none of our media
+// types is replaced by a 406 problem detail, while error responses are still
+// sent, as JSON, since an error the client did not ask for beats none at all.
+// Problem details are completed by withProblemMembers and sent with the
+// problem variant of the media type, such as application/problem+json.
 // It is important to note that once w.WriteHeader is called, the response headers are sent.
 // Any subsequent calls to w.WriteHeader will have no effect.
-func encodeResponseJSON(w http.ResponseWriter, status int, data any) error {
-	w.Header().Set("Content-Type", "application/json")
+func encodeResponse(w http.ResponseWriter, r *http.Request, status int, data any) error {
+	w.Header().Add("Vary", "Accept")
+
+	c, ok := responseCodec(r)
+	if !ok {
+		c = codecs[0]
+		if status < http.StatusBadRequest {
+			status, data = http.StatusNotAcceptable, newNotAcceptableResponse(r)
+		}
+	}
+
+	mediaType := c.mediaType
+	if problem, ok := withProblemMembers(w, r, status, data); ok {
+		data, mediaType = problem, c.problemMediaType
+	}
+
+	w.Header().Set("Content-Type", mediaType)
 	w.WriteHeader(status)
 
-	if err := json.NewEncoder(w).Encode(data); err != nil {
+	if err := c.encode(w, data); err != nil {
 		return fmt.Errorf("failed to encode response: %w", err)
 	}
 	return nil
 }
+
+// acceptable reports whether a request accepts one of the media types of our
+// codecs, and writes a 406 problem detail if it does not. Handlers that
+// change state check it first, so that a change is not made only for its
+// response to be refused, as do handlers that set validators, which describe
+// the negotiated representation.
+func acceptable(w http.ResponseWriter, r *http.Request) bool {
+	if _, ok := responseCodec(r); ok {
+		return true
+	}
+
+	_ = encodeResponse(w, r, http.StatusNotAcceptable, newNotAcceptableResponse(r))
+
+	return false
+}
+
+// newNotAcceptableResponse creates the 406 problem detail for a request that
+// accepts none of the media types of our codecs.
+func newNotAcceptableResponse(r *http.Request) ProblemDetail {
+	return NewNotAcceptable(
+		r.Context(),
+		fmt.Sprintf("The response can only be encoded as one of %v.", codecMediaTypes()),
+	)
+}
+
+// withProblemMembers fills in the type and instance members of a problem
+// detail left empty by its handler: the type from the status code, and the
+// instance from the path of the request. Its title is translated into the
+// language the Accept-Language header of the request prefers, which is sent
+// as Content-Language. The boolean is false if data is not a problem detail.
+func withProblemMembers(w http.ResponseWriter, r *http.Request, status int, data any) (any, bool) {
+	complete := func(p *ProblemDetail) {
+		trans := i18n.FromRequest(r)
+		w.Header().Add("Vary", "Accept-Language")
+		w.Header().Set("Content-Language", trans.Locale())
+
+		p.Title = i18n.Title(trans, p.Title)
+		if p.Type == "" {
+			p.Type = problemtype.ForStatus(status).URI()
+		}
+		if p.Instance == "" {
+			p.Instance = r.URL.Path
+		}
+	}
+
+	switch p := data.(type) {
+	case ProblemDetail:
+		complete(&p)
+
+		return p, true
+	case ProblemDetailValidation:
+		complete(&p.ProblemDetail)
+
+		return p, true
+	default:
+		return data, false
+	}
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"go.opentelemetry.io/otel/codes"
+
+	"example.com/examples/api/layered/internal/middleware"
+	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/validation"
+)
+
+// blogUpdater represents a type capable of updating a blog and returning it
+// or an error.
+type blogUpdater interface {
+	UpdateBlog(ctx context.Context, id uint64, patch models.Blog) (models.Blog, error)
+}
+
+// HandleUpdateBlog handles the updating of an existing blog by ID. Only its
+// title changes; a blog keeps its author.
+//
+//	@Summary		Update Blog
+//	@Description	Update Blog by ID
+//	@Tags			blog
+//	@Accept			json
+//	@Accept			xml
+//	@Accept			application/msgpack
+//	@Accept			application/cbor
+//	@Produce		json
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
+//	@Param			id		path		string		true	"Blog ID"
+//	@Param			request	body		BlogRequest	true	"Blog to Update"
+//	@Success		200		{object}	BlogResponse
+//	@Failure		400		{object}	ProblemDetailValidation
+//	@Failure		401		{object}	ProblemDetail
+//	@Failure		403		{object}	ProblemDetail
+//	@Failure		404		{object}	ProblemDetail
+//	@Failure		406		{object}	ProblemDetail
+//	@Failure		415		{object}	ProblemDetail
+//	@Failure		422		{object}	ProblemDetailValidation
+//	@Failure		429		{object}	ProblemDetail
+//	@Failure		500		{object}	ProblemDetail
+//	@Security		BearerAuth
+//	@Router			/blog/{id}  [PUT]
+func HandleUpdateBlog(
+	logger *slog.Logger,
+	validate *validation.Validator,
+	blogUpdater blogUpdater,
+) http.HandlerFunc {
+	const name = "handlers.HandleUpdateBlog"
+	logger = logger.With(slog.String("func", name))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracer.Start(r.Context(), name)
+		defer span.End()
+
+		// Read id from path parameters
+		idStr := r.PathValue("id")
+
+		// Convert the ID from string to int
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to parse id from url",
+				slog.String("id", idStr),
+				slog.String("error", err.Error()),
+			)
+			span.SetStatus(codes.Error, "ID conversion failed")
+			span.RecordError(err)
+
+			_ = encodeResponse(w, r, http.StatusBadRequest, ProblemDetail{
+				Title:   "Invalid ID",
+				Status:  http.StatusBadRequest,
+				Detail:  "The provided ID is not a valid integer.",
+				TraceID: middleware.GetTraceID(ctx),
+			})
+
+			return
+		}
+
+		// Content negotiation
+		if !acceptable(w, r) || !decodable(w, r) {
+			return
+		}
+
+		// Request validation
+		request, problems, err := decodeValid[BlogRequest](r, validate)
+		if err != nil && len(problems) == 0 {
+			encodeDecodeError(ctx, w, r, logger, err)
+
+			return
+		}
+		if len(problems) > 0 {
+			validationError := "validation failed"
+			logger.ErrorContext(
+				ctx,
+				validationError,
+				slog.Any("validation_errors", problems),
+			)
+			span.SetStatus(codes.Error, validationError)
+			span.RecordError(errors.New(validationError))
+
+			_ = encodeResponse(w, r, http.StatusBadRequest, NewValidationBadRequest(ctx, problems))
+
+			return
+		}
+
+		modelRequest := models.Blog{
+			Title: request.Title,
+		}
+
+		// Update the blog
+		blog, err := blogUpdater.UpdateBlog(ctx, uint64(id), modelRequest)
+		if err != nil {
+			encodeError(ctx, w, r, logger, "failed to update blog", err)
+
+			return
+		}
+
+		// Encode the response model as JSON
+		_ = encodeResponse(w, r, http.StatusOK, BlogResponse{
+			ID:       blog.ID,
+			AuthorID: blog.AuthorID,
+			Title:    blog.Title,
+			Score:    blog.Score,
+		})
+	}
+}
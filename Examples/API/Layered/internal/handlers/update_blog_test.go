@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/services"
+)
+
+func TestHandleUpdateBlog(t *testing.T) {
+	tests := map[string]struct {
+		body       string
+		mockBlog   models.Blog
+		mockErr    error
+		wantStatus int
+		wantBody   BlogResponse
+	}{
+		"happy path": {
+			body:       `{"title":"Updated Title"}`,
+			mockBlog:   models.Blog{ID: 1, AuthorID: 1, Title: "Updated Title", Score: 8.5},
+			wantStatus: http.StatusOK,
+			wantBody:   BlogResponse{ID: 1, AuthorID: 1, Title: "Updated Title", Score: 8.5},
+		},
+		"naming another author": {
+			// The blog keeps its author
+			body:       `{"authorId":3,"title":"Updated Title"}`,
+			mockBlog:   models.Blog{ID: 1, AuthorID: 1, Title: "Updated Title", Score: 8.5},
+			wantStatus: http.StatusOK,
+			wantBody:   BlogResponse{ID: 1, AuthorID: 1, Title: "Updated Title", Score: 8.5},
+		},
+		"missing title": {
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		"blog not found": {
+			body:       `{"title":"Updated Title"}`,
+			mockErr:    fmt.Errorf("blog 1: %w", services.ErrBlogNotFound),
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(
+			name, func(t *testing.T) {
+				// Create a new request
+				req := httptest.NewRequest(http.MethodPut, "/blog/1", strings.NewReader(tc.body))
+				req.SetPathValue("id", "1")
+
+				// Create a new response recorder
+				rec := httptest.NewRecorder()
+
+				// Create a new ctxhandler
+				logger := slog.Default()
+
+				mockedBlogUpdater := &moqblogUpdater{
+					UpdateBlogFunc: func(
+						_ context.Context,
+						_ uint64,
+						patch models.Blog,
+					) (models.Blog, error) {
+						assert.Equal(t, models.Blog{Title: "Updated Title"}, patch)
+
+						return tc.mockBlog, tc.mockErr
+					},
+				}
+
+				// Call the handler
+				handler := HandleUpdateBlog(logger, newTestValidator(t), mockedBlogUpdater)
+
+				handler.ServeHTTP(rec, req)
+				// Check the status code
+				assert.Equal(t, tc.wantStatus, rec.Code)
+
+				// Check the body
+				if tc.wantStatus == http.StatusOK {
+					var respBody BlogResponse
+					_ = json.Unmarshal(rec.Body.Bytes(), &respBody)
+
+					assert.Equal(t, tc.wantBody, respBody)
+				}
+			},
+		)
+	}
+}
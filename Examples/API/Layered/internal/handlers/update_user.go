@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -11,29 +12,53 @@ import (
 
 	"example.com/examples/api/layered/internal/middleware"
 	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/validation"
 )
 
 // userUpdater represents a type capable of updating a user and
 // returning it or an error.
 type userUpdater interface {
-	UpdateUser(ctx context.Context, id uint64, patch models.User) (models.User, error)
+	UpdateUser(ctx context.Context, id uint64, patch models.User, version uint64) (models.User, error)
 }
 
-// HandleUpdateUser handles the updating of an existing user by ID.
+// HandleUpdateUser handles the updating of an existing user by ID. If the
+// request has an If-Match header, the user is only updated while its ETag
+// matches.
 //
 //	@Summary		Update User
 //	@Description	Update User by ID
 //	@Tags			user
 //	@Accept			json
+//	@Accept			xml
+//	@Accept			application/msgpack
+//	@Accept			application/cbor
 //	@Produce		json
-//	@Param			id		path		string		true	"User ID"
-//	@Param			request	body		UserRequest	true	"User to Create"
-//	@Success		200		{object}	models.User
-//	@Failure		400		{object}	string
-//	@Failure		404		{object}	string
-//	@Failure		500		{object}	string
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
+//	@Param			id			path		string		true	"User ID"
+//	@Param			request		body		UserRequest	true	"User to Create"
+//	@Param			If-Match	header		string		false	"ETag of the user version to replace"
+//	@Success		200			{object}	models.User
+//	@Header			200			{string}	ETag	"Version of the updated user"
+//	@Failure		400			{object}	string
+//	@Failure		401			{object}	string
+//	@Failure		403			{object}	string
+//	@Failure		404			{object}	string
+//	@Failure		406			{object}	ProblemDetail
+//	@Failure		412			{object}	ProblemDetail
+//	@Failure		409			{object}	ProblemDetailValidation
+//	@Failure		415			{object}	ProblemDetail
+//	@Failure		429			{object}	ProblemDetail
+//	@Failure		500			{object}	string
+//	@Failure		503			{object}	ProblemDetail
+//	@Security		BearerAuth
 //	@Router			/user/{id}  [PUT]
-func HandleUpdateUser(logger *slog.Logger, userUpdater userUpdater) http.HandlerFunc {
+func HandleUpdateUser(
+	logger *slog.Logger,
+	validate *validation.Validator,
+	userUpdater userUpdater,
+) http.HandlerFunc {
 	const name = "handlers.HandleUpdateUser"
 	logger = logger.With(slog.String("func", name))
 
@@ -56,7 +81,7 @@ func HandleUpdateUser(logger *slog.Logger, userUpdater userUpdater) http.Handler
 			span.SetStatus(codes.Error, "ID conversion failed")
 			span.RecordError(err)
 
-			_ = encodeResponseJSON(w, http.StatusBadRequest, ProblemDetail{
+			_ = encodeResponse(w, r, http.StatusBadRequest, ProblemDetail{
 				Title:   "Invalid ID",
 				Status:  http.StatusBadRequest,
 				Detail:  "The provided ID is not a valid integer.",
@@ -66,17 +91,26 @@ func HandleUpdateUser(logger *slog.Logger, userUpdater userUpdater) http.Handler
 			return
 		}
 
-		// Request validation
-		request, problems, err := decodeValid[UserRequest](r)
-		if err != nil && len(problems) == 0 {
-			logger.ErrorContext(
+		// Only the version of the user named by If-Match may be changed
+		version, ok := parseIfMatch(r)
+		if !ok {
+			_ = encodeResponse(w, r, http.StatusPreconditionFailed, NewPreconditionFailed(
 				ctx,
-				"failed to decode request",
-				slog.String("error", err.Error()))
-			span.SetStatus(codes.Error, "decoding request failed")
-			span.RecordError(err)
+				fmt.Sprintf("User with ID %d does not match the If-Match header.", id),
+			))
+
+			return
+		}
+
+		// Content negotiation
+		if !acceptable(w, r) || !decodable(w, r) {
+			return
+		}
 
-			_ = encodeResponseJSON(w, http.StatusInternalServerError, NewInternalServerError(ctx))
+		// Request validation
+		request, problems, err := decodeValid[UserRequest](r, validate)
+		if err != nil && len(problems) == 0 {
+			encodeDecodeError(ctx, w, r, logger, err)
 
 			return
 		}
@@ -90,7 +124,7 @@ func HandleUpdateUser(logger *slog.Logger, userUpdater userUpdater) http.Handler
 			span.SetStatus(codes.Error, validationError)
 			span.RecordError(errors.New(validationError))
 
-			_ = encodeResponseJSON(w, http.StatusBadRequest, NewValidationBadRequest(ctx, problems))
+			_ = encodeResponse(w, r, http.StatusBadRequest, NewValidationBadRequest(ctx, problems))
 
 			return
 		}
@@ -102,26 +136,23 @@ func HandleUpdateUser(logger *slog.Logger, userUpdater userUpdater) http.Handler
 		}
 
 		// Update the user
-		user, err := userUpdater.UpdateUser(ctx, uint64(id), modelRequest)
+		user, err := userUpdater.UpdateUser(ctx, uint64(id), modelRequest, version)
 		if err != nil {
-			logger.ErrorContext(
-				ctx,
-				"failed to update user",
-				slog.String("error", err.Error()),
-			)
-			span.SetStatus(codes.Error, "user update failed")
-			span.RecordError(err)
-
-			_ = encodeResponseJSON(w, http.StatusInternalServerError, NewInternalServerError(ctx))
+			encodeError(ctx, w, r, logger, "failed to update user", err)
 
 			return
 		}
 
-		// Encode the response model as JSON
-		_ = encodeResponseJSON(w, http.StatusOK, UserResponse{
-			ID:    user.ID,
-			Name:  user.Name,
-			Email: user.Email,
+		// Encode the response model, tagged for the negotiated media type
+		c, _ := responseCodec(r)
+		setValidators(w, versionETag(user.Version, c.mediaType), user.UpdatedAt)
+		_ = encodeResponse(w, r, http.StatusOK, UserResponse{
+			ID:        user.ID,
+			Name:      user.Name,
+			Email:     user.Email,
+			Role:      string(user.Role),
+			CreatedAt: user.CreatedAt,
+			UpdatedAt: user.UpdatedAt,
 		})
 	}
 }
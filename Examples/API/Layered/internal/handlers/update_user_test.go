@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
@@ -12,13 +13,20 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/services"
 )
 
 func TestHandleUpdateUser(t *testing.T) {
 	tests := map[string]struct {
-		wantStatus int
-		wantBody   models.User
-		input      models.User
+		ifMatch       string
+		mockErr       error
+		wantVersion   uint64
+		wantStatus    int
+		wantBody      models.User
+		wantETag      string
+		wantNotCalled bool
+		input         UserRequest
+		rawBody       string
 	}{
 		"happy path": {
 			wantStatus: 200,
@@ -27,21 +35,61 @@ func TestHandleUpdateUser(t *testing.T) {
 				Name:     "john",
 				Email:    "john@mail.com",
 				Password: "password123!",
+				Version:  2,
 			},
-			input: models.User{
+			wantETag: `"2-json"`,
+			input: UserRequest{
 				Name:     "john",
 				Email:    "john@mail.com",
 				Password: "password123!",
 			},
 		},
+		"matching version": {
+			ifMatch:     `"1-json"`,
+			wantVersion: 1,
+			wantStatus:  200,
+			wantBody:    models.User{ID: 1, Name: "john", Email: "john@mail.com", Version: 2},
+			wantETag:    `"2-json"`,
+			input:       UserRequest{Name: "john", Email: "john@mail.com", Password: "password123!"},
+		},
+		"stale version": {
+			ifMatch:     `"1-json"`,
+			mockErr:     fmt.Errorf("updating: %w", services.ErrVersionMismatch),
+			wantVersion: 1,
+			wantStatus:  http.StatusPreconditionFailed,
+			input:       UserRequest{Name: "john", Email: "john@mail.com", Password: "password123!"},
+		},
+		"weak etag never matches": {
+			ifMatch:       `W/"1-json"`,
+			wantStatus:    http.StatusPreconditionFailed,
+			wantNotCalled: true,
+			input:         UserRequest{Name: "john", Email: "john@mail.com", Password: "password123!"},
+		},
+		"malformed body": {
+			wantStatus:    http.StatusBadRequest,
+			wantNotCalled: true,
+			rawBody:       `{"name":"john",`,
+		},
+		"user not found": {
+			mockErr:    fmt.Errorf("updating: %w", services.ErrUserNotFound),
+			wantStatus: http.StatusNotFound,
+			input:      UserRequest{Name: "john", Email: "john@mail.com", Password: "password123!"},
+		},
 	}
 	for name, tc := range tests {
 		t.Run(
 			name, func(t *testing.T) {
 				// Create a new request
+				// A raw body, when set, is sent instead of the input
 				reqBody, _ := json.Marshal(tc.input)
+				if tc.rawBody != "" {
+					reqBody = []byte(tc.rawBody)
+				}
 				req := httptest.NewRequest(http.MethodPut, "/users", bytes.NewBuffer(reqBody))
 				req.SetPathValue("id", "1")
+				if tc.ifMatch != "" {
+					req.Header.Set("If-Match", tc.ifMatch)
+				}
 
 				// Create a new response recorder
 				rec := httptest.NewRecorder()
@@ -54,17 +102,25 @@ func TestHandleUpdateUser(t *testing.T) {
 						_ context.Context,
 						_ uint64,
 						_ models.User,
+						version uint64,
 					) (models.User, error) {
-						return tc.wantBody, nil
+						assert.Equal(t, tc.wantVersion, version)
+
+						return tc.wantBody, tc.mockErr
 					},
 				}
 
 				// Call the handler
-				handler := HandleUpdateUser(logger, mockedUserUpdater)
+				handler := HandleUpdateUser(logger, newTestValidator(t), mockedUserUpdater)
 
 				handler.ServeHTTP(rec, req)
 				// Check the status code
 				assert.Equal(t, tc.wantStatus, rec.Code)
+				assert.Equal(t, tc.wantETag, rec.Header().Get("ETag"))
+
+				if tc.wantNotCalled {
+					assert.Empty(t, mockedUserUpdater.UpdateUserCalls())
+				}
 
 				// Check the body
 				var respBody models.User
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"go.opentelemetry.io/otel/codes"
+
+	"example.com/examples/api/layered/internal/middleware"
+	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/validation"
+)
+
+// blogVoter represents a type capable of recording a vote on a blog and
+// returning the re-scored blog or an error.
+type blogVoter interface {
+	VoteBlog(ctx context.Context, vote models.Vote) (models.Blog, error)
+}
+
+// HandleVoteBlog handles the authenticated user's up or down vote on a blog by
+// ID. Each user may vote on a blog once.
+//
+//	@Summary		Vote on Blog
+//	@Description	Up or down vote a Blog by ID, returning the re-scored Blog
+//	@Tags			blog
+//	@Accept			json
+//	@Accept			xml
+//	@Accept			application/msgpack
+//	@Accept			application/cbor
+//	@Produce		json
+//	@Produce		xml
+//	@Produce		application/msgpack
+//	@Produce		application/cbor
+//	@Param			id				path		string		true	"Blog ID"
+//	@Param			request			body		VoteRequest	true	"Vote to Cast"
+//	@Param			Idempotency-Key	header		string		false	"Key that makes retries of the request safe"
+//	@Success		200				{object}	BlogResponse
+//	@Failure		400				{object}	ProblemDetailValidation
+//	@Failure		401				{object}	ProblemDetail
+//	@Failure		404				{object}	ProblemDetail
+//	@Failure		406				{object}	ProblemDetail
+//	@Failure		409				{object}	ProblemDetail
+//	@Failure		415				{object}	ProblemDetail
+//	@Failure		422				{object}	ProblemDetailValidation
+//	@Failure		429				{object}	ProblemDetail
+//	@Failure		500				{object}	ProblemDetail
+//	@Failure		503				{object}	ProblemDetail
+//	@Security		BearerAuth
+//	@Router			/blog/{id}/vote  [POST]
+func HandleVoteBlog(
+	logger *slog.Logger,
+	validate *validation.Validator,
+	blogVoter blogVoter,
+) http.HandlerFunc {
+	const name = "handlers.HandleVoteBlog"
+	logger = logger.With(slog.String("func", name))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracer.Start(r.Context(), name)
+		defer span.End()
+
+		// Read id from path parameters
+		idStr := r.PathValue("id")
+
+		// Convert the ID from string to int
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to parse id from url",
+				slog.String("id", idStr),
+				slog.String("error", err.Error()),
+			)
+			span.SetStatus(codes.Error, "ID conversion failed")
+			span.RecordError(err)
+
+			_ = encodeResponse(w, r, http.StatusBadRequest, ProblemDetail{
+				Title:   "Invalid ID",
+				Status:  http.StatusBadRequest,
+				Detail:  "The provided ID is not a valid integer.",
+				TraceID: middleware.GetTraceID(ctx),
+			})
+
+			return
+		}
+
+		// The authenticated user acts on the blog
+		principal, ok := middleware.GetPrincipal(ctx)
+		if !ok {
+			encodeError(ctx, w, r, logger, "missing principal", errNoPrincipal)
+
+			return
+		}
+
+		// Content negotiation
+		if !acceptable(w, r) || !decodable(w, r) {
+			return
+		}
+
+		// Request validation
+		request, problems, err := decodeValid[VoteRequest](r, validate)
+		if err != nil && len(problems) == 0 {
+			encodeDecodeError(ctx, w, r, logger, err)
+
+			return
+		}
+		if len(problems) > 0 {
+			validationError := "validation failed"
+			logger.ErrorContext(
+				ctx,
+				validationError,
+				slog.Any("validation_errors", problems),
+			)
+			span.SetStatus(codes.Error, validationError)
+			span.RecordError(errors.New(validationError))
+
+			_ = encodeResponse(w, r, http.StatusBadRequest, NewValidationBadRequest(ctx, problems))
+
+			return
+		}
+
+		modelRequest := models.Vote{
+			UserID: principal.UserID,
+			BlogID: uint(id),
+			Value:  models.VoteUp,
+		}
+		if request.Direction == "down" {
+			modelRequest.Value = models.VoteDown
+		}
+
+		// Record the vote
+		blog, err := blogVoter.VoteBlog(ctx, modelRequest)
+		if err != nil {
+			encodeError(ctx, w, r, logger, "failed to vote on blog", err)
+
+			return
+		}
+
+		// Encode the response model as JSON
+		_ = encodeResponse(w, r, http.StatusOK, BlogResponse{
+			ID:       blog.ID,
+			AuthorID: blog.AuthorID,
+			Title:    blog.Title,
+			Score:    blog.Score,
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/examples/api/layered/internal/middleware"
+	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/services"
+)
+
+func TestHandleVoteBlog(t *testing.T) {
+	tests := map[string]struct {
+		id         string
+		anonymous  bool
+		input      VoteRequest
+		wantValue  int
+		mockBlog   models.Blog
+		mockErr    error
+		wantStatus int
+		wantBody   BlogResponse
+	}{
+		"up vote": {
+			id:         "1",
+			input:      VoteRequest{Direction: "up"},
+			wantValue:  models.VoteUp,
+			mockBlog:   models.Blog{ID: 1, AuthorID: 1, Title: "First Blog Post", Score: 9.5},
+			wantStatus: http.StatusOK,
+			wantBody:   BlogResponse{ID: 1, AuthorID: 1, Title: "First Blog Post", Score: 9.5},
+		},
+		"down vote": {
+			id:         "1",
+			input:      VoteRequest{Direction: "down"},
+			wantValue:  models.VoteDown,
+			mockBlog:   models.Blog{ID: 1, AuthorID: 1, Title: "First Blog Post", Score: 7.5},
+			wantStatus: http.StatusOK,
+			wantBody:   BlogResponse{ID: 1, AuthorID: 1, Title: "First Blog Post", Score: 7.5},
+		},
+		"invalid id": {
+			id:         "abc",
+			input:      VoteRequest{Direction: "up"},
+			wantStatus: http.StatusBadRequest,
+		},
+		"invalid direction": {
+			id:         "1",
+			input:      VoteRequest{Direction: "sideways"},
+			wantStatus: http.StatusBadRequest,
+		},
+		"blog not found": {
+			id:         "99",
+			input:      VoteRequest{Direction: "up"},
+			wantValue:  models.VoteUp,
+			mockErr:    fmt.Errorf("blog 99: %w", services.ErrBlogNotFound),
+			wantStatus: http.StatusNotFound,
+		},
+		"unauthenticated": {
+			id:         "1",
+			anonymous:  true,
+			input:      VoteRequest{Direction: "up"},
+			wantStatus: http.StatusUnauthorized,
+		},
+		"already voted": {
+			id:         "1",
+			input:      VoteRequest{Direction: "up"},
+			wantValue:  models.VoteUp,
+			mockErr:    fmt.Errorf("user 2 on blog 1: %w", services.ErrAlreadyVoted),
+			wantStatus: http.StatusConflict,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(
+			name, func(t *testing.T) {
+				// Create a new request
+				reqBody, _ := json.Marshal(tc.input)
+				req := httptest.NewRequest(
+					http.MethodPost,
+					"/blog/"+tc.id+"/vote",
+					bytes.NewBuffer(reqBody),
+				)
+				req.SetPathValue("id", tc.id)
+				if !tc.anonymous {
+					req = req.WithContext(middleware.WithPrincipal(req.Context(), models.Principal{UserID: 2}))
+				}
+
+				// Create a new response recorder
+				rec := httptest.NewRecorder()
+
+				// Create a new ctxhandler
+				logger := slog.Default()
+
+				mockedBlogVoter := &moqblogVoter{
+					VoteBlogFunc: func(_ context.Context, vote models.Vote) (models.Blog, error) {
+						assert.Equal(t, uint(2), vote.UserID)
+						assert.Equal(t, tc.wantValue, vote.Value)
+
+						return tc.mockBlog, tc.mockErr
+					},
+				}
+
+				// Call the handler
+				handler := HandleVoteBlog(logger, newTestValidator(t), mockedBlogVoter)
+
+				handler.ServeHTTP(rec, req)
+				// Check the status code
+				assert.Equal(t, tc.wantStatus, rec.Code)
+
+				// Check the body
+				if tc.wantStatus == http.StatusOK {
+					var respBody BlogResponse
+					_ = json.Unmarshal(rec.Body.Bytes(), &respBody)
+
+					assert.Equal(t, tc.wantBody, respBody)
+				}
+			},
+		)
+	}
+}
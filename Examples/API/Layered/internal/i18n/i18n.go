@@ -0,0 +1,265 @@
+// Package i18n is the translation catalogue of the messages the API shows to
+// end users: the titles of problem details and the messages of validation
+// problems. Messages are available in English, Spanish and French, and a
+// request gets the language its Accept-Language header prefers:
+//
+//	trans := i18n.FromRequest(r)
+//	title := i18n.Title(trans, "Not Found") // "No encontrado" for es-MX
+//
+// Requests that accept none of them get English.
+package i18n
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	"github.com/go-playground/locales/fr"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	entranslations "github.com/go-playground/validator/v10/translations/en"
+	estranslations "github.com/go-playground/validator/v10/translations/es"
+	frtranslations "github.com/go-playground/validator/v10/translations/fr"
+	"golang.org/x/text/language"
+)
+
+// Keys of the messages of validation problems the validator does not report
+// itself. The parameter of each is the name of the offending field.
+const (
+	// CannotBeRemoved is for a field a merge patch sets to null.
+	CannotBeRemoved = "cannot-be-removed"
+	// AlreadyTaken is for a value that must be unique but is not.
+	AlreadyTaken = "already-taken"
+	// NoSuchResource is for a reference to a record that does not exist.
+	NoSuchResource = "no-such-resource"
+	// NotAllowed is for a value a check constraint forbids.
+	NotAllowed = "not-allowed"
+	// WeakPassword is for a password without both a letter and a digit.
+	WeakPassword = "weak-password"
+	// DomainNotAllowed is for an email address at a domain that is not
+	// allowed.
+	DomainNotAllowed = "domain-not-allowed"
+)
+
+// languages are the languages of the catalogue, English first as the
+// fallback, each with its locale and the registration of the validator's
+// default messages in it.
+var languages = []struct {
+	tag      language.Tag
+	locale   string
+	register func(v *validator.Validate, trans ut.Translator) error
+}{
+	{tag: language.English, locale: "en", register: entranslations.RegisterDefaultTranslations},
+	{tag: language.Spanish, locale: "es", register: estranslations.RegisterDefaultTranslations},
+	{tag: language.French, locale: "fr", register: frtranslations.RegisterDefaultTranslations},
+}
+
+// catalogue holds the messages of the API beyond the validator's own, by
+// locale and key. English titles are their own keys, so they need no entry.
+var catalogue = map[string]map[string]string{
+	"en": {
+		CannotBeRemoved:  "{0} cannot be removed",
+		AlreadyTaken:     "{0} is already taken",
+		NoSuchResource:   "{0} does not refer to an existing resource",
+		NotAllowed:       "{0} is not an allowed value",
+		WeakPassword:     "{0} must contain at least one letter and one digit",
+		DomainNotAllowed: "{0} must be an address at an allowed domain",
+	},
+	"es": {
+		CannotBeRemoved:          "{0} no se puede eliminar",
+		AlreadyTaken:             "{0} ya está en uso",
+		NoSuchResource:           "{0} no hace referencia a un recurso existente",
+		NotAllowed:               "{0} no es un valor permitido",
+		WeakPassword:             "{0} debe contener al menos una letra y un dígito",
+		DomainNotAllowed:         "{0} debe ser una dirección de un dominio permitido",
+		"Bad Request":            "Solicitud incorrecta",
+		"Unauthorized":           "No autorizado",
+		"Forbidden":              "Prohibido",
+		"Not Found":              "No encontrado",
+		"Not Acceptable":         "No aceptable",
+		"Conflict":               "Conflicto",
+		"Precondition Failed":    "Precondición fallida",
+		"Unsupported Media Type": "Tipo de medio no soportado",
+		"Unprocessable Entity":   "Entidad no procesable",
+		"Too Many Requests":      "Demasiadas solicitudes",
+		"Internal Server Error":  "Error interno del servidor",
+		"Service Unavailable":    "Servicio no disponible",
+		"Invalid ID":             "ID no válido",
+		"Path Not Found":         "Ruta no encontrada",
+		"Author Not Found":       "Autor no encontrado",
+		"Blog Not Found":         "Blog no encontrado",
+		"Comment Not Found":      "Comentario no encontrado",
+		"User Not Found":         "Usuario no encontrado",
+	},
+	"fr": {
+		CannotBeRemoved:          "{0} ne peut pas être supprimé",
+		AlreadyTaken:             "{0} est déjà utilisé",
+		NoSuchResource:           "{0} ne fait pas référence à une ressource existante",
+		NotAllowed:               "{0} n'est pas une valeur autorisée",
+		WeakPassword:             "{0} doit contenir au moins une lettre et un chiffre",
+		DomainNotAllowed:         "{0} doit être une adresse d'un domaine autorisé",
+		"Bad Request":            "Requête incorrecte",
+		"Unauthorized":           "Non autorisé",
+		"Forbidden":              "Interdit",
+		"Not Found":              "Introuvable",
+		"Not Acceptable":         "Non acceptable",
+		"Conflict":               "Conflit",
+		"Precondition Failed":    "Échec de la précondition",
+		"Unsupported Media Type": "Type de média non pris en charge",
+		"Unprocessable Entity":   "Entité non traitable",
+		"Too Many Requests":      "Trop de requêtes",
+		"Internal Server Error":  "Erreur interne du serveur",
+		"Service Unavailable":    "Service indisponible",
+		"Invalid ID":             "ID non valide",
+		"Path Not Found":         "Chemin introuvable",
+		"Author Not Found":       "Auteur introuvable",
+		"Blog Not Found":         "Blog introuvable",
+		"Comment Not Found":      "Commentaire introuvable",
+		"User Not Found":         "Utilisateur introuvable",
+	},
+}
+
+var (
+	universal = ut.New(en.New(), en.New(), es.New(), fr.New())
+	matcher   = language.NewMatcher(tags())
+
+	// translators holds the translator of every language by locale.
+	translators = map[string]ut.Translator{}
+)
+
+func init() {
+	for _, l := range languages {
+		trans, _ := universal.GetTranslator(l.locale)
+		for key, message := range catalogue[l.locale] {
+			if err := trans.Add(key, message, false); err != nil {
+				panic(fmt.Sprintf("i18n: add %s message %q: %v", l.locale, key, err))
+			}
+		}
+
+		// Registering the validator's messages adds them to the translator,
+		// which a throwaway validator does once for all
+		if err := l.register(validator.New(), trans); err != nil {
+			panic(fmt.Sprintf("i18n: add %s validation messages: %v", l.locale, err))
+		}
+
+		translators[l.locale] = complete{trans}
+	}
+}
+
+// complete is a translator that already holds every message, so adding one
+// does nothing. Registering the validator's messages with a validator adds
+// them to the translator again, which this makes safe to do at any time, even
+// while other requests are being translated.
+type complete struct {
+	ut.Translator
+}
+
+func (complete) Add(any, string, bool) error {
+	return nil
+}
+
+func (complete) AddCardinal(any, string, locales.PluralRule, bool) error {
+	return nil
+}
+
+func (complete) AddOrdinal(any, string, locales.PluralRule, bool) error {
+	return nil
+}
+
+func (complete) AddRange(any, string, locales.PluralRule, bool) error {
+	return nil
+}
+
+// tags returns the language tags of the catalogue, in order.
+func tags() []language.Tag {
+	t := make([]language.Tag, len(languages))
+	for i, l := range languages {
+		t[i] = l.tag
+	}
+
+	return t
+}
+
+// Translator returns the translator of the language an Accept-Language
+// header prefers, or of English if it accepts none of the catalogue's.
+func Translator(acceptLanguage string) ut.Translator {
+	// A malformed header leaves no tags, which matches English
+	accepted, _, _ := language.ParseAcceptLanguage(acceptLanguage)
+	_, i, _ := matcher.Match(accepted...)
+
+	return translators[languages[i].locale]
+}
+
+// FromRequest returns the translator of the language the Accept-Language
+// header of r prefers.
+func FromRequest(r *http.Request) ut.Translator {
+	return Translator(r.Header.Get("Accept-Language"))
+}
+
+// Title translates the title of a problem detail. Titles missing from the
+// catalogue are returned as they are.
+func Title(trans ut.Translator, title string) string {
+	if translated, err := trans.T(title); err == nil {
+		return translated
+	}
+
+	return title
+}
+
+// Message translates the message with key, filling in its parameters.
+// Messages missing from the catalogue are returned as their key.
+func Message(trans ut.Translator, key string, params ...string) string {
+	if translated, err := trans.T(key, params...); err == nil {
+		return translated
+	}
+
+	return key
+}
+
+// RegisterValidator registers the validator's messages in every language of
+// the catalogue with v, and makes v name fields by their JSON names so that
+// messages name fields as clients send them.
+func RegisterValidator(v *validator.Validate) error {
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+
+		return name
+	})
+
+	for _, l := range languages {
+		if err := l.register(v, translators[l.locale]); err != nil {
+			return fmt.Errorf("[in i18n.RegisterValidator] register %s messages failed: %w", l.locale, err)
+		}
+	}
+
+	return nil
+}
+
+// RegisterTag registers the message with key as the message of a custom
+// validation tag of v, in every language of the catalogue. The message is
+// given the name of the offending field as its parameter.
+func RegisterTag(v *validator.Validate, tag string, key string) error {
+	for _, l := range languages {
+		err := v.RegisterTranslation(
+			tag,
+			translators[l.locale],
+			// The translator already holds the message
+			func(ut.Translator) error { return nil },
+			func(trans ut.Translator, fieldError validator.FieldError) string {
+				return Message(trans, key, fieldError.Field())
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("[in i18n.RegisterTag] register %s message of %s failed: %w", l.locale, tag, err)
+		}
+	}
+
+	return nil
+}
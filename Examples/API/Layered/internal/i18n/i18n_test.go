@@ -0,0 +1,78 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslator(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		acceptLanguage string
+		wantLocale     string
+	}{
+		"no header":            {acceptLanguage: "", wantLocale: "en"},
+		"exact":                {acceptLanguage: "fr", wantLocale: "fr"},
+		"region":               {acceptLanguage: "es-MX", wantLocale: "es"},
+		"highest quality wins": {acceptLanguage: "en;q=0.5, fr-CA;q=0.8", wantLocale: "fr"},
+		"unsupported skipped":  {acceptLanguage: "de, es;q=0.9", wantLocale: "es"},
+		"none supported":       {acceptLanguage: "de, ja", wantLocale: "en"},
+		"malformed":            {acceptLanguage: ";;q=x", wantLocale: "en"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.wantLocale, Translator(tc.acceptLanguage).Locale())
+		})
+	}
+}
+
+func TestTitle(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "Not Found", Title(Translator("en"), "Not Found"))
+	assert.Equal(t, "No encontrado", Title(Translator("es"), "Not Found"))
+	assert.Equal(t, "Introuvable", Title(Translator("fr"), "Not Found"))
+	assert.Equal(t, "Teapot", Title(Translator("fr"), "Teapot"), "titles missing from the catalogue are kept")
+}
+
+func TestMessage(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "email is already taken", Message(Translator("en"), AlreadyTaken, "email"))
+	assert.Equal(t, "email ya está en uso", Message(Translator("es"), AlreadyTaken, "email"))
+	assert.Equal(t, "missing", Message(Translator("fr"), "missing"))
+}
+
+func TestRegisterValidator(t *testing.T) {
+	t.Parallel()
+
+	v := validator.New()
+	require.NoError(t, RegisterValidator(v))
+	require.NoError(t, RegisterValidator(validator.New()), "messages can be registered for another validator")
+
+	var request struct {
+		Email string `json:"email,omitempty" validate:"required,email"`
+		Name  string `json:"-"               validate:"required"`
+	}
+	request.Email = "not-an-email"
+
+	var fieldErrors validator.ValidationErrors
+	require.ErrorAs(t, v.Struct(request), &fieldErrors)
+	require.Len(t, fieldErrors, 2)
+
+	assert.Equal(t, "email", fieldErrors[0].Field(), "fields are named by their JSON names")
+	assert.Equal(t, "Name", fieldErrors[1].Field(), "fields without a JSON name keep their own")
+	assert.Equal(t, "email must be a valid email address", fieldErrors[0].Translate(Translator("en")))
+	assert.Equal(
+		t,
+		"email debe ser una dirección de correo electrónico válida",
+		fieldErrors[0].Translate(Translator("es")),
+	)
+	assert.Equal(t, "email doit être une adresse email valide", fieldErrors[0].Translate(Translator("fr")))
+}
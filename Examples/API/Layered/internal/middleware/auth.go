@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/codes"
+
+	"example.com/examples/api/layered/internal/i18n"
+	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/problemtype"
+)
+
+type principalKey struct{}
+
+// TokenVerifier represents a type capable of verifying a bearer access token
+// and returning the principal it was issued to or an error.
+type TokenVerifier interface {
+	VerifyAccessToken(ctx context.Context, accessToken string) (models.Principal, error)
+}
+
+// problemDetail mirrors the problem detail returned by the handlers package,
+// which cannot be imported here without an import cycle.
+type problemDetail struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail"`
+	Instance string `json:"instance,omitempty"`
+	TraceID  string `json:"traceId,omitempty"`
+}
+
+// Authenticate is a middleware that requires a valid bearer access token in
+// the Authorization header. The principal the token was issued to is set in
+// the request context; requests without a valid token receive a 401.
+func Authenticate(logger *slog.Logger, verifier TokenVerifier) Func {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, span := tracer.Start(r.Context(), "middleware.Authenticate")
+			defer span.End()
+
+			scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+			if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+				span.SetStatus(codes.Error, "missing bearer token")
+
+				w.Header().Set("WWW-Authenticate", `Bearer`)
+				writeProblem(ctx, w, r, problemtype.Unauthorized, "A bearer access token is required.")
+
+				return
+			}
+
+			principal, err := verifier.VerifyAccessToken(ctx, token)
+			if err != nil {
+				logger.WarnContext(
+					ctx,
+					"failed to verify access token",
+					slog.String("error", err.Error()),
+				)
+				span.SetStatus(codes.Error, "invalid bearer token")
+				span.RecordError(err)
+
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				writeProblem(
+					ctx,
+					w,
+					r,
+					problemtype.Unauthorized,
+					"The access token is invalid or has expired.",
+				)
+
+				return
+			}
+
+			// Set the principal in the request context
+			ctx = WithPrincipal(ctx, principal)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// writeProblem writes a problem detail of type t with the provided detail, as
+// application/problem+json. Its title is in the language the Accept-Language
+// header of the request prefers.
+func writeProblem(ctx context.Context, w http.ResponseWriter, r *http.Request, t problemtype.Type, detail string) {
+	trans := i18n.FromRequest(r)
+	w.Header().Add("Vary", "Accept-Language")
+	w.Header().Set("Content-Language", trans.Locale())
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(t.Status)
+
+	_ = json.NewEncoder(w).Encode(problemDetail{
+		Type:     t.URI(),
+		Title:    i18n.Title(trans, t.Title),
+		Status:   t.Status,
+		Detail:   detail,
+		Instance: r.URL.Path,
+		TraceID:  GetTraceID(ctx),
+	})
+}
+
+// WithPrincipal returns a copy of ctx carrying the provided principal, as set
+// by Authenticate.
+func WithPrincipal(ctx context.Context, principal models.Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// GetPrincipal retrieves the authenticated principal from the context. The
+// boolean is false if the request was not authenticated.
+func GetPrincipal(ctx context.Context) (models.Principal, bool) {
+	if ctx == nil {
+		return models.Principal{}, false
+	}
+
+	principal, ok := ctx.Value(principalKey{}).(models.Principal)
+
+	return principal, ok
+}
+
+// GetUserIDAsAttr retrieves the authenticated user id from the context and
+// returns it as a slog.Attr.
+func GetUserIDAsAttr(ctx context.Context) slog.Attr {
+	principal, ok := GetPrincipal(ctx)
+	if !ok {
+		return slog.Attr{}
+	}
+
+	return slog.Uint64("user_id", uint64(principal.UserID))
+}
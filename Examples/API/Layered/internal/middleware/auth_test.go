@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/examples/api/layered/internal/models"
+)
+
+// tokenVerifierFunc adapts a function to the TokenVerifier interface.
+type tokenVerifierFunc func(ctx context.Context, accessToken string) (models.Principal, error)
+
+func (f tokenVerifierFunc) VerifyAccessToken(ctx context.Context, accessToken string) (models.Principal, error) {
+	return f(ctx, accessToken)
+}
+
+func TestAuthenticate(t *testing.T) {
+	verifier := tokenVerifierFunc(func(_ context.Context, accessToken string) (models.Principal, error) {
+		if accessToken != "valid-token" {
+			return models.Principal{}, errors.New("invalid token")
+		}
+
+		return models.Principal{UserID: 7}, nil
+	})
+
+	tests := map[string]struct {
+		authorization   string
+		wantStatus      int
+		wantChallenge   string
+		wantPrincipal   models.Principal
+		wantNextHandler bool
+	}{
+		"valid token": {
+			authorization:   "Bearer valid-token",
+			wantStatus:      http.StatusOK,
+			wantPrincipal:   models.Principal{UserID: 7},
+			wantNextHandler: true,
+		},
+		"lower case scheme": {
+			authorization:   "bearer valid-token",
+			wantStatus:      http.StatusOK,
+			wantPrincipal:   models.Principal{UserID: 7},
+			wantNextHandler: true,
+		},
+		"missing header": {
+			authorization: "",
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `Bearer`,
+		},
+		"wrong scheme": {
+			authorization: "Basic dXNlcjpwYXNz",
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `Bearer`,
+		},
+		"empty token": {
+			authorization: "Bearer ",
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `Bearer`,
+		},
+		"invalid token": {
+			authorization: "Bearer expired-token",
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `Bearer error="invalid_token"`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(
+			name, func(t *testing.T) {
+				// Create a test handler that captures the principal from the context
+				var (
+					called            bool
+					capturedPrincipal models.Principal
+				)
+				testHandler := http.HandlerFunc(
+					func(w http.ResponseWriter, r *http.Request) {
+						called = true
+						capturedPrincipal, _ = GetPrincipal(r.Context())
+						w.WriteHeader(http.StatusOK)
+					},
+				)
+
+				// Apply middleware
+				handler := Authenticate(slog.Default(), verifier)(testHandler)
+
+				req := httptest.NewRequest(http.MethodGet, "/test", nil)
+				if tc.authorization != "" {
+					req.Header.Set("Authorization", tc.authorization)
+				}
+
+				// Execute request
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, req)
+
+				assert.Equal(t, tc.wantStatus, recorder.Code)
+				assert.Equal(t, tc.wantNextHandler, called)
+				assert.Equal(t, tc.wantPrincipal, capturedPrincipal)
+				assert.Equal(t, tc.wantChallenge, recorder.Header().Get("WWW-Authenticate"))
+			},
+		)
+	}
+}
+
+func TestGetUserIDAsAttr(t *testing.T) {
+	tests := map[string]struct {
+		ctx          context.Context
+		expectedAttr slog.Attr
+	}{
+		"nil context": {
+			ctx:          nil,
+			expectedAttr: slog.Attr{},
+		},
+		"unauthenticated context": {
+			ctx:          context.Background(),
+			expectedAttr: slog.Attr{},
+		},
+		"authenticated context": {
+			ctx:          context.WithValue(context.Background(), principalKey{}, models.Principal{UserID: 7}),
+			expectedAttr: slog.Uint64("user_id", 7),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(
+			name, func(t *testing.T) {
+				assert.Equal(t, tc.expectedAttr, GetUserIDAsAttr(tc.ctx))
+			},
+		)
+	}
+}
@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"go.opentelemetry.io/otel/codes"
+
+	"example.com/examples/api/layered/internal/errs"
+	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/problemtype"
+)
+
+// Permission reports whether the provided principal may make the provided
+// request.
+type Permission func(principal models.Principal, r *http.Request) bool
+
+// Authenticated permits any authenticated principal.
+func Authenticated(models.Principal, *http.Request) bool {
+	return true
+}
+
+// HasRole permits principals holding any of the provided roles.
+func HasRole(roles ...models.Role) Permission {
+	return func(principal models.Principal, _ *http.Request) bool {
+		return slices.Contains(roles, principal.Role)
+	}
+}
+
+// IsSelf permits principals whose user id matches the named path value, e.g.
+// IsSelf("id") on "PUT /api/user/{id}" lets users act only on their own
+// record.
+func IsSelf(pathValue string) Permission {
+	return func(principal models.Principal, r *http.Request) bool {
+		return r.PathValue(pathValue) == strconv.FormatUint(uint64(principal.UserID), 10)
+	}
+}
+
+// OwnerLookup returns the id of the user who owns the resource a request acts
+// on, such as the author of a blog, or an error.
+type OwnerLookup func(r *http.Request) (uint, error)
+
+// IsOwner permits principals who own the resource a request acts on, as
+// reported by lookup. Requests for a resource that does not exist, or with an
+// invalid id, are permitted so that the handler reports them as such; any
+// other failure to look up the owner is denied.
+func IsOwner(lookup OwnerLookup) Permission {
+	return func(principal models.Principal, r *http.Request) bool {
+		owner, err := lookup(r)
+		if err != nil {
+			kind := errs.KindOf(err)
+
+			return kind == errs.NotFound || kind == errs.Invalid
+		}
+
+		return owner == principal.UserID
+	}
+}
+
+// AnyOf permits principals granted any of the provided permissions.
+func AnyOf(permissions ...Permission) Permission {
+	return func(principal models.Principal, r *http.Request) bool {
+		for _, permission := range permissions {
+			if permission(principal, r) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// Authorize is a middleware that checks the principal set by Authenticate
+// against the provided permission. Requests without a principal receive a 401
+// and requests the principal is not permitted to make receive a 403.
+func Authorize(logger *slog.Logger, permission Permission) Func {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, span := tracer.Start(r.Context(), "middleware.Authorize")
+			defer span.End()
+
+			principal, ok := GetPrincipal(ctx)
+			if !ok {
+				span.SetStatus(codes.Error, "missing principal")
+
+				w.Header().Set("WWW-Authenticate", `Bearer`)
+				writeProblem(ctx, w, r, problemtype.Unauthorized, "A bearer access token is required.")
+
+				return
+			}
+
+			if !permission(principal, r) {
+				logger.WarnContext(
+					ctx,
+					"permission denied",
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+					slog.String("role", string(principal.Role)),
+				)
+				span.SetStatus(codes.Error, "permission denied")
+
+				writeProblem(
+					ctx,
+					w,
+					r,
+					problemtype.Forbidden,
+					"You do not have permission to perform this action.",
+				)
+
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/examples/api/layered/internal/errs"
+	"example.com/examples/api/layered/internal/models"
+)
+
+func TestAuthorize(t *testing.T) {
+	admin := models.Principal{UserID: 1, Role: models.RoleAdmin}
+	user := models.Principal{UserID: 2, Role: models.RoleUser}
+
+	tests := map[string]struct {
+		permission    Permission
+		principal     *models.Principal
+		pathID        string
+		wantStatus    int
+		wantNextCalls bool
+	}{
+		"authenticated permits any principal": {
+			permission:    Authenticated,
+			principal:     &user,
+			wantStatus:    http.StatusOK,
+			wantNextCalls: true,
+		},
+		"has role permits matching role": {
+			permission:    HasRole(models.RoleAdmin),
+			principal:     &admin,
+			wantStatus:    http.StatusOK,
+			wantNextCalls: true,
+		},
+		"has role denies other roles": {
+			permission: HasRole(models.RoleAdmin),
+			principal:  &user,
+			wantStatus: http.StatusForbidden,
+		},
+		"is self permits own record": {
+			permission:    IsSelf("id"),
+			principal:     &user,
+			pathID:        "2",
+			wantStatus:    http.StatusOK,
+			wantNextCalls: true,
+		},
+		"is self denies other records": {
+			permission: IsSelf("id"),
+			principal:  &user,
+			pathID:     "3",
+			wantStatus: http.StatusForbidden,
+		},
+		"any of permits when one permits": {
+			permission:    AnyOf(IsSelf("id"), HasRole(models.RoleAdmin)),
+			principal:     &admin,
+			pathID:        "3",
+			wantStatus:    http.StatusOK,
+			wantNextCalls: true,
+		},
+		"any of denies when none permit": {
+			permission: AnyOf(IsSelf("id"), HasRole(models.RoleAdmin)),
+			principal:  &user,
+			pathID:     "3",
+			wantStatus: http.StatusForbidden,
+		},
+		"is owner permits owner": {
+			permission:    IsOwner(ownedBy(2, nil)),
+			principal:     &user,
+			wantStatus:    http.StatusOK,
+			wantNextCalls: true,
+		},
+		"is owner denies others": {
+			permission: IsOwner(ownedBy(3, nil)),
+			principal:  &user,
+			wantStatus: http.StatusForbidden,
+		},
+		"is owner permits missing resource": {
+			permission:    IsOwner(ownedBy(0, errs.New(errs.NotFound, "blog not found"))),
+			principal:     &user,
+			wantStatus:    http.StatusOK,
+			wantNextCalls: true,
+		},
+		"is owner permits invalid id": {
+			permission:    IsOwner(ownedBy(0, errs.E(errs.Invalid, errors.New("invalid id")))),
+			principal:     &user,
+			wantStatus:    http.StatusOK,
+			wantNextCalls: true,
+		},
+		"is owner denies failed lookup": {
+			permission: IsOwner(ownedBy(0, errors.New("connection refused"))),
+			principal:  &user,
+			wantStatus: http.StatusForbidden,
+		},
+		"missing principal": {
+			permission: Authenticated,
+			principal:  nil,
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(
+			name, func(t *testing.T) {
+				var called bool
+				testHandler := http.HandlerFunc(
+					func(w http.ResponseWriter, r *http.Request) {
+						called = true
+						w.WriteHeader(http.StatusOK)
+					},
+				)
+
+				// Apply middleware
+				handler := Authorize(slog.Default(), tc.permission)(testHandler)
+
+				req := httptest.NewRequest(http.MethodPut, "/api/user/"+tc.pathID, nil)
+				req.SetPathValue("id", tc.pathID)
+
+				ctx := context.WithValue(req.Context(), traceIDKey{}, "test-trace-id")
+				if tc.principal != nil {
+					ctx = context.WithValue(ctx, principalKey{}, *tc.principal)
+				}
+
+				// Execute request
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, req.WithContext(ctx))
+
+				assert.Equal(t, tc.wantStatus, recorder.Code)
+				assert.Equal(t, tc.wantNextCalls, called)
+
+				if tc.wantStatus != http.StatusOK {
+					var problem problemDetail
+					require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+					assert.Equal(t, tc.wantStatus, problem.Status)
+					assert.Equal(t, "test-trace-id", problem.TraceID)
+				}
+			},
+		)
+	}
+}
+
+// ownedBy returns an OwnerLookup reporting owner and err for every request.
+func ownedBy(owner uint, err error) OwnerLookup {
+	return func(*http.Request) (uint, error) {
+		return owner, err
+	}
+}
@@ -0,0 +1,451 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"example.com/examples/api/layered/internal/problemtype"
+)
+
+const (
+	// idempotencyKeyHeader is the request header carrying the idempotency key.
+	idempotencyKeyHeader = "Idempotency-Key"
+
+	// idempotentReplayedHeader marks a response replayed from the store.
+	idempotentReplayedHeader = "Idempotent-Replayed"
+
+	// maxIdempotencyKeyLength is the longest idempotency key accepted.
+	maxIdempotencyKeyLength = 255
+
+	// idempotencyLockExpiration bounds how long a request holds its key while
+	// in progress, so a key is released even if the server dies mid-request.
+	idempotencyLockExpiration = time.Minute
+)
+
+// IdempotencyStore represents a type capable of storing values under a key
+// with an expiration, such as the Redis client.
+type IdempotencyStore interface {
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.BoolCmd
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd
+	Get(ctx context.Context, key string) *redis.StringCmd
+	Del(ctx context.Context, keys ...string) *redis.IntCmd
+}
+
+// idempotentResponse is the stored state of a request made with an
+// idempotency key. Until the request completes only its fingerprint is
+// stored; afterwards, the response it received.
+type idempotentResponse struct {
+	Fingerprint string      `json:"fingerprint"`
+	Done        bool        `json:"done"`
+	Status      int         `json:"status,omitempty"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+}
+
+// recordingWriter passes a response through to the client while keeping a
+// copy of it to store.
+type recordingWriter struct {
+	http.ResponseWriter
+	statusCode int
+	header     http.Header
+	body       bytes.Buffer
+}
+
+func (w *recordingWriter) WriteHeader(statusCode int) {
+	if w.header == nil {
+		w.statusCode = statusCode
+		w.header = w.ResponseWriter.Header().Clone()
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	if w.header == nil {
+		w.WriteHeader(http.StatusOK)
+	}
+	w.body.Write(b)
+
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the underlying http.ResponseWriter, so that an
+// http.ResponseController can reach it.
+func (w *recordingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Idempotency is a middleware that makes requests carrying an Idempotency-Key
+// header safe to retry. The first response to a key is stored for ttl, keyed
+// by the caller and the key, and replayed with an Idempotent-Replayed header
+// to any retry. A retry made while the first request is still in progress
+// receives a 409, and reusing a key for a different request receives a 422.
+// Server errors are not stored, so a request that failed can be retried with
+// the same key. Requests without the header are passed through unchanged.
+func Idempotency(logger *slog.Logger, store IdempotencyStore, ttl time.Duration) Func {
+	logger = logger.With(slog.String("func", "middleware.Idempotency"))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(idempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			ctx, span := tracer.Start(r.Context(), "middleware.Idempotency")
+			defer span.End()
+
+			if len(key) > maxIdempotencyKeyLength {
+				writeProblem(
+					ctx,
+					w,
+					r,
+					problemtype.BadRequest,
+					fmt.Sprintf(
+						"The Idempotency-Key header must be at most %d characters.",
+						maxIdempotencyKeyLength,
+					),
+				)
+
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				logger.ErrorContext(
+					ctx,
+					"failed to read request body",
+					slog.String("error", err.Error()),
+				)
+				span.SetStatus(codes.Error, "reading body failed")
+				span.RecordError(err)
+
+				writeProblem(ctx, w, r, problemtype.BadRequest, "The request body could not be read.")
+
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			storeKey := idempotencyStoreKey(r, key)
+			fingerprint := idempotencyFingerprint(r, body)
+			span.SetAttributes(attribute.String("idempotency.key", storeKey))
+
+			// Claim the key, or find out what happened to the request that did
+			pending, err := json.Marshal(idempotentResponse{Fingerprint: fingerprint})
+			if err != nil {
+				logger.ErrorContext(
+					ctx,
+					"failed to marshal idempotent request",
+					slog.String("error", err.Error()),
+				)
+				span.SetStatus(codes.Error, "marshal failed")
+				span.RecordError(err)
+
+				writeProblem(
+					ctx,
+					w,
+					r,
+					problemtype.Internal,
+					"An unexpected error occurred.",
+				)
+
+				return
+			}
+			claimed, err := store.SetNX(ctx, storeKey, pending, idempotencyLockExpiration).Result()
+			if err != nil {
+				writeStoreUnavailable(ctx, w, r, logger, err)
+
+				return
+			}
+
+			if !claimed {
+				stored, err := store.Get(ctx, storeKey).Bytes()
+				if errors.Is(err, redis.Nil) {
+					// The first request finished with a server error, or its
+					// claim expired, between our claim and read; the key is
+					// free again, so the client only needs to retry.
+					writeProblem(
+						ctx,
+						w,
+						r,
+						problemtype.RequestInProgress,
+						"A request with this Idempotency-Key is already in progress.",
+					)
+
+					return
+				}
+				if err != nil {
+					writeStoreUnavailable(ctx, w, r, logger, err)
+
+					return
+				}
+
+				var previous idempotentResponse
+				if err = json.Unmarshal(stored, &previous); err != nil {
+					writeStoreUnavailable(ctx, w, r, logger, err)
+
+					return
+				}
+
+				switch {
+				case previous.Fingerprint != fingerprint:
+					logger.InfoContext(ctx, "idempotency key reused for a different request")
+
+					writeProblem(
+						ctx,
+						w,
+						r,
+						problemtype.IdempotencyKeyReused,
+						"The Idempotency-Key has already been used for a different request.",
+					)
+				case !previous.Done:
+					logger.InfoContext(ctx, "idempotent request already in progress")
+
+					writeProblem(
+						ctx,
+						w,
+						r,
+						problemtype.RequestInProgress,
+						"A request with this Idempotency-Key is already in progress.",
+					)
+				default:
+					logger.DebugContext(ctx, "replaying idempotent response")
+
+					for name, values := range previous.Header {
+						if w.Header().Get(name) == "" {
+							w.Header()[name] = values
+						}
+					}
+					w.Header().Set(idempotentReplayedHeader, strconv.FormatBool(true))
+					w.WriteHeader(previous.Status)
+					_, _ = w.Write(previous.Body)
+				}
+
+				return
+			}
+
+			// Handle the request, then store its response for any retries
+			recorder := &recordingWriter{ResponseWriter: w}
+			next.ServeHTTP(recorder, r.WithContext(ctx))
+
+			if recorder.header == nil || recorder.statusCode >= http.StatusInternalServerError {
+				if err = store.Del(ctx, storeKey).Err(); err != nil {
+					logger.ErrorContext(
+						ctx,
+						"failed to release idempotency key",
+						slog.String("error", err.Error()),
+					)
+				}
+
+				return
+			}
+
+			response, err := json.Marshal(idempotentResponse{
+				Fingerprint: fingerprint,
+				Done:        true,
+				Status:      recorder.statusCode,
+				Header:      recorder.header,
+				Body:        recorder.body.Bytes(),
+			})
+			if err == nil {
+				err = store.Set(ctx, storeKey, response, ttl).Err()
+			}
+			if err != nil {
+				// The response has been sent, so all that is left is to
+				// release the key and let a retry run the request again.
+				logger.ErrorContext(
+					ctx,
+					"failed to store idempotent response",
+					slog.String("error", err.Error()),
+				)
+				span.SetStatus(codes.Error, "storing response failed")
+				span.RecordError(err)
+
+				_ = store.Del(ctx, storeKey).Err()
+			}
+		})
+	}
+}
+
+// idempotencyStoreKey returns the key a response is stored under, which
+// scopes the idempotency key to the client of the request, as identified by
+// requestClient, so that clients cannot replay each other's responses.
+func idempotencyStoreKey(r *http.Request, key string) string {
+	return "idempotency:" + requestClient(r) + ":" + key
+}
+
+// idempotencyFingerprint returns a hash of the method, path and body of a
+// request, which identifies it among requests reusing the same key.
+func idempotencyFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// writeStoreUnavailable logs a failure of the idempotency store and writes a
+// 503, since the request cannot be made safe to retry without it.
+func writeStoreUnavailable(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	logger *slog.Logger,
+	err error,
+) {
+	logger.ErrorContext(
+		ctx,
+		"idempotency store unavailable",
+		slog.String("error", err.Error()),
+	)
+	span := trace.SpanFromContext(ctx)
+	span.SetStatus(codes.Error, "idempotency store unavailable")
+	span.RecordError(err)
+
+	writeProblem(
+		ctx,
+		w,
+		r,
+		problemtype.Unavailable,
+		"The service is temporarily unavailable, please try again later.",
+	)
+}
+
+// LocalIdempotencyStore is an IdempotencyStore kept in the memory of the
+// process, for running without Redis. Keys are only claimed within one
+// instance of the service, so a retry that reaches another instance is not
+// recognised.
+type LocalIdempotencyStore struct {
+	mu        sync.Mutex
+	entries   map[string]localIdempotencyEntry
+	nextSweep time.Time
+	now       func() time.Time
+}
+
+type localIdempotencyEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewLocalIdempotencyStore creates an empty LocalIdempotencyStore.
+func NewLocalIdempotencyStore() *LocalIdempotencyStore {
+	return &LocalIdempotencyStore{entries: map[string]localIdempotencyEntry{}, now: time.Now}
+}
+
+// SetNX satisfies IdempotencyStore.
+func (s *LocalIdempotencyStore) SetNX(
+	_ context.Context,
+	key string,
+	value interface{},
+	expiration time.Duration,
+) *redis.BoolCmd {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.lookup(key); ok {
+		return redis.NewBoolResult(false, nil)
+	}
+	s.set(key, value, expiration)
+
+	return redis.NewBoolResult(true, nil)
+}
+
+// Set satisfies IdempotencyStore.
+func (s *LocalIdempotencyStore) Set(
+	_ context.Context,
+	key string,
+	value interface{},
+	expiration time.Duration,
+) *redis.StatusCmd {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.set(key, value, expiration)
+
+	return redis.NewStatusResult("OK", nil)
+}
+
+// Get satisfies IdempotencyStore.
+func (s *LocalIdempotencyStore) Get(_ context.Context, key string) *redis.StringCmd {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.lookup(key)
+	if !ok {
+		return redis.NewStringResult("", redis.Nil)
+	}
+
+	return redis.NewStringResult(string(entry.value), nil)
+}
+
+// Del satisfies IdempotencyStore.
+func (s *LocalIdempotencyStore) Del(_ context.Context, keys ...string) *redis.IntCmd {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deleted int64
+	for _, key := range keys {
+		if _, ok := s.lookup(key); ok {
+			delete(s.entries, key)
+			deleted++
+		}
+	}
+
+	return redis.NewIntResult(deleted, nil)
+}
+
+// lookup returns the entry under key, removing it if it has expired. Callers
+// hold s.mu.
+func (s *LocalIdempotencyStore) lookup(key string) (localIdempotencyEntry, bool) {
+	entry, ok := s.entries[key]
+	if ok && !s.now().Before(entry.expiresAt) {
+		delete(s.entries, key)
+
+		return localIdempotencyEntry{}, false
+	}
+
+	return entry, ok
+}
+
+// set stores value under key. Expired entries are swept out from time to
+// time, so that keys that are never read again do not accumulate. Callers
+// hold s.mu.
+func (s *LocalIdempotencyStore) set(key string, value interface{}, expiration time.Duration) {
+	now := s.now()
+	if now.After(s.nextSweep) {
+		for k, entry := range s.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(s.entries, k)
+			}
+		}
+		s.nextSweep = now.Add(localSweepInterval)
+	}
+
+	var b []byte
+	switch v := value.(type) {
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		b = fmt.Append(nil, v)
+	}
+	s.entries[key] = localIdempotencyEntry{value: b, expiresAt: now.Add(expiration)}
+}
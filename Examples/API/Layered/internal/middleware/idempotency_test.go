@@ -0,0 +1,263 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+
+	"example.com/examples/api/layered/internal/models"
+)
+
+// memoryStore is an in-memory IdempotencyStore. Expirations are ignored.
+type memoryStore struct {
+	mu     sync.Mutex
+	values map[string][]byte
+	err    error
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{values: map[string][]byte{}}
+}
+
+func (s *memoryStore) SetNX(_ context.Context, key string, value interface{}, _ time.Duration) *redis.BoolCmd {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return redis.NewBoolResult(false, s.err)
+	}
+	if _, ok := s.values[key]; ok {
+		return redis.NewBoolResult(false, nil)
+	}
+	s.values[key] = value.([]byte)
+
+	return redis.NewBoolResult(true, nil)
+}
+
+func (s *memoryStore) Set(_ context.Context, key string, value interface{}, _ time.Duration) *redis.StatusCmd {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values[key] = value.([]byte)
+
+	return redis.NewStatusResult("OK", nil)
+}
+
+func (s *memoryStore) Get(_ context.Context, key string) *redis.StringCmd {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, ok := s.values[key]
+	if !ok {
+		return redis.NewStringResult("", redis.Nil)
+	}
+
+	return redis.NewStringResult(string(value), nil)
+}
+
+func (s *memoryStore) Del(_ context.Context, keys ...string) *redis.IntCmd {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		delete(s.values, key)
+	}
+
+	return redis.NewIntResult(int64(len(keys)), nil)
+}
+
+func TestIdempotency(t *testing.T) {
+	type request struct {
+		key        string
+		body       string
+		principal  *models.Principal
+		remoteAddr string
+	}
+
+	tests := map[string]struct {
+		requests     []request
+		pending      bool
+		handlerCode  int
+		storeErr     error
+		wantStatuses []int
+		wantReplayed []bool
+		wantCalls    int
+	}{
+		"without a key": {
+			requests:     []request{{body: `{"name":"john"}`}, {body: `{"name":"john"}`}},
+			handlerCode:  http.StatusCreated,
+			wantStatuses: []int{http.StatusCreated, http.StatusCreated},
+			wantReplayed: []bool{false, false},
+			wantCalls:    2,
+		},
+		"retry is replayed": {
+			requests:     []request{{key: "k1", body: `{"name":"john"}`}, {key: "k1", body: `{"name":"john"}`}},
+			handlerCode:  http.StatusCreated,
+			wantStatuses: []int{http.StatusCreated, http.StatusCreated},
+			wantReplayed: []bool{false, true},
+			wantCalls:    1,
+		},
+		"different body": {
+			requests:     []request{{key: "k1", body: `{"name":"john"}`}, {key: "k1", body: `{"name":"jane"}`}},
+			handlerCode:  http.StatusCreated,
+			wantStatuses: []int{http.StatusCreated, http.StatusUnprocessableEntity},
+			wantReplayed: []bool{false, false},
+			wantCalls:    1,
+		},
+		"still in progress": {
+			requests:     []request{{key: "k1", body: `{"name":"john"}`}},
+			pending:      true,
+			handlerCode:  http.StatusCreated,
+			wantStatuses: []int{http.StatusConflict},
+			wantReplayed: []bool{false},
+		},
+		"server errors are not stored": {
+			requests:     []request{{key: "k1", body: `{"name":"john"}`}, {key: "k1", body: `{"name":"john"}`}},
+			handlerCode:  http.StatusInternalServerError,
+			wantStatuses: []int{http.StatusInternalServerError, http.StatusInternalServerError},
+			wantReplayed: []bool{false, false},
+			wantCalls:    2,
+		},
+		"keys are scoped to the caller": {
+			requests: []request{
+				{key: "k1", body: `{"name":"john"}`, principal: &models.Principal{UserID: 1}},
+				{key: "k1", body: `{"name":"john"}`, principal: &models.Principal{UserID: 2}},
+			},
+			handlerCode:  http.StatusCreated,
+			wantStatuses: []int{http.StatusCreated, http.StatusCreated},
+			wantReplayed: []bool{false, false},
+			wantCalls:    2,
+		},
+		"anonymous keys are scoped to the address": {
+			requests: []request{
+				{key: "k1", body: `{"name":"john"}`, remoteAddr: "192.0.2.1:1234"},
+				{key: "k1", body: `{"name":"john"}`, remoteAddr: "192.0.2.2:1234"},
+				{key: "k1", body: `{"name":"john"}`, remoteAddr: "192.0.2.1:5678"},
+			},
+			handlerCode:  http.StatusCreated,
+			wantStatuses: []int{http.StatusCreated, http.StatusCreated, http.StatusCreated},
+			wantReplayed: []bool{false, false, true},
+			wantCalls:    2,
+		},
+		"key too long": {
+			requests:     []request{{key: strings.Repeat("k", maxIdempotencyKeyLength+1), body: `{}`}},
+			handlerCode:  http.StatusCreated,
+			wantStatuses: []int{http.StatusBadRequest},
+			wantReplayed: []bool{false},
+		},
+		"store unavailable": {
+			requests:     []request{{key: "k1", body: `{"name":"john"}`}},
+			handlerCode:  http.StatusCreated,
+			storeErr:     errors.New("connection refused"),
+			wantStatuses: []int{http.StatusServiceUnavailable},
+			wantReplayed: []bool{false},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(
+			name, func(t *testing.T) {
+				store := newMemoryStore()
+				store.err = tc.storeErr
+
+				// Create a test handler that echoes the body it received
+				calls := 0
+				next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					calls++
+					body, _ := io.ReadAll(r.Body)
+
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(tc.handlerCode)
+					_, _ = w.Write(body)
+				})
+
+				handler := Idempotency(slog.Default(), store, time.Hour)(next)
+
+				if tc.pending {
+					req := httptest.NewRequest(http.MethodPost, "/user", strings.NewReader(tc.requests[0].body))
+					store.values[idempotencyStoreKey(req, tc.requests[0].key)] = []byte(
+						`{"fingerprint":"` + idempotencyFingerprint(req, []byte(tc.requests[0].body)) + `"}`,
+					)
+				}
+
+				for i, request := range tc.requests {
+					req := httptest.NewRequest(http.MethodPost, "/user", strings.NewReader(request.body))
+					if request.key != "" {
+						req.Header.Set(idempotencyKeyHeader, request.key)
+					}
+					if request.remoteAddr != "" {
+						req.RemoteAddr = request.remoteAddr
+					}
+					if request.principal != nil {
+						req = req.WithContext(context.WithValue(req.Context(), principalKey{}, *request.principal))
+					}
+					rec := httptest.NewRecorder()
+
+					handler.ServeHTTP(rec, req)
+
+					assert.Equal(t, tc.wantStatuses[i], rec.Code, "status of request %d", i)
+					if rec.Code == tc.handlerCode {
+						assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+					} else {
+						assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+					}
+					if tc.wantReplayed[i] {
+						assert.Equal(t, "true", rec.Header().Get(idempotentReplayedHeader))
+					} else {
+						assert.Empty(t, rec.Header().Get(idempotentReplayedHeader))
+					}
+					if rec.Code == tc.handlerCode {
+						assert.Equal(t, request.body, rec.Body.String())
+					}
+				}
+
+				assert.Equal(t, tc.wantCalls, calls)
+			},
+		)
+	}
+}
+
+func TestLocalIdempotencyStore(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	store := NewLocalIdempotencyStore()
+	store.now = func() time.Time { return now }
+	ctx := t.Context()
+
+	claimed, err := store.SetNX(ctx, "key", []byte("pending"), time.Minute).Result()
+	assert.NoError(t, err)
+	assert.True(t, claimed, "first claim")
+
+	claimed, err = store.SetNX(ctx, "key", []byte("other"), time.Minute).Result()
+	assert.NoError(t, err)
+	assert.False(t, claimed, "second claim")
+
+	assert.NoError(t, store.Set(ctx, "key", []byte("done"), time.Hour).Err())
+	value, err := store.Get(ctx, "key").Result()
+	assert.NoError(t, err)
+	assert.Equal(t, "done", value)
+
+	// The stored response outlives the claim, but not its own expiration
+	now = start.Add(time.Hour)
+	_, err = store.Get(ctx, "key").Result()
+	assert.ErrorIs(t, err, redis.Nil)
+
+	assert.NoError(t, store.Set(ctx, "key", []byte("done"), time.Hour).Err())
+	deleted, err := store.Del(ctx, "key", "missing").Result()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	claimed, err = store.SetNX(ctx, "key", []byte("pending"), time.Minute).Result()
+	assert.NoError(t, err)
+	assert.True(t, claimed, "claim after delete")
+}
@@ -16,6 +16,12 @@ func (w *wrappedWriter) WriteHeader(statusCode int) {
 	w.statusCode = statusCode
 }
 
+// Unwrap returns the underlying http.ResponseWriter, so that an
+// http.ResponseController can reach it, e.g. to flush a streamed response.
+func (w *wrappedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 // Logger is a middleware that logs the request method, path, duration, and
 // status code.
 func Logger(logger *slog.Logger) Func {
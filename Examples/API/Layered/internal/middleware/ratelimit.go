@@ -0,0 +1,329 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+
+	"example.com/examples/api/layered/internal/problemtype"
+)
+
+const (
+	// localSweepInterval is how often expired counters are removed from the
+	// in-process fallback.
+	localSweepInterval = time.Minute
+
+	// storeRetryInterval is how long the in-process fallback is used after the
+	// store fails before the store is tried again, so that requests do not all
+	// wait on an unavailable store.
+	storeRetryInterval = 10 * time.Second
+)
+
+// RateLimitStore represents a type capable of keeping expiring counters, such
+// as the Redis client.
+type RateLimitStore interface {
+	redis.Scripter
+	Get(ctx context.Context, key string) *redis.StringCmd
+}
+
+// incrScript increments the counter KEYS[1] and, when that creates it, sets it
+// to expire after ARGV[1] milliseconds. Running both in one script means a
+// counter is never left without an expiry, as it could be if the connection
+// failed between an INCR and an EXPIRE, and costs a single round trip.
+var incrScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return count
+`)
+
+// RateLimit is the number of requests a client may make in a window of time.
+type RateLimit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// counter keeps a count per key that expires after a time to live.
+type counter interface {
+	incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+	get(ctx context.Context, key string) (int64, error)
+}
+
+// redisCounter keeps counts in a RateLimitStore, shared by every instance of
+// the service.
+type redisCounter struct {
+	store RateLimitStore
+}
+
+func (c redisCounter) incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	return incrScript.Run(ctx, c.store, []string{key}, ttl.Milliseconds()).Int64()
+}
+
+func (c redisCounter) get(ctx context.Context, key string) (int64, error) {
+	count, err := c.store.Get(ctx, key).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+
+	return count, err
+}
+
+// localCounter keeps counts in memory, for use when the store is unavailable.
+// Each instance of the service then limits clients on its own.
+type localCounter struct {
+	mu        sync.Mutex
+	counts    map[string]localCount
+	nextSweep time.Time
+	now       func() time.Time
+}
+
+type localCount struct {
+	count     int64
+	expiresAt time.Time
+}
+
+func newLocalCounter(now func() time.Time) *localCounter {
+	return &localCounter{counts: map[string]localCount{}, now: now}
+}
+
+func (c *localCounter) incr(_ context.Context, key string, ttl time.Duration) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if now.After(c.nextSweep) {
+		for k, v := range c.counts {
+			if now.After(v.expiresAt) {
+				delete(c.counts, k)
+			}
+		}
+		c.nextSweep = now.Add(localSweepInterval)
+	}
+
+	v, ok := c.counts[key]
+	if !ok || now.After(v.expiresAt) {
+		v = localCount{expiresAt: now.Add(ttl)}
+	}
+	v.count++
+	c.counts[key] = v
+
+	return v.count, nil
+}
+
+func (c *localCounter) get(_ context.Context, key string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v, ok := c.counts[key]
+	if !ok || c.now().After(v.expiresAt) {
+		return 0, nil
+	}
+
+	return v.count, nil
+}
+
+// RateLimiter limits the rate of requests each client makes to a route using
+// a sliding window. Counts are kept in Redis so that every instance of the
+// service shares them; while Redis is unavailable each instance falls back to
+// counting in memory.
+//
+// The sliding window is approximated from two fixed windows: the count of the
+// current window plus the count of the previous window, weighted by how much
+// of it still overlaps the sliding window.
+type RateLimiter struct {
+	logger       *slog.Logger
+	store        counter
+	fallback     counter
+	now          func() time.Time
+	storeRetryAt atomic.Int64 // Unix nanoseconds before which the store is not used.
+}
+
+// NewRateLimiter creates a new RateLimiter keeping its counts in store.
+func NewRateLimiter(logger *slog.Logger, store RateLimitStore) *RateLimiter {
+	return &RateLimiter{
+		logger:   logger.With(slog.String("func", "middleware.RateLimiter")),
+		store:    redisCounter{store: store},
+		fallback: newLocalCounter(time.Now),
+		now:      time.Now,
+	}
+}
+
+// NewLocalRateLimiter creates a new RateLimiter keeping its counts in memory,
+// for running without a store. Each instance of the service then limits
+// clients on its own.
+func NewLocalRateLimiter(logger *slog.Logger) *RateLimiter {
+	local := newLocalCounter(time.Now)
+
+	return &RateLimiter{
+		logger:   logger.With(slog.String("func", "middleware.RateLimiter")),
+		store:    local,
+		fallback: local,
+		now:      time.Now,
+	}
+}
+
+// Limit is a middleware that allows each client at most limit.Requests
+// requests to the routes it wraps in any window of limit.Window. Routes
+// limited with the same name share a budget. Clients are the authenticated
+// principal, so wrap Limit inside Authenticate, or otherwise the remote IP
+// address.
+//
+// Every response carries RateLimit-Policy, RateLimit-Limit,
+// RateLimit-Remaining and RateLimit-Reset headers, and a client over its limit
+// receives a 429 with a Retry-After header.
+func (l *RateLimiter) Limit(name string, limit RateLimit) Func {
+	policy := strconv.Itoa(limit.Requests) + ";w=" + strconv.Itoa(int(limit.Window.Seconds()))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, span := tracer.Start(r.Context(), "middleware.RateLimit")
+			defer span.End()
+
+			client := requestClient(r)
+			span.SetAttributes(
+				attribute.String("ratelimit.name", name),
+				attribute.String("ratelimit.client", client),
+			)
+
+			// Find the current window and how far through it we are
+			now := l.now()
+			window := now.UnixNano() / int64(limit.Window)
+			elapsed := time.Duration(now.UnixNano() - window*int64(limit.Window))
+			prefix := "ratelimit:" + name + ":" + client + ":"
+
+			var (
+				previous, current int64
+				err               error
+			)
+			if now.UnixNano() >= l.storeRetryAt.Load() {
+				previous, current, err = l.count(ctx, l.store, prefix, window, limit.Window)
+				if err != nil {
+					l.logger.WarnContext(
+						ctx,
+						"rate limit store unavailable, counting in process",
+						slog.String("error", err.Error()),
+					)
+					span.RecordError(err)
+
+					l.storeRetryAt.Store(now.Add(storeRetryInterval).UnixNano())
+				}
+			}
+			if now.UnixNano() < l.storeRetryAt.Load() {
+				span.SetAttributes(attribute.Bool("ratelimit.fallback", true))
+
+				previous, current, _ = l.count(ctx, l.fallback, prefix, window, limit.Window)
+			}
+
+			weight := 1 - float64(elapsed)/float64(limit.Window)
+			estimate := float64(previous)*weight + float64(current)
+			remaining := max(int(float64(limit.Requests)-estimate), 0)
+			reset := limit.Window - elapsed
+
+			w.Header().Set("RateLimit-Policy", policy)
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(limit.Requests))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(remaining))
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(reset)))
+
+			if estimate > float64(limit.Requests) {
+				l.logger.InfoContext(
+					ctx,
+					"rate limit exceeded",
+					slog.String("name", name),
+					slog.String("client", client),
+				)
+				span.SetAttributes(attribute.Bool("ratelimit.exceeded", true))
+
+				retryAfter := limit.retryAfter(previous, current, elapsed)
+				w.Header().Set("Retry-After", strconv.Itoa(max(ceilSeconds(retryAfter), 1)))
+				writeProblem(
+					ctx,
+					w,
+					r,
+					problemtype.TooManyRequests,
+					"The rate limit for this route has been exceeded, please try again later.",
+				)
+
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// count counts the current request in the current window and returns the
+// counts of the previous and current windows.
+func (l *RateLimiter) count(
+	ctx context.Context,
+	c counter,
+	prefix string,
+	window int64,
+	length time.Duration,
+) (int64, int64, error) {
+	// A window's count is needed until the end of the window after it
+	current, err := c.incr(ctx, prefix+strconv.FormatInt(window, 10), 2*length)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	previous, err := c.get(ctx, prefix+strconv.FormatInt(window-1, 10))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return previous, current, nil
+}
+
+// retryAfter returns how long after elapsed into the current window a client
+// that made previous and current requests in the previous and current windows
+// must wait before its next request is allowed, assuming it makes no more
+// requests until then.
+func (l RateLimit) retryAfter(previous int64, current int64, elapsed time.Duration) time.Duration {
+	limit := float64(l.Requests)
+
+	// Within the current window, the previous window's weight falls until its
+	// count and the next request fit
+	if previous > 0 && float64(current)+1 <= limit {
+		at := time.Duration((1 - (limit-float64(current)-1)/float64(previous)) * float64(l.Window))
+
+		return max(at-elapsed, 0)
+	}
+
+	// Otherwise, wait for the current window to become the previous one and
+	// its weight to fall in turn
+	at := time.Duration(math.Max(1-(limit-1)/float64(current), 0) * float64(l.Window))
+
+	return l.Window - elapsed + at
+}
+
+// requestClient identifies the client of a request by its authenticated
+// principal, or by its remote IP address if it is not authenticated. Rate
+// limits are counted, and idempotency keys scoped, per client.
+func requestClient(r *http.Request) string {
+	if principal, ok := GetPrincipal(r.Context()); ok {
+		return "user:" + strconv.FormatUint(uint64(principal.UserID), 10)
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	return "ip:" + host
+}
+
+// ceilSeconds returns d in whole seconds, rounded up.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,238 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+
+	"example.com/examples/api/layered/internal/models"
+)
+
+// counterStore is an in-memory RateLimitStore. Its only script is taken to be
+// incrScript, and expirations are ignored.
+type counterStore struct {
+	redis.Scripter
+	counts map[string]int64
+	calls  int
+	err    error
+}
+
+func (s *counterStore) EvalSha(_ context.Context, _ string, keys []string, _ ...any) *redis.Cmd {
+	s.calls++
+	if s.err != nil {
+		return redis.NewCmdResult(nil, s.err)
+	}
+	s.counts[keys[0]]++
+
+	return redis.NewCmdResult(s.counts[keys[0]], nil)
+}
+
+func (s *counterStore) Get(_ context.Context, key string) *redis.StringCmd {
+	count, ok := s.counts[key]
+	if !ok {
+		return redis.NewStringResult("", redis.Nil)
+	}
+
+	return redis.NewStringResult(strconv.FormatInt(count, 10), nil)
+}
+
+func TestRedisCounter_incr(t *testing.T) {
+	tests := map[string]struct {
+		mockCount int64
+		mockErr   error
+		wantCount int64
+		wantErr   bool
+	}{
+		"first request": {
+			mockCount: 1,
+			wantCount: 1,
+		},
+		"later request": {
+			mockCount: 3,
+			wantCount: 3,
+		},
+		"store unavailable": {
+			mockErr: errors.New("connection refused"),
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, mock := redismock.NewClientMock()
+
+			// The count and its expiry are set in a single round trip
+			expect := mock.ExpectEvalSha(incrScript.Hash(), []string{"ratelimit:test:1"}, int64(120000))
+			if tc.mockErr != nil {
+				expect.SetErr(tc.mockErr)
+			} else {
+				expect.SetVal(tc.mockCount)
+			}
+
+			count, err := redisCounter{store: db}.incr(context.Background(), "ratelimit:test:1", 2*time.Minute)
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.wantCount, count)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRateLimiter_Limit(t *testing.T) {
+	// 15 seconds into a minute long window
+	start := time.Date(2024, 5, 1, 12, 0, 15, 0, time.UTC)
+	limit := RateLimit{Requests: 3, Window: time.Minute}
+
+	tests := map[string]struct {
+		previous       int64
+		storeErr       error
+		requests       int
+		wantStatuses   []int
+		wantRemaining  []string
+		wantRetryAfter string
+	}{
+		"under the limit": {
+			requests:      3,
+			wantStatuses:  []int{http.StatusOK, http.StatusOK, http.StatusOK},
+			wantRemaining: []string{"2", "1", "0"},
+		},
+		"over the limit": {
+			requests:       4,
+			wantStatuses:   []int{http.StatusOK, http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+			wantRemaining:  []string{"2", "1", "0", "0"},
+			wantRetryAfter: "75",
+		},
+		"previous window still counts": {
+			previous:       2,
+			requests:       2,
+			wantStatuses:   []int{http.StatusOK, http.StatusTooManyRequests},
+			wantRemaining:  []string{"0", "0"},
+			wantRetryAfter: "45",
+		},
+		"falls back to counting in process": {
+			storeErr:       errors.New("connection refused"),
+			requests:       4,
+			wantStatuses:   []int{http.StatusOK, http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+			wantRemaining:  []string{"2", "1", "0", "0"},
+			wantRetryAfter: "75",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(
+			name, func(t *testing.T) {
+				store := &counterStore{counts: map[string]int64{}, err: tc.storeErr}
+				window := start.UnixNano() / int64(limit.Window)
+				store.counts["ratelimit:test:ip:192.0.2.1:"+strconv.FormatInt(window-1, 10)] = tc.previous
+
+				limiter := NewRateLimiter(slog.Default(), store)
+				limiter.now = func() time.Time { return start }
+				limiter.fallback = newLocalCounter(limiter.now)
+
+				handler := limiter.Limit("test", limit)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusOK)
+				}))
+
+				var rec *httptest.ResponseRecorder
+				for i := range tc.requests {
+					req := httptest.NewRequest(http.MethodGet, "/blog", nil)
+					req.RemoteAddr = "192.0.2.1:1234"
+					rec = httptest.NewRecorder()
+
+					handler.ServeHTTP(rec, req)
+
+					assert.Equal(t, tc.wantStatuses[i], rec.Code, "status of request %d", i)
+					assert.Equal(t, "3", rec.Header().Get("RateLimit-Limit"))
+					assert.Equal(t, tc.wantRemaining[i], rec.Header().Get("RateLimit-Remaining"), "request %d", i)
+					assert.Equal(t, "45", rec.Header().Get("RateLimit-Reset"))
+					assert.Equal(t, "3;w=60", rec.Header().Get("RateLimit-Policy"))
+				}
+				assert.Equal(t, tc.wantRetryAfter, rec.Header().Get("Retry-After"))
+
+				if tc.storeErr != nil {
+					// The store is not tried again until the retry interval
+					assert.Equal(t, 1, store.calls)
+				}
+			},
+		)
+	}
+}
+
+func TestNewLocalRateLimiter(t *testing.T) {
+	limiter := NewLocalRateLimiter(slog.Default())
+	handler := limiter.Limit("test", RateLimit{Requests: 2, Window: time.Minute})(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+
+	var statuses []int
+	for range 3 {
+		req := httptest.NewRequest(http.MethodGet, "/blog", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		statuses = append(statuses, rec.Code)
+	}
+
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}, statuses)
+}
+
+func TestRateLimit_retryAfter(t *testing.T) {
+	limit := RateLimit{Requests: 10, Window: time.Minute}
+
+	tests := map[string]struct {
+		previous int64
+		current  int64
+		elapsed  time.Duration
+		want     time.Duration
+	}{
+		"previous window decays within the current one": {
+			previous: 10,
+			current:  5,
+			elapsed:  15 * time.Second,
+			want:     21 * time.Second,
+		},
+		"current window is full": {
+			previous: 0,
+			current:  11,
+			elapsed:  15 * time.Second,
+			want:     45*time.Second + 60*time.Second*2/11,
+		},
+		"current window is exactly full": {
+			previous: 4,
+			current:  10,
+			elapsed:  30 * time.Second,
+			want:     30*time.Second + 6*time.Second,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.InDelta(t, tc.want, limit.retryAfter(tc.previous, tc.current, tc.elapsed), float64(time.Millisecond))
+		})
+	}
+}
+
+func TestRequestClient(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/blog", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	assert.Equal(t, "ip:192.0.2.1", requestClient(req))
+
+	req = req.WithContext(context.WithValue(req.Context(), principalKey{}, models.Principal{UserID: 7}))
+	assert.Equal(t, "user:7", requestClient(req))
+}
@@ -6,12 +6,19 @@ import (
 )
 
 // Recover is a middleware that recover from panics that occur in the handlers,
-// logs the error, and returns a 500 status code.
+// logs the error, and returns a 500 status code. Panics with
+// http.ErrAbortHandler are passed on, so that the server aborts the response.
 func Recover(logger *slog.Logger) Func {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			defer func() {
 				if rc := recover(); rc != nil {
+					// An aborted response has already been started, and is
+					// aborted on purpose, so leave it to the server.
+					if rc == http.ErrAbortHandler {
+						panic(rc)
+					}
+
 					logger.InfoContext(
 						r.Context(),
 						"panic recovered",
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Principal represents the authenticated caller of a request.
+type Principal struct {
+	UserID uint `json:"userId"`
+	Role   Role `json:"role"`
+}
+
+// TokenPair represents the access and refresh tokens issued to a user on
+// login or refresh.
+type TokenPair struct {
+	AccessToken           string    `json:"accessToken"`
+	AccessTokenExpiresAt  time.Time `json:"accessTokenExpiresAt"`
+	RefreshToken          string    `json:"refreshToken"`
+	RefreshTokenExpiresAt time.Time `json:"refreshTokenExpiresAt"`
+}
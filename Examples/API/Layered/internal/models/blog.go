@@ -0,0 +1,9 @@
+package models
+
+// Blog represents a blog post written by a user in the system.
+type Blog struct {
+	ID       uint    `db:"id"        json:"id"`
+	AuthorID uint    `db:"author_id" json:"authorId"`
+	Title    string  `db:"title"     json:"title"`
+	Score    float32 `db:"score"     json:"score"`
+}
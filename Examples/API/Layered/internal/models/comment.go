@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Comment represents a comment left by a user on a blog.
+type Comment struct {
+	ID        uint      `db:"id"         json:"id"`
+	UserID    uint      `db:"user_id"    json:"userId"`
+	BlogID    uint      `db:"blog_id"    json:"blogId"`
+	Message   string    `db:"message"    json:"message"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+}
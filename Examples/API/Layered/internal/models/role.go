@@ -0,0 +1,11 @@
+package models
+
+// Role represents the role a user holds, which decides the routes they may
+// call.
+type Role string
+
+// Roles a user may hold. New users are given RoleUser.
+const (
+	RoleAdmin Role = "admin"
+	RoleUser  Role = "user"
+)
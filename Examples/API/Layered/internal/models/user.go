@@ -1,9 +1,45 @@
 package models
 
-// User represents a user in the system.
+import "time"
+
+// User represents a user in the system. Password holds the plaintext password
+// on its way into the service and the bcrypt hash when read for credential
+// checks; it is never marshaled, so it cannot leak into the cache or a
+// response. Version is incremented and UpdatedAt set on every update; together
+// they identify the state of the user for optimistic concurrency and
+// conditional requests.
 type User struct {
-	ID       uint   `db:"id"       json:"id"`
-	Name     string `db:"name"     json:"name"`
-	Email    string `db:"email"    json:"email"`
-	Password string `db:"password" json:"password"`
+	ID        uint      `db:"id"         json:"id"`
+	Name      string    `db:"name"       json:"name"`
+	Email     string    `db:"email"      json:"email"`
+	Password  string    `db:"password"   json:"-"`
+	Role      Role      `db:"role"       json:"role"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt time.Time `db:"updated_at" json:"updatedAt"`
+	Version   uint64    `db:"version"    json:"version"`
+}
+
+// UserPatch represents a partial update of a user. Only the non-nil fields
+// are changed; Password holds the plaintext password, which is hashed before
+// it is stored.
+type UserPatch struct {
+	Name     *string
+	Email    *string
+	Password *string
+}
+
+// UserImportRow is a user read from a bulk import, along with the line of the
+// import it was read from so that problems can be reported against it.
+type UserImportRow struct {
+	Line int
+	User User
+}
+
+// UserImportResult summarises a bulk import of users. Imported counts the users
+// created, or that would have been created by a dry run, and DuplicateLines
+// lists the lines whose email was already taken by an existing user or an
+// earlier line.
+type UserImportResult struct {
+	Imported       int
+	DuplicateLines []int
 }
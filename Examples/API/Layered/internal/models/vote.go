@@ -0,0 +1,14 @@
+package models
+
+// Vote values. A vote either raises or lowers a blog's score by one.
+const (
+	VoteUp   = 1
+	VoteDown = -1
+)
+
+// Vote represents a single user's up or down vote on a blog.
+type Vote struct {
+	UserID uint `db:"user_id" json:"userId"`
+	BlogID uint `db:"blog_id" json:"blogId"`
+	Value  int  `db:"value"   json:"value"`
+}
@@ -0,0 +1,230 @@
+// Package problemtype is the registry of the problem types of the API, as
+// described by RFC 9457. Every problem detail the API returns names one of
+// these types in its type member, so that clients can tell problems apart by
+// a stable URI rather than by parsing titles or details:
+//
+//	{"type": "/api/problems/not-found", "title": "Not Found", "status": 404, ...}
+//
+// Type URIs are relative to the API and each one serves human-readable
+// documentation for its type.
+package problemtype
+
+import (
+	"cmp"
+	"maps"
+	"net/http"
+	"slices"
+
+	"example.com/examples/api/layered/internal/errs"
+)
+
+// BasePath is the path under which the documentation of every problem type
+// is served, and so the prefix of every type URI.
+const BasePath = "/api/problems/"
+
+// Type is a problem type. Every problem of a type has the same title and
+// status, and may carry the type's extension members as well as the members
+// every problem has.
+type Type struct {
+	// Slug identifies the type within the registry and is the last segment
+	// of its URI.
+	Slug        string
+	Title       string
+	Status      int
+	Description string
+	Extensions  []Extension
+}
+
+// Extension is a member of a problem detail beyond those RFC 9457 defines.
+type Extension struct {
+	Name        string
+	Description string
+}
+
+// URI returns the URI of the type, or about:blank for the zero Type, which
+// RFC 9457 defines as a problem with no semantics beyond its status code.
+func (t Type) URI() string {
+	if t.Slug == "" {
+		return "about:blank"
+	}
+
+	return BasePath + t.Slug
+}
+
+// TraceID is the extension member every problem carries, identifying the
+// trace of the request that failed.
+var TraceID = Extension{
+	Name:        "traceId",
+	Description: "The ID of the trace of the request, to quote when reporting the problem.",
+}
+
+// invalidParams is the extension member of problems caused by particular
+// request parameters.
+var invalidParams = Extension{
+	Name: "invalidParams",
+	Description: "The parameters that caused the problem, each with its field, a code naming the rule it broke, " +
+		"a message and, for filter and sort expressions, its position.",
+}
+
+// registry holds every registered type by its slug.
+var registry = map[string]Type{}
+
+// The problem types of the API.
+var (
+	BadRequest = register(Type{
+		Slug:        "bad-request",
+		Title:       "Bad Request",
+		Status:      http.StatusBadRequest,
+		Description: "The request could not be understood, such as a malformed body or an ID that is not a number.",
+	})
+	InvalidParameters = register(Type{
+		Slug:        "invalid-parameters",
+		Title:       "Bad Request",
+		Status:      http.StatusBadRequest,
+		Description: "One or more parameters of the request failed validation. Correct them and try again.",
+		Extensions:  []Extension{invalidParams},
+	})
+	Unauthorized = register(Type{
+		Slug:        "unauthorized",
+		Title:       "Unauthorized",
+		Status:      http.StatusUnauthorized,
+		Description: "The request has no valid bearer access token, or its credentials are wrong.",
+	})
+	Forbidden = register(Type{
+		Slug:        "forbidden",
+		Title:       "Forbidden",
+		Status:      http.StatusForbidden,
+		Description: "The caller is authenticated but is not permitted to make the request.",
+	})
+	NotFound = register(Type{
+		Slug:        "not-found",
+		Title:       "Not Found",
+		Status:      http.StatusNotFound,
+		Description: "The requested resource, or one the request refers to, does not exist.",
+	})
+	NotAcceptable = register(Type{
+		Slug:        "not-acceptable",
+		Title:       "Not Acceptable",
+		Status:      http.StatusNotAcceptable,
+		Description: "The response cannot be encoded in any media type the Accept header of the request accepts.",
+	})
+	Conflict = register(Type{
+		Slug:        "conflict",
+		Title:       "Conflict",
+		Status:      http.StatusConflict,
+		Description: "The request conflicts with the current state of a resource, such as a value that is already taken.",
+		Extensions:  []Extension{invalidParams},
+	})
+	RequestInProgress = register(Type{
+		Slug:        "request-in-progress",
+		Title:       "Conflict",
+		Status:      http.StatusConflict,
+		Description: "A request with the same Idempotency-Key is still being processed. Retry it once that one completes.",
+	})
+	PreconditionFailed = register(Type{
+		Slug:        "precondition-failed",
+		Title:       "Precondition Failed",
+		Status:      http.StatusPreconditionFailed,
+		Description: "The resource has changed since it was read, so it does not match the If-Match header of the request.",
+	})
+	UnsupportedMediaType = register(Type{
+		Slug:        "unsupported-media-type",
+		Title:       "Unsupported Media Type",
+		Status:      http.StatusUnsupportedMediaType,
+		Description: "The body of the request is in a media type the route cannot decode.",
+	})
+	Unprocessable = register(Type{
+		Slug:        "unprocessable",
+		Title:       "Unprocessable Entity",
+		Status:      http.StatusUnprocessableEntity,
+		Description: "The request is well formed but cannot be applied, such as a reference to a deleted resource.",
+		Extensions:  []Extension{invalidParams},
+	})
+	IdempotencyKeyReused = register(Type{
+		Slug:        "idempotency-key-reused",
+		Title:       "Unprocessable Entity",
+		Status:      http.StatusUnprocessableEntity,
+		Description: "The Idempotency-Key of the request was already used for a request with a different body.",
+	})
+	TooManyRequests = register(Type{
+		Slug:        "too-many-requests",
+		Title:       "Too Many Requests",
+		Status:      http.StatusTooManyRequests,
+		Description: "The caller has exceeded the rate limit of the route. Retry after the Retry-After header's seconds.",
+	})
+	Internal = register(Type{
+		Slug:        "internal",
+		Title:       "Internal Server Error",
+		Status:      http.StatusInternalServerError,
+		Description: "An unexpected error occurred. Quote the traceId when reporting it.",
+	})
+	Unavailable = register(Type{
+		Slug:        "unavailable",
+		Title:       "Service Unavailable",
+		Status:      http.StatusServiceUnavailable,
+		Description: "A dependency of the service is temporarily unavailable. The request may succeed if retried.",
+	})
+)
+
+// byKind maps each kind of domain error to its type.
+var byKind = map[errs.Kind]Type{
+	errs.Internal:     Internal,
+	errs.NotFound:     NotFound,
+	errs.Conflict:     Conflict,
+	errs.Invalid:      Unprocessable,
+	errs.Unauthorized: Unauthorized,
+	errs.Unavailable:  Unavailable,
+}
+
+// byStatus maps status codes to the type of problems that name no type of
+// their own.
+var byStatus = map[int]Type{
+	http.StatusBadRequest:           BadRequest,
+	http.StatusUnauthorized:         Unauthorized,
+	http.StatusForbidden:            Forbidden,
+	http.StatusNotFound:             NotFound,
+	http.StatusNotAcceptable:        NotAcceptable,
+	http.StatusConflict:             Conflict,
+	http.StatusPreconditionFailed:   PreconditionFailed,
+	http.StatusUnsupportedMediaType: UnsupportedMediaType,
+	http.StatusUnprocessableEntity:  Unprocessable,
+	http.StatusTooManyRequests:      TooManyRequests,
+	http.StatusInternalServerError:  Internal,
+	http.StatusServiceUnavailable:   Unavailable,
+}
+
+// register adds t to the registry and returns it.
+func register(t Type) Type {
+	registry[t.Slug] = t
+
+	return t
+}
+
+// Lookup returns the type with slug. The boolean is false if there is none.
+func Lookup(slug string) (Type, bool) {
+	t, ok := registry[slug]
+
+	return t, ok
+}
+
+// All returns every registered type, ordered by status and then slug.
+func All() []Type {
+	return slices.SortedFunc(maps.Values(registry), func(a, b Type) int {
+		return cmp.Or(cmp.Compare(a.Status, b.Status), cmp.Compare(a.Slug, b.Slug))
+	})
+}
+
+// ForKind returns the type of problems caused by a domain error of kind.
+func ForKind(kind errs.Kind) Type {
+	if t, ok := byKind[kind]; ok {
+		return t
+	}
+
+	return Internal
+}
+
+// ForStatus returns the type of a problem with status that names no type of
+// its own, or the zero Type, about:blank, if the status has none.
+func ForStatus(status int) Type {
+	return byStatus[status]
+}
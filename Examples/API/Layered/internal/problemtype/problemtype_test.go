@@ -0,0 +1,50 @@
+package problemtype
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/examples/api/layered/internal/errs"
+)
+
+func TestRegistry(t *testing.T) {
+	all := All()
+	assert.Len(t, all, len(registry))
+
+	for i, pt := range all {
+		got, ok := Lookup(pt.Slug)
+		assert.True(t, ok, "type %s is not registered", pt.Slug)
+		assert.Equal(t, pt, got)
+		assert.Equal(t, "/api/problems/"+pt.Slug, pt.URI())
+		assert.NotEmpty(t, pt.Description, "type %s has no description", pt.Slug)
+		if i > 0 {
+			assert.LessOrEqual(t, all[i-1].Status, pt.Status, "types are not ordered by status")
+		}
+	}
+
+	_, ok := Lookup("does-not-exist")
+	assert.False(t, ok)
+}
+
+func TestForKind(t *testing.T) {
+	for _, kind := range []errs.Kind{
+		errs.Internal, errs.NotFound, errs.Conflict, errs.Invalid, errs.Unauthorized, errs.Unavailable,
+	} {
+		_, ok := Lookup(ForKind(kind).Slug)
+		assert.True(t, ok, "kind %s has no registered type", kind)
+	}
+
+	assert.Equal(t, Unprocessable, ForKind(errs.Invalid))
+	assert.Equal(t, Internal, ForKind(errs.Kind(255)))
+}
+
+func TestForStatus(t *testing.T) {
+	for status, pt := range byStatus {
+		assert.Equal(t, status, pt.Status, "type %s", pt.Slug)
+	}
+
+	assert.Equal(t, BadRequest, ForStatus(http.StatusBadRequest))
+	assert.Equal(t, "about:blank", ForStatus(http.StatusTeapot).URI())
+}
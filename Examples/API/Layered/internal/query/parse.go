@@ -0,0 +1,518 @@
+package query
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// operator describes a comparison operator of the filter language.
+type operator struct {
+	sql   string
+	types []Type
+}
+
+// operators maps the operators of the filter language to their SQL and the
+// field types they apply to. co, sw and ew match substrings and ignore case.
+var operators = map[string]operator{
+	"eq": {sql: "=", types: []Type{String, Int, Float, Time}},
+	"ne": {sql: "<>", types: []Type{String, Int, Float, Time}},
+	"gt": {sql: ">", types: []Type{Int, Float, Time}},
+	"ge": {sql: ">=", types: []Type{Int, Float, Time}},
+	"lt": {sql: "<", types: []Type{Int, Float, Time}},
+	"le": {sql: "<=", types: []Type{Int, Float, Time}},
+	"co": {sql: "LIKE", types: []Type{String}},
+	"sw": {sql: "LIKE", types: []Type{String}},
+	"ew": {sql: "LIKE", types: []Type{String}},
+}
+
+// tokenKind is the kind of a lexical token.
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenLParen
+	tokenRParen
+)
+
+// token is a lexical token of a filter expression.
+type token struct {
+	kind tokenKind
+	// text is the token as written, including any quotes.
+	text string
+	// value is the unquoted value of a string token.
+	value string
+	// pos is the 1-based position of the token in the expression.
+	pos int
+}
+
+// lex splits a filter expression into tokens. Words run until whitespace, a
+// parenthesis or a quote, so dates and signed numbers are single words.
+func lex(filter string) ([]token, *Error) {
+	var tokens []token
+
+	runes := []rune(filter)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: i + 1})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: i + 1})
+			i++
+		case r == '"':
+			start := i
+			var value strings.Builder
+			for i++; ; i++ {
+				if i >= len(runes) {
+					return nil, &Error{
+						Param:    "filter",
+						Code:     "syntax",
+						Message:  "unterminated string",
+						Token:    string(runes[start:]),
+						Position: start + 1,
+					}
+				}
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+					value.WriteRune(runes[i])
+
+					continue
+				}
+				if runes[i] == '"' {
+					i++
+
+					break
+				}
+				value.WriteRune(runes[i])
+			}
+			tokens = append(tokens, token{
+				kind:  tokenString,
+				text:  string(runes[start:i]),
+				value: value.String(),
+				pos:   start + 1,
+			})
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune(`()"`, runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: string(runes[start:i]), pos: start + 1})
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(runes) + 1}), nil
+}
+
+// parser is a recursive descent parser for filter expressions:
+//
+//	expr       = term { "or" term }
+//	term       = factor { "and" factor }
+//	factor     = "not" factor | "(" expr ")" | comparison
+//	comparison = field operator value
+type parser[T any] struct {
+	schema Schema[T]
+	tokens []token
+	next   int
+}
+
+// ParseFilter parses a filter expression. An empty filter matches every row
+// and is returned as a nil Expr. The first problem found is returned as
+// Errors holding a single *Error.
+func (s Schema[T]) ParseFilter(filter string) (Expr, error) {
+	if strings.TrimSpace(filter) == "" {
+		return nil, nil
+	}
+
+	tokens, lexErr := lex(filter)
+	if lexErr != nil {
+		return nil, Errors{lexErr}
+	}
+
+	p := parser[T]{schema: s, tokens: tokens}
+
+	expr, err := p.parseExpr()
+	if err != nil {
+		return nil, Errors{err}
+	}
+
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, Errors{p.errorAt(tok, "syntax", fmt.Sprintf("unexpected %q, expected and or or", tok.text))}
+	}
+
+	return expr, nil
+}
+
+// peek returns the next token without consuming it.
+func (p *parser[T]) peek() token {
+	return p.tokens[p.next]
+}
+
+// advance consumes and returns the next token.
+func (p *parser[T]) advance() token {
+	tok := p.tokens[p.next]
+	if tok.kind != tokenEOF {
+		p.next++
+	}
+
+	return tok
+}
+
+// keyword reports whether the next token is the provided keyword, ignoring
+// case, and consumes it if it is.
+func (p *parser[T]) keyword(keyword string) bool {
+	tok := p.peek()
+	if tok.kind == tokenWord && strings.EqualFold(tok.text, keyword) {
+		p.next++
+
+		return true
+	}
+
+	return false
+}
+
+func (p *parser[T]) parseExpr() (Expr, *Error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.keyword("or") {
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = logical{op: "OR", left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser[T]) parseTerm() (Expr, *Error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.keyword("and") {
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		left = logical{op: "AND", left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser[T]) parseFactor() (Expr, *Error) {
+	if p.keyword("not") {
+		expr, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+
+		return not{expr: expr}, nil
+	}
+
+	if p.peek().kind == tokenLParen {
+		p.advance()
+
+		expr, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+
+		if tok := p.advance(); tok.kind != tokenRParen {
+			return nil, p.errorAt(tok, "syntax", fmt.Sprintf("unexpected %s, expected )", describe(tok)))
+		}
+
+		return expr, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *parser[T]) parseComparison() (Expr, *Error) {
+	fieldTok := p.advance()
+	if fieldTok.kind != tokenWord {
+		return nil, p.errorAt(fieldTok, "syntax", fmt.Sprintf("unexpected %s, expected a field", describe(fieldTok)))
+	}
+
+	field, ok := p.schema.Fields[fieldTok.text]
+	if !ok {
+		return nil, p.errorAt(fieldTok, "unknown_field", fmt.Sprintf("unknown field %q", fieldTok.text))
+	}
+
+	opTok := p.advance()
+	if opTok.kind != tokenWord {
+		return nil, p.errorAt(opTok, "syntax", fmt.Sprintf("unexpected %s, expected an operator", describe(opTok)))
+	}
+
+	opName := strings.ToLower(opTok.text)
+	op, ok := operators[opName]
+	if !ok {
+		return nil, p.errorAt(opTok, "unknown_operator", fmt.Sprintf("unknown operator %q", opTok.text))
+	}
+	if !supports(op, field.Type) {
+		return nil, p.errorAt(
+			opTok,
+			"invalid_operator",
+			fmt.Sprintf("operator %q cannot be used with field %q", opName, fieldTok.text),
+		)
+	}
+
+	valueTok := p.advance()
+	value, err := parseValue(field.Type, valueTok)
+	if err != nil {
+		return nil, p.errorAt(valueTok, "invalid_value", err.Error())
+	}
+
+	// Substring operators match case-insensitively against a LIKE pattern.
+	switch opName {
+	case "co":
+		value = "%" + escapeLike(value.(string)) + "%"
+	case "sw":
+		value = escapeLike(value.(string)) + "%"
+	case "ew":
+		value = "%" + escapeLike(value.(string))
+	}
+
+	return comparison{column: field.Column, op: op.sql, value: value, fold: op.sql == "LIKE"}, nil
+}
+
+// errorAt returns an *Error pointing at the provided filter token.
+func (p *parser[T]) errorAt(tok token, code string, message string) *Error {
+	return &Error{Param: "filter", Code: code, Message: message, Token: tok.text, Position: tok.pos}
+}
+
+// describe returns a description of a token for use in error messages.
+func describe(tok token) string {
+	if tok.kind == tokenEOF {
+		return "end of filter"
+	}
+
+	return strconv.Quote(tok.text)
+}
+
+// supports reports whether op can be used with fields of type t.
+func supports(op operator, t Type) bool {
+	for _, supported := range op.types {
+		if supported == t {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseValue parses the value token of a comparison against a field of type
+// t.
+func parseValue(t Type, tok token) (any, error) {
+	switch tok.kind {
+	case tokenString:
+		if t != String && t != Time {
+			return nil, fmt.Errorf("expected a number, got string %s", tok.text)
+		}
+	case tokenWord:
+		if t == String {
+			return nil, fmt.Errorf("expected a quoted string, got %q", tok.text)
+		}
+	default:
+		return nil, fmt.Errorf("unexpected %s, expected a value", describe(tok))
+	}
+
+	switch t {
+	case Int:
+		v, err := strconv.ParseInt(tok.text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected an integer, got %q", tok.text)
+		}
+
+		return v, nil
+	case Float:
+		v, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("expected a number, got %q", tok.text)
+		}
+
+		return v, nil
+	case Time:
+		text := tok.text
+		if tok.kind == tokenString {
+			text = tok.value
+		}
+
+		v, err := parseTime(text)
+		if err != nil {
+			return nil, fmt.Errorf("expected an RFC 3339 date or date-time, got %q", text)
+		}
+
+		return v, nil
+	default:
+		return tok.value, nil
+	}
+}
+
+// parseTime parses an RFC 3339 date-time, or a date which is taken as
+// midnight UTC.
+func parseTime(text string) (time.Time, error) {
+	if v, err := time.Parse(time.RFC3339Nano, text); err == nil {
+		return v, nil
+	}
+
+	return time.Parse(time.DateOnly, text)
+}
+
+// escapeLike escapes the LIKE wildcards in s so that it is matched literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// ParseSort parses a sort expression into orders. An empty sort sorts by the
+// schema key alone; otherwise the key is appended as a tiebreaker unless it
+// is already present. Every problem found is returned as Errors.
+func (s Schema[T]) ParseSort(sort string) ([]Order, error) {
+	var (
+		orders []Order
+		errs   Errors
+		seen   = map[string]bool{}
+	)
+
+	pos := 1
+	for _, item := range strings.Split(sort, ",") {
+		itemPos := pos
+		pos += len([]rune(item)) + 1
+
+		// Skip leading whitespace, which is also what a + becomes when it
+		// is not percent-encoded in a query string.
+		trimmed := strings.TrimLeftFunc(item, unicode.IsSpace)
+		itemPos += len([]rune(item)) - len([]rune(trimmed))
+		trimmed = strings.TrimRightFunc(trimmed, unicode.IsSpace)
+
+		if trimmed == "" {
+			if strings.TrimSpace(sort) != "" {
+				errs = append(errs, &Error{
+					Param:    "sort",
+					Code:     "syntax",
+					Message:  "empty sort field",
+					Position: itemPos,
+				})
+			}
+
+			continue
+		}
+
+		name, desc := trimmed, false
+		switch name[0] {
+		case '-':
+			name, desc = name[1:], true
+		case '+':
+			name = name[1:]
+		}
+
+		field, ok := s.Fields[name]
+		if !ok {
+			errs = append(errs, &Error{
+				Param:    "sort",
+				Code:     "unknown_field",
+				Message:  fmt.Sprintf("unknown field %q", name),
+				Token:    trimmed,
+				Position: itemPos,
+			})
+
+			continue
+		}
+
+		if seen[name] {
+			errs = append(errs, &Error{
+				Param:    "sort",
+				Code:     "duplicate_field",
+				Message:  fmt.Sprintf("field %q is sorted on more than once", name),
+				Token:    trimmed,
+				Position: itemPos,
+			})
+
+			continue
+		}
+		seen[name] = true
+
+		orders = append(orders, Order{Field: name, Column: field.Column, Desc: desc})
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	if !seen[s.Key] {
+		orders = append(orders, Order{Field: s.Key, Column: s.Fields[s.Key].Column})
+	}
+
+	return orders, nil
+}
+
+// After converts cursor values, as produced by Cursor and decoded from JSON,
+// back into values of the types of the sort fields so they can be used as
+// List.After.
+func (s Schema[T]) After(orders []Order, values []any) ([]any, error) {
+	if len(values) != len(orders) {
+		return nil, fmt.Errorf(
+			"[in query.Schema.After] expected %d cursor values, got %d",
+			len(orders),
+			len(values),
+		)
+	}
+
+	after := make([]any, len(values))
+	for i, order := range orders {
+		v, err := convert(s.Fields[order.Field].Type, values[i])
+		if err != nil {
+			return nil, fmt.Errorf("[in query.Schema.After] invalid value for %q: %w", order.Field, err)
+		}
+		after[i] = v
+	}
+
+	return after, nil
+}
+
+// convert converts a value decoded from JSON into a value of type t.
+func convert(t Type, value any) (any, error) {
+	switch t {
+	case Int:
+		v, ok := value.(float64)
+		if !ok || v != math.Trunc(v) {
+			return nil, fmt.Errorf("expected an integer, got %v", value)
+		}
+
+		return int64(v), nil
+	case Float:
+		v, ok := value.(float64)
+		if !ok {
+			return nil, fmt.Errorf("expected a number, got %v", value)
+		}
+
+		return v, nil
+	case Time:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a timestamp, got %v", value)
+		}
+
+		return time.Parse(time.RFC3339Nano, s)
+	default:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string, got %v", value)
+		}
+
+		return s, nil
+	}
+}
@@ -0,0 +1,130 @@
+// Package query implements the small filter and sort language accepted by the
+// list endpoints, and renders parsed queries as parameterised SQL.
+//
+// A filter is one or more comparisons joined with and, or and not, grouped
+// with parentheses:
+//
+//	name co "smith" and (created_at gt 2024-01-01 or role eq "admin")
+//
+// A sort is a comma separated list of fields, each optionally prefixed with -
+// for descending order:
+//
+//	-created_at,name
+//
+// Only the fields declared in a Schema may be used, so column names in the
+// generated SQL never come from the client.
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Type is the type of a field. It decides which operators a field supports
+// and how its values are parsed.
+type Type int
+
+const (
+	// String fields hold text and take quoted values.
+	String Type = iota
+	// Int fields hold integers.
+	Int
+	// Float fields hold decimal numbers.
+	Float
+	// Time fields hold timestamps, given as an RFC 3339 date or date-time.
+	Time
+)
+
+// Field describes a field clients may filter and sort on.
+type Field[T any] struct {
+	// Column is the SQL column the field maps to.
+	Column string
+	// Type is the type of the column.
+	Type Type
+	// Value returns the value of the field for a row. It is used to build
+	// pagination cursors and may be nil for lists that are not paginated.
+	Value func(T) any
+}
+
+// Schema declares the fields of T that clients may filter and sort on.
+type Schema[T any] struct {
+	// Fields maps the field names clients use to their columns.
+	Fields map[string]Field[T]
+	// Key is the name of a unique field. It is appended to every sort so
+	// that rows with equal sort values still have a stable order.
+	Key string
+}
+
+// Order is a single sort key.
+type Order struct {
+	Field  string
+	Column string
+	Desc   bool
+}
+
+// FormatSort formats orders using the same syntax ParseSort accepts.
+func FormatSort(orders []Order) string {
+	fields := make([]string, len(orders))
+	for i, order := range orders {
+		if order.Desc {
+			fields[i] = "-" + order.Field
+		} else {
+			fields[i] = order.Field
+		}
+	}
+
+	return strings.Join(fields, ",")
+}
+
+// Cursor returns the values of the sort fields of row, in sort order. Passed
+// back as List.After, after conversion by Schema.After, they resume a list
+// directly after row.
+func (s Schema[T]) Cursor(row T, orders []Order) ([]any, error) {
+	values := make([]any, len(orders))
+	for i, order := range orders {
+		field, ok := s.Fields[order.Field]
+		if !ok || field.Value == nil {
+			return nil, fmt.Errorf("[in query.Schema.Cursor] field %q has no value", order.Field)
+		}
+
+		values[i] = field.Value(row)
+	}
+
+	return values, nil
+}
+
+// Error describes a problem with a filter or sort expression and points at
+// the token that caused it.
+type Error struct {
+	// Param is the query parameter the expression came from, either filter
+	// or sort.
+	Param string
+	// Code is a short, machine-readable description of the problem.
+	Code string
+	// Message is a human-readable description of the problem.
+	Message string
+	// Token is the offending token; it is empty at the end of the
+	// expression.
+	Token string
+	// Position is the 1-based position of the offending token in the
+	// expression.
+	Position int
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s (at position %d)", e.Param, e.Message, e.Position)
+}
+
+// Errors collects every Error found in an expression.
+type Errors []*Error
+
+// Error implements the error interface.
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+
+	return strings.Join(messages, "; ")
+}
@@ -0,0 +1,288 @@
+package query
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testRow struct {
+	ID        uint
+	Name      string
+	Score     float32
+	CreatedAt time.Time
+}
+
+var testSchema = Schema[testRow]{
+	Fields: map[string]Field[testRow]{
+		"id":         {Column: "id", Type: Int, Value: func(r testRow) any { return r.ID }},
+		"name":       {Column: "name", Type: String, Value: func(r testRow) any { return r.Name }},
+		"score":      {Column: "score", Type: Float, Value: func(r testRow) any { return r.Score }},
+		"created_at": {Column: "created_at", Type: Time, Value: func(r testRow) any { return r.CreatedAt }},
+	},
+	Key: "id",
+}
+
+func TestSchema_ParseFilter(t *testing.T) {
+	jan1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		filter   string
+		wantSQL  string
+		wantArgs []any
+	}{
+		"empty": {
+			filter: "  ",
+		},
+		"substring and date": {
+			filter:   `name co "smith" and created_at gt 2024-01-01`,
+			wantSQL:  `WHERE (LOWER(name) LIKE LOWER($1) ESCAPE '\' AND created_at > $2)`,
+			wantArgs: []any{"%smith%", jan1},
+		},
+		"keywords and operators ignore case": {
+			filter:   `id GE 2 AND score Lt 7.5`,
+			wantSQL:  `WHERE (id >= $1 AND score < $2)`,
+			wantArgs: []any{int64(2), 7.5},
+		},
+		"and binds tighter than or": {
+			filter:   `id eq 1 or id eq 2 and name eq "bob"`,
+			wantSQL:  `WHERE (id = $1 OR (id = $2 AND name = $3))`,
+			wantArgs: []any{int64(1), int64(2), "bob"},
+		},
+		"parentheses and not": {
+			filter:   `not (id eq 1 or id eq 2) and name sw "a"`,
+			wantSQL:  `WHERE (NOT ((id = $1 OR id = $2)) AND LOWER(name) LIKE LOWER($3) ESCAPE '\')`,
+			wantArgs: []any{int64(1), int64(2), "a%"},
+		},
+		"wildcards and quotes are literal": {
+			filter:   `name ew "50%_\"off\""`,
+			wantSQL:  `WHERE LOWER(name) LIKE LOWER($1) ESCAPE '\'`,
+			wantArgs: []any{`%50\%\_"off"`},
+		},
+		"quoted date-time": {
+			filter:   `created_at le "2024-01-01T00:00:00Z"`,
+			wantSQL:  `WHERE created_at <= $1`,
+			wantArgs: []any{jan1},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			filter, err := testSchema.ParseFilter(tc.filter)
+			require.NoError(t, err)
+
+			sql, args := List{Filter: filter}.SQL()
+			assert.Equal(t, tc.wantSQL, sql)
+			assert.Equal(t, tc.wantArgs, args)
+		})
+	}
+}
+
+func TestSchema_ParseFilter_Errors(t *testing.T) {
+	tests := map[string]struct {
+		filter       string
+		wantCode     string
+		wantToken    string
+		wantPosition int
+	}{
+		"unknown field": {
+			filter:       `name co "smith" and nmae eq "x"`,
+			wantCode:     "unknown_field",
+			wantToken:    "nmae",
+			wantPosition: 21,
+		},
+		"unknown operator": {
+			filter:       `name like "smith"`,
+			wantCode:     "unknown_operator",
+			wantToken:    "like",
+			wantPosition: 6,
+		},
+		"operator not supported by type": {
+			filter:       `name gt "smith"`,
+			wantCode:     "invalid_operator",
+			wantToken:    "gt",
+			wantPosition: 6,
+		},
+		"unquoted string": {
+			filter:       `name eq smith`,
+			wantCode:     "invalid_value",
+			wantToken:    "smith",
+			wantPosition: 9,
+		},
+		"bad date": {
+			filter:       `created_at gt 2024-13-01`,
+			wantCode:     "invalid_value",
+			wantToken:    "2024-13-01",
+			wantPosition: 15,
+		},
+		"bad integer": {
+			filter:       `id eq 1.5`,
+			wantCode:     "invalid_value",
+			wantToken:    "1.5",
+			wantPosition: 7,
+		},
+		"missing value": {
+			filter:       `id eq`,
+			wantCode:     "invalid_value",
+			wantToken:    "",
+			wantPosition: 6,
+		},
+		"unterminated string": {
+			filter:       `name eq "smith`,
+			wantCode:     "syntax",
+			wantToken:    `"smith`,
+			wantPosition: 9,
+		},
+		"unclosed parenthesis": {
+			filter:       `(id eq 1`,
+			wantCode:     "syntax",
+			wantToken:    "",
+			wantPosition: 9,
+		},
+		"trailing token": {
+			filter:       `id eq 1 id eq 2`,
+			wantCode:     "syntax",
+			wantToken:    "id",
+			wantPosition: 9,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := testSchema.ParseFilter(tc.filter)
+
+			var errs Errors
+			require.ErrorAs(t, err, &errs)
+			require.Len(t, errs, 1)
+			assert.Equal(t, "filter", errs[0].Param)
+			assert.Equal(t, tc.wantCode, errs[0].Code)
+			assert.Equal(t, tc.wantToken, errs[0].Token)
+			assert.Equal(t, tc.wantPosition, errs[0].Position)
+		})
+	}
+}
+
+func TestSchema_ParseSort(t *testing.T) {
+	tests := map[string]struct {
+		sort          string
+		wantOrders    []Order
+		wantPositions []int
+	}{
+		"empty sorts by key": {
+			sort:       "",
+			wantOrders: []Order{{Field: "id", Column: "id"}},
+		},
+		"key is appended as tiebreaker": {
+			sort: "-created_at, name",
+			wantOrders: []Order{
+				{Field: "created_at", Column: "created_at", Desc: true},
+				{Field: "name", Column: "name"},
+				{Field: "id", Column: "id"},
+			},
+		},
+		"unescaped plus": {
+			sort: " name,-id",
+			wantOrders: []Order{
+				{Field: "name", Column: "name"},
+				{Field: "id", Column: "id", Desc: true},
+			},
+		},
+		"every bad field is reported": {
+			sort:          "nmae,-id,,id,-score",
+			wantPositions: []int{1, 10, 11},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			orders, err := testSchema.ParseSort(tc.sort)
+			if tc.wantPositions != nil {
+				var errs Errors
+				require.ErrorAs(t, err, &errs)
+
+				positions := make([]int, len(errs))
+				for i, e := range errs {
+					assert.Equal(t, "sort", e.Param)
+					positions[i] = e.Position
+				}
+				assert.Equal(t, tc.wantPositions, positions)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantOrders, orders)
+		})
+	}
+}
+
+func TestList_SQL(t *testing.T) {
+	jan1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	filter, err := testSchema.ParseFilter(`name co "a"`)
+	require.NoError(t, err)
+
+	orders, err := testSchema.ParseSort("-created_at")
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		list     List
+		wantSQL  string
+		wantArgs []any
+	}{
+		"empty": {
+			list: List{},
+		},
+		"first page": {
+			list:     List{Filter: filter, Sort: orders, Limit: 10},
+			wantSQL:  `WHERE LOWER(name) LIKE LOWER($1) ESCAPE '\' ORDER BY created_at DESC, id LIMIT $2`,
+			wantArgs: []any{"%a%", 10},
+		},
+		"next page": {
+			list: List{Filter: filter, Sort: orders, After: []any{jan1, int64(7)}, Limit: 10},
+			wantSQL: `WHERE LOWER(name) LIKE LOWER($1) ESCAPE '\' ` +
+				`AND (created_at < $2 OR (created_at = $2 AND (id > $3))) ` +
+				`ORDER BY created_at DESC, id LIMIT $4`,
+			wantArgs: []any{"%a%", jan1, int64(7), 10},
+		},
+		"next page without filter": {
+			list:     List{Sort: []Order{{Field: "id", Column: "id"}}, After: []any{int64(7)}, Limit: 10},
+			wantSQL:  `WHERE (id > $1) ORDER BY id LIMIT $2`,
+			wantArgs: []any{int64(7), 10},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			sql, args := tc.list.SQL()
+			assert.Equal(t, tc.wantSQL, sql)
+			assert.Equal(t, tc.wantArgs, args)
+		})
+	}
+}
+
+func TestSchema_CursorRoundTrip(t *testing.T) {
+	row := testRow{ID: 7, Name: "bob", Score: 1.5, CreatedAt: time.Date(2024, 5, 1, 12, 30, 0, 123000, time.UTC)}
+
+	orders, err := testSchema.ParseSort("-created_at,score,name")
+	require.NoError(t, err)
+
+	values, err := testSchema.Cursor(row, orders)
+	require.NoError(t, err)
+
+	// Cursors travel as JSON, which loses the Go types of the values.
+	b, err := json.Marshal(values)
+	require.NoError(t, err)
+
+	var decoded []any
+	require.NoError(t, json.Unmarshal(b, &decoded))
+
+	after, err := testSchema.After(orders, decoded)
+	require.NoError(t, err)
+	assert.Equal(t, []any{row.CreatedAt, 1.5, "bob", int64(7)}, after)
+
+	_, err = testSchema.After(orders, decoded[:2])
+	require.Error(t, err)
+
+	_, err = testSchema.After(orders, []any{"yesterday", 1.5, "bob", 7.0})
+	require.Error(t, err)
+}
@@ -0,0 +1,164 @@
+package query
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Expr is a parsed filter expression.
+type Expr interface {
+	writeSQL(b *builder)
+}
+
+// comparison compares a column against a value. Folded comparisons ignore
+// case.
+type comparison struct {
+	column string
+	op     string
+	value  any
+	fold   bool
+}
+
+func (c comparison) writeSQL(b *builder) {
+	if c.fold {
+		b.WriteString("LOWER(" + c.column + ") " + c.op + " LOWER(" + b.arg(c.value) + `) ESCAPE '\'`)
+
+		return
+	}
+
+	b.WriteString(c.column + " " + c.op + " " + b.arg(c.value))
+}
+
+// logical joins two expressions with AND or OR.
+type logical struct {
+	op    string
+	left  Expr
+	right Expr
+}
+
+func (l logical) writeSQL(b *builder) {
+	b.WriteString("(")
+	l.left.writeSQL(b)
+	b.WriteString(" " + l.op + " ")
+	l.right.writeSQL(b)
+	b.WriteString(")")
+}
+
+// not negates an expression.
+type not struct {
+	expr Expr
+}
+
+func (n not) writeSQL(b *builder) {
+	b.WriteString("NOT (")
+	n.expr.writeSQL(b)
+	b.WriteString(")")
+}
+
+// builder accumulates SQL and its arguments, numbering placeholders from $1.
+type builder struct {
+	strings.Builder
+	args []any
+}
+
+// arg adds an argument and returns its placeholder.
+func (b *builder) arg(v any) string {
+	b.args = append(b.args, v)
+
+	return "$" + strconv.Itoa(len(b.args))
+}
+
+// List describes a filtered, sorted and optionally paginated list query.
+type List struct {
+	// Filter restricts the rows returned; nil matches every row.
+	Filter Expr
+	// Sort orders the rows. It should end with a unique field, as the
+	// orders returned by ParseSort do, for pagination to be stable.
+	Sort []Order
+	// After holds the sort values of the last row of the previous page, as
+	// returned by Schema.After; nil starts at the first row.
+	After []any
+	// Limit is the maximum number of rows returned; zero means no limit.
+	Limit int
+}
+
+// SQL renders the WHERE, ORDER BY and LIMIT clauses of the list, to be
+// appended to a SELECT, and the arguments of their placeholders.
+func (l List) SQL() (string, []any) {
+	var b builder
+
+	var conditions []func()
+	if l.Filter != nil {
+		conditions = append(conditions, func() { l.Filter.writeSQL(&b) })
+	}
+	if len(l.After) > 0 && len(l.After) == len(l.Sort) {
+		conditions = append(conditions, func() { l.writeAfter(&b) })
+	}
+
+	for i, condition := range conditions {
+		if i == 0 {
+			b.WriteString("WHERE ")
+		} else {
+			b.WriteString(" AND ")
+		}
+		condition()
+	}
+
+	if len(l.Sort) > 0 {
+		if len(conditions) > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString("ORDER BY ")
+		for i, order := range l.Sort {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(order.Column)
+			if order.Desc {
+				b.WriteString(" DESC")
+			}
+		}
+	}
+
+	if l.Limit > 0 {
+		if b.Len() > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString("LIMIT " + b.arg(l.Limit))
+	}
+
+	return b.String(), b.args
+}
+
+// writeAfter writes the keyset condition selecting the rows that sort after
+// l.After. For sort columns a, b and c it is
+//
+//	(a > $1 OR (a = $1 AND (b > $2 OR (b = $2 AND (c > $3)))))
+//
+// with < in place of > for descending columns.
+func (l List) writeAfter(b *builder) {
+	placeholders := make([]string, len(l.After))
+	for i, v := range l.After {
+		placeholders[i] = b.arg(v)
+	}
+
+	for i, order := range l.Sort {
+		op := " > "
+		if order.Desc {
+			op = " < "
+		}
+
+		b.WriteString("(" + order.Column + op + placeholders[i])
+		if i < len(l.Sort)-1 {
+			b.WriteString(" OR (" + order.Column + " = " + placeholders[i] + " AND ")
+		}
+	}
+
+	for i := range l.Sort {
+		if i < len(l.Sort)-1 {
+			b.WriteString("))")
+		} else {
+			b.WriteString(")")
+		}
+	}
+}
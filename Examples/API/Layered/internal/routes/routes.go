@@ -1,15 +1,22 @@
 package routes
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"time"
 
 	httpSwagger "github.com/swaggo/http-swagger/v2"
 
 	// Import the generated Swagger docs
 	_ "example.com/examples/api/layered/cmd/api/docs"
+	"example.com/examples/api/layered/internal/errs"
 	"example.com/examples/api/layered/internal/handlers"
+	"example.com/examples/api/layered/internal/middleware"
+	"example.com/examples/api/layered/internal/models"
 	"example.com/examples/api/layered/internal/services"
+	"example.com/examples/api/layered/internal/validation"
 )
 
 // endpointMapper is an interface that defines a method for registering HTTP handlers
@@ -32,22 +39,134 @@ type endpointMapper interface {
 //	@BasePath					/api
 //	@externalDocs.description	OpenAPI
 //	@externalDocs.url			https://swagger.io/resources/open-api/
+//
+//	@securityDefinitions.apikey	BearerAuth
+//	@in							header
+//	@name						Authorization
+//	@description				Access token from /auth/login, sent as "Bearer {token}"
 func AddRoutes(
 	mux endpointMapper,
 	logger *slog.Logger,
 	usersService *services.UsersService,
+	blogsService *services.BlogsService,
+	commentsService *services.CommentsService,
+	authService *services.AuthService,
+	validate *validation.Validator,
+	idempotent middleware.Func,
+	limiter *middleware.RateLimiter,
 	swaggerEnabled bool,
 ) {
-	// User endpoints
-	mux.Handle("GET /api/user/{id}", handlers.HandleReadUser(logger, usersService))
-	mux.Handle("GET /api/user", handlers.HandleListUsers(logger, usersService))
-	mux.Handle("POST /api/user", handlers.HandleCreateUser(logger, usersService))
-	mux.Handle("PUT /api/user/{id}", handlers.HandleUpdateUser(logger, usersService))
-	mux.Handle("DELETE /api/user/{id}", handlers.HandleDeleteUser(logger, usersService))
+	// protect requires a valid bearer access token on a route and checks the
+	// caller against the route's permission. Every protected route declares
+	// its permission here, next to its pattern.
+	authenticate := middleware.Authenticate(logger, authService)
+	protect := func(permission middleware.Permission, handler http.Handler) http.Handler {
+		return authenticate(middleware.Authorize(logger, permission)(handler))
+	}
+
+	var (
+		adminOnly   = middleware.HasRole(models.RoleAdmin)
+		selfOrAdmin = middleware.AnyOf(middleware.IsSelf("id"), adminOnly)
+	)
+
+	// Every route but the health check is rate limited, per authenticated
+	// user on protected routes and per IP address otherwise. Routes sharing a
+	// limit share a budget. Routes that create something are also wrapped in
+	// idempotent, so that clients can retry them safely by sending an
+	// Idempotency-Key header.
+	var (
+		authLimit   = limiter.Limit("auth", middleware.RateLimit{Requests: 10, Window: time.Minute})
+		signUpLimit = limiter.Limit("signup", middleware.RateLimit{Requests: 10, Window: time.Hour})
+		bulkLimit   = limiter.Limit("bulk", middleware.RateLimit{Requests: 5, Window: time.Minute})
+		readLimit   = limiter.Limit("read", middleware.RateLimit{Requests: 300, Window: time.Minute})
+		writeLimit  = limiter.Limit("write", middleware.RateLimit{Requests: 60, Window: time.Minute})
+	)
+
+	// Auth endpoints
+	mux.Handle("POST /api/auth/login", authLimit(handlers.HandleLogin(logger, validate, authService)))
+	mux.Handle("POST /api/auth/refresh", authLimit(handlers.HandleRefreshToken(logger, validate, authService)))
+
+	// User endpoints. Creating a user stays open so that new users can sign up.
+	mux.Handle(
+		"GET /api/user/{id}",
+		protect(middleware.Authenticated, readLimit(handlers.HandleReadUser(logger, usersService))),
+	)
+	mux.Handle("GET /api/user", protect(adminOnly, readLimit(handlers.HandleListUsers(logger, usersService))))
+	mux.Handle("POST /api/user", signUpLimit(idempotent(handlers.HandleCreateUser(logger, validate, usersService))))
+	mux.Handle(
+		"POST /api/user/import",
+		protect(adminOnly, bulkLimit(handlers.HandleImportUsers(logger, validate, usersService))),
+	)
+	mux.Handle(
+		"GET /api/user/export",
+		protect(adminOnly, bulkLimit(handlers.HandleExportUsers(logger, usersService))),
+	)
+	mux.Handle(
+		"PUT /api/user/{id}",
+		protect(selfOrAdmin, writeLimit(handlers.HandleUpdateUser(logger, validate, usersService))),
+	)
+	mux.Handle(
+		"PATCH /api/user/{id}",
+		protect(selfOrAdmin, writeLimit(handlers.HandlePatchUser(logger, validate, usersService))),
+	)
+	mux.Handle(
+		"DELETE /api/user/{id}",
+		protect(adminOnly, writeLimit(handlers.HandleDeleteUser(logger, usersService))),
+	)
+	mux.Handle(
+		"GET /api/user/{id}/comments",
+		protect(middleware.Authenticated, readLimit(handlers.HandleListUserComments(logger, commentsService))),
+	)
+
+	// Blog endpoints. Blogs and comments can be changed or removed only by
+	// their authors and by admins.
+	var (
+		blogOwnerOrAdmin    = middleware.AnyOf(middleware.IsOwner(blogAuthor(blogsService)), adminOnly)
+		commentOwnerOrAdmin = middleware.AnyOf(middleware.IsOwner(commentAuthor(commentsService)), adminOnly)
+	)
+	mux.Handle("GET /api/blog/{id}", readLimit(handlers.HandleReadBlog(logger, blogsService)))
+	mux.Handle("GET /api/blog", readLimit(handlers.HandleListBlogs(logger, blogsService)))
+	mux.Handle(
+		"POST /api/blog",
+		protect(middleware.Authenticated, writeLimit(idempotent(handlers.HandleCreateBlog(logger, validate, blogsService)))),
+	)
+	mux.Handle(
+		"PUT /api/blog/{id}",
+		protect(blogOwnerOrAdmin, writeLimit(handlers.HandleUpdateBlog(logger, validate, blogsService))),
+	)
+	mux.Handle(
+		"DELETE /api/blog/{id}",
+		protect(blogOwnerOrAdmin, writeLimit(handlers.HandleDeleteBlog(logger, blogsService))),
+	)
+	mux.Handle(
+		"POST /api/blog/{id}/vote",
+		protect(middleware.Authenticated, writeLimit(idempotent(handlers.HandleVoteBlog(logger, validate, blogsService)))),
+	)
+
+	// Comment endpoints
+	mux.Handle(
+		"GET /api/blog/{id}/comments",
+		readLimit(handlers.HandleListBlogComments(logger, commentsService)),
+	)
+	mux.Handle(
+		"POST /api/blog/{id}/comments",
+		protect(
+			middleware.Authenticated,
+			writeLimit(idempotent(handlers.HandleCreateComment(logger, validate, commentsService))),
+		),
+	)
+	mux.Handle(
+		"DELETE /api/blog/{id}/comments/{commentId}",
+		protect(commentOwnerOrAdmin, writeLimit(handlers.HandleDeleteComment(logger, commentsService))),
+	)
 
 	// Health check
 	mux.Handle("GET /api/health", handlers.HandleHealthCheck(logger, usersService))
 
+	// Problem type documentation, served at each type's URI
+	mux.Handle("GET /api/problems/{$}", readLimit(handlers.HandleListProblemTypes(logger)))
+	mux.Handle("GET /api/problems/{type}", readLimit(handlers.HandleReadProblemType(logger)))
+
 	if swaggerEnabled {
 		// Swagger docs
 		mux.Handle(
@@ -59,3 +178,33 @@ func AddRoutes(
 	// Catch-all route for 404 Not Found with problem detail
 	mux.Handle("/", handlers.HandleCatchAll())
 }
+
+// blogAuthor looks up the author of the blog named by the id path value.
+func blogAuthor(blogsService *services.BlogsService) middleware.OwnerLookup {
+	return func(r *http.Request) (uint, error) {
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+		if err != nil {
+			return 0, errs.E(errs.Invalid, err)
+		}
+
+		blog, err := blogsService.ReadBlog(r.Context(), id)
+
+		return blog.AuthorID, err
+	}
+}
+
+// commentAuthor looks up the author of the comment named by the commentId
+// path value, on the blog named by the id path value.
+func commentAuthor(commentsService *services.CommentsService) middleware.OwnerLookup {
+	return func(r *http.Request) (uint, error) {
+		blogID, blogErr := strconv.ParseUint(r.PathValue("id"), 10, 64)
+		id, idErr := strconv.ParseUint(r.PathValue("commentId"), 10, 64)
+		if err := errors.Join(blogErr, idErr); err != nil {
+			return 0, errs.E(errs.Invalid, err)
+		}
+
+		comment, err := commentsService.ReadComment(r.Context(), blogID, id)
+
+		return comment.UserID, err
+	}
+}
@@ -0,0 +1,291 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/codes"
+
+	"example.com/examples/api/layered/internal/models"
+)
+
+// tokenIssuer is the iss claim set on, and required of, every token.
+const tokenIssuer = "api-layered-user-service"
+
+// Token types. The type is carried in every token so that a refresh token can
+// never be used as an access token and vice versa.
+const (
+	accessTokenType  = "access"
+	refreshTokenType = "refresh"
+)
+
+// tokenClaims represents the claims carried by access and refresh tokens. The
+// user id is carried in the sub claim.
+type tokenClaims struct {
+	TokenType string      `json:"token_type"`
+	Role      models.Role `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// credentialsVerifier represents a type capable of checking an email and
+// password pair and returning the matching user or an error.
+type credentialsVerifier interface {
+	VerifyCredentials(ctx context.Context, email string, password string) (models.User, error)
+}
+
+// AuthService is a service capable of issuing and verifying the signed JWT
+// access and refresh tokens used to authenticate callers.
+type AuthService struct {
+	logger            *slog.Logger
+	db                *sqlx.DB
+	users             credentialsVerifier
+	secret            []byte
+	accessExpiration  time.Duration
+	refreshExpiration time.Duration
+}
+
+// NewAuthService creates a new AuthService and returns a pointer to it. Tokens
+// are signed with HMAC-SHA256 using the provided secret.
+func NewAuthService(
+	logger *slog.Logger,
+	db *sqlx.DB,
+	users credentialsVerifier,
+	secret []byte,
+	accessExpiration time.Duration,
+	refreshExpiration time.Duration,
+) *AuthService {
+	return &AuthService{
+		logger:            logger,
+		db:                db,
+		users:             users,
+		secret:            secret,
+		accessExpiration:  accessExpiration,
+		refreshExpiration: refreshExpiration,
+	}
+}
+
+// Login attempts to authenticate the user with the provided email and
+// password, returning a new models.TokenPair or an error.
+// ErrInvalidCredentials is returned if the credentials do not match a user.
+func (s *AuthService) Login(ctx context.Context, email string, password string) (models.TokenPair, error) {
+	const name = "services.AuthService.Login"
+
+	ctx, span := tracer.Start(ctx, name)
+	defer span.End()
+
+	logger := s.logger.With(slog.String("func", name))
+	logger.DebugContext(ctx, "Logging in user")
+
+	user, err := s.users.VerifyCredentials(ctx, email, password)
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to verify credentials")
+		span.RecordError(err)
+
+		return models.TokenPair{}, fmt.Errorf(
+			"[in services.AuthService.Login] failed to verify credentials: %w",
+			err,
+		)
+	}
+
+	tokens, err := s.issueTokens(models.Principal{UserID: user.ID, Role: user.Role})
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to issue tokens")
+		span.RecordError(err)
+
+		return models.TokenPair{}, fmt.Errorf(
+			"[in services.AuthService.Login] failed to issue tokens: %w",
+			err,
+		)
+	}
+
+	return tokens, nil
+}
+
+// Refresh attempts to exchange the provided refresh token for a new
+// models.TokenPair. The user's role is re-read so that role changes take
+// effect on refresh. ErrInvalidToken is returned if the token is not a valid
+// refresh token or its user no longer exists.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (models.TokenPair, error) {
+	const name = "services.AuthService.Refresh"
+
+	ctx, span := tracer.Start(ctx, name)
+	defer span.End()
+
+	logger := s.logger.With(slog.String("func", name))
+	logger.DebugContext(ctx, "Refreshing tokens")
+
+	principal, err := s.parseToken(refreshToken, refreshTokenType)
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to parse refresh token")
+		span.RecordError(err)
+
+		return models.TokenPair{}, fmt.Errorf(
+			"[in services.AuthService.Refresh] failed to parse refresh token: %w",
+			err,
+		)
+	}
+
+	// A refresh token outlives its access tokens, so make sure the user has
+	// not been deleted since it was issued and pick up their current role.
+	err = s.db.GetContext(
+		ctx,
+		&principal.Role,
+		`
+		SELECT role
+		FROM users
+		WHERE id = $1::int
+		`,
+		principal.UserID,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return models.TokenPair{}, fmt.Errorf(
+				"[in services.AuthService.Refresh] user %d no longer exists: %w",
+				principal.UserID,
+				ErrInvalidToken,
+			)
+		default:
+			span.SetStatus(codes.Error, "failed to read user role")
+			span.RecordError(err)
+
+			return models.TokenPair{}, fmt.Errorf(
+				"[in services.AuthService.Refresh] failed to read user role: %w",
+				err,
+			)
+		}
+	}
+
+	tokens, err := s.issueTokens(principal)
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to issue tokens")
+		span.RecordError(err)
+
+		return models.TokenPair{}, fmt.Errorf(
+			"[in services.AuthService.Refresh] failed to issue tokens: %w",
+			err,
+		)
+	}
+
+	return tokens, nil
+}
+
+// VerifyAccessToken attempts to verify the provided access token, returning
+// the models.Principal it was issued to or an error. ErrInvalidToken is
+// returned if the token is not a valid, unexpired access token.
+func (s *AuthService) VerifyAccessToken(ctx context.Context, accessToken string) (models.Principal, error) {
+	const name = "services.AuthService.VerifyAccessToken"
+
+	_, span := tracer.Start(ctx, name)
+	defer span.End()
+
+	principal, err := s.parseToken(accessToken, accessTokenType)
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to parse access token")
+		span.RecordError(err)
+
+		return models.Principal{}, fmt.Errorf(
+			"[in services.AuthService.VerifyAccessToken] failed to parse access token: %w",
+			err,
+		)
+	}
+
+	return principal, nil
+}
+
+// issueTokens signs a new access and refresh token for the provided
+// principal.
+func (s *AuthService) issueTokens(principal models.Principal) (models.TokenPair, error) {
+	now := time.Now()
+
+	accessExpiresAt := now.Add(s.accessExpiration)
+	accessToken, err := s.signToken(principal, accessTokenType, now, accessExpiresAt)
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+
+	refreshExpiresAt := now.Add(s.refreshExpiration)
+	refreshToken, err := s.signToken(principal, refreshTokenType, now, refreshExpiresAt)
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+
+	return models.TokenPair{
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessExpiresAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshExpiresAt,
+	}, nil
+}
+
+// signToken signs a token of the provided type for the provided principal.
+func (s *AuthService) signToken(
+	principal models.Principal,
+	tokenType string,
+	issuedAt time.Time,
+	expiresAt time.Time,
+) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, tokenClaims{
+		TokenType: tokenType,
+		Role:      principal.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    tokenIssuer,
+			Subject:   strconv.FormatUint(uint64(principal.UserID), 10),
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	})
+
+	signed, err := token.SignedString(s.secret)
+	if err != nil {
+		return "", fmt.Errorf("[in services.AuthService.signToken] failed to sign %s token: %w", tokenType, err)
+	}
+
+	return signed, nil
+}
+
+// parseToken verifies the signature, issuer, expiry and type of the provided
+// token and returns the principal it was issued to.
+func (s *AuthService) parseToken(token string, tokenType string) (models.Principal, error) {
+	var claims tokenClaims
+	_, err := jwt.ParseWithClaims(
+		token,
+		&claims,
+		func(*jwt.Token) (any, error) {
+			return s.secret, nil
+		},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(tokenIssuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return models.Principal{}, fmt.Errorf("[in services.AuthService.parseToken] %w: %w", ErrInvalidToken, err)
+	}
+
+	if claims.TokenType != tokenType {
+		return models.Principal{}, fmt.Errorf(
+			"[in services.AuthService.parseToken] expected %s token, got %q: %w",
+			tokenType,
+			claims.TokenType,
+			ErrInvalidToken,
+		)
+	}
+
+	userID, err := strconv.ParseUint(claims.Subject, 10, 0)
+	if err != nil {
+		return models.Principal{}, fmt.Errorf(
+			"[in services.AuthService.parseToken] invalid subject: %w: %w",
+			ErrInvalidToken,
+			err,
+		)
+	}
+
+	return models.Principal{UserID: uint(userID), Role: claims.Role}, nil
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/examples/api/layered/internal/models"
+)
+
+// credentialsVerifierFunc adapts a function to the credentialsVerifier
+// interface.
+type credentialsVerifierFunc func(ctx context.Context, email string, password string) (models.User, error)
+
+func (f credentialsVerifierFunc) VerifyCredentials(
+	ctx context.Context,
+	email string,
+	password string,
+) (models.User, error) {
+	return f(ctx, email, password)
+}
+
+var testSecret = []byte("test-secret")
+
+func newTestAuthService(t *testing.T) (*AuthService, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf(
+			"an error '%s' was not expected when opening a stub database connection",
+			err,
+		)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	users := credentialsVerifierFunc(func(_ context.Context, email string, password string) (models.User, error) {
+		if email != "john@me.com" || password != "password123!" {
+			return models.User{}, ErrInvalidCredentials
+		}
+
+		return models.User{ID: 1, Name: "john", Email: "john@me.com", Role: models.RoleUser}, nil
+	})
+
+	authService := NewAuthService(
+		slog.Default(),
+		sqlx.NewDb(db, "sqlmock"),
+		users,
+		testSecret,
+		time.Minute,
+		time.Hour,
+	)
+
+	return authService, mock
+}
+
+// signTestToken signs a token with the provided claims, for building tokens the
+// service would never issue itself.
+func signTestToken(t *testing.T, method jwt.SigningMethod, key any, claims tokenClaims) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	require.NoError(t, err)
+
+	return token
+}
+
+func TestAuthService_Login(t *testing.T) {
+	testcases := map[string]struct {
+		email         string
+		password      string
+		expectedError error
+	}{
+		"happy path": {
+			email:         "john@me.com",
+			password:      "password123!",
+			expectedError: nil,
+		},
+		"invalid credentials": {
+			email:         "john@me.com",
+			password:      "wrong",
+			expectedError: ErrInvalidCredentials,
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			authService, _ := newTestAuthService(t)
+
+			tokens, err := authService.Login(t.Context(), tc.email, tc.password)
+			require.ErrorIs(t, err, tc.expectedError)
+
+			if tc.expectedError != nil {
+				assert.Equal(t, models.TokenPair{}, tokens)
+
+				return
+			}
+
+			principal, err := authService.VerifyAccessToken(t.Context(), tokens.AccessToken)
+			require.NoError(t, err)
+			assert.Equal(t, models.Principal{UserID: 1, Role: models.RoleUser}, principal)
+			assert.True(t, tokens.RefreshTokenExpiresAt.After(tokens.AccessTokenExpiresAt))
+		})
+	}
+}
+
+func TestAuthService_Refresh(t *testing.T) {
+	testcases := map[string]struct {
+		mockOutput     *sqlmock.Rows
+		mockError      error
+		useAccess      bool
+		expectedOutput models.Principal
+		expectedError  error
+	}{
+		"happy path": {
+			mockOutput:     sqlmock.NewRows([]string{"role"}).AddRow("user"),
+			expectedOutput: models.Principal{UserID: 1, Role: models.RoleUser},
+			expectedError:  nil,
+		},
+		"role changed": {
+			mockOutput:     sqlmock.NewRows([]string{"role"}).AddRow("admin"),
+			expectedOutput: models.Principal{UserID: 1, Role: models.RoleAdmin},
+			expectedError:  nil,
+		},
+		"user deleted": {
+			mockError:     sql.ErrNoRows,
+			expectedError: ErrInvalidToken,
+		},
+		"access token": {
+			useAccess:     true,
+			expectedError: ErrInvalidToken,
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			authService, mock := newTestAuthService(t)
+
+			issued, err := authService.Login(t.Context(), "john@me.com", "password123!")
+			require.NoError(t, err)
+
+			token := issued.RefreshToken
+			if tc.useAccess {
+				token = issued.AccessToken
+			} else {
+				expectation := mock.
+					ExpectQuery(regexp.QuoteMeta(`SELECT role FROM users WHERE id = $1::int`)).
+					WithArgs(1)
+				if tc.mockError != nil {
+					expectation.WillReturnError(tc.mockError)
+				} else {
+					expectation.WillReturnRows(tc.mockOutput)
+				}
+			}
+
+			tokens, err := authService.Refresh(t.Context(), token)
+			require.ErrorIs(t, err, tc.expectedError)
+
+			if tc.expectedError == nil {
+				principal, err := authService.VerifyAccessToken(t.Context(), tokens.AccessToken)
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedOutput, principal)
+			}
+
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestAuthService_VerifyAccessToken(t *testing.T) {
+	now := time.Now()
+	validClaims := func() tokenClaims {
+		return tokenClaims{
+			TokenType: accessTokenType,
+			Role:      models.RoleAdmin,
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    tokenIssuer,
+				Subject:   "1",
+				IssuedAt:  jwt.NewNumericDate(now),
+				ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+			},
+		}
+	}
+
+	testcases := map[string]struct {
+		token          func(t *testing.T) string
+		expectedOutput models.Principal
+		expectedError  error
+	}{
+		"happy path": {
+			token: func(t *testing.T) string {
+				return signTestToken(t, jwt.SigningMethodHS256, testSecret, validClaims())
+			},
+			expectedOutput: models.Principal{UserID: 1, Role: models.RoleAdmin},
+			expectedError:  nil,
+		},
+		"expired": {
+			token: func(t *testing.T) string {
+				claims := validClaims()
+				claims.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Minute))
+
+				return signTestToken(t, jwt.SigningMethodHS256, testSecret, claims)
+			},
+			expectedError: ErrInvalidToken,
+		},
+		"no expiry": {
+			token: func(t *testing.T) string {
+				claims := validClaims()
+				claims.ExpiresAt = nil
+
+				return signTestToken(t, jwt.SigningMethodHS256, testSecret, claims)
+			},
+			expectedError: ErrInvalidToken,
+		},
+		"wrong secret": {
+			token: func(t *testing.T) string {
+				return signTestToken(t, jwt.SigningMethodHS256, []byte("other-secret"), validClaims())
+			},
+			expectedError: ErrInvalidToken,
+		},
+		"wrong algorithm": {
+			token: func(t *testing.T) string {
+				return signTestToken(t, jwt.SigningMethodHS512, testSecret, validClaims())
+			},
+			expectedError: ErrInvalidToken,
+		},
+		"unsigned": {
+			token: func(t *testing.T) string {
+				return signTestToken(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, validClaims())
+			},
+			expectedError: ErrInvalidToken,
+		},
+		"wrong issuer": {
+			token: func(t *testing.T) string {
+				claims := validClaims()
+				claims.Issuer = "someone-else"
+
+				return signTestToken(t, jwt.SigningMethodHS256, testSecret, claims)
+			},
+			expectedError: ErrInvalidToken,
+		},
+		"refresh token": {
+			token: func(t *testing.T) string {
+				claims := validClaims()
+				claims.TokenType = refreshTokenType
+
+				return signTestToken(t, jwt.SigningMethodHS256, testSecret, claims)
+			},
+			expectedError: ErrInvalidToken,
+		},
+		"non numeric subject": {
+			token: func(t *testing.T) string {
+				claims := validClaims()
+				claims.Subject = "john"
+
+				return signTestToken(t, jwt.SigningMethodHS256, testSecret, claims)
+			},
+			expectedError: ErrInvalidToken,
+		},
+		"malformed": {
+			token: func(*testing.T) string {
+				return "not-a-token"
+			},
+			expectedError: ErrInvalidToken,
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			authService, _ := newTestAuthService(t)
+
+			principal, err := authService.VerifyAccessToken(t.Context(), tc.token(t))
+			require.ErrorIs(t, err, tc.expectedError)
+			assert.Equal(t, tc.expectedOutput, principal)
+		})
+	}
+}
@@ -0,0 +1,544 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/codes"
+
+	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/query"
+)
+
+// BlogsService is a service capable of performing CRUD operations for
+// models.Blog models.
+type BlogsService struct {
+	logger *slog.Logger
+	db     *sqlx.DB
+	cache  *Client
+}
+
+// NewBlogsService creates a new BlogsService and returns a pointer to it.
+func NewBlogsService(
+	logger *slog.Logger,
+	db *sqlx.DB,
+	cache Cache,
+	expiration time.Duration,
+) *BlogsService {
+	return &BlogsService{
+		logger: logger,
+		db:     db,
+		cache:  NewClient(cache, expiration),
+	}
+}
+
+// blogCacheKey returns the cache key for the blog with the provided id. Blog
+// keys are namespaced so they do not collide with user keys.
+func blogCacheKey(id uint64) string {
+	return "blog:" + strconv.FormatUint(id, 10)
+}
+
+// CreateBlog attempts to create the provided blog, returning a fully hydrated
+// models.Blog or an error. ErrUserNotFound is returned if the author does not
+// exist.
+func (s *BlogsService) CreateBlog(ctx context.Context, blog models.Blog) (models.Blog, error) {
+	const name = "services.BlogsService.CreateBlog"
+
+	ctx, span := tracer.Start(ctx, name)
+	defer span.End()
+
+	logger := s.logger.With(slog.String("func", name))
+	logger.DebugContext(ctx, "Creating blog", "author_id", blog.AuthorID, "title", blog.Title)
+
+	// Make sure the author exists before writing the blog
+	exists, err := userExists(ctx, s.db, uint64(blog.AuthorID))
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to check author")
+		span.RecordError(err)
+
+		return models.Blog{}, fmt.Errorf(
+			"[in services.BlogsService.CreateBlog] failed to check author: %w",
+			err,
+		)
+	}
+	if !exists {
+		return models.Blog{}, fmt.Errorf(
+			"[in services.BlogsService.CreateBlog] author %d: %w",
+			blog.AuthorID,
+			ErrUserNotFound,
+		)
+	}
+
+	err = s.db.GetContext(
+		ctx,
+		&blog,
+		`
+		INSERT
+		INTO blogs (author_id, title)
+		VALUES ($1, $2)
+		RETURNING id, author_id, title, score
+		`,
+		blog.AuthorID,
+		blog.Title,
+	)
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to create blog")
+		span.RecordError(err)
+
+		return models.Blog{}, fmt.Errorf(
+			"[in services.BlogsService.CreateBlog] failed to create blog: %w",
+			asConstraintError(err),
+		)
+	}
+
+	// Write the blog to the cache
+	logger.DebugContext(ctx, "Setting blog in cache", "id", blog.ID)
+	if err = s.cache.SetMarshal(ctx, blogCacheKey(uint64(blog.ID)), blog); err != nil {
+		span.SetStatus(codes.Error, "failed to write blog to cache")
+		span.RecordError(err)
+
+		return models.Blog{}, fmt.Errorf(
+			"[in services.BlogsService.CreateBlog] failed to write blog to cache: %w",
+			err,
+		)
+	}
+
+	return blog, nil
+}
+
+// ReadBlog attempts to read a blog from the database using the provided id. A
+// fully hydrated models.Blog or error is returned. ErrBlogNotFound is returned
+// if the blog does not exist.
+func (s *BlogsService) ReadBlog(ctx context.Context, id uint64) (models.Blog, error) {
+	const name = "services.BlogsService.ReadBlog"
+
+	ctx, span := tracer.Start(ctx, name)
+	defer span.End()
+
+	logger := s.logger.With(slog.String("func", name))
+	logger.DebugContext(ctx, "Reading blog", "id", id)
+
+	// Check the cache for the blog object
+	logger.DebugContext(ctx, "Reading blog from cache", "id", id)
+
+	var blog models.Blog
+	found, err := s.cache.Get(ctx, blogCacheKey(id)).Unmarshal(&blog)
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to read blog from cache")
+		span.RecordError(err)
+
+		return models.Blog{}, fmt.Errorf(
+			"[in services.BlogsService.ReadBlog] failed to read blog from cache: %w",
+			err,
+		)
+	}
+
+	// If the blog was found in the cache, return it
+	if found {
+		return blog, nil
+	}
+
+	err = s.db.GetContext(
+		ctx,
+		&blog,
+		`
+		SELECT id,
+		       author_id,
+		       title,
+		       score
+		FROM blogs
+		WHERE id = $1::int
+        `,
+		id,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return models.Blog{}, fmt.Errorf(
+				"[in services.BlogsService.ReadBlog] blog %d: %w",
+				id,
+				ErrBlogNotFound,
+			)
+		default:
+			span.SetStatus(codes.Error, "failed to read blog from database")
+			span.RecordError(err)
+
+			return models.Blog{}, fmt.Errorf(
+				"[in services.BlogsService.ReadBlog] failed to read blog: %w",
+				err,
+			)
+		}
+	}
+
+	// Write the blog to the cache
+	logger.DebugContext(ctx, "Setting blog in cache", "id", id)
+	if err = s.cache.SetMarshal(ctx, blogCacheKey(id), blog); err != nil {
+		span.SetStatus(codes.Error, "failed to write blog to cache")
+		span.RecordError(err)
+
+		return models.Blog{}, fmt.Errorf(
+			"[in services.BlogsService.ReadBlog] failed to write blog to cache: %w",
+			err,
+		)
+	}
+
+	return blog, nil
+}
+
+// UpdateBlog attempts to perform an update of the blog with the provided id,
+// updating its title to that of the provided patch object; a blog keeps its
+// author. The updated models.Blog or an error is returned. ErrBlogNotFound is
+// returned if the blog does not exist.
+func (s *BlogsService) UpdateBlog(
+	ctx context.Context,
+	id uint64,
+	patch models.Blog,
+) (models.Blog, error) {
+	const name = "services.BlogsService.UpdateBlog"
+
+	ctx, span := tracer.Start(ctx, name)
+	defer span.End()
+
+	logger := s.logger.With(slog.String("func", name))
+	logger.DebugContext(ctx, "Updating blog", "id", id, "patch", patch)
+
+	var blog models.Blog
+	err := s.db.GetContext(
+		ctx,
+		&blog,
+		`
+		UPDATE blogs
+		SET title = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+		RETURNING id, author_id, title, score
+		`,
+		patch.Title,
+		id,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return models.Blog{}, fmt.Errorf(
+				"[in services.BlogsService.UpdateBlog] blog %d: %w",
+				id,
+				ErrBlogNotFound,
+			)
+		default:
+			span.SetStatus(codes.Error, "failed to update blog")
+			span.RecordError(err)
+
+			return models.Blog{}, fmt.Errorf(
+				"[in services.BlogsService.UpdateBlog] failed to update blog: %w",
+				asConstraintError(err),
+			)
+		}
+	}
+
+	// Write the updated blog to the cache
+	logger.DebugContext(ctx, "Setting updated blog in cache", "id", id)
+	if err = s.cache.SetMarshal(ctx, blogCacheKey(id), blog); err != nil {
+		span.SetStatus(codes.Error, "failed to write updated blog to cache")
+		span.RecordError(err)
+
+		return models.Blog{}, fmt.Errorf(
+			"[in services.BlogsService.UpdateBlog] failed to write updated blog to cache: %w",
+			err,
+		)
+	}
+
+	return blog, nil
+}
+
+// DeleteBlog attempts to delete the blog with the provided id. An error is
+// returned if the delete fails. ErrBlogNotFound is returned if the blog does
+// not exist.
+func (s *BlogsService) DeleteBlog(ctx context.Context, id uint64) error {
+	const name = "services.BlogsService.DeleteBlog"
+
+	ctx, span := tracer.Start(ctx, name)
+	defer span.End()
+
+	logger := s.logger.With(slog.String("func", name))
+	logger.DebugContext(ctx, "Deleting blog", "id", id)
+
+	// Delete blog from blog table
+	result, err := s.db.ExecContext(
+		ctx,
+		`
+		DELETE
+		FROM blogs
+		WHERE id = $1::int
+		`,
+		id,
+	)
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to delete blog")
+		span.RecordError(err)
+
+		return fmt.Errorf(
+			"[in services.BlogsService.DeleteBlog] failed to delete blog: %w",
+			err,
+		)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to get rows affected")
+		span.RecordError(err)
+
+		return fmt.Errorf(
+			"[in services.BlogsService.DeleteBlog] failed to get rows affected: %w",
+			err,
+		)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("[in services.BlogsService.DeleteBlog] blog %d: %w", id, ErrBlogNotFound)
+	}
+
+	// Remove the blog from the cache
+	logger.DebugContext(ctx, "Removing blog from cache", "id", id)
+	if err = s.cache.Delete(ctx, blogCacheKey(id)); err != nil {
+		span.SetStatus(codes.Error, "failed to remove blog from cache")
+		span.RecordError(err)
+
+		return fmt.Errorf(
+			"[in services.BlogsService.DeleteBlog] failed to remove blog from cache: %w",
+			err,
+		)
+	}
+
+	return nil
+}
+
+// VoteBlog attempts to record the provided vote and apply it to the blog's
+// score. The vote is written and the score is updated in a single
+// transaction, so the score always reflects the stored votes. The updated
+// models.Blog or an error is returned. ErrBlogNotFound or ErrUserNotFound is
+// returned if the blog or voting user does not exist, and ErrAlreadyVoted if
+// the user has already voted on the blog.
+func (s *BlogsService) VoteBlog(ctx context.Context, vote models.Vote) (models.Blog, error) {
+	const name = "services.BlogsService.VoteBlog"
+
+	ctx, span := tracer.Start(ctx, name)
+	defer span.End()
+
+	logger := s.logger.With(slog.String("func", name))
+	logger.DebugContext(
+		ctx,
+		"Voting on blog",
+		"id", vote.BlogID,
+		"user_id", vote.UserID,
+		"value", vote.Value,
+	)
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to begin transaction")
+		span.RecordError(err)
+
+		return models.Blog{}, fmt.Errorf(
+			"[in services.BlogsService.VoteBlog] failed to begin transaction: %w",
+			err,
+		)
+	}
+	// Rollback is a no-op once the transaction has been committed
+	defer func() { _ = tx.Rollback() }()
+
+	exists, err := blogExists(ctx, tx, uint64(vote.BlogID))
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to check blog")
+		span.RecordError(err)
+
+		return models.Blog{}, fmt.Errorf(
+			"[in services.BlogsService.VoteBlog] failed to check blog: %w",
+			err,
+		)
+	}
+	if !exists {
+		return models.Blog{}, fmt.Errorf(
+			"[in services.BlogsService.VoteBlog] blog %d: %w",
+			vote.BlogID,
+			ErrBlogNotFound,
+		)
+	}
+
+	exists, err = userExists(ctx, tx, uint64(vote.UserID))
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to check user")
+		span.RecordError(err)
+
+		return models.Blog{}, fmt.Errorf(
+			"[in services.BlogsService.VoteBlog] failed to check user: %w",
+			err,
+		)
+	}
+	if !exists {
+		return models.Blog{}, fmt.Errorf(
+			"[in services.BlogsService.VoteBlog] user %d: %w",
+			vote.UserID,
+			ErrUserNotFound,
+		)
+	}
+
+	// The unique (blog_id, user_id) constraint makes a second vote a no-op
+	// rather than an error, which is then reported as ErrAlreadyVoted.
+	result, err := tx.ExecContext(
+		ctx,
+		`
+		INSERT
+		INTO votes (user_id, blog_id, value)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (blog_id, user_id) DO NOTHING
+		`,
+		vote.UserID,
+		vote.BlogID,
+		vote.Value,
+	)
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to record vote")
+		span.RecordError(err)
+
+		return models.Blog{}, fmt.Errorf(
+			"[in services.BlogsService.VoteBlog] failed to record vote: %w",
+			asConstraintError(err),
+		)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to get rows affected")
+		span.RecordError(err)
+
+		return models.Blog{}, fmt.Errorf(
+			"[in services.BlogsService.VoteBlog] failed to get rows affected: %w",
+			err,
+		)
+	}
+	if rowsAffected == 0 {
+		return models.Blog{}, fmt.Errorf(
+			"[in services.BlogsService.VoteBlog] user %d on blog %d: %w",
+			vote.UserID,
+			vote.BlogID,
+			ErrAlreadyVoted,
+		)
+	}
+
+	// Apply the vote relative to the current score so concurrent votes
+	// serialise on the row lock instead of overwriting each other.
+	var blog models.Blog
+	err = tx.GetContext(
+		ctx,
+		&blog,
+		`
+		UPDATE blogs
+		SET score = score + $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+		RETURNING id, author_id, title, score
+		`,
+		vote.Value,
+		vote.BlogID,
+	)
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to update blog score")
+		span.RecordError(err)
+
+		return models.Blog{}, fmt.Errorf(
+			"[in services.BlogsService.VoteBlog] failed to update blog score: %w",
+			err,
+		)
+	}
+
+	if err = tx.Commit(); err != nil {
+		span.SetStatus(codes.Error, "failed to commit transaction")
+		span.RecordError(err)
+
+		return models.Blog{}, fmt.Errorf(
+			"[in services.BlogsService.VoteBlog] failed to commit transaction: %w",
+			err,
+		)
+	}
+
+	// Remove the stale blog from the cache so the next read sees the new score.
+	// Removing rather than replacing it keeps concurrent votes from caching
+	// each other's older scores. Should that fail, the new score is cached
+	// instead, and only if both fail, leaving the old score to be read, is the
+	// stored vote reported as a failure.
+	key := blogCacheKey(uint64(vote.BlogID))
+	logger.DebugContext(ctx, "Removing blog from cache", "id", vote.BlogID)
+	if err = s.cache.Delete(ctx, key); err != nil {
+		logger.WarnContext(ctx, "failed to remove blog from cache", slog.String("error", err.Error()))
+		span.RecordError(err)
+
+		if err = s.cache.SetMarshal(ctx, key, blog); err != nil {
+			span.SetStatus(codes.Error, "failed to write voted blog to cache")
+			span.RecordError(err)
+
+			return models.Blog{}, fmt.Errorf(
+				"[in services.BlogsService.VoteBlog] failed to write voted blog to cache: %w",
+				err,
+			)
+		}
+	}
+
+	return blog, nil
+}
+
+// BlogsListSchema declares the fields blogs may be filtered and sorted on. The
+// blogs list is not paginated, so its fields have no cursor values.
+var BlogsListSchema = query.Schema[models.Blog]{
+	Fields: map[string]query.Field[models.Blog]{
+		"id":         {Column: "id", Type: query.Int},
+		"author_id":  {Column: "author_id", Type: query.Int},
+		"title":      {Column: "title", Type: query.String},
+		"score":      {Column: "score", Type: query.Float},
+		"created_at": {Column: "created_at", Type: query.Time},
+	},
+	Key: "id",
+}
+
+// ListBlogs attempts to list the blogs in the database matching the filter
+// and sort of the provided query.List, whose fields must come from
+// BlogsListSchema. A slice of models.Blog or an error is returned.
+func (s *BlogsService) ListBlogs(ctx context.Context, list query.List) ([]models.Blog, error) {
+	const name = "services.BlogsService.ListBlogs"
+
+	ctx, span := tracer.Start(ctx, name)
+	defer span.End()
+
+	clauses, args := list.SQL()
+
+	logger := s.logger.With(slog.String("func", name))
+	logger.DebugContext(ctx, "Listing blogs", "clauses", clauses)
+
+	blogs := []models.Blog{}
+
+	err := s.db.SelectContext(
+		ctx,
+		&blogs,
+		`
+		SELECT id,
+		       author_id,
+		       title,
+		       score
+		FROM blogs
+		`+clauses,
+		args...,
+	)
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to list blogs")
+		span.RecordError(err)
+
+		return nil, fmt.Errorf(
+			"[in services.BlogsService.ListBlogs] failed to read blogs: %w",
+			err,
+		)
+	}
+
+	return blogs, nil
+}
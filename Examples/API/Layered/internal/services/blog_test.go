@@ -0,0 +1,564 @@
+package services
+
+import (
+	"database/sql/driver"
+	"errors"
+	"log/slog"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-redis/redismock/v9"
+	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/query"
+)
+
+func TestBlogsService_CreateBlog(t *testing.T) {
+	testcases := map[string]struct {
+		authorExists   bool
+		mockOutput     *sqlmock.Rows
+		input          models.Blog
+		expectedOutput models.Blog
+		expectedError  error
+	}{
+		"happy path": {
+			authorExists: true,
+			mockOutput: sqlmock.NewRows([]string{"id", "author_id", "title", "score"}).
+				AddRow(1, 1, "First Blog Post", 0),
+			input: models.Blog{
+				AuthorID: 1,
+				Title:    "First Blog Post",
+			},
+			expectedOutput: models.Blog{
+				ID:       1,
+				AuthorID: 1,
+				Title:    "First Blog Post",
+				Score:    0,
+			},
+			expectedError: nil,
+		},
+		"author not found": {
+			authorExists: false,
+			input: models.Blog{
+				AuthorID: 99,
+				Title:    "First Blog Post",
+			},
+			expectedOutput: models.Blog{},
+			expectedError:  ErrUserNotFound,
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf(
+					"an error '%s' was not expected when opening a stub database connection",
+					err,
+				)
+			}
+			defer db.Close()
+
+			logger := slog.Default()
+
+			mock.
+				ExpectQuery(regexp.QuoteMeta(`
+					SELECT EXISTS (
+						SELECT 1 FROM users WHERE id = $1::int
+					)
+				`)).
+				WithArgs(tc.input.AuthorID).
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(tc.authorExists))
+
+			rdb, rmock := redismock.NewClientMock()
+			if tc.authorExists {
+				mock.
+					ExpectQuery(regexp.QuoteMeta(`
+						INSERT INTO blogs (author_id, title) VALUES ($1, $2)
+						RETURNING id, author_id, title, score
+					`)).
+					WithArgs(tc.input.AuthorID, tc.input.Title).
+					WillReturnRows(tc.mockOutput)
+
+				rmock.Regexp().ExpectSet(blogCacheKey(uint64(tc.expectedOutput.ID)), `.*`, 0).
+					SetVal("OK")
+			}
+
+			blogService := NewBlogsService(logger, sqlx.NewDb(db, "sqlmock"), NewRedisCache(rdb), 0)
+
+			output, err := blogService.CreateBlog(t.Context(), tc.input)
+			require.ErrorIs(t, err, tc.expectedError)
+			assert.Equal(t, tc.expectedOutput, output)
+
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+			assert.NoError(t, rmock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestBlogsService_ReadBlog(t *testing.T) {
+	testcases := map[string]struct {
+		mockInputArgs  []driver.Value
+		mockOutput     *sqlmock.Rows
+		input          uint64
+		expectedOutput models.Blog
+		expectedError  error
+	}{
+		"happy path": {
+			mockInputArgs: []driver.Value{1},
+			mockOutput: sqlmock.NewRows([]string{"id", "author_id", "title", "score"}).
+				AddRow(1, 1, "First Blog Post", 8.5),
+			input: 1,
+			expectedOutput: models.Blog{
+				ID:       1,
+				AuthorID: 1,
+				Title:    "First Blog Post",
+				Score:    8.5,
+			},
+			expectedError: nil,
+		},
+		"not found": {
+			mockInputArgs:  []driver.Value{2},
+			mockOutput:     sqlmock.NewRows([]string{"id", "author_id", "title", "score"}),
+			input:          2,
+			expectedOutput: models.Blog{},
+			expectedError:  ErrBlogNotFound,
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf(
+					"an error '%s' was not expected when opening a stub database connection",
+					err,
+				)
+			}
+			defer db.Close()
+
+			logger := slog.Default()
+
+			mock.
+				ExpectQuery(regexp.QuoteMeta(`
+					SELECT id,
+					       author_id,
+					       title,
+					       score
+					FROM blogs
+					WHERE id = $1::int
+				`)).
+				WithArgs(tc.mockInputArgs...).
+				WillReturnRows(tc.mockOutput)
+
+			rdb, rmock := redismock.NewClientMock()
+			rmock.ExpectGet(blogCacheKey(tc.input)).SetErr(redis.Nil)
+			if tc.expectedError == nil {
+				rmock.Regexp().ExpectSet(blogCacheKey(tc.input), `.*`, 0).SetVal("OK")
+			}
+
+			blogService := NewBlogsService(logger, sqlx.NewDb(db, "sqlmock"), NewRedisCache(rdb), 0)
+
+			output, err := blogService.ReadBlog(t.Context(), tc.input)
+			require.ErrorIs(t, err, tc.expectedError)
+			assert.Equal(t, tc.expectedOutput, output)
+
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+			assert.NoError(t, rmock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestBlogsService_UpdateBlog(t *testing.T) {
+	testcases := map[string]struct {
+		mockOutput     *sqlmock.Rows
+		input          models.Blog
+		expectedOutput models.Blog
+		expectedError  error
+	}{
+		"happy path": {
+			mockOutput: sqlmock.NewRows([]string{"id", "author_id", "title", "score"}).
+				AddRow(1, 2, "Updated Title", 8.5),
+			input: models.Blog{
+				Title: "Updated Title",
+			},
+			expectedOutput: models.Blog{
+				ID:       1,
+				AuthorID: 2,
+				Title:    "Updated Title",
+				Score:    8.5,
+			},
+			expectedError: nil,
+		},
+		"blog not found": {
+			mockOutput: sqlmock.NewRows([]string{"id", "author_id", "title", "score"}),
+			input: models.Blog{
+				Title: "Updated Title",
+			},
+			expectedOutput: models.Blog{},
+			expectedError:  ErrBlogNotFound,
+		},
+		"author is kept": {
+			mockOutput: sqlmock.NewRows([]string{"id", "author_id", "title", "score"}).
+				AddRow(1, 2, "Updated Title", 8.5),
+			input: models.Blog{
+				AuthorID: 99,
+				Title:    "Updated Title",
+			},
+			expectedOutput: models.Blog{
+				ID:       1,
+				AuthorID: 2,
+				Title:    "Updated Title",
+				Score:    8.5,
+			},
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf(
+					"an error '%s' was not expected when opening a stub database connection",
+					err,
+				)
+			}
+			defer db.Close()
+
+			logger := slog.Default()
+
+			mock.
+				ExpectQuery(regexp.QuoteMeta(`
+					UPDATE blogs
+					SET title = $1, updated_at = CURRENT_TIMESTAMP
+					WHERE id = $2
+					RETURNING id, author_id, title, score
+				`)).
+				WithArgs(tc.input.Title, 1).
+				WillReturnRows(tc.mockOutput)
+
+			rdb, rmock := redismock.NewClientMock()
+			if tc.expectedError == nil {
+				rmock.Regexp().ExpectSet(blogCacheKey(1), `.*`, 0).SetVal("OK")
+			}
+
+			blogService := NewBlogsService(logger, sqlx.NewDb(db, "sqlmock"), NewRedisCache(rdb), 0)
+
+			output, err := blogService.UpdateBlog(t.Context(), 1, tc.input)
+			require.ErrorIs(t, err, tc.expectedError)
+			assert.Equal(t, tc.expectedOutput, output)
+
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+			assert.NoError(t, rmock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestBlogsService_DeleteBlog(t *testing.T) {
+	testcases := map[string]struct {
+		rowsAffected  int64
+		input         uint64
+		expectedError error
+	}{
+		"happy path": {
+			rowsAffected:  1,
+			input:         1,
+			expectedError: nil,
+		},
+		"not found": {
+			rowsAffected:  0,
+			input:         2,
+			expectedError: ErrBlogNotFound,
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf(
+					"an error '%s' was not expected when opening a stub database connection",
+					err,
+				)
+			}
+			defer db.Close()
+
+			logger := slog.Default()
+
+			mock.
+				ExpectExec(regexp.QuoteMeta(`DELETE FROM blogs WHERE id = $1::int`)).
+				WithArgs(tc.input).
+				WillReturnResult(sqlmock.NewResult(0, tc.rowsAffected))
+
+			rdb, rmock := redismock.NewClientMock()
+			if tc.expectedError == nil {
+				rmock.ExpectDel(blogCacheKey(tc.input)).SetVal(1)
+			}
+
+			blogService := NewBlogsService(logger, sqlx.NewDb(db, "sqlmock"), NewRedisCache(rdb), 0)
+
+			err = blogService.DeleteBlog(t.Context(), tc.input)
+			require.ErrorIs(t, err, tc.expectedError)
+
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+			assert.NoError(t, rmock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestBlogsService_ListBlogs(t *testing.T) {
+	testcases := map[string]struct {
+		filter         string
+		sort           string
+		expectedQuery  string
+		expectedArgs   []driver.Value
+		mockOutput     *sqlmock.Rows
+		expectedOutput []models.Blog
+		expectedError  error
+	}{
+		"happy path": {
+			expectedQuery: "ORDER BY id",
+			mockOutput: sqlmock.NewRows([]string{"id", "author_id", "title", "score"}).
+				AddRow(1, 1, "First Blog Post", 8.5).
+				AddRow(2, 2, "Travel Adventures", 7.2),
+			expectedOutput: []models.Blog{
+				{ID: 1, AuthorID: 1, Title: "First Blog Post", Score: 8.5},
+				{ID: 2, AuthorID: 2, Title: "Travel Adventures", Score: 7.2},
+			},
+			expectedError: nil,
+		},
+		"filtered and sorted": {
+			filter:        `title co "travel" and score ge 5`,
+			sort:          "-score",
+			expectedQuery: `WHERE (LOWER(title) LIKE LOWER($1) ESCAPE '\' AND score >= $2) ORDER BY score DESC, id`,
+			expectedArgs:  []driver.Value{"%travel%", 5.0},
+			mockOutput: sqlmock.NewRows([]string{"id", "author_id", "title", "score"}).
+				AddRow(2, 2, "Travel Adventures", 7.2),
+			expectedOutput: []models.Blog{
+				{ID: 2, AuthorID: 2, Title: "Travel Adventures", Score: 7.2},
+			},
+			expectedError: nil,
+		},
+		"no results": {
+			expectedQuery:  "ORDER BY id",
+			mockOutput:     sqlmock.NewRows([]string{"id", "author_id", "title", "score"}),
+			expectedOutput: []models.Blog{},
+			expectedError:  nil,
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf(
+					"an error '%s' was not expected when opening a stub database connection",
+					err,
+				)
+			}
+			defer db.Close()
+
+			logger := slog.Default()
+
+			filter, err := BlogsListSchema.ParseFilter(tc.filter)
+			require.NoError(t, err)
+			orders, err := BlogsListSchema.ParseSort(tc.sort)
+			require.NoError(t, err)
+
+			mock.
+				ExpectQuery(regexp.QuoteMeta(`
+					SELECT id,
+					       author_id,
+					       title,
+					       score
+					FROM blogs
+				` + tc.expectedQuery)).
+				WithArgs(tc.expectedArgs...).
+				WillReturnRows(tc.mockOutput)
+
+			rdb, _ := redismock.NewClientMock()
+			blogService := NewBlogsService(logger, sqlx.NewDb(db, "sqlmock"), NewRedisCache(rdb), 0)
+
+			output, err := blogService.ListBlogs(t.Context(), query.List{Filter: filter, Sort: orders})
+			require.ErrorIs(t, err, tc.expectedError)
+			assert.Equal(t, tc.expectedOutput, output)
+
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestBlogsService_VoteBlog(t *testing.T) {
+	errConnectionRefused := errors.New("connection refused")
+
+	testcases := map[string]struct {
+		blogExists     bool
+		userExists     bool
+		rowsAffected   int64
+		deleteErr      error
+		setErr         error
+		input          models.Vote
+		expectedOutput models.Blog
+		expectedError  error
+	}{
+		"happy path": {
+			blogExists:   true,
+			userExists:   true,
+			rowsAffected: 1,
+			input:        models.Vote{UserID: 2, BlogID: 1, Value: models.VoteUp},
+			expectedOutput: models.Blog{
+				ID:       1,
+				AuthorID: 1,
+				Title:    "First Blog Post",
+				Score:    9.5,
+			},
+			expectedError: nil,
+		},
+		"cached blog replaced when it cannot be removed": {
+			blogExists:   true,
+			userExists:   true,
+			rowsAffected: 1,
+			deleteErr:    errors.New("connection refused"),
+			input:        models.Vote{UserID: 2, BlogID: 1, Value: models.VoteUp},
+			expectedOutput: models.Blog{
+				ID:       1,
+				AuthorID: 1,
+				Title:    "First Blog Post",
+				Score:    9.5,
+			},
+			expectedError: nil,
+		},
+		"cache unavailable": {
+			// The cached score would be stale, so the vote is reported as
+			// failed even though it is stored
+			blogExists:     true,
+			userExists:     true,
+			rowsAffected:   1,
+			deleteErr:      errors.New("connection refused"),
+			setErr:         errConnectionRefused,
+			input:          models.Vote{UserID: 2, BlogID: 1, Value: models.VoteUp},
+			expectedOutput: models.Blog{},
+			expectedError:  errConnectionRefused,
+		},
+		"blog not found": {
+			blogExists:     false,
+			input:          models.Vote{UserID: 2, BlogID: 99, Value: models.VoteUp},
+			expectedOutput: models.Blog{},
+			expectedError:  ErrBlogNotFound,
+		},
+		"user not found": {
+			blogExists:     true,
+			userExists:     false,
+			input:          models.Vote{UserID: 99, BlogID: 1, Value: models.VoteDown},
+			expectedOutput: models.Blog{},
+			expectedError:  ErrUserNotFound,
+		},
+		"already voted": {
+			blogExists:     true,
+			userExists:     true,
+			rowsAffected:   0,
+			input:          models.Vote{UserID: 2, BlogID: 1, Value: models.VoteDown},
+			expectedOutput: models.Blog{},
+			expectedError:  ErrAlreadyVoted,
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf(
+					"an error '%s' was not expected when opening a stub database connection",
+					err,
+				)
+			}
+			defer db.Close()
+
+			logger := slog.Default()
+
+			mock.ExpectBegin()
+			mock.
+				ExpectQuery(regexp.QuoteMeta(blogExistsQuery)).
+				WithArgs(tc.input.BlogID).
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(tc.blogExists))
+
+			if tc.blogExists {
+				mock.
+					ExpectQuery(regexp.QuoteMeta(userExistsQuery)).
+					WithArgs(tc.input.UserID).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(tc.userExists))
+			}
+
+			if tc.blogExists && tc.userExists {
+				mock.
+					ExpectExec(regexp.QuoteMeta(`
+						INSERT INTO votes (user_id, blog_id, value) VALUES ($1, $2, $3)
+						ON CONFLICT (blog_id, user_id) DO NOTHING
+					`)).
+					WithArgs(tc.input.UserID, tc.input.BlogID, tc.input.Value).
+					WillReturnResult(sqlmock.NewResult(0, tc.rowsAffected))
+			}
+
+			// The vote is stored unless it was rejected
+			stored := tc.blogExists && tc.userExists && tc.rowsAffected > 0
+			if stored {
+				mock.
+					ExpectQuery(regexp.QuoteMeta(`
+						UPDATE blogs
+						SET score = score + $1, updated_at = CURRENT_TIMESTAMP
+						WHERE id = $2
+						RETURNING id, author_id, title, score
+					`)).
+					WithArgs(tc.input.Value, tc.input.BlogID).
+					WillReturnRows(
+						sqlmock.NewRows([]string{"id", "author_id", "title", "score"}).
+							AddRow(1, 1, "First Blog Post", 9.5),
+					)
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
+
+			rdb, rmock := redismock.NewClientMock()
+			if stored {
+				del := rmock.ExpectDel(blogCacheKey(uint64(tc.input.BlogID)))
+				if tc.deleteErr != nil {
+					del.SetErr(tc.deleteErr)
+
+					set := rmock.ExpectSet(
+						blogCacheKey(uint64(tc.input.BlogID)),
+						[]byte(`{"id":1,"authorId":1,"title":"First Blog Post","score":9.5}`),
+						0,
+					)
+					if tc.setErr != nil {
+						set.SetErr(tc.setErr)
+					} else {
+						set.SetVal("OK")
+					}
+				} else {
+					del.SetVal(1)
+				}
+			}
+
+			blogService := NewBlogsService(logger, sqlx.NewDb(db, "sqlmock"), NewRedisCache(rdb), 0)
+
+			output, err := blogService.VoteBlog(t.Context(), tc.input)
+			require.ErrorIs(t, err, tc.expectedError)
+			assert.Equal(t, tc.expectedOutput, output)
+
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+			assert.NoError(t, rmock.ExpectationsWereMet())
+		})
+	}
+}
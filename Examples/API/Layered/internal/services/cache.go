@@ -3,47 +3,68 @@ package services
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"time"
 
-	"github.com/redis/go-redis/v9"
+	"example.com/examples/api/layered/internal/errs"
 )
 
-// RedisClient is an interface that defines some of the methods used by Redis.
-type RedisClient interface {
-	Set(
-		ctx context.Context,
-		key string,
-		value interface{},
-		expiration time.Duration,
-	) *redis.StatusCmd
-	Get(ctx context.Context, key string) *redis.StringCmd
-	Del(ctx context.Context, keys ...string) *redis.IntCmd
-	Ping(ctx context.Context) *redis.StatusCmd
+// Cache backends, as named by config.Config.CacheBackend.
+const (
+	CacheBackendRedis  = "redis"
+	CacheBackendMemory = "memory"
+	CacheBackendNone   = "none"
+)
+
+// Cache is an interface that defines a store of values by key which expire,
+// such as Redis. Get reports a missing or expired key as not found rather than
+// as an error.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, expiration time.Duration) error
+	Delete(ctx context.Context, key string) error
+	Ping(ctx context.Context) error
 }
 
-// Wraps a *redis.Client and provides methods for setting and getting cached values with
+// lockPollInterval is how often an instance waiting on another's lock checks
+// whether the value it waits for has been cached.
+const lockPollInterval = 20 * time.Millisecond
+
+// Locker is implemented by caches shared between instances of the service,
+// which can lock a key for a short while so that only one instance fills it.
+// Lock reports whether the lock was acquired, along with a token identifying
+// its holder; it is released by Unlock with that token or once ttl passes,
+// whichever is first. Locked reports whether a key is still locked.
+type Locker interface {
+	Lock(ctx context.Context, key string, ttl time.Duration) (string, bool, error)
+	Unlock(ctx context.Context, key string, token string) error
+	Locked(ctx context.Context, key string) (bool, error)
+}
+
+// Wraps a Cache and provides methods for setting and getting cached values with
 // automatic JSON marshaling/unmarshaling and expiration handling.
 type Client struct {
-	Redis      RedisClient
+	cache      Cache
 	expiration time.Duration
 }
 
-// Constructs a new Client with the given Redis client and default expiration duration.
-func NewClient(client RedisClient, expiration time.Duration) *Client {
+// Constructs a new Client with the given cache and default expiration duration.
+func NewClient(cache Cache, expiration time.Duration) *Client {
 	return &Client{
-		Redis:      client,
+		cache:      cache,
 		expiration: expiration,
 	}
 }
 
-// Wraps redis.StringCmd to provide additional helpers for result extraction and unmarshaling.
+// Holds the result of reading a key from the cache and provides helpers for
+// result extraction and unmarshaling.
 type StringCmd struct {
-	*redis.StringCmd
+	val   []byte
+	found bool
+	err   error
 }
 
-// Marshals the given value to JSON and stores it in Redis under the specified key with the
+// Marshals the given value to JSON and stores it in the cache under the specified key with the
 // configured expiration.
 func (c *Client) SetMarshal(ctx context.Context, key string, value any) error {
 	jsonData, err := json.Marshal(value)
@@ -51,60 +72,55 @@ func (c *Client) SetMarshal(ctx context.Context, key string, value any) error {
 		return fmt.Errorf("[in services.Client.Set] failed to marshal value: %w", err)
 	}
 
-	if err = c.Redis.Set(ctx, key, jsonData, c.expiration).Err(); err != nil {
-		return fmt.Errorf("[in services.Client.Set] failed to set value in cache: %w", err)
+	if err = c.cache.Set(ctx, key, jsonData, c.expiration); err != nil {
+		return fmt.Errorf("[in services.Client.Set] failed to set value in cache: %w", errs.E(errs.Unavailable, err))
 	}
 
 	return nil
 }
 
-// Retrieves the value for the given key from Redis, returning a StringCmd wrapper.
+// Retrieves the value for the given key from the cache, returning a StringCmd wrapper.
 func (c *Client) Get(ctx context.Context, key string) *StringCmd {
-	return &StringCmd{c.Redis.Get(ctx, key)}
+	val, found, err := c.cache.Get(ctx, key)
+
+	return &StringCmd{val: val, found: found, err: err}
 }
 
-// Deletes the value for the given key from Redis, returning an error if any.
+// Deletes the value for the given key from the cache, returning an error if any.
 func (c *Client) Delete(ctx context.Context, key string) error {
-	return c.Redis.Del(ctx, key).Err()
+	return errs.E(errs.Unavailable, c.cache.Delete(ctx, key))
+}
+
+// Checks that the cache can be reached, returning an error if not.
+func (c *Client) Ping(ctx context.Context) error {
+	return errs.E(errs.Unavailable, c.cache.Ping(ctx))
 }
 
 // Returns the string result, a boolean indicating existence, and an error if any.
 func (cmd *StringCmd) Result() (string, bool, error) {
-	val, err := cmd.StringCmd.Result()
-	if err != nil {
-		switch {
-		case errors.Is(err, redis.Nil):
-			return "", false, nil
-		default:
-			return "", false, err
-		}
+	if cmd.err != nil {
+		return "", false, errs.E(errs.Unavailable, cmd.err)
 	}
 
-	if val == "" {
+	if !cmd.found || len(cmd.val) == 0 {
 		return "", false, nil
 	}
 
-	return val, true, nil
+	return string(cmd.val), true, nil
 }
 
-// Unmarshals the JSON value from Redis into the provided variable. Returns a boolean indicating
+// Unmarshals the JSON value from the cache into the provided variable. Returns a boolean indicating
 // existence and an error if unmarshaling fails.
 func (cmd *StringCmd) Unmarshal(v any) (bool, error) {
-	val, err := cmd.StringCmd.Result()
-	if err != nil {
-		switch {
-		case errors.Is(err, redis.Nil):
-			return false, nil
-		default:
-			return false, err
-		}
+	if cmd.err != nil {
+		return false, errs.E(errs.Unavailable, cmd.err)
 	}
 
-	if val == "" {
+	if !cmd.found || len(cmd.val) == 0 {
 		return false, nil
 	}
 
-	if err = json.Unmarshal([]byte(val), v); err != nil {
+	if err := json.Unmarshal(cmd.val, v); err != nil {
 		return false, fmt.Errorf(
 			"[in services.StringCmd.Unmarshal] failed to unmarshal from cache: %w",
 			err,
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryCache is a Cache kept in the memory of the process, for running
+// without Redis. It holds at most a fixed number of entries, evicting the
+// least recently used when full, and entries expire like Redis keys do. Each
+// instance of the service keeps its own MemoryCache, so one instance does not
+// see another's writes or deletions until its own entries expire.
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	// order holds the entries, most recently used first.
+	order *list.List
+	now   func() time.Time
+}
+
+type memoryEntry struct {
+	key   string
+	value []byte
+	// expiresAt is the zero time for an entry that does not expire.
+	expiresAt time.Time
+}
+
+// NewMemoryCache creates a MemoryCache holding at most maxEntries entries.
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return newMemoryCache(maxEntries, time.Now)
+}
+
+func newMemoryCache(maxEntries int, now func() time.Time) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: max(maxEntries, 1),
+		entries:    map[string]*list.Element{},
+		order:      list.New(),
+		now:        now,
+	}
+}
+
+// Get satisfies Cache.
+func (c *MemoryCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	entry := e.Value.(*memoryEntry)
+	if c.expired(entry) {
+		c.remove(e)
+
+		return nil, false, nil
+	}
+	c.order.MoveToFront(e)
+
+	return entry.value, true, nil
+}
+
+// Set satisfies Cache. An expiration of zero keeps the value until it is
+// evicted, as it does in Redis.
+func (c *MemoryCache) Set(_ context.Context, key string, value []byte, expiration time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &memoryEntry{key: key, value: value}
+	if expiration > 0 {
+		entry.expiresAt = c.now().Add(expiration)
+	}
+
+	if e, ok := c.entries[key]; ok {
+		e.Value = entry
+		c.order.MoveToFront(e)
+
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+	}
+
+	return nil
+}
+
+// Delete satisfies Cache.
+func (c *MemoryCache) Delete(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		c.remove(e)
+	}
+
+	return nil
+}
+
+// Ping satisfies Cache. The memory of the process is always reachable.
+func (c *MemoryCache) Ping(context.Context) error {
+	return nil
+}
+
+// Len returns the number of entries held, including any that have expired but
+// not yet been removed.
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+func (c *MemoryCache) expired(entry *memoryEntry) bool {
+	return !entry.expiresAt.IsZero() && !c.now().Before(entry.expiresAt)
+}
+
+func (c *MemoryCache) remove(e *list.Element) {
+	c.order.Remove(e)
+	delete(c.entries, e.Value.(*memoryEntry).key)
+}
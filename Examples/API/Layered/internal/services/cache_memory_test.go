@@ -0,0 +1,123 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCache(t *testing.T) {
+
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	// step is one operation on the cache. get steps check want and found;
+	// set steps store value with expiration; delete steps remove key.
+	type step struct {
+		op         string
+		key        string
+		value      string
+		expiration time.Duration
+		advance    time.Duration
+		want       string
+		found      bool
+	}
+
+	tests := map[string]struct {
+		maxEntries int
+		steps      []step
+		wantLen    int
+	}{
+		"miss": {
+			maxEntries: 2,
+			steps:      []step{{op: "get", key: "1"}},
+			wantLen:    0,
+		},
+		"hit": {
+			maxEntries: 2,
+			steps: []step{
+				{op: "set", key: "1", value: "a", expiration: time.Minute},
+				{op: "get", key: "1", want: "a", found: true},
+			},
+			wantLen: 1,
+		},
+		"overwrite": {
+			maxEntries: 2,
+			steps: []step{
+				{op: "set", key: "1", value: "a", expiration: time.Minute},
+				{op: "set", key: "1", value: "b", expiration: time.Minute},
+				{op: "get", key: "1", want: "b", found: true},
+			},
+			wantLen: 1,
+		},
+		"expires": {
+			maxEntries: 2,
+			steps: []step{
+				{op: "set", key: "1", value: "a", expiration: time.Minute},
+				{op: "get", key: "1", advance: 59 * time.Second, want: "a", found: true},
+				{op: "get", key: "1", advance: time.Second},
+			},
+			wantLen: 0,
+		},
+		"zero expiration never expires": {
+			maxEntries: 2,
+			steps: []step{
+				{op: "set", key: "1", value: "a"},
+				{op: "get", key: "1", advance: 24 * time.Hour, want: "a", found: true},
+			},
+			wantLen: 1,
+		},
+		"evicts least recently used": {
+			maxEntries: 2,
+			steps: []step{
+				{op: "set", key: "1", value: "a", expiration: time.Minute},
+				{op: "set", key: "2", value: "b", expiration: time.Minute},
+				// Reading 1 leaves 2 as the least recently used
+				{op: "get", key: "1", want: "a", found: true},
+				{op: "set", key: "3", value: "c", expiration: time.Minute},
+				{op: "get", key: "2"},
+				{op: "get", key: "1", want: "a", found: true},
+				{op: "get", key: "3", want: "c", found: true},
+			},
+			wantLen: 2,
+		},
+		"delete": {
+			maxEntries: 2,
+			steps: []step{
+				{op: "set", key: "1", value: "a", expiration: time.Minute},
+				{op: "delete", key: "1"},
+				{op: "delete", key: "2"},
+				{op: "get", key: "1"},
+			},
+			wantLen: 0,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+
+			now := start
+			cache := newMemoryCache(tc.maxEntries, func() time.Time { return now })
+
+			for i, s := range tc.steps {
+				now = now.Add(s.advance)
+
+				switch s.op {
+				case "set":
+					require.NoError(t, cache.Set(t.Context(), s.key, []byte(s.value), s.expiration))
+				case "delete":
+					require.NoError(t, cache.Delete(t.Context(), s.key))
+				case "get":
+					got, found, err := cache.Get(t.Context(), s.key)
+					require.NoError(t, err)
+					assert.Equal(t, s.found, found, "step %d", i)
+					assert.Equal(t, s.want, string(got), "step %d", i)
+				}
+			}
+
+			assert.Equal(t, tc.wantLen, cache.Len())
+			assert.NoError(t, cache.Ping(t.Context()))
+		})
+	}
+}
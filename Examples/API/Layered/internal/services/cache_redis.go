@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisClient is an interface that defines some of the methods used by Redis.
+type RedisClient interface {
+	Set(
+		ctx context.Context,
+		key string,
+		value interface{},
+		expiration time.Duration,
+	) *redis.StatusCmd
+	SetNX(
+		ctx context.Context,
+		key string,
+		value interface{},
+		expiration time.Duration,
+	) *redis.BoolCmd
+	Get(ctx context.Context, key string) *redis.StringCmd
+	Del(ctx context.Context, keys ...string) *redis.IntCmd
+	Exists(ctx context.Context, keys ...string) *redis.IntCmd
+	Ping(ctx context.Context) *redis.StatusCmd
+	redis.Scripter
+}
+
+// unlockScript deletes the lock KEYS[1] only if it still holds the token
+// ARGV[1], so that a holder that outlived its lock cannot release a lock
+// another instance has since taken.
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// RedisCache is a Cache kept in Redis, and so shared by every instance of the
+// service.
+type RedisCache struct {
+	rdb RedisClient
+}
+
+// NewRedisCache creates a RedisCache using the provided Redis client.
+func NewRedisCache(rdb RedisClient) *RedisCache {
+	return &RedisCache{rdb: rdb}
+}
+
+// Get satisfies Cache.
+func (c *RedisCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	val, err := c.rdb.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return val, true, nil
+}
+
+// Set satisfies Cache.
+func (c *RedisCache) Set(ctx context.Context, key string, value []byte, expiration time.Duration) error {
+	return c.rdb.Set(ctx, key, value, expiration).Err()
+}
+
+// Delete satisfies Cache.
+func (c *RedisCache) Delete(ctx context.Context, key string) error {
+	return c.rdb.Del(ctx, key).Err()
+}
+
+// Ping satisfies Cache.
+func (c *RedisCache) Ping(ctx context.Context) error {
+	return c.rdb.Ping(ctx).Err()
+}
+
+// Lock satisfies Locker. The lock is a key set only if it does not exist, to a
+// random token that Unlock must present.
+func (c *RedisCache) Lock(ctx context.Context, key string, ttl time.Duration) (string, bool, error) {
+	token := rand.Text()
+
+	locked, err := c.rdb.SetNX(ctx, key, token, ttl).Result()
+	if err != nil || !locked {
+		return "", false, err
+	}
+
+	return token, true, nil
+}
+
+// Unlock satisfies Locker.
+func (c *RedisCache) Unlock(ctx context.Context, key string, token string) error {
+	return unlockScript.Run(ctx, c.rdb, []string{key}, token).Err()
+}
+
+// Locked satisfies Locker.
+func (c *RedisCache) Locked(ctx context.Context, key string) (bool, error) {
+	n, err := c.rdb.Exists(ctx, key).Result()
+
+	return n > 0, err
+}
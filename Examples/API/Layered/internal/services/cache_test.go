@@ -0,0 +1,78 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_memoryCache(t *testing.T) {
+
+	client := NewClient(NewMemoryCache(10), time.Minute)
+
+	type cached struct {
+		Name string `json:"name"`
+	}
+
+	var got cached
+	found, err := client.Get(t.Context(), "1").Unmarshal(&got)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	require.NoError(t, client.SetMarshal(t.Context(), "1", cached{Name: "john"}))
+
+	found, err = client.Get(t.Context(), "1").Unmarshal(&got)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, cached{Name: "john"}, got)
+
+	require.NoError(t, client.Delete(t.Context(), "1"))
+
+	_, found, err = client.Get(t.Context(), "1").Result()
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestClient_noopCache(t *testing.T) {
+
+	client := NewClient(NoopCache{}, time.Minute)
+
+	require.NoError(t, client.SetMarshal(t.Context(), "1", "john"))
+
+	var got string
+	found, err := client.Get(t.Context(), "1").Unmarshal(&got)
+	require.NoError(t, err)
+	assert.False(t, found)
+	assert.NoError(t, client.Delete(t.Context(), "1"))
+	assert.NoError(t, client.Ping(t.Context()))
+}
+
+func TestRedisCache_Lock(t *testing.T) {
+	rdb, rmock := redismock.NewClientMock()
+	rmock.Regexp().ExpectSetNX("lock:user:1", `^[A-Z2-7]{26}$`, time.Second).SetVal(true)
+	rmock.Regexp().ExpectSetNX("lock:user:1", `^[A-Z2-7]{26}$`, time.Second).SetVal(false)
+
+	cache := NewRedisCache(rdb)
+
+	token, locked, err := cache.Lock(t.Context(), "lock:user:1", time.Second)
+	require.NoError(t, err)
+	assert.True(t, locked, "first lock")
+	assert.NotEmpty(t, token)
+
+	_, locked, err = cache.Lock(t.Context(), "lock:user:1", time.Second)
+	require.NoError(t, err)
+	assert.False(t, locked, "second lock")
+
+	// The lock is only deleted if it still holds the token
+	rmock.ExpectEvalSha(unlockScript.Hash(), []string{"lock:user:1"}, token).SetVal(int64(1))
+	require.NoError(t, cache.Unlock(t.Context(), "lock:user:1", token))
+
+	rmock.ExpectExists("lock:user:1").SetVal(0)
+	locked, err = cache.Locked(t.Context(), "lock:user:1")
+	require.NoError(t, err)
+	assert.False(t, locked, "after unlock")
+	assert.NoError(t, rmock.ExpectationsWereMet())
+}
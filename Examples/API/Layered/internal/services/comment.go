@@ -0,0 +1,364 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/codes"
+
+	"example.com/examples/api/layered/internal/models"
+)
+
+// CommentsService is a service capable of creating, listing and deleting
+// models.Comment models.
+type CommentsService struct {
+	logger *slog.Logger
+	db     *sqlx.DB
+}
+
+// NewCommentsService creates a new CommentsService and returns a pointer to it.
+func NewCommentsService(logger *slog.Logger, db *sqlx.DB) *CommentsService {
+	return &CommentsService{
+		logger: logger,
+		db:     db,
+	}
+}
+
+// CreateComment attempts to create the provided comment, returning a fully
+// hydrated models.Comment or an error. ErrBlogNotFound or ErrUserNotFound is
+// returned if the blog or the commenting user does not exist.
+func (s *CommentsService) CreateComment(
+	ctx context.Context,
+	comment models.Comment,
+) (models.Comment, error) {
+	const name = "services.CommentsService.CreateComment"
+
+	ctx, span := tracer.Start(ctx, name)
+	defer span.End()
+
+	logger := s.logger.With(slog.String("func", name))
+	logger.DebugContext(
+		ctx,
+		"Creating comment",
+		"blog_id", comment.BlogID,
+		"user_id", comment.UserID,
+	)
+
+	// Check the foreign keys up front so a missing blog or user is reported
+	// as such rather than as a constraint violation.
+	exists, err := blogExists(ctx, s.db, uint64(comment.BlogID))
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to check blog")
+		span.RecordError(err)
+
+		return models.Comment{}, fmt.Errorf(
+			"[in services.CommentsService.CreateComment] failed to check blog: %w",
+			err,
+		)
+	}
+	if !exists {
+		return models.Comment{}, fmt.Errorf(
+			"[in services.CommentsService.CreateComment] blog %d: %w",
+			comment.BlogID,
+			ErrBlogNotFound,
+		)
+	}
+
+	exists, err = userExists(ctx, s.db, uint64(comment.UserID))
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to check user")
+		span.RecordError(err)
+
+		return models.Comment{}, fmt.Errorf(
+			"[in services.CommentsService.CreateComment] failed to check user: %w",
+			err,
+		)
+	}
+	if !exists {
+		return models.Comment{}, fmt.Errorf(
+			"[in services.CommentsService.CreateComment] user %d: %w",
+			comment.UserID,
+			ErrUserNotFound,
+		)
+	}
+
+	err = s.db.GetContext(
+		ctx,
+		&comment,
+		`
+		INSERT
+		INTO comments (user_id, blog_id, message)
+		VALUES ($1, $2, $3)
+		RETURNING id, user_id, blog_id, message, created_at
+		`,
+		comment.UserID,
+		comment.BlogID,
+		comment.Message,
+	)
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to create comment")
+		span.RecordError(err)
+
+		return models.Comment{}, fmt.Errorf(
+			"[in services.CommentsService.CreateComment] failed to create comment: %w",
+			asConstraintError(err),
+		)
+	}
+
+	return comment, nil
+}
+
+// ListBlogComments attempts to list a page of the comments on the blog with
+// the provided id, oldest first. ErrBlogNotFound is returned if the blog does
+// not exist.
+func (s *CommentsService) ListBlogComments(
+	ctx context.Context,
+	blogID uint64,
+	limit int,
+	offset int,
+) ([]models.Comment, error) {
+	const name = "services.CommentsService.ListBlogComments"
+
+	ctx, span := tracer.Start(ctx, name)
+	defer span.End()
+
+	logger := s.logger.With(slog.String("func", name))
+	logger.DebugContext(
+		ctx,
+		"Listing blog comments",
+		"blog_id", blogID,
+		"limit", limit,
+		"offset", offset,
+	)
+
+	exists, err := blogExists(ctx, s.db, blogID)
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to check blog")
+		span.RecordError(err)
+
+		return nil, fmt.Errorf(
+			"[in services.CommentsService.ListBlogComments] failed to check blog: %w",
+			err,
+		)
+	}
+	if !exists {
+		return nil, fmt.Errorf(
+			"[in services.CommentsService.ListBlogComments] blog %d: %w",
+			blogID,
+			ErrBlogNotFound,
+		)
+	}
+
+	comments := []models.Comment{}
+	err = s.db.SelectContext(
+		ctx,
+		&comments,
+		`
+		SELECT id,
+		       user_id,
+		       blog_id,
+		       message,
+		       created_at
+		FROM comments
+		WHERE blog_id = $1::int
+		ORDER BY created_at, id
+		LIMIT $2 OFFSET $3
+		`,
+		blogID,
+		limit,
+		offset,
+	)
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to list blog comments")
+		span.RecordError(err)
+
+		return nil, fmt.Errorf(
+			"[in services.CommentsService.ListBlogComments] failed to read comments: %w",
+			err,
+		)
+	}
+
+	return comments, nil
+}
+
+// ListUserComments attempts to list a page of the comments written by the user
+// with the provided id, oldest first. ErrUserNotFound is returned if the user
+// does not exist.
+func (s *CommentsService) ListUserComments(
+	ctx context.Context,
+	userID uint64,
+	limit int,
+	offset int,
+) ([]models.Comment, error) {
+	const name = "services.CommentsService.ListUserComments"
+
+	ctx, span := tracer.Start(ctx, name)
+	defer span.End()
+
+	logger := s.logger.With(slog.String("func", name))
+	logger.DebugContext(
+		ctx,
+		"Listing user comments",
+		"user_id", userID,
+		"limit", limit,
+		"offset", offset,
+	)
+
+	exists, err := userExists(ctx, s.db, userID)
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to check user")
+		span.RecordError(err)
+
+		return nil, fmt.Errorf(
+			"[in services.CommentsService.ListUserComments] failed to check user: %w",
+			err,
+		)
+	}
+	if !exists {
+		return nil, fmt.Errorf(
+			"[in services.CommentsService.ListUserComments] user %d: %w",
+			userID,
+			ErrUserNotFound,
+		)
+	}
+
+	comments := []models.Comment{}
+	err = s.db.SelectContext(
+		ctx,
+		&comments,
+		`
+		SELECT id,
+		       user_id,
+		       blog_id,
+		       message,
+		       created_at
+		FROM comments
+		WHERE user_id = $1::int
+		ORDER BY created_at, id
+		LIMIT $2 OFFSET $3
+		`,
+		userID,
+		limit,
+		offset,
+	)
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to list user comments")
+		span.RecordError(err)
+
+		return nil, fmt.Errorf(
+			"[in services.CommentsService.ListUserComments] failed to read comments: %w",
+			err,
+		)
+	}
+
+	return comments, nil
+}
+
+// ReadComment attempts to read the comment with the provided id on the blog
+// with the provided blog id. ErrCommentNotFound is returned if no such comment
+// exists on the blog.
+func (s *CommentsService) ReadComment(ctx context.Context, blogID uint64, id uint64) (models.Comment, error) {
+	const name = "services.CommentsService.ReadComment"
+
+	ctx, span := tracer.Start(ctx, name)
+	defer span.End()
+
+	logger := s.logger.With(slog.String("func", name))
+	logger.DebugContext(ctx, "Reading comment", "blog_id", blogID, "id", id)
+
+	var comment models.Comment
+	err := s.db.GetContext(
+		ctx,
+		&comment,
+		`
+		SELECT id,
+		       user_id,
+		       blog_id,
+		       message,
+		       created_at
+		FROM comments
+		WHERE id = $1::int AND blog_id = $2::int
+		`,
+		id,
+		blogID,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return models.Comment{}, fmt.Errorf(
+				"[in services.CommentsService.ReadComment] comment %d on blog %d: %w",
+				id,
+				blogID,
+				ErrCommentNotFound,
+			)
+		default:
+			span.SetStatus(codes.Error, "failed to read comment")
+			span.RecordError(err)
+
+			return models.Comment{}, fmt.Errorf(
+				"[in services.CommentsService.ReadComment] failed to read comment: %w",
+				err,
+			)
+		}
+	}
+
+	return comment, nil
+}
+
+// DeleteComment attempts to delete the comment with the provided id from the
+// blog with the provided blog id. ErrCommentNotFound is returned if no such
+// comment exists on the blog.
+func (s *CommentsService) DeleteComment(ctx context.Context, blogID uint64, id uint64) error {
+	const name = "services.CommentsService.DeleteComment"
+
+	ctx, span := tracer.Start(ctx, name)
+	defer span.End()
+
+	logger := s.logger.With(slog.String("func", name))
+	logger.DebugContext(ctx, "Deleting comment", "blog_id", blogID, "id", id)
+
+	result, err := s.db.ExecContext(
+		ctx,
+		`
+		DELETE
+		FROM comments
+		WHERE id = $1::int AND blog_id = $2::int
+		`,
+		id,
+		blogID,
+	)
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to delete comment")
+		span.RecordError(err)
+
+		return fmt.Errorf(
+			"[in services.CommentsService.DeleteComment] failed to delete comment: %w",
+			err,
+		)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to get rows affected")
+		span.RecordError(err)
+
+		return fmt.Errorf(
+			"[in services.CommentsService.DeleteComment] failed to get rows affected: %w",
+			err,
+		)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf(
+			"[in services.CommentsService.DeleteComment] comment %d on blog %d: %w",
+			id,
+			blogID,
+			ErrCommentNotFound,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,371 @@
+package services
+
+import (
+	"log/slog"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/examples/api/layered/internal/models"
+)
+
+const (
+	blogExistsQuery = `SELECT EXISTS ( SELECT 1 FROM blogs WHERE id = $1::int )`
+	userExistsQuery = `SELECT EXISTS ( SELECT 1 FROM users WHERE id = $1::int )`
+)
+
+func TestCommentsService_CreateComment(t *testing.T) {
+	createdAt := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+
+	testcases := map[string]struct {
+		blogExists     bool
+		userExists     bool
+		input          models.Comment
+		expectedOutput models.Comment
+		expectedError  error
+	}{
+		"happy path": {
+			blogExists: true,
+			userExists: true,
+			input:      models.Comment{UserID: 2, BlogID: 1, Message: "Great post!"},
+			expectedOutput: models.Comment{
+				ID:        1,
+				UserID:    2,
+				BlogID:    1,
+				Message:   "Great post!",
+				CreatedAt: createdAt,
+			},
+			expectedError: nil,
+		},
+		"blog not found": {
+			blogExists:     false,
+			input:          models.Comment{UserID: 2, BlogID: 99, Message: "Great post!"},
+			expectedOutput: models.Comment{},
+			expectedError:  ErrBlogNotFound,
+		},
+		"user not found": {
+			blogExists:     true,
+			userExists:     false,
+			input:          models.Comment{UserID: 99, BlogID: 1, Message: "Great post!"},
+			expectedOutput: models.Comment{},
+			expectedError:  ErrUserNotFound,
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf(
+					"an error '%s' was not expected when opening a stub database connection",
+					err,
+				)
+			}
+			defer db.Close()
+
+			logger := slog.Default()
+
+			mock.
+				ExpectQuery(regexp.QuoteMeta(blogExistsQuery)).
+				WithArgs(tc.input.BlogID).
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(tc.blogExists))
+
+			if tc.blogExists {
+				mock.
+					ExpectQuery(regexp.QuoteMeta(userExistsQuery)).
+					WithArgs(tc.input.UserID).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(tc.userExists))
+			}
+
+			if tc.blogExists && tc.userExists {
+				mock.
+					ExpectQuery(regexp.QuoteMeta(`
+						INSERT INTO comments (user_id, blog_id, message) VALUES ($1, $2, $3)
+						RETURNING id, user_id, blog_id, message, created_at
+					`)).
+					WithArgs(tc.input.UserID, tc.input.BlogID, tc.input.Message).
+					WillReturnRows(
+						sqlmock.NewRows([]string{"id", "user_id", "blog_id", "message", "created_at"}).
+							AddRow(1, 2, 1, "Great post!", createdAt),
+					)
+			}
+
+			commentsService := NewCommentsService(logger, sqlx.NewDb(db, "sqlmock"))
+
+			output, err := commentsService.CreateComment(t.Context(), tc.input)
+			require.ErrorIs(t, err, tc.expectedError)
+			assert.Equal(t, tc.expectedOutput, output)
+
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestCommentsService_ListBlogComments(t *testing.T) {
+	createdAt := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+
+	testcases := map[string]struct {
+		blogExists     bool
+		mockOutput     *sqlmock.Rows
+		expectedOutput []models.Comment
+		expectedError  error
+	}{
+		"happy path": {
+			blogExists: true,
+			mockOutput: sqlmock.NewRows([]string{"id", "user_id", "blog_id", "message", "created_at"}).
+				AddRow(8, 1, 1, "Great post!", createdAt).
+				AddRow(15, 6, 1, "Interesting perspective.", createdAt.Add(time.Hour)),
+			expectedOutput: []models.Comment{
+				{ID: 8, UserID: 1, BlogID: 1, Message: "Great post!", CreatedAt: createdAt},
+				{
+					ID:        15,
+					UserID:    6,
+					BlogID:    1,
+					Message:   "Interesting perspective.",
+					CreatedAt: createdAt.Add(time.Hour),
+				},
+			},
+			expectedError: nil,
+		},
+		"no comments": {
+			blogExists:     true,
+			mockOutput:     sqlmock.NewRows([]string{"id", "user_id", "blog_id", "message", "created_at"}),
+			expectedOutput: []models.Comment{},
+			expectedError:  nil,
+		},
+		"blog not found": {
+			blogExists:     false,
+			expectedOutput: nil,
+			expectedError:  ErrBlogNotFound,
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf(
+					"an error '%s' was not expected when opening a stub database connection",
+					err,
+				)
+			}
+			defer db.Close()
+
+			logger := slog.Default()
+
+			mock.
+				ExpectQuery(regexp.QuoteMeta(blogExistsQuery)).
+				WithArgs(1).
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(tc.blogExists))
+
+			if tc.blogExists {
+				mock.
+					ExpectQuery(regexp.QuoteMeta(`
+						SELECT id,
+						       user_id,
+						       blog_id,
+						       message,
+						       created_at
+						FROM comments
+						WHERE blog_id = $1::int
+						ORDER BY created_at, id
+						LIMIT $2 OFFSET $3
+					`)).
+					WithArgs(1, 20, 0).
+					WillReturnRows(tc.mockOutput)
+			}
+
+			commentsService := NewCommentsService(logger, sqlx.NewDb(db, "sqlmock"))
+
+			output, err := commentsService.ListBlogComments(t.Context(), 1, 20, 0)
+			require.ErrorIs(t, err, tc.expectedError)
+			assert.Equal(t, tc.expectedOutput, output)
+
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestCommentsService_ListUserComments(t *testing.T) {
+	createdAt := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+
+	testcases := map[string]struct {
+		userExists     bool
+		mockOutput     *sqlmock.Rows
+		expectedOutput []models.Comment
+		expectedError  error
+	}{
+		"happy path": {
+			userExists: true,
+			mockOutput: sqlmock.NewRows([]string{"id", "user_id", "blog_id", "message", "created_at"}).
+				AddRow(2, 2, 11, "I agree with your points.", createdAt),
+			expectedOutput: []models.Comment{
+				{ID: 2, UserID: 2, BlogID: 11, Message: "I agree with your points.", CreatedAt: createdAt},
+			},
+			expectedError: nil,
+		},
+		"user not found": {
+			userExists:     false,
+			expectedOutput: nil,
+			expectedError:  ErrUserNotFound,
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf(
+					"an error '%s' was not expected when opening a stub database connection",
+					err,
+				)
+			}
+			defer db.Close()
+
+			logger := slog.Default()
+
+			mock.
+				ExpectQuery(regexp.QuoteMeta(userExistsQuery)).
+				WithArgs(2).
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(tc.userExists))
+
+			if tc.userExists {
+				mock.
+					ExpectQuery(regexp.QuoteMeta(`
+						SELECT id,
+						       user_id,
+						       blog_id,
+						       message,
+						       created_at
+						FROM comments
+						WHERE user_id = $1::int
+						ORDER BY created_at, id
+						LIMIT $2 OFFSET $3
+					`)).
+					WithArgs(2, 10, 10).
+					WillReturnRows(tc.mockOutput)
+			}
+
+			commentsService := NewCommentsService(logger, sqlx.NewDb(db, "sqlmock"))
+
+			output, err := commentsService.ListUserComments(t.Context(), 2, 10, 10)
+			require.ErrorIs(t, err, tc.expectedError)
+			assert.Equal(t, tc.expectedOutput, output)
+
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestCommentsService_ReadComment(t *testing.T) {
+	createdAt := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+
+	testcases := map[string]struct {
+		rows           *sqlmock.Rows
+		expectedOutput models.Comment
+		expectedError  error
+	}{
+		"happy path": {
+			rows: sqlmock.NewRows([]string{"id", "user_id", "blog_id", "message", "created_at"}).
+				AddRow(8, 2, 1, "Great post!", createdAt),
+			expectedOutput: models.Comment{
+				ID:        8,
+				UserID:    2,
+				BlogID:    1,
+				Message:   "Great post!",
+				CreatedAt: createdAt,
+			},
+			expectedError: nil,
+		},
+		"not found": {
+			rows:           sqlmock.NewRows([]string{"id", "user_id", "blog_id", "message", "created_at"}),
+			expectedOutput: models.Comment{},
+			expectedError:  ErrCommentNotFound,
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf(
+					"an error '%s' was not expected when opening a stub database connection",
+					err,
+				)
+			}
+			defer db.Close()
+
+			logger := slog.Default()
+
+			mock.
+				ExpectQuery(regexp.QuoteMeta(
+					`SELECT id, user_id, blog_id, message, created_at FROM comments WHERE id = $1::int AND blog_id = $2::int`,
+				)).
+				WithArgs(8, 1).
+				WillReturnRows(tc.rows)
+
+			commentsService := NewCommentsService(logger, sqlx.NewDb(db, "sqlmock"))
+
+			output, err := commentsService.ReadComment(t.Context(), 1, 8)
+			require.ErrorIs(t, err, tc.expectedError)
+			assert.Equal(t, tc.expectedOutput, output)
+
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestCommentsService_DeleteComment(t *testing.T) {
+	testcases := map[string]struct {
+		rowsAffected  int64
+		expectedError error
+	}{
+		"happy path": {
+			rowsAffected:  1,
+			expectedError: nil,
+		},
+		"not found": {
+			rowsAffected:  0,
+			expectedError: ErrCommentNotFound,
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf(
+					"an error '%s' was not expected when opening a stub database connection",
+					err,
+				)
+			}
+			defer db.Close()
+
+			logger := slog.Default()
+
+			mock.
+				ExpectExec(regexp.QuoteMeta(
+					`DELETE FROM comments WHERE id = $1::int AND blog_id = $2::int`,
+				)).
+				WithArgs(8, 1).
+				WillReturnResult(sqlmock.NewResult(0, tc.rowsAffected))
+
+			commentsService := NewCommentsService(logger, sqlx.NewDb(db, "sqlmock"))
+
+			err = commentsService.DeleteComment(t.Context(), 1, 8)
+			require.ErrorIs(t, err, tc.expectedError)
+
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"example.com/examples/api/layered/internal/errs"
+)
+
+// Postgres error codes of the constraint violations we recognise.
+const (
+	pgForeignKeyViolation = "23503"
+	pgUniqueViolation     = "23505"
+	pgCheckViolation      = "23514"
+)
+
+var (
+	// ErrUniqueViolation is returned when a write would duplicate a value
+	// that must be unique, such as a user's email.
+	ErrUniqueViolation = errs.New(errs.Conflict, "unique violation")
+
+	// ErrForeignKeyViolation is returned when a write references a record
+	// that does not exist, typically because it was deleted concurrently.
+	ErrForeignKeyViolation = errs.New(errs.Invalid, "foreign key violation")
+
+	// ErrCheckViolation is returned when a write holds a value that a check
+	// constraint does not allow.
+	ErrCheckViolation = errs.New(errs.Invalid, "check violation")
+)
+
+// constraintFields maps the constraints of our schema to the JSON name of the
+// field they constrain.
+var constraintFields = map[string]string{
+	"users_email_key":   "email",
+	"chk_user_role":     "role",
+	"fk_author":         "authorId",
+	"fk_comment_user":   "userId",
+	"fk_comment_blog":   "blogId",
+	"fk_vote_user":      "userId",
+	"fk_vote_blog":      "blogId",
+	"uq_vote_blog_user": "userId",
+	"chk_vote_value":    "direction",
+}
+
+// ConstraintError is returned when a write violates a database constraint.
+// It wraps one of ErrUniqueViolation, ErrForeignKeyViolation or
+// ErrCheckViolation, along with the error reported by the database.
+type ConstraintError struct {
+	Kind       error  // The kind of violation, e.g. ErrUniqueViolation.
+	Constraint string // The name of the violated constraint.
+	Field      string // The JSON name of the constrained field, if known.
+	Err        error  // The error reported by the database.
+}
+
+// Error satisfies the error interface.
+func (e *ConstraintError) Error() string {
+	return fmt.Sprintf("%s of %s on %s: %s", e.Kind, e.Constraint, e.Field, e.Err)
+}
+
+// Unwrap returns the kind of violation and the database error, so both can be
+// matched with errors.Is and errors.As.
+func (e *ConstraintError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// asConstraintError converts err into a *ConstraintError if it is a
+// constraint violation reported by Postgres, and returns it unchanged
+// otherwise.
+func asConstraintError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	var kind error
+	switch pgErr.Code {
+	case pgUniqueViolation:
+		kind = ErrUniqueViolation
+	case pgForeignKeyViolation:
+		kind = ErrForeignKeyViolation
+	case pgCheckViolation:
+		kind = ErrCheckViolation
+	default:
+		return err
+	}
+
+	field, ok := constraintFields[pgErr.ConstraintName]
+	if !ok {
+		field = pgErr.ColumnName
+	}
+
+	return &ConstraintError{
+		Kind:       kind,
+		Constraint: pgErr.ConstraintName,
+		Field:      field,
+		Err:        err,
+	}
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAsConstraintError(t *testing.T) {
+	testcases := map[string]struct {
+		err           error
+		expectedKind  error
+		expectedField string
+	}{
+		"unique email": {
+			err:           &pgconn.PgError{Code: pgUniqueViolation, ConstraintName: "users_email_key"},
+			expectedKind:  ErrUniqueViolation,
+			expectedField: "email",
+		},
+		"foreign key": {
+			err:           fmt.Errorf("wrapped: %w", &pgconn.PgError{Code: pgForeignKeyViolation, ConstraintName: "fk_author"}),
+			expectedKind:  ErrForeignKeyViolation,
+			expectedField: "authorId",
+		},
+		"check falls back to the column": {
+			err:           &pgconn.PgError{Code: pgCheckViolation, ConstraintName: "chk_unknown", ColumnName: "score"},
+			expectedKind:  ErrCheckViolation,
+			expectedField: "score",
+		},
+		"other postgres error": {
+			err: &pgconn.PgError{Code: "40001"},
+		},
+		"not a postgres error": {
+			err: errors.New("connection reset"),
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			err := asConstraintError(tc.err)
+
+			var constraintErr *ConstraintError
+			if tc.expectedKind == nil {
+				assert.False(t, errors.As(err, &constraintErr))
+				assert.Equal(t, tc.err, err)
+
+				return
+			}
+
+			if assert.ErrorAs(t, err, &constraintErr) {
+				assert.Equal(t, tc.expectedField, constraintErr.Field)
+			}
+			assert.ErrorIs(t, err, tc.expectedKind)
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+}
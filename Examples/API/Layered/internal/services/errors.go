@@ -0,0 +1,31 @@
+package services
+
+import "example.com/examples/api/layered/internal/errs"
+
+var (
+	// ErrUserNotFound is returned when a referenced user does not exist.
+	ErrUserNotFound = errs.New(errs.NotFound, "user not found")
+
+	// ErrBlogNotFound is returned when a referenced blog does not exist.
+	ErrBlogNotFound = errs.New(errs.NotFound, "blog not found")
+
+	// ErrVersionMismatch is returned when a conditional write expects a
+	// version of a record other than its current one, meaning it has been
+	// changed since the caller read it.
+	ErrVersionMismatch = errs.New(errs.Conflict, "version mismatch")
+
+	// ErrCommentNotFound is returned when a referenced comment does not exist.
+	ErrCommentNotFound = errs.New(errs.NotFound, "comment not found")
+
+	// ErrInvalidCredentials is returned when an email and password pair does not
+	// match a user. It deliberately does not say which of the two was wrong.
+	ErrInvalidCredentials = errs.New(errs.Unauthorized, "the email or password is incorrect")
+
+	// ErrInvalidToken is returned when an access or refresh token is
+	// malformed, expired, wrongly signed or of the wrong type.
+	ErrInvalidToken = errs.New(errs.Unauthorized, "the token is invalid or has expired")
+
+	// ErrAlreadyVoted is returned when a user tries to vote on a blog they
+	// have already voted on.
+	ErrAlreadyVoted = errs.New(errs.Conflict, "user has already voted on blog")
+)
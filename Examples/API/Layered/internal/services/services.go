@@ -1,9 +1,56 @@
 package services
 
 import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
 	"go.opentelemetry.io/otel"
 )
 
 const name = "example.com/examples/api/layered/internal/services"
 
 var tracer = otel.Tracer(name)
+
+// userExists reports whether a user with the provided id exists. The check
+// can be run against the database or inside a transaction.
+func userExists(ctx context.Context, q sqlx.QueryerContext, id uint64) (bool, error) {
+	var exists bool
+	err := sqlx.GetContext(
+		ctx,
+		q,
+		&exists,
+		`
+		SELECT EXISTS (
+			SELECT 1 FROM users WHERE id = $1::int
+		)
+		`,
+		id,
+	)
+	if err != nil {
+		return false, fmt.Errorf("[in services.userExists] failed to check user: %w", err)
+	}
+
+	return exists, nil
+}
+
+// blogExists reports whether a blog with the provided id exists.
+func blogExists(ctx context.Context, q sqlx.QueryerContext, id uint64) (bool, error) {
+	var exists bool
+	err := sqlx.GetContext(
+		ctx,
+		q,
+		&exists,
+		`
+		SELECT EXISTS (
+			SELECT 1 FROM blogs WHERE id = $1::int
+		)
+		`,
+		id,
+	)
+	if err != nil {
+		return false, fmt.Errorf("[in services.blogExists] failed to check blog: %w", err)
+	}
+
+	return exists, nil
+}
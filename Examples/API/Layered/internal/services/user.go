@@ -5,42 +5,88 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"iter"
 	"log/slog"
+	"runtime"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
 
 	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/query"
 )
 
 // UsersService is a service capable of performing CRUD operations for
 // models.User models.
 type UsersService struct {
-	logger *slog.Logger
-	db     *sqlx.DB
-	cache  *Client
+	logger       *slog.Logger
+	db           *sqlx.DB
+	cache        *Client
+	locker       Locker
+	lockTTL      time.Duration
+	reads        singleflight.Group
+	passwordCost int
+	// dummyPasswordHash is compared against when no user matches an email so
+	// that VerifyCredentials takes about as long for unknown emails as for
+	// wrong passwords, which keeps it from revealing which emails are
+	// registered. It is hashed at passwordCost, as real passwords are, on
+	// first use.
+	dummyPasswordHash func() []byte
 }
 
 // NewUsersService creates a new UsersService and returns a pointer to it.
+// Passwords are hashed with bcrypt using the provided cost. If lockTTL is not
+// zero and the cache is a Locker, an instance reading a user missing from the
+// cache holds a lock on it for at most lockTTL, so that other instances wait
+// for the user to be cached rather than all reading it from the database.
 func NewUsersService(
 	logger *slog.Logger,
 	db *sqlx.DB,
-	rdb RedisClient,
+	cache Cache,
 	expiration time.Duration,
+	lockTTL time.Duration,
+	passwordCost int,
 ) *UsersService {
-	return &UsersService{
-		logger: logger,
-		db:     db,
-		cache:  NewClient(rdb, expiration),
+	s := &UsersService{
+		logger:       logger,
+		db:           db,
+		cache:        NewClient(cache, expiration),
+		passwordCost: passwordCost,
+		dummyPasswordHash: sync.OnceValue(func() []byte {
+			hash, _ := bcrypt.GenerateFromPassword([]byte("dummy-password"), passwordCost)
+
+			return hash
+		}),
+	}
+	if locker, ok := cache.(Locker); ok && lockTTL > 0 {
+		s.locker, s.lockTTL = locker, lockTTL
 	}
+
+	return s
+}
+
+// hashPassword returns the bcrypt hash of the provided plaintext password.
+func (s *UsersService) hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), s.passwordCost)
+	if err != nil {
+		return "", fmt.Errorf("[in services.UsersService.hashPassword] failed to hash password: %w", err)
+	}
+
+	return string(hash), nil
 }
 
 // HealthStatus represents the status of each dependency.
 type HealthStatus struct {
-	Name   string `json:"name"`
-	Status string `json:"status"`
+	Name   string `json:"name"   xml:"name"`
+	Status string `json:"status" xml:"status"`
 }
 
 // DeepHealthCheck checks the health of the DB and cache, returning their statuses and an error if any are unhealthy.
@@ -69,7 +115,7 @@ func (s *UsersService) DeepHealthCheck(ctx context.Context) ([]HealthStatus, err
 
 	// Cache check
 	cacheStatus := HealthStatus{Name: "cache", Status: "healthy"}
-	if cacheErr := s.cache.Redis.Ping(ctx).Err(); cacheErr != nil {
+	if cacheErr := s.cache.Ping(ctx); cacheErr != nil {
 		cacheStatus.Status = "unhealthy"
 		if err != nil {
 			err = fmt.Errorf(
@@ -97,18 +143,36 @@ func (s *UsersService) CreateUser(ctx context.Context, user models.User) (models
 	logger := s.logger.With(slog.String("func", name))
 	logger.DebugContext(ctx, "Creating user", "name", user.Name)
 
-	err := s.db.GetContext(
+	hash, err := s.hashPassword(user.Password)
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to hash password")
+		span.RecordError(err)
+
+		return models.User{}, fmt.Errorf(
+			"[in services.UsersService.CreateUser] failed to hash password: %w",
+			err,
+		)
+	}
+
+	// The plaintext password is dropped as soon as it has been hashed
+	user.Password = ""
+
+	// New users are always regular users; admins are promoted out of band
+	user.Role = models.RoleUser
+
+	err = s.db.GetContext(
 		ctx,
-		&user.ID,
+		&user,
 		`
 		INSERT 
-		INTO users (name, email, password) 
-		VALUES ($1, $2, $3) 
-		RETURNING id
+		INTO users (name, email, password, role) 
+		VALUES ($1, $2, $3, $4) 
+		RETURNING id, created_at, updated_at, version
 		`,
 		user.Name,
 		user.Email,
-		user.Password,
+		hash,
+		user.Role,
 	)
 	if err != nil {
 		span.SetStatus(codes.Error, "failed to create user")
@@ -116,7 +180,7 @@ func (s *UsersService) CreateUser(ctx context.Context, user models.User) (models
 
 		return models.User{}, fmt.Errorf(
 			"[in services.UsersService.CreateUser] failed to create user: %w",
-			err,
+			asConstraintError(err),
 		)
 	}
 
@@ -136,7 +200,9 @@ func (s *UsersService) CreateUser(ctx context.Context, user models.User) (models
 }
 
 // ReadUser attempts to read a user from the database using the provided id. A
-// fully hydrated models.User or error is returned.
+// fully hydrated models.User or error is returned. ErrUserNotFound is returned
+// if the user does not exist. Concurrent reads of a user missing from the
+// cache share a single read of the database.
 func (s *UsersService) ReadUser(ctx context.Context, id uint64) (models.User, error) {
 	const name = "services.UsersService.ReadUser"
 
@@ -149,8 +215,10 @@ func (s *UsersService) ReadUser(ctx context.Context, id uint64) (models.User, er
 	// Check the cache for the user object
 	logger.DebugContext(ctx, "Reading user from cache", "id", id)
 
+	key := strconv.FormatUint(id, 10)
+
 	var user models.User
-	found, err := s.cache.Get(ctx, strconv.FormatUint(id, 10)).Unmarshal(&user)
+	found, err := s.cache.Get(ctx, key).Unmarshal(&user)
 	if err != nil {
 		span.SetStatus(codes.Error, "failed to read user from cache")
 		span.RecordError(err)
@@ -160,20 +228,107 @@ func (s *UsersService) ReadUser(ctx context.Context, id uint64) (models.User, er
 			err,
 		)
 	}
+	span.SetAttributes(attribute.Bool("cache.hit", found))
 
 	// If the user was found in the cache, return it
 	if found {
 		return user, nil
 	}
 
-	err = s.db.GetContext(
+	// Otherwise load it, or wait for the caller already loading it
+	led := false
+	loaded := s.reads.DoChan(key, func() (any, error) {
+		led = true
+
+		// The load is shared, so it must not be cancelled along with
+		// whichever caller happened to start it
+		return s.loadUser(context.WithoutCancel(ctx), id)
+	})
+
+	select {
+	case result := <-loaded:
+		if led {
+			span.SetAttributes(attribute.String("cache.fill", "led"))
+		} else {
+			span.SetAttributes(attribute.String("cache.fill", "waited"))
+			logger.DebugContext(ctx, "Waited for user loaded by another request", "id", id)
+		}
+		if result.Err != nil {
+			return models.User{}, result.Err
+		}
+
+		return result.Val.(models.User), nil
+	case <-ctx.Done():
+		return models.User{}, fmt.Errorf(
+			"[in services.UsersService.ReadUser] stopped waiting for user: %w",
+			ctx.Err(),
+		)
+	}
+}
+
+// loadUser reads the user with the provided id from the database and writes
+// it to the cache. When the service holds a Locker, it first locks the user,
+// and if another instance already holds the lock, waits for that instance to
+// cache the user instead, reading the database itself only if the lock is
+// released or expires first. ErrUserNotFound is returned if the user does not
+// exist.
+func (s *UsersService) loadUser(ctx context.Context, id uint64) (models.User, error) {
+	const name = "services.UsersService.loadUser"
+
+	ctx, span := tracer.Start(ctx, name)
+	defer span.End()
+
+	logger := s.logger.With(slog.String("func", name))
+	key := strconv.FormatUint(id, 10)
+
+	var user models.User
+	if s.locker != nil {
+		lockKey := "lock:user:" + key
+		token, locked, err := s.locker.Lock(ctx, lockKey, s.lockTTL)
+		switch {
+		case err != nil:
+			// Without the lock the read still works, it is just not shared
+			logger.WarnContext(ctx, "failed to lock user", slog.String("error", err.Error()))
+			span.SetAttributes(attribute.String("cache.lock", "failed"))
+		case locked:
+			span.SetAttributes(attribute.String("cache.lock", "acquired"))
+			defer func() {
+				if err := s.locker.Unlock(ctx, lockKey, token); err != nil {
+					logger.WarnContext(ctx, "failed to unlock user", slog.String("error", err.Error()))
+				}
+			}()
+		default:
+			span.SetAttributes(attribute.String("cache.lock", "waited"))
+			logger.DebugContext(ctx, "Waiting for user cached by another instance", "id", id)
+
+			found, err := s.waitForUser(ctx, key, lockKey, &user)
+			if err != nil {
+				span.SetStatus(codes.Error, "failed to read user from cache")
+				span.RecordError(err)
+
+				return models.User{}, fmt.Errorf(
+					"[in services.UsersService.loadUser] failed to read user from cache: %w",
+					err,
+				)
+			}
+			span.SetAttributes(attribute.Bool("cache.lock_filled", found))
+			if found {
+				return user, nil
+			}
+		}
+	}
+
+	err := s.db.GetContext(
 		ctx,
 		&user,
 		`
 		SELECT id,
 		       name,
 		       email,
-		       password
+		       role,
+		       created_at,
+		       updated_at,
+		       version
 		FROM users
 		WHERE id = $1::int
         `,
@@ -182,13 +337,17 @@ func (s *UsersService) ReadUser(ctx context.Context, id uint64) (models.User, er
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
-			return models.User{}, nil
+			return models.User{}, fmt.Errorf(
+				"[in services.UsersService.loadUser] user %d: %w",
+				id,
+				ErrUserNotFound,
+			)
 		default:
 			span.SetStatus(codes.Error, "failed to read user from database")
 			span.RecordError(err)
 
 			return models.User{}, fmt.Errorf(
-				"[in services.UsersService.ReadUser] failed to read user: %w",
+				"[in services.UsersService.loadUser] failed to read user: %w",
 				err,
 			)
 		}
@@ -196,12 +355,12 @@ func (s *UsersService) ReadUser(ctx context.Context, id uint64) (models.User, er
 
 	// Write the user to the cache
 	logger.DebugContext(ctx, "Setting user in cache", "id", id)
-	if err = s.cache.SetMarshal(ctx, strconv.FormatUint(id, 10), user); err != nil {
+	if err = s.cache.SetMarshal(ctx, key, user); err != nil {
 		span.SetStatus(codes.Error, "failed to write user to cache")
 		span.RecordError(err)
 
 		return models.User{}, fmt.Errorf(
-			"[in services.UsersService.ReadUser] failed to write user to cache: %w",
+			"[in services.UsersService.loadUser] failed to write user to cache: %w",
 			err,
 		)
 	}
@@ -209,13 +368,54 @@ func (s *UsersService) ReadUser(ctx context.Context, id uint64) (models.User, er
 	return user, nil
 }
 
+// waitForUser polls the cache for the user under key until it is found, the
+// instance holding lockKey releases it without caching the user, as it does
+// when the user does not exist, or the lock would have expired. The boolean is
+// false if the user was not cached.
+func (s *UsersService) waitForUser(
+	ctx context.Context,
+	key string,
+	lockKey string,
+	user *models.User,
+) (bool, error) {
+	ticker := time.NewTicker(lockPollInterval)
+	defer ticker.Stop()
+
+	deadline := time.After(s.lockTTL)
+	for {
+		select {
+		case <-ticker.C:
+			found, err := s.cache.Get(ctx, key).Unmarshal(user)
+			if err != nil || found {
+				return found, err
+			}
+
+			locked, err := s.locker.Locked(ctx, lockKey)
+			if err != nil {
+				return false, err
+			}
+			if !locked {
+				// The lock may have been released just after the user was
+				// cached
+				return s.cache.Get(ctx, key).Unmarshal(user)
+			}
+		case <-deadline:
+			return false, nil
+		}
+	}
+}
+
 // UpdateUser attempts to perform an update of the user with the provided id,
-// updating, it to reflect the properties on the provided patch object. A
-// models.User or an error.
+// updating, it to reflect the properties on the provided patch object. If
+// version is not zero the update only happens while the user is at that
+// version. The updated models.User or an error is returned. ErrUserNotFound is
+// returned if the user does not exist and ErrVersionMismatch if it is at
+// another version.
 func (s *UsersService) UpdateUser(
 	ctx context.Context,
 	id uint64,
 	patch models.User,
+	version uint64,
 ) (models.User, error) {
 	const name = "services.UsersService.UpdateUser"
 
@@ -223,38 +423,53 @@ func (s *UsersService) UpdateUser(
 	defer span.End()
 
 	logger := s.logger.With(slog.String("func", name))
-	logger.DebugContext(ctx, "Updating user", "id", id, "patch", patch)
+	logger.DebugContext(ctx, "Updating user", "id", id, "name", patch.Name, "version", version)
 
-	_, err := s.db.ExecContext(
-		ctx,
-		`
-		UPDATE users 
-		SET name = $1, email = $2, password = $3
-		WHERE id = $4
-		`,
-		patch.Name,
-		patch.Email,
-		patch.Password,
-		id,
-	)
+	hash, err := s.hashPassword(patch.Password)
 	if err != nil {
-		span.SetStatus(codes.Error, "failed to update user")
+		span.SetStatus(codes.Error, "failed to hash password")
 		span.RecordError(err)
 
 		return models.User{}, fmt.Errorf(
-			"[in services.UsersService.UpdateUser] failed to update user: %w",
+			"[in services.UsersService.UpdateUser] failed to hash password: %w",
 			err,
 		)
 	}
 
-	// Read the updated user from the database
-	user, err := s.ReadUser(ctx, id)
+	// The plaintext password is dropped as soon as it has been hashed
+	patch.Password = ""
+
+	statement := `
+		UPDATE users
+		SET name = $1, email = $2, password = $3,
+		    updated_at = CURRENT_TIMESTAMP, version = version + 1
+		WHERE id = $4`
+	args := []any{patch.Name, patch.Email, hash, id}
+	if version != 0 {
+		statement += ` AND version = $5`
+		args = append(args, version)
+	}
+	statement += `
+		RETURNING id, name, email, role, created_at, updated_at, version
+		`
+
+	var user models.User
+	err = s.db.GetContext(ctx, &user, statement, args...)
 	if err != nil {
-		span.SetStatus(codes.Error, "failed to read updated user")
-		span.RecordError(err)
+		if errors.Is(err, sql.ErrNoRows) {
+			err = s.missedWrite(ctx, id, version)
+		} else {
+			err = asConstraintError(err)
+		}
+
+		if !errors.Is(err, ErrUserNotFound) && !errors.Is(err, ErrVersionMismatch) {
+			span.SetStatus(codes.Error, "failed to update user")
+			span.RecordError(err)
+		}
 
 		return models.User{}, fmt.Errorf(
-			"[in services.UsersService.UpdateUser] failed to read updated user: %w",
+			"[in services.UsersService.UpdateUser] failed to update user %d: %w",
+			id,
 			err,
 		)
 	}
@@ -271,32 +486,147 @@ func (s *UsersService) UpdateUser(
 		)
 	}
 
-	patch.ID = uint(id)
+	return user, nil
+}
+
+// PatchUser attempts to update only the fields of the user with the provided
+// id that are set on the provided patch, leaving the others unchanged. If
+// version is not zero the patch only applies while the user is at that
+// version. The updated models.User or an error is returned. ErrUserNotFound is
+// returned if the user does not exist and ErrVersionMismatch if it is at
+// another version.
+func (s *UsersService) PatchUser(
+	ctx context.Context,
+	id uint64,
+	patch models.UserPatch,
+	version uint64,
+) (models.User, error) {
+	const name = "services.UsersService.PatchUser"
 
-	return patch, nil
+	ctx, span := tracer.Start(ctx, name)
+	defer span.End()
+
+	logger := s.logger.With(slog.String("func", name))
+	logger.DebugContext(ctx, "Patching user", "id", id, "version", version)
+
+	var (
+		sets []string
+		args []any
+	)
+	set := func(column string, value any) {
+		args = append(args, value)
+		sets = append(sets, column+" = $"+strconv.Itoa(len(args)))
+	}
+
+	if patch.Name != nil {
+		set("name", *patch.Name)
+	}
+	if patch.Email != nil {
+		set("email", *patch.Email)
+	}
+	if patch.Password != nil {
+		hash, err := s.hashPassword(*patch.Password)
+		if err != nil {
+			span.SetStatus(codes.Error, "failed to hash password")
+			span.RecordError(err)
+
+			return models.User{}, fmt.Errorf(
+				"[in services.UsersService.PatchUser] failed to hash password: %w",
+				err,
+			)
+		}
+		set("password", hash)
+	}
+
+	// An empty patch changes nothing, so the user is only read.
+	statement := `
+		SELECT id,
+		       name,
+		       email,
+		       role,
+		       created_at,
+		       updated_at,
+		       version
+		FROM users
+		WHERE id = $1::int`
+	if len(sets) > 0 {
+		statement = `
+		UPDATE users
+		SET ` + strings.Join(sets, ", ") + `, updated_at = CURRENT_TIMESTAMP, version = version + 1
+		WHERE id = $` + strconv.Itoa(len(args)+1)
+	}
+	args = append(args, id)
+
+	if version != 0 {
+		args = append(args, version)
+		statement += ` AND version = $` + strconv.Itoa(len(args))
+	}
+	if len(sets) > 0 {
+		statement += `
+		RETURNING id, name, email, role, created_at, updated_at, version`
+	}
+
+	var user models.User
+	err := s.db.GetContext(ctx, &user, statement, args...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = s.missedWrite(ctx, id, version)
+		} else {
+			err = asConstraintError(err)
+		}
+
+		if !errors.Is(err, ErrUserNotFound) && !errors.Is(err, ErrVersionMismatch) {
+			span.SetStatus(codes.Error, "failed to patch user")
+			span.RecordError(err)
+		}
+
+		return models.User{}, fmt.Errorf(
+			"[in services.UsersService.PatchUser] failed to patch user %d: %w",
+			id,
+			err,
+		)
+	}
+
+	// Write the patched user to the cache
+	logger.DebugContext(ctx, "Setting patched user in cache", "id", id)
+	if err = s.cache.SetMarshal(ctx, strconv.FormatUint(id, 10), user); err != nil {
+		span.SetStatus(codes.Error, "failed to write patched user to cache")
+		span.RecordError(err)
+
+		return models.User{}, fmt.Errorf(
+			"[in services.UsersService.PatchUser] failed to write patched user to cache: %w",
+			err,
+		)
+	}
+
+	return user, nil
 }
 
-// DeleteUser attempts to delete the user with the provided id. An error is
-// returned if the delete fails.
-func (s *UsersService) DeleteUser(ctx context.Context, id uint64) error {
+// DeleteUser attempts to delete the user with the provided id. If version is
+// not zero the user is only deleted while it is at that version, and
+// ErrUserNotFound or ErrVersionMismatch is returned if it does not exist or is
+// at another version. An error is returned if the delete fails.
+func (s *UsersService) DeleteUser(ctx context.Context, id uint64, version uint64) error {
 	const name = "services.UsersService.DeleteUser"
 
 	ctx, span := tracer.Start(ctx, name)
 	defer span.End()
 
 	logger := s.logger.With(slog.String("func", name))
-	logger.DebugContext(ctx, "Deleting user", "id", id)
+	logger.DebugContext(ctx, "Deleting user", "id", id, "version", version)
 
-	// Delete user from user table
-	_, err := s.db.ExecContext(
-		ctx,
-		`
+	statement := `
 		DELETE 
 		FROM users 
-		WHERE id = $1::int
-		`,
-		id,
-	)
+		WHERE id = $1::int`
+	args := []any{id}
+	if version != 0 {
+		statement += ` AND version = $2`
+		args = append(args, version)
+	}
+
+	// Delete user from user table
+	result, err := s.db.ExecContext(ctx, statement, args...)
 	if err != nil {
 		span.SetStatus(codes.Error, "failed to delete user")
 		span.RecordError(err)
@@ -307,6 +637,28 @@ func (s *UsersService) DeleteUser(ctx context.Context, id uint64) error {
 		)
 	}
 
+	// An unconditional delete of a missing user is not an error, but a
+	// conditional one must say why it did not happen.
+	if version != 0 {
+		var deleted int64
+		deleted, err = result.RowsAffected()
+		if err == nil && deleted == 0 {
+			err = s.missedWrite(ctx, id, version)
+		}
+		if err != nil {
+			if !errors.Is(err, ErrUserNotFound) && !errors.Is(err, ErrVersionMismatch) {
+				span.SetStatus(codes.Error, "failed to delete user")
+				span.RecordError(err)
+			}
+
+			return fmt.Errorf(
+				"[in services.UsersService.DeleteUser] failed to delete user %d: %w",
+				id,
+				err,
+			)
+		}
+	}
+
 	// Remove the user from the cache
 	logger.DebugContext(ctx, "Removing user from cache", "id", id)
 	if err = s.cache.Delete(ctx, strconv.FormatUint(id, 10)); err != nil {
@@ -322,18 +674,87 @@ func (s *UsersService) DeleteUser(ctx context.Context, id uint64) error {
 	return nil
 }
 
-// ListUsers attempts to list all users in the database. A slice of models.User
-// or an error is returned.
-func (s *UsersService) ListUsers(ctx context.Context) ([]models.User, error) {
+// missedWrite explains why a write of the user with the provided id, made
+// conditional on version unless it is zero, matched no row. ErrUserNotFound is
+// returned if the user does not exist and ErrVersionMismatch if it is at
+// another version.
+func (s *UsersService) missedWrite(ctx context.Context, id uint64, version uint64) error {
+	if version == 0 {
+		return ErrUserNotFound
+	}
+
+	exists, err := userExists(ctx, s.db, id)
+	if err != nil {
+		return fmt.Errorf(
+			"[in services.UsersService.missedWrite] failed to check user: %w",
+			err,
+		)
+	}
+
+	if !exists {
+		return ErrUserNotFound
+	}
+
+	return ErrVersionMismatch
+}
+
+// UsersListSchema declares the fields users may be filtered and sorted on.
+var UsersListSchema = query.Schema[models.User]{
+	Fields: map[string]query.Field[models.User]{
+		"id": {
+			Column: "id",
+			Type:   query.Int,
+			Value:  func(u models.User) any { return u.ID },
+		},
+		"name": {
+			Column: "name",
+			Type:   query.String,
+			Value:  func(u models.User) any { return u.Name },
+		},
+		"email": {
+			Column: "email",
+			Type:   query.String,
+			Value:  func(u models.User) any { return u.Email },
+		},
+		"role": {
+			Column: "role",
+			Type:   query.String,
+			Value:  func(u models.User) any { return string(u.Role) },
+		},
+		"created_at": {
+			Column: "created_at",
+			Type:   query.Time,
+			Value:  func(u models.User) any { return u.CreatedAt },
+		},
+		"updated_at": {
+			Column: "updated_at",
+			Type:   query.Time,
+			Value:  func(u models.User) any { return u.UpdatedAt },
+		},
+	},
+	Key: "id",
+}
+
+// ListUsers attempts to list the users in the database matching the filter,
+// sort and page of the provided query.List, whose fields must come from
+// UsersListSchema. A slice of models.User or an error is returned.
+func (s *UsersService) ListUsers(ctx context.Context, list query.List) ([]models.User, error) {
 	const name = "services.UsersService.ListUsers"
 
 	ctx, span := tracer.Start(ctx, name)
 	defer span.End()
 
+	clauses, args := list.SQL()
+
 	logger := s.logger.With(slog.String("func", name))
-	logger.DebugContext(ctx, "Listing users")
+	logger.DebugContext(
+		ctx,
+		"Listing users",
+		"clauses", clauses,
+		"limit", list.Limit,
+	)
 
-	var users []models.User
+	users := []models.User{}
 
 	err := s.db.SelectContext(
 		ctx,
@@ -342,19 +763,408 @@ func (s *UsersService) ListUsers(ctx context.Context) ([]models.User, error) {
 		SELECT id,
 		       name,
 		       email,
-		       password
+		       role,
+		       created_at,
+		       updated_at,
+		       version
 		FROM users
-        `,
+		`+clauses,
+		args...,
 	)
 	if err != nil {
 		span.SetStatus(codes.Error, "failed to list users")
 		span.RecordError(err)
 
 		return nil, fmt.Errorf(
-			"[in services.UsersService.ListUser] failed to read users: %w",
+			"[in services.UsersService.ListUsers] failed to read users: %w",
 			err,
 		)
 	}
 
 	return users, nil
 }
+
+// ExportUsers returns every user, ordered by ID, for as long as the caller
+// keeps ranging over them. Users are read from a database cursor one at a
+// time rather than collected into a slice, so an export of any size uses
+// constant memory. Passwords are never read. If reading fails the error is
+// yielded and iteration stops.
+func (s *UsersService) ExportUsers(ctx context.Context) iter.Seq2[models.User, error] {
+	return func(yield func(models.User, error) bool) {
+		const name = "services.UsersService.ExportUsers"
+
+		ctx, span := tracer.Start(ctx, name)
+		defer span.End()
+
+		logger := s.logger.With(slog.String("func", name))
+		logger.DebugContext(ctx, "Exporting users")
+
+		rows, err := s.db.QueryxContext(
+			ctx,
+			`
+			SELECT id,
+			       name,
+			       email,
+			       role,
+			       created_at,
+			       updated_at,
+			       version
+			FROM users
+			ORDER BY id
+			`,
+		)
+		if err != nil {
+			span.SetStatus(codes.Error, "failed to export users")
+			span.RecordError(err)
+
+			yield(models.User{}, fmt.Errorf(
+				"[in services.UsersService.ExportUsers] failed to read users: %w",
+				err,
+			))
+
+			return
+		}
+		defer rows.Close()
+
+		exported := 0
+		for rows.Next() {
+			var user models.User
+			if err = rows.StructScan(&user); err != nil {
+				span.SetStatus(codes.Error, "failed to scan user")
+				span.RecordError(err)
+
+				yield(models.User{}, fmt.Errorf(
+					"[in services.UsersService.ExportUsers] failed to scan user: %w",
+					err,
+				))
+
+				return
+			}
+
+			if !yield(user, nil) {
+				return
+			}
+			exported++
+		}
+		if err = rows.Err(); err != nil {
+			span.SetStatus(codes.Error, "failed to export users")
+			span.RecordError(err)
+
+			yield(models.User{}, fmt.Errorf(
+				"[in services.UsersService.ExportUsers] failed to read users: %w",
+				err,
+			))
+
+			return
+		}
+
+		logger.DebugContext(ctx, "Exported users", "count", exported)
+	}
+}
+
+// importBatchSize is the number of users written by each statement of a bulk
+// import.
+const importBatchSize = 500
+
+// ImportUsers attempts to create the users yielded by rows, inserting them in
+// batches inside a single transaction. Rows are consumed as they are yielded,
+// so an import of any size is never held in memory. Users whose email is
+// already taken, by an existing user or an earlier row, are skipped and their
+// lines reported. If rows yields an error the import stops and nothing is
+// written. A dry run performs the same inserts but rolls them back, reporting
+// what would have been imported without hashing any passwords. Imported users
+// are cached lazily, when they are first read. A models.UserImportResult or an
+// error is returned.
+func (s *UsersService) ImportUsers(
+	ctx context.Context,
+	rows iter.Seq2[models.UserImportRow, error],
+	dryRun bool,
+) (models.UserImportResult, error) {
+	const name = "services.UsersService.ImportUsers"
+
+	ctx, span := tracer.Start(ctx, name)
+	defer span.End()
+
+	logger := s.logger.With(slog.String("func", name))
+	logger.DebugContext(ctx, "Importing users", "dry_run", dryRun)
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to begin transaction")
+		span.RecordError(err)
+
+		return models.UserImportResult{}, fmt.Errorf(
+			"[in services.UsersService.ImportUsers] failed to begin transaction: %w",
+			err,
+		)
+	}
+	// Rollback is a no-op once the transaction has been committed, and is
+	// how a dry run discards its inserts.
+	defer func() { _ = tx.Rollback() }()
+
+	var result models.UserImportResult
+	batch := make([]models.UserImportRow, 0, importBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		logger.DebugContext(ctx, "Inserting import batch", "size", len(batch))
+		imported, duplicateLines, err := s.insertImportBatch(ctx, tx, batch, dryRun)
+		if err != nil {
+			return err
+		}
+
+		result.Imported += imported
+		result.DuplicateLines = append(result.DuplicateLines, duplicateLines...)
+		batch = batch[:0]
+
+		return nil
+	}
+
+	for row, err := range rows {
+		if err != nil {
+			span.SetStatus(codes.Error, "failed to read import")
+			span.RecordError(err)
+
+			return models.UserImportResult{}, fmt.Errorf(
+				"[in services.UsersService.ImportUsers] failed to read import: %w",
+				err,
+			)
+		}
+
+		batch = append(batch, row)
+		if len(batch) == importBatchSize {
+			if err = flush(); err != nil {
+				span.SetStatus(codes.Error, "failed to insert users")
+				span.RecordError(err)
+
+				return models.UserImportResult{}, fmt.Errorf(
+					"[in services.UsersService.ImportUsers] failed to insert users: %w",
+					err,
+				)
+			}
+		}
+	}
+
+	if err = flush(); err != nil {
+		span.SetStatus(codes.Error, "failed to insert users")
+		span.RecordError(err)
+
+		return models.UserImportResult{}, fmt.Errorf(
+			"[in services.UsersService.ImportUsers] failed to insert users: %w",
+			err,
+		)
+	}
+
+	if dryRun {
+		return result, nil
+	}
+
+	if err = tx.Commit(); err != nil {
+		span.SetStatus(codes.Error, "failed to commit transaction")
+		span.RecordError(err)
+
+		return models.UserImportResult{}, fmt.Errorf(
+			"[in services.UsersService.ImportUsers] failed to commit transaction: %w",
+			err,
+		)
+	}
+
+	return result, nil
+}
+
+// insertImportBatch inserts a batch of imported users with a single statement,
+// skipping those whose email is already taken. Passwords are hashed
+// concurrently, as bcrypt dominates the cost of an import, except on a dry run
+// whose inserts are never committed. The number of users inserted and the
+// lines of the skipped ones are returned.
+func (s *UsersService) insertImportBatch(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	batch []models.UserImportRow,
+	dryRun bool,
+) (int, []int, error) {
+	hashes := make([]string, len(batch))
+	if !dryRun {
+		g, _ := errgroup.WithContext(ctx)
+		g.SetLimit(runtime.GOMAXPROCS(0))
+		for i, row := range batch {
+			g.Go(func() error {
+				hash, err := s.hashPassword(row.User.Password)
+				hashes[i] = hash
+
+				return err
+			})
+		}
+		if err := g.Wait(); err != nil {
+			return 0, nil, fmt.Errorf(
+				"[in services.UsersService.insertImportBatch] failed to hash password: %w",
+				err,
+			)
+		}
+	}
+
+	values := make([]string, len(batch))
+	args := make([]any, 0, len(batch)*4)
+	for i, row := range batch {
+		n := len(args)
+		values[i] = fmt.Sprintf("($%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4)
+		args = append(args, row.User.Name, row.User.Email, hashes[i], models.RoleUser)
+	}
+
+	var inserted []string
+	err := tx.SelectContext(
+		ctx,
+		&inserted,
+		`
+		INSERT
+		INTO users (name, email, password, role)
+		VALUES `+strings.Join(values, ", ")+`
+		ON CONFLICT (email) DO NOTHING
+		RETURNING email
+		`,
+		args...,
+	)
+	if err != nil {
+		return 0, nil, fmt.Errorf(
+			"[in services.UsersService.insertImportBatch] failed to insert users: %w",
+			err,
+		)
+	}
+
+	// Of several rows sharing an email only the first is inserted, so each
+	// returned email accounts for the earliest row that has it.
+	remaining := make(map[string]int, len(inserted))
+	for _, email := range inserted {
+		remaining[email]++
+	}
+
+	var duplicateLines []int
+	for _, row := range batch {
+		if remaining[row.User.Email] > 0 {
+			remaining[row.User.Email]--
+
+			continue
+		}
+		duplicateLines = append(duplicateLines, row.Line)
+	}
+
+	return len(inserted), duplicateLines, nil
+}
+
+// VerifyCredentials checks the provided email and plaintext password against
+// the stored bcrypt hash. The matching models.User, without its password, is
+// returned on success. ErrInvalidCredentials is returned if no user has the
+// email or the password does not match.
+func (s *UsersService) VerifyCredentials(
+	ctx context.Context,
+	email string,
+	password string,
+) (models.User, error) {
+	const name = "services.UsersService.VerifyCredentials"
+
+	ctx, span := tracer.Start(ctx, name)
+	defer span.End()
+
+	logger := s.logger.With(slog.String("func", name))
+	logger.DebugContext(ctx, "Verifying credentials")
+
+	// Credentials are always checked against the database; the cache never
+	// holds password hashes.
+	var user models.User
+	err := s.db.GetContext(
+		ctx,
+		&user,
+		`
+		SELECT id,
+		       name,
+		       email,
+		       password,
+		       role
+		FROM users
+		WHERE email = $1
+		`,
+		email,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			_ = bcrypt.CompareHashAndPassword(s.dummyPasswordHash(), []byte(password))
+
+			return models.User{}, fmt.Errorf(
+				"[in services.UsersService.VerifyCredentials] unknown email: %w",
+				ErrInvalidCredentials,
+			)
+		default:
+			span.SetStatus(codes.Error, "failed to read user from database")
+			span.RecordError(err)
+
+			return models.User{}, fmt.Errorf(
+				"[in services.UsersService.VerifyCredentials] failed to read user: %w",
+				err,
+			)
+		}
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+	if err != nil {
+		// A stored value that is not a bcrypt hash can never match, so it is
+		// reported as bad credentials rather than a server error.
+		if !errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			logger.WarnContext(
+				ctx,
+				"stored password is not a valid bcrypt hash",
+				"id", user.ID,
+				"error", err.Error(),
+			)
+		}
+
+		return models.User{}, fmt.Errorf(
+			"[in services.UsersService.VerifyCredentials] user %d: %w",
+			user.ID,
+			ErrInvalidCredentials,
+		)
+	}
+
+	user.Password = ""
+
+	return user, nil
+}
+
+// EmailTaken reports whether a user has already registered the provided
+// email address.
+func (s *UsersService) EmailTaken(ctx context.Context, email string) (bool, error) {
+	const name = "services.UsersService.EmailTaken"
+
+	ctx, span := tracer.Start(ctx, name)
+	defer span.End()
+
+	logger := s.logger.With(slog.String("func", name))
+	logger.DebugContext(ctx, "Checking whether email is taken")
+
+	var taken bool
+	err := s.db.GetContext(
+		ctx,
+		&taken,
+		`
+		SELECT EXISTS (
+			SELECT 1
+			FROM users
+			WHERE email = $1
+		)
+		`,
+		email,
+	)
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to check email in database")
+		span.RecordError(err)
+
+		return false, fmt.Errorf(
+			"[in services.UsersService.EmailTaken] failed to check email: %w",
+			err,
+		)
+	}
+
+	return taken, nil
+}
@@ -2,23 +2,48 @@ package services
 
 import (
 	"context"
+	"database/sql"
 	"database/sql/driver"
 	"errors"
 	"log/slog"
 	"regexp"
 	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/go-redis/redismock/v9"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jmoiron/sqlx"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"golang.org/x/crypto/bcrypt"
 
+	"example.com/examples/api/layered/internal/errs"
 	"example.com/examples/api/layered/internal/models"
+	"example.com/examples/api/layered/internal/query"
 )
 
+// bcryptHash is a sqlmock.Argument matching a bcrypt hash of password.
+type bcryptHash struct {
+	password string
+}
+
+// Match satisfies sqlmock.Argument.
+func (h bcryptHash) Match(v driver.Value) bool {
+	hash, ok := v.(string)
+	if !ok {
+		return false
+	}
+
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(h.password)) == nil
+}
+
 func TestUsersService_DeepHealthCheck(t *testing.T) {
 	type fields struct {
 		dbErr    error
@@ -85,7 +110,7 @@ func TestUsersService_DeepHealthCheck(t *testing.T) {
 			}
 
 			logger := slog.Default()
-			us := NewUsersService(logger, sqlx.NewDb(db, "sqlmock"), rdb, 0)
+			us := NewUsersService(logger, sqlx.NewDb(db, "sqlmock"), NewRedisCache(rdb), 0, 0, bcrypt.MinCost)
 
 			status, err := us.DeepHealthCheck(context.Background())
 			assert.Equal(t, tc.wantStatus, status)
@@ -103,30 +128,67 @@ func TestUsersService_DeepHealthCheck(t *testing.T) {
 }
 
 func TestUsersService_ReadUser(t *testing.T) {
+	createdAt := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	updatedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	errCacheDown := errors.New("connection refused")
+
 	testcases := map[string]struct {
+		cached         string
 		mockCalled     bool
 		mockInputArgs  []driver.Value
 		mockOutput     *sqlmock.Rows
 		mockError      error
+		cacheErr       error
 		input          uint64
 		expectedOutput models.User
 		expectedError  error
+		expectedKind   errs.Kind
 	}{
 		"happy path": {
 			mockCalled:    true,
 			mockInputArgs: []driver.Value{1},
-			mockOutput: sqlmock.NewRows([]string{"id", "name", "email", "password"}).
-				AddRow(1, "john", "john@me.com", "password123!"),
+			mockOutput: sqlmock.NewRows([]string{"id", "name", "email", "role", "created_at", "updated_at", "version"}).
+				AddRow(1, "john", "john@me.com", "user", createdAt, updatedAt, 3),
 			mockError: nil,
 			input:     1,
 			expectedOutput: models.User{
-				ID:       1,
-				Name:     "john",
-				Email:    "john@me.com",
-				Password: "password123!",
+				ID:        1,
+				Name:      "john",
+				Email:     "john@me.com",
+				Role:      models.RoleUser,
+				CreatedAt: createdAt,
+				UpdatedAt: updatedAt,
+				Version:   3,
 			},
 			expectedError: nil,
 		},
+		"cache hit skips the database": {
+			cached: `{"id":1,"name":"john","email":"john@me.com","role":"user",` +
+				`"updatedAt":"2024-05-01T12:00:00Z","version":3}`,
+			input: 1,
+			expectedOutput: models.User{
+				ID:        1,
+				Name:      "john",
+				Email:     "john@me.com",
+				Role:      models.RoleUser,
+				UpdatedAt: updatedAt,
+				Version:   3,
+			},
+		},
+		"not found": {
+			mockCalled:    true,
+			mockInputArgs: []driver.Value{99},
+			mockOutput:    sqlmock.NewRows([]string{"id", "name", "email", "role", "created_at", "updated_at", "version"}),
+			input:         99,
+			expectedError: ErrUserNotFound,
+			expectedKind:  errs.NotFound,
+		},
+		"cache unavailable": {
+			cacheErr:      errCacheDown,
+			input:         1,
+			expectedError: errCacheDown,
+			expectedKind:  errs.Unavailable,
+		},
 	}
 	for name, tc := range testcases {
 		t.Run(name, func(t *testing.T) {
@@ -147,7 +209,10 @@ func TestUsersService_ReadUser(t *testing.T) {
                         SELECT id,
                                name,
                                email,
-                               password
+                               role,
+                               created_at,
+                               updated_at,
+                               version
                         FROM users
                         WHERE id = $1::int
                     `)).
@@ -157,82 +222,326 @@ func TestUsersService_ReadUser(t *testing.T) {
 			}
 
 			rdb, rmock := redismock.NewClientMock()
-			rmock.ExpectGet(strconv.FormatUint(tc.input, 10)).SetErr(redis.Nil)
-			rmock.Regexp().ExpectSet(strconv.Itoa(int(tc.expectedOutput.ID)), `.*`, 0).SetVal("OK")
-			userService := NewUsersService(logger, sqlx.NewDb(db, "sqlmock"), rdb, 0)
+			switch {
+			case tc.cached != "":
+				rmock.ExpectGet(strconv.FormatUint(tc.input, 10)).SetVal(tc.cached)
+			case tc.cacheErr != nil:
+				rmock.ExpectGet(strconv.FormatUint(tc.input, 10)).SetErr(tc.cacheErr)
+			default:
+				rmock.ExpectGet(strconv.FormatUint(tc.input, 10)).SetErr(redis.Nil)
+				if tc.expectedError == nil {
+					rmock.Regexp().ExpectSet(strconv.Itoa(int(tc.expectedOutput.ID)), `.*`, 0).SetVal("OK")
+				}
+			}
+			userService := NewUsersService(logger, sqlx.NewDb(db, "sqlmock"), NewRedisCache(rdb), 0, 0, bcrypt.MinCost)
 
 			output, err := userService.ReadUser(t.Context(), tc.input)
 			require.ErrorIs(t, err, tc.expectedError)
+			assert.Equal(t, tc.expectedKind, errs.KindOf(err))
 			assert.Equal(t, tc.expectedOutput, output)
 
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+			assert.NoError(t, rmock.ExpectationsWereMet())
+		})
+	}
+}
+
+// readUserQuery is the query ReadUser runs on a cache miss.
+const readUserQuery = `
+	SELECT id,
+	       name,
+	       email,
+	       role,
+	       created_at,
+	       updated_at,
+	       version
+	FROM users
+	WHERE id = $1::int
+`
+
+var (
+	recordSpansOnce sync.Once
+	spanRecorder    = tracetest.NewSpanRecorder()
+)
+
+// recordSpans makes spanRecorder record the spans of the package's tracer.
+// The global tracer provider can only take effect once per test binary, so
+// tests tell their spans apart by trace ID.
+func recordSpans() {
+	recordSpansOnce.Do(func() {
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder)))
+	})
+}
+
+func TestUsersService_ReadUser_coalesced(t *testing.T) {
+	recordSpans()
+
+	createdAt := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	const readers = 10
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// A single, slow read of the database serves every reader
+	mock.ExpectQuery(regexp.QuoteMeta(readUserQuery)).
+		WithArgs(1).
+		WillDelayFor(100 * time.Millisecond).
+		WillReturnRows(
+			sqlmock.NewRows([]string{"id", "name", "email", "role", "created_at", "updated_at", "version"}).
+				AddRow(1, "john", "john@me.com", "user", createdAt, createdAt, 1),
+		)
+
+	userService := NewUsersService(
+		slog.Default(),
+		sqlx.NewDb(db, "sqlmock"),
+		NewMemoryCache(10),
+		time.Minute,
+		0,
+		bcrypt.MinCost,
+	)
+
+	ctx, root := otel.Tracer("test").Start(t.Context(), "test")
+
+	var wg sync.WaitGroup
+	for range readers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			user, err := userService.ReadUser(ctx, 1)
+			assert.NoError(t, err)
+			assert.Equal(t, "john", user.Name)
+		}()
+	}
+	wg.Wait()
+	root.End()
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// Readers that missed the cache either led the read or waited on it
+	fills := map[string]int{}
+	for _, span := range spanRecorder.Ended() {
+		if span.SpanContext().TraceID() != root.SpanContext().TraceID() ||
+			span.Name() != "services.UsersService.ReadUser" {
+			continue
+		}
+		fill := "hit"
+		for _, attr := range span.Attributes() {
+			if attr.Key == "cache.fill" {
+				fill = attr.Value.AsString()
+			}
+		}
+		fills[fill]++
+	}
+	assert.Equal(t, 1, fills["led"], "readers leading the read")
+	assert.Positive(t, fills["waited"], "readers waiting on the read")
+	assert.Equal(t, readers, fills["led"]+fills["waited"]+fills["hit"], "readers")
+}
+
+// lockingCache is a MemoryCache that is also a Locker, whose locks are held by
+// another instance of the service when held is set. That instance caches
+// filled, if any, once it is asked for the lock, and if released is set,
+// releases the lock as soon as it is asked whether it holds it.
+type lockingCache struct {
+	*MemoryCache
+	held       bool
+	released   bool
+	lockErr    error
+	filled     string
+	unlocks    int
+	lockChecks int
+}
+
+// lockToken is the token of every lock a lockingCache grants.
+const lockToken = "token"
+
+func (c *lockingCache) Lock(ctx context.Context, key string, _ time.Duration) (string, bool, error) {
+	if c.lockErr != nil {
+		return "", false, c.lockErr
+	}
+	if c.filled != "" {
+		// Every test reads the user with id 1
+		_ = c.Set(ctx, "1", []byte(c.filled), 0)
+	}
+	if c.held {
+		return "", false, nil
+	}
+
+	return lockToken, true, nil
+}
+
+func (c *lockingCache) Unlock(_ context.Context, _ string, token string) error {
+	if token == lockToken {
+		c.unlocks++
+	}
+
+	return nil
+}
+
+func (c *lockingCache) Locked(context.Context, string) (bool, error) {
+	c.lockChecks++
+
+	return c.held && !c.released, nil
+}
+
+func TestUsersService_ReadUser_locked(t *testing.T) {
+	createdAt := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+
+	testcases := map[string]struct {
+		cache       *lockingCache
+		mockCalled  bool
+		wantName    string
+		wantUnlocks int
+	}{
+		"lock acquired": {
+			cache:       &lockingCache{},
+			mockCalled:  true,
+			wantName:    "john",
+			wantUnlocks: 1,
+		},
+		"filled by the instance holding the lock": {
+			cache: &lockingCache{held: true, filled: `{"id":1,"name":"johnny"}`},
+			// The other instance's read is used rather than the database
+			mockCalled: false,
+			wantName:   "johnny",
+		},
+		"lock expired before the user was cached": {
+			cache:      &lockingCache{held: true},
+			mockCalled: true,
+			wantName:   "john",
+		},
+		"lock released before the user was cached": {
+			cache:      &lockingCache{held: true, released: true},
+			mockCalled: true,
+			wantName:   "john",
+		},
+		"lock failed": {
+			cache:      &lockingCache{lockErr: errors.New("connection refused")},
+			mockCalled: true,
+			wantName:   "john",
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
 			if tc.mockCalled {
-				if err = mock.ExpectationsWereMet(); err != nil {
-					t.Errorf("there were unfulfilled expectations: %s", err)
-				}
+				mock.ExpectQuery(regexp.QuoteMeta(readUserQuery)).
+					WithArgs(1).
+					WillReturnRows(
+						sqlmock.NewRows([]string{"id", "name", "email", "role", "created_at", "updated_at", "version"}).
+							AddRow(1, "john", "john@me.com", "user", createdAt, createdAt, 1),
+					)
+			}
+
+			tc.cache.MemoryCache = NewMemoryCache(10)
+			userService := NewUsersService(
+				slog.Default(),
+				sqlx.NewDb(db, "sqlmock"),
+				tc.cache,
+				time.Minute,
+				50*time.Millisecond,
+				bcrypt.MinCost,
+			)
+
+			user, err := userService.ReadUser(t.Context(), 1)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantName, user.Name)
+			assert.Equal(t, tc.wantUnlocks, tc.cache.unlocks)
+			if tc.cache.released {
+				// Waiting stops at the first poll after the lock is released
+				assert.Equal(t, 1, tc.cache.lockChecks)
 			}
+			assert.NoError(t, mock.ExpectationsWereMet())
 		})
 	}
 }
 
 func TestUsersService_ListUsers(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
 	testcases := map[string]struct {
-		mockCalled     bool
-		mockInputArgs  []driver.Value
+		filter         string
+		sort           string
+		after          []any
+		limit          int
+		expectedQuery  string
+		expectedArgs   []driver.Value
 		mockOutput     *sqlmock.Rows
 		mockError      error
-		input          string
 		expectedOutput []models.User
 		expectedError  error
 	}{
-		"happy path": {
-			mockCalled:    true,
-			mockInputArgs: []driver.Value{1},
-			mockOutput: sqlmock.NewRows([]string{"id", "name", "email", "password"}).
-				AddRow(1, "john", "john@me.com", "password123!").
-				AddRow(2, "jane", "jane@me.com", "pwd5678!"),
-
-			mockError: nil,
+		"first page": {
+			limit:         2,
+			expectedQuery: "ORDER BY id LIMIT $1",
+			expectedArgs:  []driver.Value{2},
+			mockOutput: sqlmock.NewRows([]string{"id", "name", "email", "role", "created_at"}).
+				AddRow(1, "john", "john@me.com", "user", createdAt).
+				AddRow(2, "jane", "jane@me.com", "user", createdAt),
 			expectedOutput: []models.User{
 				{
-					ID:       1,
-					Name:     "john",
-					Email:    "john@me.com",
-					Password: "password123!",
+					ID:        1,
+					Name:      "john",
+					Email:     "john@me.com",
+					Role:      models.RoleUser,
+					CreatedAt: createdAt,
 				},
 				{
-					ID:       2,
-					Name:     "jane",
-					Email:    "jane@me.com",
-					Password: "pwd5678!",
+					ID:        2,
+					Name:      "jane",
+					Email:     "jane@me.com",
+					Role:      models.RoleUser,
+					CreatedAt: createdAt,
 				},
 			},
-			expectedError: nil,
 		},
-		"filter by name": {
-			mockCalled:    true,
-			mockInputArgs: []driver.Value{1},
-			mockOutput: sqlmock.NewRows([]string{"id", "name", "email", "password"}).
-				AddRow(2, "jane", "jane@me.com", "pwd5678!"),
-
-			mockError: nil,
+		"filtered next page": {
+			filter: `name co "ja" and created_at gt 2024-01-01`,
+			sort:   "-created_at",
+			after:  []any{createdAt, int64(1)},
+			limit:  2,
+			expectedQuery: `WHERE (LOWER(name) LIKE LOWER($1) ESCAPE '\' AND created_at > $2) ` +
+				`AND (created_at < $3 OR (created_at = $3 AND (id > $4))) ` +
+				`ORDER BY created_at DESC, id LIMIT $5`,
+			expectedArgs: []driver.Value{
+				"%ja%",
+				time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				createdAt,
+				1,
+				2,
+			},
+			mockOutput: sqlmock.NewRows([]string{"id", "name", "email", "role", "created_at"}).
+				AddRow(2, "jane", "jane@me.com", "user", createdAt),
 			expectedOutput: []models.User{
 				{
-					ID:       2,
-					Name:     "jane",
-					Email:    "jane@me.com",
-					Password: "pwd5678!",
+					ID:        2,
+					Name:      "jane",
+					Email:     "jane@me.com",
+					Role:      models.RoleUser,
+					CreatedAt: createdAt,
 				},
 			},
-			expectedError: nil,
 		},
-		"filter by name no results": {
-			mockCalled:    true,
-			mockInputArgs: []driver.Value{1},
-			mockOutput:    sqlmock.NewRows([]string{"id", "name", "email", "password"}),
-
-			mockError:      nil,
+		"past the last page": {
+			after:          []any{int64(2)},
+			limit:          2,
+			expectedQuery:  "WHERE (id > $1) ORDER BY id LIMIT $2",
+			expectedArgs:   []driver.Value{2, 2},
+			mockOutput:     sqlmock.NewRows([]string{"id", "name", "email", "role", "created_at"}),
 			expectedOutput: []models.User{},
-			expectedError:  nil,
+		},
+		"database error": {
+			limit:         2,
+			expectedQuery: "ORDER BY id LIMIT $1",
+			expectedArgs:  []driver.Value{2},
+			mockError:     errors.New("connection reset"),
+			expectedError: errors.New("connection reset"),
 		},
 	}
 	for name, tc := range testcases {
@@ -248,33 +557,156 @@ func TestUsersService_ListUsers(t *testing.T) {
 
 			logger := slog.Default()
 
-			if tc.mockCalled {
-				mock.
-					ExpectQuery(regexp.QuoteMeta(`
-                        SELECT id,
-							name,
-							email,
-							password
-						FROM users
-                    `)).
-					WillReturnRows(tc.mockOutput).
-					WillReturnError(tc.mockError)
+			filter, err := UsersListSchema.ParseFilter(tc.filter)
+			require.NoError(t, err)
+			orders, err := UsersListSchema.ParseSort(tc.sort)
+			require.NoError(t, err)
+
+			expectation := mock.
+				ExpectQuery(regexp.QuoteMeta(`
+					SELECT id,
+					       name,
+					       email,
+					       role,
+					       created_at,
+					       updated_at,
+					       version
+					FROM users
+				` + tc.expectedQuery)).
+				WithArgs(tc.expectedArgs...)
+			if tc.mockError != nil {
+				expectation.WillReturnError(tc.mockError)
+			} else {
+				expectation.WillReturnRows(tc.mockOutput)
 			}
 
 			rdb, _ := redismock.NewClientMock()
-			userService := NewUsersService(logger, sqlx.NewDb(db, "sqlmock"), rdb, 0)
+			userService := NewUsersService(logger, sqlx.NewDb(db, "sqlmock"), NewRedisCache(rdb), 0, 0, bcrypt.MinCost)
 
-			outputs, err := userService.ListUsers(t.Context())
-			require.ErrorIs(t, err, tc.expectedError)
+			outputs, err := userService.ListUsers(
+				t.Context(),
+				query.List{Filter: filter, Sort: orders, After: tc.after, Limit: tc.limit},
+			)
+			if tc.expectedError != nil {
+				require.ErrorContains(t, err, tc.expectedError.Error())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedOutput, outputs)
+			}
 
-			for i, output := range outputs {
-				assert.Equal(t, tc.expectedOutput[i], output)
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
 			}
+		})
+	}
+}
 
-			if tc.mockCalled {
-				if err = mock.ExpectationsWereMet(); err != nil {
-					t.Errorf("there were unfulfilled expectations: %s", err)
+func TestUsersService_ExportUsers(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	errScan := errors.New("connection reset")
+
+	testcases := map[string]struct {
+		mockOutput     *sqlmock.Rows
+		mockError      error
+		expectedOutput []models.User
+		expectedError  error
+	}{
+		"happy path": {
+			mockOutput: sqlmock.NewRows([]string{"id", "name", "email", "role", "created_at", "updated_at", "version"}).
+				AddRow(1, "john", "john@me.com", "admin", createdAt, createdAt, 1).
+				AddRow(2, "jane", "jane@me.com", "user", createdAt, createdAt, 3),
+			expectedOutput: []models.User{
+				{
+					ID:        1,
+					Name:      "john",
+					Email:     "john@me.com",
+					Role:      models.RoleAdmin,
+					CreatedAt: createdAt,
+					UpdatedAt: createdAt,
+					Version:   1,
+				},
+				{
+					ID:        2,
+					Name:      "jane",
+					Email:     "jane@me.com",
+					Role:      models.RoleUser,
+					CreatedAt: createdAt,
+					UpdatedAt: createdAt,
+					Version:   3,
+				},
+			},
+		},
+		"query fails": {
+			mockError:     sql.ErrConnDone,
+			expectedError: sql.ErrConnDone,
+		},
+		"reading fails part way through": {
+			mockOutput: sqlmock.NewRows([]string{"id", "name", "email", "role", "created_at", "updated_at", "version"}).
+				AddRow(1, "john", "john@me.com", "admin", createdAt, createdAt, 1).
+				AddRow(2, "jane", "jane@me.com", "user", createdAt, createdAt, 3).
+				RowError(1, errScan),
+			expectedOutput: []models.User{
+				{
+					ID:        1,
+					Name:      "john",
+					Email:     "john@me.com",
+					Role:      models.RoleAdmin,
+					CreatedAt: createdAt,
+					UpdatedAt: createdAt,
+					Version:   1,
+				},
+			},
+			expectedError: errScan,
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf(
+					"an error '%s' was not expected when opening a stub database connection",
+					err,
+				)
+			}
+			defer db.Close()
+
+			logger := slog.Default()
+
+			expectation := mock.
+				ExpectQuery(regexp.QuoteMeta(`
+					SELECT id,
+					       name,
+					       email,
+					       role,
+					       created_at,
+					       updated_at,
+					       version
+					FROM users
+					ORDER BY id
+				`)).
+				WithoutArgs()
+			if tc.mockError != nil {
+				expectation.WillReturnError(tc.mockError)
+			} else {
+				expectation.WillReturnRows(tc.mockOutput)
+			}
+
+			rdb, _ := redismock.NewClientMock()
+			userService := NewUsersService(logger, sqlx.NewDb(db, "sqlmock"), NewRedisCache(rdb), 0, 0, bcrypt.MinCost)
+
+			var output []models.User
+			for user, err := range userService.ExportUsers(t.Context()) {
+				if err != nil {
+					assert.ErrorIs(t, err, tc.expectedError)
+
+					break
 				}
+				output = append(output, user)
+			}
+			assert.Equal(t, tc.expectedOutput, output)
+
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
 			}
 		})
 	}
@@ -282,20 +714,44 @@ func TestUsersService_ListUsers(t *testing.T) {
 
 func TestBlogsService_DeleteUser(t *testing.T) {
 	testcases := map[string]struct {
-		mockCalled    bool
-		mockInputArgs []driver.Value
-		mockOutput    *sqlmock.Rows
-		mockError     error
+		version       uint64
+		expectedQuery string
+		expectedArgs  []driver.Value
+		rowsAffected  int64
+		mockExists    *sqlmock.Rows
 		input         uint64
 		expectedError error
 	}{
 		"happy path": {
-			mockCalled:    true,
-			mockInputArgs: []driver.Value{1},
-			mockError:     nil,
+			expectedQuery: `DELETE FROM users WHERE id = $1::int`,
+			expectedArgs:  []driver.Value{1},
+			rowsAffected:  1,
 			input:         1,
 			expectedError: nil,
 		},
+		"matching version": {
+			version:       2,
+			expectedQuery: `DELETE FROM users WHERE id = $1::int AND version = $2`,
+			expectedArgs:  []driver.Value{1, 2},
+			rowsAffected:  1,
+			input:         1,
+		},
+		"stale version": {
+			version:       2,
+			expectedQuery: `DELETE FROM users WHERE id = $1::int AND version = $2`,
+			expectedArgs:  []driver.Value{1, 2},
+			mockExists:    sqlmock.NewRows([]string{"exists"}).AddRow(true),
+			input:         1,
+			expectedError: ErrVersionMismatch,
+		},
+		"conditional delete of missing user": {
+			version:       2,
+			expectedQuery: `DELETE FROM users WHERE id = $1::int AND version = $2`,
+			expectedArgs:  []driver.Value{1, 2},
+			mockExists:    sqlmock.NewRows([]string{"exists"}).AddRow(false),
+			input:         1,
+			expectedError: ErrUserNotFound,
+		},
 	}
 	for name, tc := range testcases {
 		t.Run(name, func(t *testing.T) {
@@ -310,31 +766,38 @@ func TestBlogsService_DeleteUser(t *testing.T) {
 
 			logger := slog.Default()
 
-			if tc.mockCalled {
+			mock.
+				ExpectExec(regexp.QuoteMeta(tc.expectedQuery)).
+				WithArgs(tc.expectedArgs...).
+				WillReturnResult(sqlmock.NewResult(0, tc.rowsAffected))
+
+			if tc.mockExists != nil {
 				mock.
-					ExpectExec(regexp.QuoteMeta(`DELETE FROM users WHERE id = $1::int`)).
-					WithArgs(tc.mockInputArgs...).
-					WillReturnResult(sqlmock.NewResult(1, 1)).
-					WillReturnError(tc.mockError)
+					ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS ( SELECT 1 FROM users WHERE id = $1::int )`)).
+					WithArgs(1).
+					WillReturnRows(tc.mockExists)
 			}
 
 			rdb, rmock := redismock.NewClientMock()
-			rmock.ExpectDel(strconv.FormatUint(tc.input, 10)).SetVal(1)
-			userService := NewUsersService(logger, sqlx.NewDb(db, "sqlmock"), rdb, 0)
+			if tc.expectedError == nil {
+				rmock.ExpectDel(strconv.FormatUint(tc.input, 10)).SetVal(1)
+			}
+			userService := NewUsersService(logger, sqlx.NewDb(db, "sqlmock"), NewRedisCache(rdb), 0, 0, bcrypt.MinCost)
 
-			err = userService.DeleteUser(t.Context(), tc.input)
-			assert.ErrorIs(t, err, tc.expectedError)
+			err = userService.DeleteUser(t.Context(), tc.input, tc.version)
+			require.ErrorIs(t, err, tc.expectedError)
 
-			if tc.mockCalled {
-				if err = mock.ExpectationsWereMet(); err != nil {
-					t.Errorf("there were unfulfilled expectations: %s", err)
-				}
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
 			}
+			assert.NoError(t, rmock.ExpectationsWereMet())
 		})
 	}
 }
 
 func TestUsersService_CreateUser(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
 	testcases := map[string]struct {
 		mockCalled     bool
 		mockInputArgs  []driver.Value
@@ -346,9 +809,9 @@ func TestUsersService_CreateUser(t *testing.T) {
 	}{
 		"happy path": {
 			mockCalled:    true,
-			mockInputArgs: []driver.Value{"john", "john@me.com", "password123!"},
-			mockOutput: sqlmock.NewRows([]string{"id"}).
-				AddRow(1),
+			mockInputArgs: []driver.Value{"john", "john@me.com", bcryptHash{"password123!"}, "user"},
+			mockOutput: sqlmock.NewRows([]string{"id", "created_at", "updated_at", "version"}).
+				AddRow(1, createdAt, createdAt, 1),
 			mockError: nil,
 			input: models.User{
 				Name:     "john",
@@ -356,12 +819,28 @@ func TestUsersService_CreateUser(t *testing.T) {
 				Password: "password123!",
 			},
 			expectedOutput: models.User{
-				ID:       1,
+				ID:        1,
+				Name:      "john",
+				Email:     "john@me.com",
+				Role:      models.RoleUser,
+				CreatedAt: createdAt,
+				UpdatedAt: createdAt,
+				Version:   1,
+			},
+			expectedError: nil,
+		},
+		"duplicate email": {
+			mockCalled:    true,
+			mockInputArgs: []driver.Value{"john", "john@me.com", bcryptHash{"password123!"}, "user"},
+			mockOutput:    sqlmock.NewRows([]string{"id", "created_at", "updated_at", "version"}),
+			mockError:     &pgconn.PgError{Code: pgUniqueViolation, ConstraintName: "users_email_key"},
+			input: models.User{
 				Name:     "john",
 				Email:    "john@me.com",
 				Password: "password123!",
 			},
-			expectedError: nil,
+			expectedOutput: models.User{},
+			expectedError:  ErrUniqueViolation,
 		},
 	}
 	for name, tc := range testcases {
@@ -380,7 +859,8 @@ func TestUsersService_CreateUser(t *testing.T) {
 			if tc.mockCalled {
 				mock.
 					ExpectQuery(regexp.QuoteMeta(`
-                        INSERT INTO users (name, email, password) VALUES ($1, $2, $3) RETURNING id
+                        INSERT INTO users (name, email, password, role) VALUES ($1, $2, $3, $4)
+                        RETURNING id, created_at, updated_at, version
                     `)).
 					WithArgs(tc.mockInputArgs...).
 					WillReturnRows(tc.mockOutput).
@@ -389,7 +869,7 @@ func TestUsersService_CreateUser(t *testing.T) {
 
 			rdb, rmock := redismock.NewClientMock()
 			rmock.Regexp().ExpectSet(strconv.Itoa(int(tc.expectedOutput.ID)), `.*`, 0).SetVal("OK")
-			userService := NewUsersService(logger, sqlx.NewDb(db, "sqlmock"), rdb, 0)
+			userService := NewUsersService(logger, sqlx.NewDb(db, "sqlmock"), NewRedisCache(rdb), 0, 0, bcrypt.MinCost)
 
 			output, err := userService.CreateUser(t.Context(), tc.input)
 			assert.ErrorIs(t, err, tc.expectedError)
@@ -404,34 +884,193 @@ func TestUsersService_CreateUser(t *testing.T) {
 	}
 }
 
-func TestUsersService_UpdateUser(t *testing.T) {
+func TestUsersService_ImportUsers(t *testing.T) {
+	rows := []models.UserImportRow{
+		{Line: 2, User: models.User{Name: "john", Email: "john@me.com", Password: "password123!"}},
+		{Line: 3, User: models.User{Name: "jane", Email: "jane@me.com", Password: "password456!"}},
+		{Line: 5, User: models.User{Name: "jim", Email: "john@me.com", Password: "password789!"}},
+	}
+	errRead := errors.New("unexpected EOF")
+
 	testcases := map[string]struct {
-		mockCalled     bool
+		dryRun         bool
+		readErr        error
 		mockInputArgs  []driver.Value
 		mockOutput     *sqlmock.Rows
-		mockError      error
-		input          models.User
+		expectedOutput models.UserImportResult
+		expectedError  error
+	}{
+		"happy path": {
+			mockInputArgs: []driver.Value{
+				"john", "john@me.com", bcryptHash{"password123!"}, "user",
+				"jane", "jane@me.com", bcryptHash{"password456!"}, "user",
+				"jim", "john@me.com", bcryptHash{"password789!"}, "user",
+			},
+			mockOutput: sqlmock.NewRows([]string{"email"}).AddRow("john@me.com"),
+			expectedOutput: models.UserImportResult{
+				Imported:       1,
+				DuplicateLines: []int{3, 5},
+			},
+		},
+		"dry run skips hashing and rolls back": {
+			dryRun: true,
+			mockInputArgs: []driver.Value{
+				"john", "john@me.com", "", "user",
+				"jane", "jane@me.com", "", "user",
+				"jim", "john@me.com", "", "user",
+			},
+			mockOutput: sqlmock.NewRows([]string{"email"}).AddRow("john@me.com").AddRow("jane@me.com"),
+			expectedOutput: models.UserImportResult{
+				Imported:       2,
+				DuplicateLines: []int{5},
+			},
+		},
+		"read error rolls back": {
+			readErr:        errRead,
+			expectedOutput: models.UserImportResult{},
+			expectedError:  errRead,
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf(
+					"an error '%s' was not expected when opening a stub database connection",
+					err,
+				)
+			}
+			defer db.Close()
+
+			logger := slog.Default()
+
+			mock.ExpectBegin()
+			if tc.mockOutput != nil {
+				mock.
+					ExpectQuery(regexp.QuoteMeta(`
+						INSERT
+						INTO users (name, email, password, role)
+						VALUES ($1, $2, $3, $4), ($5, $6, $7, $8), ($9, $10, $11, $12)
+						ON CONFLICT (email) DO NOTHING
+						RETURNING email
+					`)).
+					WithArgs(tc.mockInputArgs...).
+					WillReturnRows(tc.mockOutput)
+			}
+			if tc.dryRun || tc.readErr != nil {
+				mock.ExpectRollback()
+			} else {
+				mock.ExpectCommit()
+			}
+
+			input := func(yield func(models.UserImportRow, error) bool) {
+				for _, row := range rows {
+					if !yield(row, nil) {
+						return
+					}
+				}
+				if tc.readErr != nil {
+					yield(models.UserImportRow{}, tc.readErr)
+				}
+			}
+
+			rdb, _ := redismock.NewClientMock()
+			userService := NewUsersService(logger, sqlx.NewDb(db, "sqlmock"), NewRedisCache(rdb), 0, 0, bcrypt.MinCost)
+
+			output, err := userService.ImportUsers(t.Context(), input, tc.dryRun)
+			assert.ErrorIs(t, err, tc.expectedError)
+			assert.Equal(t, tc.expectedOutput, output)
+
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestUsersService_UpdateUser(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	input := models.User{
+		Name:     "john",
+		Email:    "john@me.com",
+		Password: "password123!",
+	}
+
+	testcases := map[string]struct {
+		version        uint64
+		expectedQuery  string
+		expectedArgs   []driver.Value
+		mockOutput     *sqlmock.Rows
+		mockExists     *sqlmock.Rows
 		expectedOutput models.User
 		expectedError  error
 	}{
 		"happy path": {
-			mockCalled:    true,
-			mockInputArgs: []driver.Value{"john", "john@me.com", "password123!", 1},
-			mockOutput: sqlmock.NewRows([]string{"id", "name", "email", "password"}).
-				AddRow(1, "john", "john@me.com", "password123!"),
-			mockError: nil,
-			input: models.User{
-				Name:     "john",
-				Email:    "john@me.com",
-				Password: "password123!",
+			expectedQuery: `
+				UPDATE users
+				SET name = $1, email = $2, password = $3,
+				    updated_at = CURRENT_TIMESTAMP, version = version + 1
+				WHERE id = $4
+				RETURNING id, name, email, role, created_at, updated_at, version
+			`,
+			expectedArgs: []driver.Value{"john", "john@me.com", bcryptHash{"password123!"}, 1},
+			mockOutput: sqlmock.NewRows([]string{"id", "name", "email", "role", "created_at", "version"}).
+				AddRow(1, "john", "john@me.com", "user", createdAt, 2),
+			expectedOutput: models.User{
+				ID:        1,
+				Name:      "john",
+				Email:     "john@me.com",
+				Role:      models.RoleUser,
+				CreatedAt: createdAt,
+				Version:   2,
 			},
+		},
+		"matching version": {
+			version: 4,
+			expectedQuery: `
+				UPDATE users
+				SET name = $1, email = $2, password = $3,
+				    updated_at = CURRENT_TIMESTAMP, version = version + 1
+				WHERE id = $4 AND version = $5
+				RETURNING id, name, email, role, created_at, updated_at, version
+			`,
+			expectedArgs: []driver.Value{"john", "john@me.com", bcryptHash{"password123!"}, 1, 4},
+			mockOutput: sqlmock.NewRows([]string{"id", "name", "email", "role", "created_at", "version"}).
+				AddRow(1, "john", "john@me.com", "user", createdAt, 5),
 			expectedOutput: models.User{
-				ID:       1,
-				Name:     "john",
-				Email:    "john@me.com",
-				Password: "password123!",
+				ID:        1,
+				Name:      "john",
+				Email:     "john@me.com",
+				Role:      models.RoleUser,
+				CreatedAt: createdAt,
+				Version:   5,
 			},
-			expectedError: nil,
+		},
+		"stale version": {
+			version: 4,
+			expectedQuery: `
+				UPDATE users
+				SET name = $1, email = $2, password = $3,
+				    updated_at = CURRENT_TIMESTAMP, version = version + 1
+				WHERE id = $4 AND version = $5
+				RETURNING id, name, email, role, created_at, updated_at, version
+			`,
+			expectedArgs:  []driver.Value{"john", "john@me.com", bcryptHash{"password123!"}, 1, 4},
+			mockOutput:    sqlmock.NewRows([]string{"id", "name", "email", "role", "created_at", "version"}),
+			mockExists:    sqlmock.NewRows([]string{"exists"}).AddRow(true),
+			expectedError: ErrVersionMismatch,
+		},
+		"user not found": {
+			expectedQuery: `
+				UPDATE users
+				SET name = $1, email = $2, password = $3,
+				    updated_at = CURRENT_TIMESTAMP, version = version + 1
+				WHERE id = $4
+				RETURNING id, name, email, role, created_at, updated_at, version
+			`,
+			expectedArgs:  []driver.Value{"john", "john@me.com", bcryptHash{"password123!"}, 1},
+			mockOutput:    sqlmock.NewRows([]string{"id", "name", "email", "role", "created_at", "version"}),
+			expectedError: ErrUserNotFound,
 		},
 	}
 	for name, tc := range testcases {
@@ -447,45 +1086,369 @@ func TestUsersService_UpdateUser(t *testing.T) {
 
 			logger := slog.Default()
 
-			if tc.mockCalled {
+			mock.
+				ExpectQuery(regexp.QuoteMeta(tc.expectedQuery)).
+				WithArgs(tc.expectedArgs...).
+				WillReturnRows(tc.mockOutput)
+
+			if tc.mockExists != nil {
 				mock.
-					ExpectExec(regexp.QuoteMeta(`
-                        UPDATE users 
-						SET name = $1, email = $2, password = $3
-						WHERE id = $4
-                    `)).
-					WithArgs(tc.mockInputArgs...).
-					WillReturnResult(sqlmock.NewResult(1, 1)).
-					WillReturnError(tc.mockError)
+					ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS ( SELECT 1 FROM users WHERE id = $1::int )`)).
+					WithArgs(1).
+					WillReturnRows(tc.mockExists)
+			}
+
+			rdb, rmock := redismock.NewClientMock()
+			if tc.expectedError == nil {
+				rmock.Regexp().ExpectSet("1", `.*`, 0).SetVal("OK")
+			}
+			userService := NewUsersService(logger, sqlx.NewDb(db, "sqlmock"), NewRedisCache(rdb), 0, 0, bcrypt.MinCost)
+
+			output, err := userService.UpdateUser(t.Context(), 1, input, tc.version)
+			require.ErrorIs(t, err, tc.expectedError)
+			assert.Equal(t, tc.expectedOutput, output)
+
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+			assert.NoError(t, rmock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUsersService_PatchUser(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	name, email, password := "jim", "jim@me.com", "password123!"
+
+	testcases := map[string]struct {
+		patch          models.UserPatch
+		version        uint64
+		expectedQuery  string
+		expectedArgs   []driver.Value
+		mockOutput     *sqlmock.Rows
+		mockExists     *sqlmock.Rows
+		expectedOutput models.User
+		expectedError  error
+	}{
+		"only present fields are updated": {
+			patch: models.UserPatch{Email: &email},
+			expectedQuery: `
+				UPDATE users
+				SET email = $1, updated_at = CURRENT_TIMESTAMP, version = version + 1
+				WHERE id = $2
+				RETURNING id, name, email, role, created_at, updated_at, version
+			`,
+			expectedArgs: []driver.Value{email, 1},
+			mockOutput: sqlmock.NewRows([]string{"id", "name", "email", "role", "created_at", "version"}).
+				AddRow(1, "john", email, "user", createdAt, 2),
+			expectedOutput: models.User{
+				ID:        1,
+				Name:      "john",
+				Email:     email,
+				Role:      models.RoleUser,
+				CreatedAt: createdAt,
+				Version:   2,
+			},
+		},
+		"password is hashed": {
+			patch: models.UserPatch{Name: &name, Password: &password},
+			expectedQuery: `
+				UPDATE users
+				SET name = $1, password = $2, updated_at = CURRENT_TIMESTAMP, version = version + 1
+				WHERE id = $3
+				RETURNING id, name, email, role, created_at, updated_at, version
+			`,
+			expectedArgs: []driver.Value{name, bcryptHash{password}, 1},
+			mockOutput: sqlmock.NewRows([]string{"id", "name", "email", "role", "created_at", "version"}).
+				AddRow(1, name, "john@me.com", "user", createdAt, 2),
+			expectedOutput: models.User{
+				ID:        1,
+				Name:      name,
+				Email:     "john@me.com",
+				Role:      models.RoleUser,
+				CreatedAt: createdAt,
+				Version:   2,
+			},
+		},
+		"empty patch reads the user": {
+			patch: models.UserPatch{},
+			expectedQuery: `
+				SELECT id,
+				       name,
+				       email,
+				       role,
+				       created_at,
+				       updated_at,
+				       version
+				FROM users
+				WHERE id = $1::int
+			`,
+			expectedArgs: []driver.Value{1},
+			mockOutput: sqlmock.NewRows([]string{"id", "name", "email", "role", "created_at", "version"}).
+				AddRow(1, "john", "john@me.com", "user", createdAt, 2),
+			expectedOutput: models.User{
+				ID:        1,
+				Name:      "john",
+				Email:     "john@me.com",
+				Role:      models.RoleUser,
+				CreatedAt: createdAt,
+				Version:   2,
+			},
+		},
+		"matching version": {
+			patch:   models.UserPatch{Email: &email},
+			version: 1,
+			expectedQuery: `
+				UPDATE users
+				SET email = $1, updated_at = CURRENT_TIMESTAMP, version = version + 1
+				WHERE id = $2 AND version = $3
+				RETURNING id, name, email, role, created_at, updated_at, version
+			`,
+			expectedArgs: []driver.Value{email, 1, 1},
+			mockOutput: sqlmock.NewRows([]string{"id", "name", "email", "role", "created_at", "version"}).
+				AddRow(1, "john", email, "user", createdAt, 2),
+			expectedOutput: models.User{
+				ID:        1,
+				Name:      "john",
+				Email:     email,
+				Role:      models.RoleUser,
+				CreatedAt: createdAt,
+				Version:   2,
+			},
+		},
+		"empty patch at stale version": {
+			patch:   models.UserPatch{},
+			version: 1,
+			expectedQuery: `
+				SELECT id,
+				       name,
+				       email,
+				       role,
+				       created_at,
+				       updated_at,
+				       version
+				FROM users
+				WHERE id = $1::int AND version = $2
+			`,
+			expectedArgs:  []driver.Value{1, 1},
+			mockOutput:    sqlmock.NewRows([]string{"id", "name", "email", "role", "created_at", "version"}),
+			mockExists:    sqlmock.NewRows([]string{"exists"}).AddRow(true),
+			expectedError: ErrVersionMismatch,
+		},
+		"user not found": {
+			patch: models.UserPatch{Name: &name},
+			expectedQuery: `
+				UPDATE users
+				SET name = $1, updated_at = CURRENT_TIMESTAMP, version = version + 1
+				WHERE id = $2
+				RETURNING id, name, email, role, created_at, updated_at, version
+			`,
+			expectedArgs:  []driver.Value{name, 1},
+			mockOutput:    sqlmock.NewRows([]string{"id", "name", "email", "role", "created_at", "version"}),
+			expectedError: ErrUserNotFound,
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf(
+					"an error '%s' was not expected when opening a stub database connection",
+					err,
+				)
+			}
+			defer db.Close()
+
+			logger := slog.Default()
 
+			mock.
+				ExpectQuery(regexp.QuoteMeta(tc.expectedQuery)).
+				WithArgs(tc.expectedArgs...).
+				WillReturnRows(tc.mockOutput)
+
+			if tc.mockExists != nil {
 				mock.
-					ExpectQuery(regexp.QuoteMeta(`
-                        SELECT id,
-                               name,
-                               email,
-                               password
-                        FROM users
-                        WHERE id = $1::int
-                    `)).
-					WithArgs(tc.expectedOutput.ID).
-					WillReturnRows(tc.mockOutput).
-					WillReturnError(tc.mockError)
+					ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS ( SELECT 1 FROM users WHERE id = $1::int )`)).
+					WithArgs(1).
+					WillReturnRows(tc.mockExists)
 			}
 
 			rdb, rmock := redismock.NewClientMock()
-			rmock.ExpectGet(strconv.FormatUint(uint64(tc.expectedOutput.ID), 10)).SetErr(redis.Nil)
-			rmock.Regexp().ExpectSet(strconv.Itoa(int(tc.expectedOutput.ID)), `.*`, 0).SetVal("OK")
-			rmock.Regexp().ExpectSet(strconv.Itoa(int(tc.expectedOutput.ID)), `.*`, 0).SetVal("OK")
-			userService := NewUsersService(logger, sqlx.NewDb(db, "sqlmock"), rdb, 0)
+			if tc.expectedError == nil {
+				rmock.Regexp().ExpectSet("1", `.*`, 0).SetVal("OK")
+			}
+			userService := NewUsersService(logger, sqlx.NewDb(db, "sqlmock"), NewRedisCache(rdb), 0, 0, bcrypt.MinCost)
 
-			output, err := userService.UpdateUser(t.Context(), 1, tc.input)
-			assert.ErrorIs(t, err, tc.expectedError)
+			output, err := userService.PatchUser(t.Context(), 1, tc.patch, tc.version)
+			require.ErrorIs(t, err, tc.expectedError)
 			assert.Equal(t, tc.expectedOutput, output)
 
-			if tc.mockCalled {
-				if err = mock.ExpectationsWereMet(); err != nil {
-					t.Errorf("there were unfulfilled expectations: %s", err)
-				}
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+			assert.NoError(t, rmock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUsersService_VerifyCredentials(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123!"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	testcases := map[string]struct {
+		mockOutput     *sqlmock.Rows
+		mockError      error
+		password       string
+		expectedOutput models.User
+		expectedError  error
+	}{
+		"happy path": {
+			mockOutput: sqlmock.NewRows([]string{"id", "name", "email", "password", "role"}).
+				AddRow(1, "john", "john@me.com", string(hash), "user"),
+			password: "password123!",
+			expectedOutput: models.User{
+				ID:    1,
+				Name:  "john",
+				Email: "john@me.com",
+				Role:  models.RoleUser,
+			},
+			expectedError: nil,
+		},
+		"wrong password": {
+			mockOutput: sqlmock.NewRows([]string{"id", "name", "email", "password", "role"}).
+				AddRow(1, "john", "john@me.com", string(hash), "user"),
+			password:       "wrong",
+			expectedOutput: models.User{},
+			expectedError:  ErrInvalidCredentials,
+		},
+		"stored password not hashed": {
+			mockOutput: sqlmock.NewRows([]string{"id", "name", "email", "password", "role"}).
+				AddRow(1, "john", "john@me.com", "password123!", "user"),
+			password:       "password123!",
+			expectedOutput: models.User{},
+			expectedError:  ErrInvalidCredentials,
+		},
+		"unknown email": {
+			mockError:      sql.ErrNoRows,
+			password:       "password123!",
+			expectedOutput: models.User{},
+			expectedError:  ErrInvalidCredentials,
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf(
+					"an error '%s' was not expected when opening a stub database connection",
+					err,
+				)
+			}
+			defer db.Close()
+
+			logger := slog.Default()
+
+			expectation := mock.
+				ExpectQuery(regexp.QuoteMeta(`
+					SELECT id,
+					       name,
+					       email,
+					       password,
+					       role
+					FROM users
+					WHERE email = $1
+				`)).
+				WithArgs("john@me.com")
+			if tc.mockError != nil {
+				expectation.WillReturnError(tc.mockError)
+			} else {
+				expectation.WillReturnRows(tc.mockOutput)
+			}
+
+			rdb, _ := redismock.NewClientMock()
+			userService := NewUsersService(logger, sqlx.NewDb(db, "sqlmock"), NewRedisCache(rdb), 0, 0, bcrypt.MinCost)
+
+			output, err := userService.VerifyCredentials(t.Context(), "john@me.com", tc.password)
+			require.ErrorIs(t, err, tc.expectedError)
+			assert.Equal(t, tc.expectedOutput, output)
+
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestUsersService_dummyPasswordHash(t *testing.T) {
+	for _, cost := range []int{bcrypt.MinCost, bcrypt.MinCost + 1} {
+		userService := NewUsersService(slog.Default(), nil, NoopCache{}, 0, 0, cost)
+
+		// Unknown emails must cost as much to check as real passwords
+		got, err := bcrypt.Cost(userService.dummyPasswordHash())
+		require.NoError(t, err)
+		assert.Equal(t, cost, got)
+	}
+}
+
+func TestUsersService_EmailTaken(t *testing.T) {
+	testcases := map[string]struct {
+		mockOutput     *sqlmock.Rows
+		mockError      error
+		expectedOutput bool
+		expectedError  bool
+	}{
+		"taken": {
+			mockOutput:     sqlmock.NewRows([]string{"exists"}).AddRow(true),
+			expectedOutput: true,
+		},
+		"free": {
+			mockOutput:     sqlmock.NewRows([]string{"exists"}).AddRow(false),
+			expectedOutput: false,
+		},
+		"db error": {
+			mockError:     errors.New("connection refused"),
+			expectedError: true,
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf(
+					"an error '%s' was not expected when opening a stub database connection",
+					err,
+				)
+			}
+			defer db.Close()
+
+			expectation := mock.
+				ExpectQuery(regexp.QuoteMeta(`
+					SELECT EXISTS (
+						SELECT 1
+						FROM users
+						WHERE email = $1
+					)
+				`)).
+				WithArgs("john@me.com")
+			if tc.mockError != nil {
+				expectation.WillReturnError(tc.mockError)
+			} else {
+				expectation.WillReturnRows(tc.mockOutput)
+			}
+
+			rdb, _ := redismock.NewClientMock()
+			userService := NewUsersService(slog.Default(), sqlx.NewDb(db, "sqlmock"), NewRedisCache(rdb), 0, 0, bcrypt.MinCost)
+
+			taken, err := userService.EmailTaken(t.Context(), "john@me.com")
+			if tc.expectedError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedOutput, taken)
+
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
 			}
 		})
 	}
@@ -0,0 +1,140 @@
+// Package validation builds the validator of the API's request models. One
+// Validator is built at startup and shared by every request, which keeps the
+// validator's cache of struct metadata and gives the API's own rules a place
+// to be registered. Besides the validator's built-in tags, models can use:
+//
+//	password      a password with at least one letter and one digit
+//	email_domain  an email address at one of the allowed domains
+//	unique_email  an email address no user has registered yet
+//
+// Messages are translated by the i18n catalogue and name fields by their JSON
+// names.
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+
+	"example.com/examples/api/layered/internal/i18n"
+)
+
+// EmailChecker represents a type capable of reporting whether an email
+// address is already registered.
+type EmailChecker interface {
+	EmailTaken(ctx context.Context, email string) (bool, error)
+}
+
+// Validator validates request models.
+type Validator struct {
+	validate *validator.Validate
+}
+
+// New creates a Validator with the API's rules. Email addresses must be at
+// one of allowedEmailDomains, unless it is empty, and unique_email checks
+// addresses with emails. logger records failed uniqueness checks.
+func New(logger *slog.Logger, allowedEmailDomains []string, emails EmailChecker) (*Validator, error) {
+	v := validator.New()
+	if err := i18n.RegisterValidator(v); err != nil {
+		return nil, fmt.Errorf("[in validation.New] %w", err)
+	}
+
+	rules := []struct {
+		tag  string
+		key  string
+		rule validator.FuncCtx
+	}{
+		{tag: "password", key: i18n.WeakPassword, rule: password},
+		{tag: "email_domain", key: i18n.DomainNotAllowed, rule: emailDomain(allowedEmailDomains)},
+		{tag: "unique_email", key: i18n.AlreadyTaken, rule: uniqueEmail(logger, emails)},
+	}
+	for _, r := range rules {
+		if err := v.RegisterValidationCtx(r.tag, r.rule); err != nil {
+			return nil, fmt.Errorf("[in validation.New] register %s failed: %w", r.tag, err)
+		}
+		if err := i18n.RegisterTag(v, r.tag, r.key); err != nil {
+			return nil, fmt.Errorf("[in validation.New] %w", err)
+		}
+	}
+
+	return &Validator{validate: v}, nil
+}
+
+// Struct validates the fields of data, which must be a pointer to a struct,
+// and returns the errors of those that are invalid. The error is only
+// non-nil if data cannot be validated at all.
+func (v *Validator) Struct(ctx context.Context, data any) ([]validator.FieldError, error) {
+	if err := v.validate.StructCtx(ctx, data); err != nil {
+		var fieldErrors validator.ValidationErrors
+		if !errors.As(err, &fieldErrors) {
+			return []validator.FieldError{}, fmt.Errorf(
+				"[in validation.Validator.Struct] invalid validation error: %w",
+				err,
+			)
+		}
+
+		return fieldErrors, nil
+	}
+
+	return []validator.FieldError{}, nil
+}
+
+// password reports whether a password contains at least one letter and one
+// digit. Its length is left to the min and max tags.
+func password(_ context.Context, fl validator.FieldLevel) bool {
+	s := fl.Field().String()
+
+	return strings.ContainsFunc(s, unicode.IsLetter) && strings.ContainsFunc(s, unicode.IsDigit)
+}
+
+// emailDomain returns a rule reporting whether an email address is at one of
+// domains, compared case-insensitively. Every domain is allowed if domains is
+// empty.
+func emailDomain(domains []string) validator.FuncCtx {
+	allowed := make([]string, len(domains))
+	for i, domain := range domains {
+		allowed[i] = strings.ToLower(strings.TrimSpace(domain))
+	}
+
+	return func(_ context.Context, fl validator.FieldLevel) bool {
+		if len(allowed) == 0 {
+			return true
+		}
+
+		i := strings.LastIndex(fl.Field().String(), "@")
+		if i < 0 {
+			return false
+		}
+
+		return slices.Contains(allowed, strings.ToLower(fl.Field().String()[i+1:]))
+	}
+}
+
+// uniqueEmail returns a rule reporting whether no user has registered an
+// email address. The check only gives clients an early, field-level answer:
+// the database's unique constraint still guards the write, so an address that
+// cannot be checked is let through rather than failing the request.
+func uniqueEmail(logger *slog.Logger, emails EmailChecker) validator.FuncCtx {
+	logger = logger.With(slog.String("func", "validation.uniqueEmail"))
+
+	return func(ctx context.Context, fl validator.FieldLevel) bool {
+		taken, err := emails.EmailTaken(ctx, fl.Field().String())
+		if err != nil {
+			logger.WarnContext(
+				ctx,
+				"failed to check whether email is taken",
+				slog.String("error", err.Error()),
+			)
+
+			return true
+		}
+
+		return !taken
+	}
+}
@@ -0,0 +1,174 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/examples/api/layered/internal/i18n"
+)
+
+// userRequest mirrors the user requests of the handlers package, which
+// cannot be imported here without an import cycle.
+type userRequest struct {
+	Name     string `json:"name"     validate:"required,min=2,max=50"`
+	Email    string `json:"email"    validate:"required,email,email_domain,unique_email"`
+	Password string `json:"password" validate:"required,min=8,max=30,password"`
+}
+
+// emailChecker is an EmailChecker for which only taken is taken, failing with
+// err if it is set.
+type emailChecker struct {
+	taken string
+	err   error
+}
+
+func (c emailChecker) EmailTaken(_ context.Context, email string) (bool, error) {
+	return email == c.taken, c.err
+}
+
+func TestValidator_Struct(t *testing.T) {
+	t.Parallel()
+
+	valid := userRequest{Name: "john", Email: "john@example.com", Password: "password123"}
+
+	tests := map[string]struct {
+		domains     []string
+		emails      emailChecker
+		modify      func(r *userRequest)
+		wantField   string
+		wantTag     string
+		wantMessage string
+	}{
+		"valid": {
+			modify: func(*userRequest) {},
+		},
+		"password without digit": {
+			modify:      func(r *userRequest) { r.Password = "password" },
+			wantField:   "password",
+			wantTag:     "password",
+			wantMessage: "password must contain at least one letter and one digit",
+		},
+		"password without letter": {
+			modify:    func(r *userRequest) { r.Password = "12345678" },
+			wantField: "password",
+			wantTag:   "password",
+		},
+		"allowed domain": {
+			domains: []string{"mail.com", " Example.com "},
+			modify:  func(r *userRequest) { r.Email = "john@EXAMPLE.com" },
+		},
+		"domain not allowed": {
+			domains:     []string{"mail.com"},
+			modify:      func(*userRequest) {},
+			wantField:   "email",
+			wantTag:     "email_domain",
+			wantMessage: "email must be an address at an allowed domain",
+		},
+		"email taken": {
+			emails:      emailChecker{taken: "john@example.com"},
+			modify:      func(*userRequest) {},
+			wantField:   "email",
+			wantTag:     "unique_email",
+			wantMessage: "email is already taken",
+		},
+		"email check failed": {
+			emails: emailChecker{taken: "john@example.com", err: errors.New("connection refused")},
+			modify: func(*userRequest) {},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			validate, err := New(slog.New(slog.DiscardHandler), tc.domains, tc.emails)
+			require.NoError(t, err)
+
+			request := valid
+			tc.modify(&request)
+
+			fieldErrors, err := validate.Struct(t.Context(), &request)
+			require.NoError(t, err)
+
+			if tc.wantField == "" {
+				assert.Empty(t, fieldErrors)
+
+				return
+			}
+			if assert.Len(t, fieldErrors, 1) {
+				assert.Equal(t, tc.wantField, fieldErrors[0].Field())
+				assert.Equal(t, tc.wantTag, fieldErrors[0].Tag())
+				if tc.wantMessage != "" {
+					assert.Equal(t, tc.wantMessage, fieldErrors[0].Translate(i18n.Translator("en")))
+				}
+			}
+		})
+	}
+}
+
+func TestValidator_Struct_translated(t *testing.T) {
+	t.Parallel()
+
+	validate, err := New(slog.New(slog.DiscardHandler), nil, emailChecker{})
+	require.NoError(t, err)
+
+	request := userRequest{Name: "john", Email: "john@example.com", Password: "password"}
+	fieldErrors, err := validate.Struct(t.Context(), &request)
+	require.NoError(t, err)
+	require.Len(t, fieldErrors, 1)
+
+	assert.Equal(
+		t,
+		"password debe contener al menos una letra y un dígito",
+		fieldErrors[0].Translate(i18n.Translator("es")),
+	)
+	assert.Equal(
+		t,
+		"password doit contenir au moins une lettre et un chiffre",
+		fieldErrors[0].Translate(i18n.Translator("fr")),
+	)
+}
+
+func TestValidator_Struct_notStruct(t *testing.T) {
+	t.Parallel()
+
+	validate, err := New(slog.New(slog.DiscardHandler), nil, emailChecker{})
+	require.NoError(t, err)
+
+	_, err = validate.Struct(t.Context(), nil)
+	assert.Error(t, err)
+}
+
+// BenchmarkValidate compares validating a request with a validator created
+// for it, as every request used to, against a validator shared by every
+// request. The fresh validator has to parse the struct's tags each time,
+// while the shared one parses them once and caches them.
+func BenchmarkValidate(b *testing.B) {
+	request := userRequest{Name: "john", Email: "john@example.com", Password: "password123"}
+
+	b.Run("new per request", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			v := validator.New()
+			_ = v.RegisterValidation("email_domain", func(validator.FieldLevel) bool { return true })
+			_ = v.RegisterValidation("unique_email", func(validator.FieldLevel) bool { return true })
+			_ = v.RegisterValidation("password", func(validator.FieldLevel) bool { return true })
+			_ = v.Struct(&request)
+		}
+	})
+
+	b.Run("shared", func(b *testing.B) {
+		validate, err := New(slog.New(slog.DiscardHandler), nil, emailChecker{})
+		require.NoError(b, err)
+
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = validate.Struct(b.Context(), &request)
+		}
+	})
+}
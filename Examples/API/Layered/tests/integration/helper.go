@@ -1,15 +1,17 @@
 package integration
 
 import (
-	"context"
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"testing"
 	"time"
 
 	"github.com/jmoiron/sqlx"
-	"github.com/redis/go-redis/v9"
+	"golang.org/x/crypto/bcrypt"
 
 	// Import the SQLite driver
 	_ "github.com/mattn/go-sqlite3"
@@ -18,32 +20,13 @@ import (
 	"example.com/examples/api/layered/internal/routes"
 	"example.com/examples/api/layered/internal/services"
 	"example.com/examples/api/layered/internal/telemetry"
+	"example.com/examples/api/layered/internal/validation"
 )
 
-type TestRedis struct{}
+// testJWTSecret signs the tokens issued by the test server.
+const testJWTSecret = "integration-test-secret"
 
-func (r *TestRedis) Set(
-	ctx context.Context,
-	_ string,
-	_ any,
-	_ time.Duration,
-) *redis.StatusCmd {
-	return redis.NewStatusCmd(ctx, "OK")
-}
-
-func (r *TestRedis) Get(ctx context.Context, key string) *redis.StringCmd {
-	return redis.NewStringCmd(ctx, redis.Nil)
-}
-
-func (r *TestRedis) Del(ctx context.Context, keys ...string) *redis.IntCmd {
-	return redis.NewIntCmd(ctx, int64(len(keys)))
-}
-
-func (r *TestRedis) Ping(ctx context.Context) *redis.StatusCmd {
-	return redis.NewStatusCmd(ctx, "OK")
-}
-
-// NewTestDB returns an in-memory SQLite DB with a users table and some test data.
+// NewTestDB returns an in-memory SQLite DB with users and blogs tables and some test data.
 func newTestDB() (*sqlx.DB, error) {
 	db, err := sqlx.Open("sqlite3", ":memory:")
 	if err != nil {
@@ -55,13 +38,49 @@ func newTestDB() (*sqlx.DB, error) {
         id INTEGER PRIMARY KEY AUTOINCREMENT,
         name TEXT NOT NULL,
         email TEXT NOT NULL UNIQUE,
-        password TEXT NOT NULL
+        password TEXT NOT NULL,
+        role TEXT NOT NULL DEFAULT 'user' CHECK (role IN ('admin', 'user')),
+        created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        version INTEGER NOT NULL DEFAULT 1
     );
+    INSERT INTO users (name, email, password, role) VALUES
+        ('Alice', 'alice@example.com', 'password123', 'admin');
     INSERT INTO users (name, email, password) VALUES
-        ('Alice', 'alice@example.com', 'password123'),
         ('Bob', 'bob@example.com', 'securepass456'),
         ('Carol', 'carol@example.com', 'carolpass789'),
         ('Dave', 'dave@example.com', 'davepass321');
+    CREATE TABLE blogs (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        author_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+        title TEXT NOT NULL,
+        score REAL NOT NULL DEFAULT 0,
+        created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );
+    INSERT INTO blogs (author_id, title, score) VALUES
+        (1, 'First Blog Post', 8.5),
+        (2, 'Travel Adventures', 7.2);
+
+    CREATE TABLE comments (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+        blog_id INTEGER NOT NULL REFERENCES blogs (id) ON DELETE CASCADE,
+        message TEXT NOT NULL,
+        created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );
+    INSERT INTO comments (user_id, blog_id, message, created_at) VALUES
+        (2, 1, 'Great post!', '2024-05-15 12:00:00'),
+        (1, 1, 'Thanks for reading.', '2024-05-15 13:00:00');
+
+    CREATE TABLE votes (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+        blog_id INTEGER NOT NULL REFERENCES blogs (id) ON DELETE CASCADE,
+        value INTEGER NOT NULL CHECK (value IN (-1, 1)),
+        created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        UNIQUE (blog_id, user_id)
+    );
     `
 
 	_, err = db.Exec(schema)
@@ -69,6 +88,25 @@ func newTestDB() (*sqlx.DB, error) {
 		return nil, fmt.Errorf("failed to create schema or insert test data: %w", err)
 	}
 
+	// Store the seeded passwords as bcrypt hashes so that seeded users can log in
+	var users []struct {
+		ID       int    `db:"id"`
+		Password string `db:"password"`
+	}
+	if err = db.Select(&users, "SELECT id, password FROM users"); err != nil {
+		return nil, fmt.Errorf("failed to read seeded users: %w", err)
+	}
+	for _, user := range users {
+		hash, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.MinCost)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash seeded password: %w", err)
+		}
+
+		if _, err = db.Exec("UPDATE users SET password = ? WHERE id = ?", string(hash), user.ID); err != nil {
+			return nil, fmt.Errorf("failed to store seeded password hash: %w", err)
+		}
+	}
+
 	return db, nil
 }
 
@@ -81,26 +119,99 @@ func newTestServer() (*httptest.Server, *sqlx.DB, error) {
 
 	logger := slog.Default()
 
-	rdb := &TestRedis{}
+	// Cache in memory, as the service does without Redis, so that reads
+	// really go through the cache
+	cache := services.NewMemoryCache(1000)
 
 	// Create a new users service
-	usersService := services.NewUsersService(logger, db, rdb, 0)
+	usersService := services.NewUsersService(logger, db, cache, 0, 0, bcrypt.MinCost)
+
+	// Create a new blogs service
+	blogsService := services.NewBlogsService(logger, db, cache, 0)
+
+	// Create a new comments service
+	commentsService := services.NewCommentsService(logger, db)
+
+	// Create a new auth service
+	authService := services.NewAuthService(
+		logger,
+		db,
+		usersService,
+		[]byte(testJWTSecret),
+		time.Minute,
+		time.Hour,
+	)
+
+	// Create the validator shared by every request, allowing any email domain
+	validate, err := validation.New(logger, nil, usersService)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create validator: %w", err)
+	}
 
 	// Create a serve mux to act as our route multiplexer
 	mux := telemetry.InstrumentServeMux(http.NewServeMux())
 
 	// Add our routes to the mux
-	routes.AddRoutes(mux, logger, usersService, false)
-
-	// Create handler and wrap in middleware
-	wrappedMux := middleware.WrapHandler(
-		mux.InstrumentRootHandler(),
-		middleware.TraceID(),
-		middleware.Logger(logger),
-		middleware.Recover(logger),
+	routes.AddRoutes(
+		mux,
+		logger,
+		usersService,
+		blogsService,
+		commentsService,
+		authService,
+		validate,
+		middleware.Idempotency(logger, middleware.NewLocalIdempotencyStore(), time.Hour),
+		middleware.NewLocalRateLimiter(logger),
+		false,
 	)
 
-	// Start a test server with your wrapped handler
-	server := httptest.NewServer(wrappedMux)
+	// Add middleware
+	mux.AddMiddleware(middleware.TraceID())
+	mux.AddMiddleware(middleware.Logger(logger))
+	mux.AddMiddleware(middleware.Recover(logger))
+
+	// Start a test server with the instrumented handler
+	server := httptest.NewServer(mux.InstrumentRootHandler())
 	return server, db, nil
 }
+
+// login logs in as the seeded user with the provided email and password and
+// returns the issued access token.
+func login(t *testing.T, serverURL string, email string, password string) string {
+	t.Helper()
+
+	body, err := json.Marshal(map[string]string{"email": email, "password": password})
+	if err != nil {
+		t.Fatalf("Failed to marshal login request: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(
+		t.Context(),
+		http.MethodPost,
+		serverURL+"/api/auth/login",
+		bytes.NewBuffer(body),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create login request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to make login request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Login failed with status %d", resp.StatusCode)
+	}
+
+	var tokens struct {
+		AccessToken string `json:"accessToken"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		t.Fatalf("Failed to decode login response: %v", err)
+	}
+
+	return tokens.AccessToken
+}
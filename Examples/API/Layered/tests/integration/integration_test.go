@@ -3,11 +3,19 @@ package integration
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/fxamacker/cbor/v2"
 	"github.com/stretchr/testify/assert"
+	"github.com/vmihailenco/msgpack/v5"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestHealth(t *testing.T) {
@@ -58,6 +66,8 @@ func TestReadUser(t *testing.T) {
 	}
 	t.Cleanup(server.Close)
 
+	token := login(t, server.URL, "alice@example.com", "password123")
+
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			req, err := http.NewRequestWithContext(
@@ -69,6 +79,7 @@ func TestReadUser(t *testing.T) {
 			if err != nil {
 				t.Fatalf("Failed to create GET request: %v", err)
 			}
+			req.Header.Set("Authorization", "Bearer "+token)
 
 			resp, err := http.DefaultClient.Do(req)
 			if err != nil {
@@ -94,6 +105,113 @@ func TestReadUser(t *testing.T) {
 	}
 }
 
+// TestReadUserCached verifies that a user, once read, is served from the
+// cache: a change made directly in the database, bypassing the service, is
+// not seen by later reads.
+func TestReadUserCached(t *testing.T) {
+	t.Parallel()
+
+	server, db, err := newTestServer()
+	if err != nil {
+		t.Fatalf("Failed to create test server: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	token := login(t, server.URL, "alice@example.com", "password123")
+
+	readName := func() string {
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL+"/api/user/2", nil)
+		if err != nil {
+			t.Fatalf("Failed to create GET request: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to make GET request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var user struct {
+			Name string `json:"name"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+
+		return user.Name
+	}
+
+	assert.Equal(t, "Bob", readName(), "First read")
+
+	if _, err := db.Exec("UPDATE users SET name = 'Robert' WHERE id = 2"); err != nil {
+		t.Fatalf("Failed to update user in DB: %v", err)
+	}
+
+	assert.Equal(t, "Bob", readName(), "Read served from the cache")
+}
+
+func TestReadUserNotFound(t *testing.T) {
+	t.Parallel()
+
+	server, _, err := newTestServer()
+	if err != nil {
+		t.Fatalf("Failed to create test server: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	token := login(t, server.URL, "alice@example.com", "password123")
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL+"/api/user/999", nil)
+	if err != nil {
+		t.Fatalf("Failed to create GET request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept-Language", "fr-FR, en;q=0.8")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to make GET request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode, "Expected status code 404 Not Found")
+	assert.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"), "Content type mismatch")
+	assert.Equal(t, "fr", resp.Header.Get("Content-Language"), "Content language mismatch")
+
+	var problem struct {
+		Type     string `json:"type"`
+		Title    string `json:"title"`
+		Status   int    `json:"status"`
+		Detail   string `json:"detail"`
+		Instance string `json:"instance"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&problem); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	assert.Equal(t, "/api/problems/not-found", problem.Type, "Problem type mismatch")
+	assert.Equal(t, "Introuvable", problem.Title, "Problem title mismatch")
+	assert.Equal(t, http.StatusNotFound, problem.Status, "Problem status mismatch")
+	assert.Equal(t, "User not found.", problem.Detail, "Problem detail mismatch")
+	assert.Equal(t, "/api/user/999", problem.Instance, "Problem instance mismatch")
+
+	// The type URI serves documentation of the type
+	docsReq, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL+problem.Type, nil)
+	if err != nil {
+		t.Fatalf("Failed to create GET request: %v", err)
+	}
+
+	docsResp, err := http.DefaultClient.Do(docsReq)
+	if err != nil {
+		t.Fatalf("Failed to make GET request: %v", err)
+	}
+	defer docsResp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, docsResp.StatusCode, "Expected status code 200 OK")
+	assert.Equal(t, "text/html; charset=utf-8", docsResp.Header.Get("Content-Type"), "Content type mismatch")
+}
+
 func TestListUsers(t *testing.T) {
 	t.Parallel()
 
@@ -119,6 +237,7 @@ func TestListUsers(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create GET request: %v", err)
 	}
+	req.Header.Set("Authorization", "Bearer "+login(t, server.URL, "alice@example.com", "password123"))
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -149,6 +268,166 @@ func TestListUsers(t *testing.T) {
 	}
 }
 
+func TestListUsersPagination(t *testing.T) {
+	t.Parallel()
+
+	server, _, err := newTestServer()
+	if err != nil {
+		t.Fatalf("Failed to create test server: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	token := login(t, server.URL, "alice@example.com", "password123")
+
+	// Walk the users two at a time, following the next cursor.
+	var ids []int
+	pageURL := server.URL + "/api/user?limit=2"
+	for pages := 0; pageURL != ""; pages++ {
+		if pages > 3 {
+			t.Fatalf("Expected pagination to finish, still following %s", pageURL)
+		}
+
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, pageURL, nil)
+		if err != nil {
+			t.Fatalf("Failed to create GET request: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to make GET request: %v", err)
+		}
+
+		var response struct {
+			Users []struct {
+				ID int `json:"id"`
+			} `json:"Users"`
+			Next string `json:"next"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status code 200 OK")
+		assert.LessOrEqual(t, len(response.Users), 2, "Expected at most 2 users per page")
+
+		for _, user := range response.Users {
+			ids = append(ids, user.ID)
+		}
+
+		pageURL = ""
+		if response.Next != "" {
+			pageURL = server.URL + "/api/user?limit=2&cursor=" + response.Next
+		}
+	}
+
+	assert.Equal(t, []int{1, 2, 3, 4}, ids, "Expected every user exactly once, in id order")
+
+	// An invalid limit is rejected before the database is queried.
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL+"/api/user?limit=1000", nil)
+	if err != nil {
+		t.Fatalf("Failed to create GET request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to make GET request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "Expected status code 400 Bad Request")
+}
+
+func TestListUsersFilterAndSort(t *testing.T) {
+	t.Parallel()
+
+	server, _, err := newTestServer()
+	if err != nil {
+		t.Fatalf("Failed to create test server: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	token := login(t, server.URL, "alice@example.com", "password123")
+
+	tests := map[string]struct {
+		query        string
+		wantStatus   int
+		wantNames    []string
+		wantProblems []string
+	}{
+		"substring filter ignores case": {
+			query:      `filter=` + url.QueryEscape(`name co "A" or email sw "d"`),
+			wantStatus: http.StatusOK,
+			wantNames:  []string{"Alice", "Carol", "Dave"},
+		},
+		"descending sort": {
+			query:      "sort=-name",
+			wantStatus: http.StatusOK,
+			wantNames:  []string{"Dave", "Carol", "Bob", "Alice"},
+		},
+		"filter on role": {
+			query:      `filter=` + url.QueryEscape(`role eq "admin"`),
+			wantStatus: http.StatusOK,
+			wantNames:  []string{"Alice"},
+		},
+		"wildcards are matched literally": {
+			query:      `filter=` + url.QueryEscape(`name co "%"`),
+			wantStatus: http.StatusOK,
+			wantNames:  []string{},
+		},
+		"invalid filter and sort": {
+			query:        `filter=` + url.QueryEscape(`password eq "x"`) + "&sort=-nmae",
+			wantStatus:   http.StatusBadRequest,
+			wantProblems: []string{"filter", "sort"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL+"/api/user?"+tc.query, nil)
+			if err != nil {
+				t.Fatalf("Failed to create GET request: %v", err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Failed to make GET request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			assert.Equal(t, tc.wantStatus, resp.StatusCode, "Unexpected status code")
+
+			var response struct {
+				Users []struct {
+					Name string `json:"name"`
+				} `json:"Users"`
+				InvalidParams []struct {
+					Field string `json:"field"`
+				} `json:"invalidParams"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+
+			if tc.wantStatus == http.StatusOK {
+				names := []string{}
+				for _, user := range response.Users {
+					names = append(names, user.Name)
+				}
+				assert.Equal(t, tc.wantNames, names, "Unexpected users")
+			} else {
+				fields := []string{}
+				for _, problem := range response.InvalidParams {
+					fields = append(fields, problem.Field)
+				}
+				assert.Equal(t, tc.wantProblems, fields, "Unexpected invalid params")
+			}
+		})
+	}
+}
+
 func TestCreateUser(t *testing.T) {
 	t.Parallel()
 
@@ -225,10 +504,63 @@ func TestCreateUser(t *testing.T) {
 	}
 	assert.Equal(t, newUser.Name, dbUser.Name, "DB user name mismatch")
 	assert.Equal(t, newUser.Email, dbUser.Email, "DB user email mismatch")
-	assert.Equal(t, newUser.Password, dbUser.Password, "DB user password mismatch")
+	assert.NotEqual(t, newUser.Password, dbUser.Password, "DB user password stored in plaintext")
+	assert.NoError(
+		t,
+		bcrypt.CompareHashAndPassword([]byte(dbUser.Password), []byte(newUser.Password)),
+		"DB user password hash mismatch",
+	)
 	assert.Equal(t, createdUser.ID, dbUser.ID, "DB user ID mismatch with API response")
 }
 
+// TestCreateUserEmailTaken verifies that signing up with a registered email
+// is rejected by validation, before the user is inserted.
+func TestCreateUserEmailTaken(t *testing.T) {
+	t.Parallel()
+
+	server, _, err := newTestServer()
+	if err != nil {
+		t.Fatalf("Failed to create test server: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	body := `{"name": "Alice", "email": "alice@example.com", "password": "password789"}`
+	req, err := http.NewRequestWithContext(
+		t.Context(),
+		http.MethodPost,
+		server.URL+"/api/user",
+		strings.NewReader(body),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create POST request: %v", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to make POST request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "Expected status code 400 Bad Request")
+
+	var problem struct {
+		InvalidParams []struct {
+			Field   string `json:"field"`
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"invalidParams"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&problem); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if assert.Len(t, problem.InvalidParams, 1) {
+		assert.Equal(t, "email", problem.InvalidParams[0].Field)
+		assert.Equal(t, "unique_email", problem.InvalidParams[0].Code)
+		assert.Equal(t, "email is already taken", problem.InvalidParams[0].Message)
+	}
+}
+
 func TestUpdateUser(t *testing.T) {
 	t.Parallel()
 
@@ -264,6 +596,7 @@ func TestUpdateUser(t *testing.T) {
 		t.Fatalf("Failed to create PUT request: %v", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+login(t, server.URL, "alice@example.com", "password123"))
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -299,11 +632,16 @@ func TestUpdateUser(t *testing.T) {
 	}
 	assert.Equal(t, updatedUser.Name, dbUser.Name, "DB user name mismatch after update")
 	assert.Equal(t, updatedUser.Email, dbUser.Email, "DB user email mismatch after update")
-	assert.Equal(t, updatedUser.Password, dbUser.Password, "DB user password mismatch after update")
+	assert.NotEqual(t, updatedUser.Password, dbUser.Password, "DB user password stored in plaintext after update")
+	assert.NoError(
+		t,
+		bcrypt.CompareHashAndPassword([]byte(dbUser.Password), []byte(updatedUser.Password)),
+		"DB user password hash mismatch after update",
+	)
 	assert.Equal(t, 1, dbUser.ID, "DB user ID mismatch after update")
 }
 
-func TestDeleteUser(t *testing.T) {
+func TestPatchUser(t *testing.T) {
 	t.Parallel()
 
 	server, db, err := newTestServer()
@@ -312,37 +650,74 @@ func TestDeleteUser(t *testing.T) {
 	}
 	t.Cleanup(server.Close)
 
-	// Delete user with ID 2 (Bob)
-	req, err := http.NewRequestWithContext(
-		t.Context(),
-		http.MethodDelete,
-		server.URL+"/api/user/2",
-		nil,
-	)
-	if err != nil {
-		t.Fatalf("Failed to create DELETE request: %v", err)
-	}
+	token := login(t, server.URL, "bob@example.com", "securepass456")
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		t.Fatalf("Failed to make DELETE request: %v", err)
+	patch := func(contentType string, body string) *http.Response {
+		t.Helper()
+
+		req, err := http.NewRequestWithContext(
+			t.Context(),
+			http.MethodPatch,
+			server.URL+"/api/user/2",
+			strings.NewReader(body),
+		)
+		if err != nil {
+			t.Fatalf("Failed to create PATCH request: %v", err)
+		}
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to make PATCH request: %v", err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+
+		return resp
 	}
-	defer resp.Body.Close()
 
-	assert.Equal(t, http.StatusNoContent, resp.StatusCode, "Expected status code 204 No Content")
+	// Only the email is sent, so the name and password must be kept
+	resp := patch("application/merge-patch+json", `{"email":"bob.patched@example.com"}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status code 200 OK")
+
+	var user struct {
+		ID    int    `json:"id"`
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	assert.Equal(t, 2, user.ID, "Patched user ID mismatch")
+	assert.Equal(t, "Bob", user.Name, "Patched user name should be unchanged")
+	assert.Equal(t, "bob.patched@example.com", user.Email, "Patched user email mismatch")
 
-	// Verify user is no longer in the database
 	var dbUser struct {
-		ID       int    `db:"id"`
 		Name     string `db:"name"`
 		Email    string `db:"email"`
 		Password string `db:"password"`
 	}
-	err = db.Get(&dbUser, "SELECT id, name, email, password FROM users WHERE id = ?", 2)
-	assert.Error(t, err, "Expected error when querying deleted user")
+	if err = db.Get(&dbUser, "SELECT name, email, password FROM users WHERE id = ?", 2); err != nil {
+		t.Fatalf("Failed to query user from DB: %v", err)
+	}
+	assert.Equal(t, "Bob", dbUser.Name, "DB user name should be unchanged")
+	assert.Equal(t, "bob.patched@example.com", dbUser.Email, "DB user email mismatch after patch")
+	assert.NoError(
+		t,
+		bcrypt.CompareHashAndPassword([]byte(dbUser.Password), []byte("securepass456")),
+		"DB user password should be unchanged",
+	)
+
+	// Fields present in the patch are still validated
+	resp = patch("application/merge-patch+json", `{"email":"not-an-email"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "Expected status code 400 Bad Request")
+
+	// A merge patch must be labelled as one
+	resp = patch("application/json", `{"name":"Bobby"}`)
+	assert.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode, "Expected status code 415")
 }
 
-func TestWildcard(t *testing.T) {
+func TestUserOptimisticConcurrency(t *testing.T) {
 	t.Parallel()
 
 	server, _, err := newTestServer()
@@ -351,21 +726,1201 @@ func TestWildcard(t *testing.T) {
 	}
 	t.Cleanup(server.Close)
 
-	req, err := http.NewRequestWithContext(
-		t.Context(),
-		http.MethodGet,
-		server.URL+"/api/wildcard/test",
-		nil,
+	userToken := login(t, server.URL, "bob@example.com", "securepass456")
+	adminToken := login(t, server.URL, "alice@example.com", "password123")
+
+	do := func(method string, token string, ifMatch string, contentType string, body string) *http.Response {
+		t.Helper()
+
+		req, err := http.NewRequestWithContext(t.Context(), method, server.URL+"/api/user/2", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("Failed to create %s request: %v", method, err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to make %s request: %v", method, err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+
+		return resp
+	}
+
+	// Both clients read the same version of the user
+	resp := do(http.MethodGet, userToken, "", "", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status code 200 OK")
+	etag := resp.Header.Get("ETag")
+	assert.NotEmpty(t, etag, "Expected an ETag on the user")
+
+	// The first write succeeds and changes the ETag
+	resp = do(http.MethodPatch, userToken, etag, "application/merge-patch+json", `{"name":"Bobby"}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status code 200 OK")
+	newETag := resp.Header.Get("ETag")
+	assert.NotEqual(t, etag, newETag, "Expected the ETag to change after an update")
+
+	// Writes based on the old version are rejected
+	resp = do(
+		http.MethodPut,
+		userToken,
+		etag,
+		"application/json",
+		`{"name":"Bob","email":"bob@example.com","password":"securepass456"}`,
 	)
+	assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode, "Expected status code 412 for a stale PUT")
+
+	resp = do(http.MethodDelete, adminToken, etag, "", "")
+	assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode, "Expected status code 412 for a stale DELETE")
+
+	// Reading again returns the new version, which may be deleted
+	resp = do(http.MethodGet, userToken, "", "", "")
+	assert.Equal(t, newETag, resp.Header.Get("ETag"), "Expected the read ETag to match the updated one")
+
+	resp = do(http.MethodDelete, adminToken, newETag, "", "")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode, "Expected status code 204 No Content")
+}
+
+func TestConditionalGet(t *testing.T) {
+	t.Parallel()
+
+	server, _, err := newTestServer()
 	if err != nil {
-		t.Fatalf("Failed to create GET request: %v", err)
+		t.Fatalf("Failed to create test server: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	token := login(t, server.URL, "alice@example.com", "password123")
+
+	get := func(path string, header string, value string) *http.Response {
+		t.Helper()
+
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL+path, nil)
+		if err != nil {
+			t.Fatalf("Failed to create GET request: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to make GET request: %v", err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+
+		return resp
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	for _, path := range []string{"/api/user/2", "/api/user"} {
+		t.Run(path, func(t *testing.T) {
+			resp := get(path, "", "")
+			assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status code 200 OK")
+
+			etag := resp.Header.Get("ETag")
+			assert.NotEmpty(t, etag, "Expected an ETag")
+
+			resp = get(path, "If-None-Match", etag)
+			assert.Equal(t, http.StatusNotModified, resp.StatusCode, "Expected status code 304 for a current ETag")
+
+			resp = get(path, "If-None-Match", `"stale"`)
+			assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status code 200 for a stale ETag")
+		})
+	}
+
+	// Only a single user has a Last-Modified
+	resp := get("/api/user/2", "", "")
+	lastModified := resp.Header.Get("Last-Modified")
+	assert.NotEmpty(t, lastModified, "Expected a Last-Modified on the user")
+
+	resp = get("/api/user/2", "If-Modified-Since", lastModified)
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode, "Expected status code 304 for a current date")
+
+	resp = get("/api/user", "", "")
+	assert.Empty(t, resp.Header.Get("Last-Modified"), "Expected no Last-Modified on the list")
+	etag := resp.Header.Get("ETag")
+
+	// Deleting a user changes the list, whatever the date the client sends
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodDelete, server.URL+"/api/user/4", nil)
 	if err != nil {
-		t.Fatalf("Failed to make GET request: %v", err)
+		t.Fatalf("Failed to create DELETE request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	deleted, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to make DELETE request: %v", err)
+	}
+	deleted.Body.Close()
+	if deleted.StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected status code 204 No Content, got %d", deleted.StatusCode)
 	}
-	defer resp.Body.Close()
 
-	assert.Equal(t, http.StatusNotFound, resp.StatusCode, "Expected status code 404 Not Found")
+	resp = get("/api/user", "If-Modified-Since", time.Now().UTC().Format(http.TimeFormat))
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status code 200 after a delete")
+
+	resp = get("/api/user", "If-None-Match", etag)
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status code 200 for the ETag before the delete")
+}
+
+func TestUserTimestamps(t *testing.T) {
+	t.Parallel()
+
+	server, db, err := newTestServer()
+	if err != nil {
+		t.Fatalf("Failed to create test server: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	// Backdate Carol so that an update visibly moves updated_at forward
+	_, err = db.Exec("UPDATE users SET created_at = ?, updated_at = ? WHERE id = ?",
+		"2024-01-01 00:00:00", "2024-01-01 00:00:00", 3)
+	if err != nil {
+		t.Fatalf("Failed to backdate user: %v", err)
+	}
+	backdated := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	token := login(t, server.URL, "carol@example.com", "carolpass789")
+
+	type user struct {
+		CreatedAt string `json:"createdAt"`
+		UpdatedAt string `json:"updatedAt"`
+	}
+	do := func(method string, contentType string, body string) user {
+		t.Helper()
+
+		req, err := http.NewRequestWithContext(t.Context(), method, server.URL+"/api/user/3", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("Failed to create %s request: %v", method, err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to make %s request: %v", method, err)
+		}
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status code 200 OK")
+
+		var u user
+		if err := json.NewDecoder(resp.Body).Decode(&u); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+
+		return u
+	}
+	parse := func(value string) time.Time {
+		t.Helper()
+
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatalf("Timestamp %q is not RFC 3339: %v", value, err)
+		}
+
+		return parsed
+	}
+
+	read := do(http.MethodGet, "", "")
+	assert.True(t, backdated.Equal(parse(read.CreatedAt)), "Read createdAt mismatch")
+	assert.True(t, backdated.Equal(parse(read.UpdatedAt)), "Read updatedAt mismatch")
+
+	patched := do(http.MethodPatch, "application/merge-patch+json", `{"name":"Caroline"}`)
+	assert.True(t, backdated.Equal(parse(patched.CreatedAt)), "createdAt should not change on update")
+	assert.True(t, parse(patched.UpdatedAt).After(backdated), "updatedAt should move forward on update")
+}
+
+func TestImportUsers(t *testing.T) {
+	t.Parallel()
+
+	server, db, err := newTestServer()
+	if err != nil {
+		t.Fatalf("Failed to create test server: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	token := login(t, server.URL, "alice@example.com", "password123")
+
+	body := "name,email,password\n" +
+		"Erin,erin@example.com,erinpass123\n" +
+		"F,frank@example.com,frankpass123\n" +
+		"Alicia,alice@example.com,aliciapass123\n" +
+		"Grace,grace@example.com,gracepass123\n"
+
+	type rejection struct {
+		Line int `json:"line"`
+	}
+	type result struct {
+		DryRun   bool        `json:"dryRun"`
+		Imported int         `json:"imported"`
+		Rejected []rejection `json:"rejected"`
+	}
+	importUsers := func(query string) result {
+		t.Helper()
+
+		req, err := http.NewRequestWithContext(
+			t.Context(),
+			http.MethodPost,
+			server.URL+"/api/user/import"+query,
+			strings.NewReader(body),
+		)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "text/csv")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status code 200 OK")
+
+		var r result
+		if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+
+		return r
+	}
+	countUsers := func() int {
+		t.Helper()
+
+		var count int
+		if err := db.Get(&count, "SELECT COUNT(*) FROM users"); err != nil {
+			t.Fatalf("Failed to count users: %v", err)
+		}
+
+		return count
+	}
+
+	// The short name and the taken email are rejected by line
+	want := []rejection{{Line: 3}, {Line: 4}}
+
+	dryRun := importUsers("?dryRun=true")
+	assert.True(t, dryRun.DryRun, "Expected a dry run")
+	assert.Equal(t, 2, dryRun.Imported, "Dry run imported count mismatch")
+	assert.Equal(t, want, dryRun.Rejected, "Dry run rejected lines mismatch")
+	assert.Equal(t, 4, countUsers(), "A dry run should not create users")
+
+	imported := importUsers("")
+	assert.False(t, imported.DryRun, "Expected a real import")
+	assert.Equal(t, 2, imported.Imported, "Imported count mismatch")
+	assert.Equal(t, want, imported.Rejected, "Rejected lines mismatch")
+	assert.Equal(t, 6, countUsers(), "Expected the valid users to be created")
+
+	// Imported users can log in with their password
+	login(t, server.URL, "grace@example.com", "gracepass123")
+}
+
+func TestExportUsers(t *testing.T) {
+	t.Parallel()
+
+	server, _, err := newTestServer()
+	if err != nil {
+		t.Fatalf("Failed to create test server: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	token := login(t, server.URL, "alice@example.com", "password123")
+
+	export := func(accept string) (string, []string) {
+		t.Helper()
+
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL+"/api/user/export", nil)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Accept", accept)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status code 200 OK")
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("Failed to read response: %v", err)
+		}
+		assert.NotContains(t, string(body), "password", "Export should not include passwords")
+
+		return resp.Header.Get("Content-Type"), strings.Split(strings.TrimSpace(string(body)), "\n")
+	}
+
+	contentType, lines := export("text/csv")
+	assert.Equal(t, "text/csv", contentType, "Content-Type mismatch")
+	assert.Equal(t, "id,name,email,role,created_at,updated_at", lines[0], "CSV header mismatch")
+	// Timestamps depend on when the test ran, so only compare up to the role
+	want := []string{
+		"1,Alice,alice@example.com,admin,",
+		"2,Bob,bob@example.com,user,",
+		"3,Carol,carol@example.com,user,",
+		"4,Dave,dave@example.com,user,",
+	}
+	if assert.Len(t, lines[1:], len(want), "Expected one line per user") {
+		for i, line := range lines[1:] {
+			assert.True(t, strings.HasPrefix(line, want[i]), "CSV line %d mismatch: %s", i+2, line)
+		}
+	}
+
+	contentType, lines = export("application/x-ndjson")
+	assert.Equal(t, "application/x-ndjson", contentType, "Content-Type mismatch")
+	assert.Len(t, lines, 4, "Expected one line per user")
+	for i, line := range lines {
+		var user struct {
+			ID    uint   `json:"id"`
+			Email string `json:"email"`
+		}
+		if err := json.Unmarshal([]byte(line), &user); err != nil {
+			t.Fatalf("Line %d is not JSON: %v", i+1, err)
+		}
+		assert.Equal(t, uint(i+1), user.ID, "Users should be exported in ID order")
+	}
+}
+
+func TestDeleteUser(t *testing.T) {
+	t.Parallel()
+
+	server, db, err := newTestServer()
+	if err != nil {
+		t.Fatalf("Failed to create test server: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	// Delete user with ID 2 (Bob)
+	req, err := http.NewRequestWithContext(
+		t.Context(),
+		http.MethodDelete,
+		server.URL+"/api/user/2",
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create DELETE request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+login(t, server.URL, "alice@example.com", "password123"))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to make DELETE request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode, "Expected status code 204 No Content")
+
+	// Verify user is no longer in the database
+	var dbUser struct {
+		ID       int    `db:"id"`
+		Name     string `db:"name"`
+		Email    string `db:"email"`
+		Password string `db:"password"`
+	}
+	err = db.Get(&dbUser, "SELECT id, name, email, password FROM users WHERE id = ?", 2)
+	assert.Error(t, err, "Expected error when querying deleted user")
+}
+
+func TestWildcard(t *testing.T) {
+	t.Parallel()
+
+	server, _, err := newTestServer()
+	if err != nil {
+		t.Fatalf("Failed to create test server: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	req, err := http.NewRequestWithContext(
+		t.Context(),
+		http.MethodGet,
+		server.URL+"/api/wildcard/test",
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create GET request: %v", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to make GET request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode, "Expected status code 404 Not Found")
+}
+
+func TestRateLimitHeaders(t *testing.T) {
+	t.Parallel()
+
+	server, _, err := newTestServer()
+	if err != nil {
+		t.Fatalf("Failed to create test server: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL+"/api/blog", nil)
+	if err != nil {
+		t.Fatalf("Failed to create GET request: %v", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to make GET request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status code 200 OK")
+	assert.Equal(t, "300", resp.Header.Get("RateLimit-Limit"), "RateLimit-Limit mismatch")
+	assert.NotEmpty(t, resp.Header.Get("RateLimit-Remaining"), "Expected a RateLimit-Remaining header")
+	assert.NotEmpty(t, resp.Header.Get("RateLimit-Reset"), "Expected a RateLimit-Reset header")
+}
+
+func TestReadBlog(t *testing.T) {
+	t.Parallel()
+
+	server, _, err := newTestServer()
+	if err != nil {
+		t.Fatalf("Failed to create test server: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	tests := map[string]struct {
+		id         int
+		wantStatus int
+		wantTitle  string
+	}{
+		"existing blog": {1, http.StatusOK, "First Blog Post"},
+		"missing blog":  {99, http.StatusNotFound, ""},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(
+				t.Context(),
+				http.MethodGet,
+				server.URL+"/api/blog/"+strconv.Itoa(tc.id),
+				nil,
+			)
+			if err != nil {
+				t.Fatalf("Failed to create GET request: %v", err)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Failed to make GET request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			assert.Equal(t, tc.wantStatus, resp.StatusCode, "Status code mismatch")
+
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+
+			var blog struct {
+				ID       int    `json:"id"`
+				AuthorID int    `json:"authorId"`
+				Title    string `json:"title"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&blog); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+
+			assert.Equal(t, tc.id, blog.ID, "Blog ID mismatch")
+			assert.Equal(t, tc.wantTitle, blog.Title, "Blog title mismatch")
+		})
+	}
+}
+
+func TestReadBlogContentNegotiation(t *testing.T) {
+	t.Parallel()
+
+	server, _, err := newTestServer()
+	if err != nil {
+		t.Fatalf("Failed to create test server: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	type blog struct {
+		ID    int    `json:"id"`
+		Title string `json:"title"`
+	}
+
+	tests := map[string]struct {
+		accept          string
+		decode          func(r io.Reader, v any) error
+		wantStatus      int
+		wantContentType string
+	}{
+		"msgpack": {
+			accept: "application/msgpack",
+			decode: func(r io.Reader, v any) error {
+				decoder := msgpack.NewDecoder(r)
+				decoder.SetCustomStructTag("json")
+
+				return decoder.Decode(v)
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/msgpack",
+		},
+		"cbor": {
+			accept: "application/cbor",
+			decode: func(r io.Reader, v any) error {
+				return cbor.NewDecoder(r).Decode(v)
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/cbor",
+		},
+		"not acceptable": {
+			accept:          "text/plain",
+			wantStatus:      http.StatusNotAcceptable,
+			wantContentType: "application/problem+json",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL+"/api/blog/1", nil)
+			if err != nil {
+				t.Fatalf("Failed to create GET request: %v", err)
+			}
+			req.Header.Set("Accept", tc.accept)
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Failed to make GET request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			assert.Equal(t, tc.wantStatus, resp.StatusCode, "Status code mismatch")
+			assert.Equal(t, tc.wantContentType, resp.Header.Get("Content-Type"), "Content type mismatch")
+
+			if tc.decode == nil {
+				return
+			}
+
+			var got blog
+			if err := tc.decode(resp.Body, &got); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+
+			assert.Equal(t, blog{ID: 1, Title: "First Blog Post"}, got, "Blog mismatch")
+		})
+	}
+}
+
+func TestCreateBlog(t *testing.T) {
+	t.Parallel()
+
+	server, db, err := newTestServer()
+	if err != nil {
+		t.Fatalf("Failed to create test server: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	// Blogs are always written by the signed-in user, carol
+	tests := map[string]struct {
+		body       map[string]any
+		wantStatus int
+	}{
+		"own blog":              {map[string]any{"title": "Own Blog"}, http.StatusCreated},
+		"naming another author": {map[string]any{"authorId": 1, "title": "Borrowed Blog"}, http.StatusCreated},
+		"missing title":         {map[string]any{"title": ""}, http.StatusBadRequest},
+	}
+
+	token := login(t, server.URL, "carol@example.com", "carolpass789")
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			body, err := json.Marshal(tc.body)
+			if err != nil {
+				t.Fatalf("Failed to marshal blog: %v", err)
+			}
+
+			req, err := http.NewRequestWithContext(
+				t.Context(),
+				http.MethodPost,
+				server.URL+"/api/blog",
+				bytes.NewReader(body),
+			)
+			if err != nil {
+				t.Fatalf("Failed to create POST request: %v", err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Failed to make POST request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			assert.Equal(t, tc.wantStatus, resp.StatusCode, "Status code mismatch")
+
+			var authors []int
+			err = db.Select(&authors, "SELECT author_id FROM blogs WHERE title = ?", tc.body["title"])
+			if err != nil {
+				t.Fatalf("Failed to query blogs from DB: %v", err)
+			}
+
+			if tc.wantStatus == http.StatusCreated {
+				assert.Equal(t, []int{3}, authors, "Expected a blog by carol to be inserted")
+			} else {
+				assert.Empty(t, authors, "Expected no blog to be inserted")
+			}
+		})
+	}
+}
+
+func TestListBlogComments(t *testing.T) {
+	t.Parallel()
+
+	server, _, err := newTestServer()
+	if err != nil {
+		t.Fatalf("Failed to create test server: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	tests := map[string]struct {
+		path         string
+		wantStatus   int
+		wantMessages []string
+	}{
+		"first page":     {"/api/blog/1/comments", http.StatusOK, []string{"Great post!", "Thanks for reading."}},
+		"offset":         {"/api/blog/1/comments?limit=1&offset=1", http.StatusOK, []string{"Thanks for reading."}},
+		"no comments":    {"/api/blog/2/comments", http.StatusOK, []string{}},
+		"missing blog":   {"/api/blog/99/comments", http.StatusNotFound, nil},
+		"user comments":  {"/api/user/2/comments", http.StatusOK, []string{"Great post!"}},
+		"missing user":   {"/api/user/99/comments", http.StatusNotFound, nil},
+		"invalid offset": {"/api/blog/1/comments?offset=-1", http.StatusBadRequest, nil},
+	}
+
+	token := login(t, server.URL, "alice@example.com", "password123")
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL+tc.path, nil)
+			if err != nil {
+				t.Fatalf("Failed to create GET request: %v", err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Failed to make GET request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			assert.Equal(t, tc.wantStatus, resp.StatusCode, "Status code mismatch")
+
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+
+			var body struct {
+				Comments []struct {
+					Message string `json:"message"`
+				}
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+
+			messages := make([]string, 0, len(body.Comments))
+			for _, comment := range body.Comments {
+				messages = append(messages, comment.Message)
+			}
+			assert.Equal(t, tc.wantMessages, messages, "Comments mismatch")
+		})
+	}
+}
+
+func TestCreateAndDeleteComment(t *testing.T) {
+	t.Parallel()
+
+	server, db, err := newTestServer()
+	if err != nil {
+		t.Fatalf("Failed to create test server: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	token := login(t, server.URL, "carol@example.com", "carolpass789")
+
+	body, err := json.Marshal(map[string]any{"message": "Integration comment"})
+	if err != nil {
+		t.Fatalf("Failed to marshal comment: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(
+		t.Context(),
+		http.MethodPost,
+		server.URL+"/api/blog/2/comments",
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create POST request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to make POST request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusCreated, resp.StatusCode, "Status code mismatch")
+
+	var created struct {
+		ID     int `json:"id"`
+		BlogID int `json:"blogId"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	assert.Equal(t, 2, created.BlogID, "Blog ID mismatch")
+
+	// Deleting the comment through the wrong blog must not remove it
+	for _, tc := range []struct {
+		blogID     int
+		wantStatus int
+	}{
+		{1, http.StatusNotFound},
+		{2, http.StatusNoContent},
+		{2, http.StatusNotFound},
+	} {
+		req, err := http.NewRequestWithContext(
+			t.Context(),
+			http.MethodDelete,
+			fmt.Sprintf("%s/api/blog/%d/comments/%d", server.URL, tc.blogID, created.ID),
+			nil,
+		)
+		if err != nil {
+			t.Fatalf("Failed to create DELETE request: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to make DELETE request: %v", err)
+		}
+		resp.Body.Close()
+
+		assert.Equal(t, tc.wantStatus, resp.StatusCode, "Status code mismatch")
+	}
+
+	var count int
+	if err := db.Get(&count, "SELECT COUNT(*) FROM comments WHERE id = ?", created.ID); err != nil {
+		t.Fatalf("Failed to query comments from DB: %v", err)
+	}
+	assert.Equal(t, 0, count, "Expected comment to be deleted")
+}
+
+func TestVoteBlog(t *testing.T) {
+	t.Parallel()
+
+	server, db, err := newTestServer()
+	if err != nil {
+		t.Fatalf("Failed to create test server: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	// Votes are cast by the user the token was issued to, whatever the body
+	// says
+	bobToken := login(t, server.URL, "bob@example.com", "securepass456")
+	carolToken := login(t, server.URL, "carol@example.com", "carolpass789")
+
+	// Steps run in order against the same blog, starting from a score of 8.5
+	steps := []struct {
+		name       string
+		blogID     int
+		token      string
+		body       map[string]any
+		wantStatus int
+		wantScore  float32
+	}{
+		{"up vote", 1, bobToken, map[string]any{"direction": "up"}, http.StatusOK, 9.5},
+		{"down vote", 1, carolToken, map[string]any{"direction": "down"}, http.StatusOK, 8.5},
+		{"second vote", 1, bobToken, map[string]any{"direction": "down"}, http.StatusConflict, 8.5},
+		{"as another user", 1, bobToken, map[string]any{"userId": 4, "direction": "up"}, http.StatusConflict, 8.5},
+		{"missing blog", 99, bobToken, map[string]any{"direction": "up"}, http.StatusNotFound, 8.5},
+	}
+
+	for _, step := range steps {
+		body, err := json.Marshal(step.body)
+		if err != nil {
+			t.Fatalf("Failed to marshal vote: %v", err)
+		}
+
+		req, err := http.NewRequestWithContext(
+			t.Context(),
+			http.MethodPost,
+			fmt.Sprintf("%s/api/blog/%d/vote", server.URL, step.blogID),
+			bytes.NewReader(body),
+		)
+		if err != nil {
+			t.Fatalf("Failed to create POST request: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+step.token)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to make POST request: %v", err)
+		}
+		resp.Body.Close()
+
+		assert.Equal(t, step.wantStatus, resp.StatusCode, "%s: status code mismatch", step.name)
+
+		var score float32
+		if err := db.Get(&score, "SELECT score FROM blogs WHERE id = 1"); err != nil {
+			t.Fatalf("Failed to query blog score from DB: %v", err)
+		}
+		assert.InDelta(t, step.wantScore, score, 0.001, "%s: score mismatch", step.name)
+	}
+
+	var votes int
+	if err := db.Get(&votes, "SELECT COUNT(*) FROM votes WHERE blog_id = 1"); err != nil {
+		t.Fatalf("Failed to query votes from DB: %v", err)
+	}
+	assert.Equal(t, 2, votes, "Expected one vote per user")
+}
+
+func TestLogin(t *testing.T) {
+	t.Parallel()
+
+	server, _, err := newTestServer()
+	if err != nil {
+		t.Fatalf("Failed to create test server: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	tests := map[string]struct {
+		email      string
+		password   string
+		wantStatus int
+	}{
+		"valid credentials": {"bob@example.com", "securepass456", http.StatusOK},
+		"wrong password":    {"bob@example.com", "wrongpass", http.StatusUnauthorized},
+		"unknown email":     {"nobody@example.com", "securepass456", http.StatusUnauthorized},
+		"missing password":  {"bob@example.com", "", http.StatusBadRequest},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			body, err := json.Marshal(map[string]string{"email": tc.email, "password": tc.password})
+			if err != nil {
+				t.Fatalf("Failed to marshal login request: %v", err)
+			}
+
+			req, err := http.NewRequestWithContext(
+				t.Context(),
+				http.MethodPost,
+				server.URL+"/api/auth/login",
+				bytes.NewReader(body),
+			)
+			if err != nil {
+				t.Fatalf("Failed to create POST request: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Failed to make POST request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			assert.Equal(t, tc.wantStatus, resp.StatusCode, "Status code mismatch")
+
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+
+			var tokens struct {
+				TokenType    string `json:"tokenType"`
+				AccessToken  string `json:"accessToken"`
+				RefreshToken string `json:"refreshToken"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+
+			assert.Equal(t, "Bearer", tokens.TokenType, "Token type mismatch")
+			assert.NotEmpty(t, tokens.AccessToken, "Expected an access token")
+			assert.NotEmpty(t, tokens.RefreshToken, "Expected a refresh token")
+		})
+	}
+}
+
+func TestRefreshToken(t *testing.T) {
+	t.Parallel()
+
+	server, _, err := newTestServer()
+	if err != nil {
+		t.Fatalf("Failed to create test server: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	// Log in to get a token pair
+	body, err := json.Marshal(map[string]string{"email": "carol@example.com", "password": "carolpass789"})
+	if err != nil {
+		t.Fatalf("Failed to marshal login request: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(
+		t.Context(),
+		http.MethodPost,
+		server.URL+"/api/auth/login",
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create POST request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to make POST request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var tokens struct {
+		AccessToken  string `json:"accessToken"`
+		RefreshToken string `json:"refreshToken"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	tests := map[string]struct {
+		refreshToken string
+		wantStatus   int
+	}{
+		"refresh token":         {tokens.RefreshToken, http.StatusOK},
+		"access token rejected": {tokens.AccessToken, http.StatusUnauthorized},
+		"malformed token":       {"not-a-token", http.StatusUnauthorized},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			body, err := json.Marshal(map[string]string{"refreshToken": tc.refreshToken})
+			if err != nil {
+				t.Fatalf("Failed to marshal refresh request: %v", err)
+			}
+
+			req, err := http.NewRequestWithContext(
+				t.Context(),
+				http.MethodPost,
+				server.URL+"/api/auth/refresh",
+				bytes.NewReader(body),
+			)
+			if err != nil {
+				t.Fatalf("Failed to create POST request: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Failed to make POST request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			assert.Equal(t, tc.wantStatus, resp.StatusCode, "Status code mismatch")
+		})
+	}
+}
+
+func TestUserRoutesRequireAuthentication(t *testing.T) {
+	t.Parallel()
+
+	server, _, err := newTestServer()
+	if err != nil {
+		t.Fatalf("Failed to create test server: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	tests := map[string]struct {
+		authorization string
+		wantStatus    int
+	}{
+		"no token":        {"", http.StatusUnauthorized},
+		"wrong scheme":    {"Basic YWxpY2U6cGFzc3dvcmQxMjM=", http.StatusUnauthorized},
+		"malformed token": {"Bearer not-a-token", http.StatusUnauthorized},
+		"valid token": {
+			"Bearer " + login(t, server.URL, "dave@example.com", "davepass321"),
+			http.StatusOK,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL+"/api/user/1", nil)
+			if err != nil {
+				t.Fatalf("Failed to create GET request: %v", err)
+			}
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Failed to make GET request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			assert.Equal(t, tc.wantStatus, resp.StatusCode, "Status code mismatch")
+			if tc.wantStatus == http.StatusUnauthorized {
+				assert.Contains(t, resp.Header.Get("WWW-Authenticate"), "Bearer", "Expected a bearer challenge")
+			}
+		})
+	}
+}
+
+func TestUserRoutesAuthorization(t *testing.T) {
+	t.Parallel()
+
+	server, _, err := newTestServer()
+	if err != nil {
+		t.Fatalf("Failed to create test server: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	// Alice is an admin, Bob is a regular user
+	adminToken := login(t, server.URL, "alice@example.com", "password123")
+	userToken := login(t, server.URL, "bob@example.com", "securepass456")
+
+	updateBody := func(id int) string {
+		return fmt.Sprintf(`{"name":"Updated","email":"updated%d@example.com","password":"password123"}`, id)
+	}
+
+	tests := map[string]struct {
+		token      string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		"user lists users":        {userToken, http.MethodGet, "/api/user", "", http.StatusForbidden},
+		"admin lists users":       {adminToken, http.MethodGet, "/api/user", "", http.StatusOK},
+		"user reads another user": {userToken, http.MethodGet, "/api/user/3", "", http.StatusOK},
+		"user updates self":       {userToken, http.MethodPut, "/api/user/2", updateBody(2), http.StatusOK},
+		"user updates another":    {userToken, http.MethodPut, "/api/user/3", updateBody(3), http.StatusForbidden},
+		"admin updates another":   {adminToken, http.MethodPut, "/api/user/4", updateBody(4), http.StatusOK},
+		"user patches another":    {userToken, http.MethodPatch, "/api/user/3", `{"name":"Updated"}`, http.StatusForbidden},
+		"user deletes another":    {userToken, http.MethodDelete, "/api/user/3", "", http.StatusForbidden},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(
+				t.Context(),
+				tc.method,
+				server.URL+tc.path,
+				bytes.NewBufferString(tc.body),
+			)
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			assert.Equal(t, tc.wantStatus, resp.StatusCode, "Status code mismatch")
+
+			if tc.wantStatus != http.StatusForbidden {
+				return
+			}
+
+			var problem struct {
+				Title   string `json:"title"`
+				Status  int    `json:"status"`
+				TraceID string `json:"traceId"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&problem); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+
+			assert.Equal(t, "Forbidden", problem.Title, "Problem title mismatch")
+			assert.Equal(t, http.StatusForbidden, problem.Status, "Problem status mismatch")
+			assert.NotEmpty(t, problem.TraceID, "Expected a trace id")
+		})
+	}
+}
+
+func TestBlogRoutesAuthorization(t *testing.T) {
+	t.Parallel()
+
+	server, _, err := newTestServer()
+	if err != nil {
+		t.Fatalf("Failed to create test server: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	// Alice is an admin, Bob wrote blog 2 and comment 1, and Carol wrote
+	// neither
+	adminToken := login(t, server.URL, "alice@example.com", "password123")
+	authorToken := login(t, server.URL, "bob@example.com", "securepass456")
+	otherToken := login(t, server.URL, "carol@example.com", "carolpass789")
+
+	// Steps run in order, as later steps delete what earlier steps expect to
+	// still exist
+	steps := []struct {
+		name       string
+		token      string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"anonymous creates blog", "", http.MethodPost, "/api/blog", "", http.StatusUnauthorized},
+		{"anonymous deletes blog", "", http.MethodDelete, "/api/blog/2", "", http.StatusUnauthorized},
+		{"anonymous votes", "", http.MethodPost, "/api/blog/2/vote", "", http.StatusUnauthorized},
+		{"anonymous comments", "", http.MethodPost, "/api/blog/2/comments", "", http.StatusUnauthorized},
+		{"other updates blog", otherToken, http.MethodPut, "/api/blog/2", `{"title":"X"}`, http.StatusForbidden},
+		{"other deletes blog", otherToken, http.MethodDelete, "/api/blog/2", "", http.StatusForbidden},
+		{"other deletes comment", otherToken, http.MethodDelete, "/api/blog/1/comments/1", "", http.StatusForbidden},
+		{"other deletes missing", otherToken, http.MethodDelete, "/api/blog/99", "", http.StatusNotFound},
+		{"author updates blog", authorToken, http.MethodPut, "/api/blog/2", `{"authorId":1,"title":"Edited"}`, http.StatusOK},
+		// Naming another author does not give the blog away
+		{"author updates blog again", authorToken, http.MethodPut, "/api/blog/2", `{"title":"Again"}`, http.StatusOK},
+		{"author deletes comment", authorToken, http.MethodDelete, "/api/blog/1/comments/1", "", http.StatusNoContent},
+		{"admin deletes blog", adminToken, http.MethodDelete, "/api/blog/2", "", http.StatusNoContent},
+	}
+
+	for _, step := range steps {
+		req, err := http.NewRequestWithContext(
+			t.Context(),
+			step.method,
+			server.URL+step.path,
+			bytes.NewBufferString(step.body),
+		)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if step.token != "" {
+			req.Header.Set("Authorization", "Bearer "+step.token)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		resp.Body.Close()
+
+		assert.Equal(t, step.wantStatus, resp.StatusCode, "%s: status code mismatch", step.name)
+	}
 }